@@ -3,11 +3,12 @@ import { useAsync } from 'react-use';
 
 import { QueryEditorProps } from '@grafana/data';
 import { EditorMode } from '@grafana/experimental';
-import { Space } from '@grafana/ui';
+import { Button, Space, Stack } from '@grafana/ui';
 
 import { SqlDatasource } from '../datasource/SqlDatasource';
 import { applyQueryDefaults } from '../defaults';
-import { SQLQuery, QueryRowFilter, SQLOptions } from '../types';
+import { SQLQuery, QueryRowFilter, SQLOptions, QueryFormat } from '../types';
+import { getContinuationToken } from '../utils/chunks';
 import { haveColumns } from '../utils/sql.utils';
 
 import { QueryHeader, QueryHeaderProps } from './QueryHeader';
@@ -25,6 +26,7 @@ export function SqlQueryEditor({
   onRunQuery,
   range,
   queryHeaderProps,
+  data,
 }: SqlQueryEditorProps) {
   const [isQueryRunnable, setIsQueryRunnable] = useState(true);
   const db = datasource.getDB();
@@ -78,6 +80,14 @@ export function SqlQueryEditor({
     }
   };
 
+  // Chunked table queries read the next rows with the continuation token of the results
+  const continuationToken = getContinuationToken(data?.series, query.refId);
+  const isChunked = Boolean(query.chunkSize) && query.format === QueryFormat.Table;
+  const onReadChunk = (token?: string) => {
+    onChange({ ...query, continuationToken: token });
+    onRunQuery();
+  };
+
   const onQueryHeaderChange = (q: SQLQuery) => {
     setQueryToValidate(q);
     onChange(q);
@@ -125,6 +135,21 @@ export function SqlQueryEditor({
           range={range}
         />
       )}
+
+      {isChunked && (continuationToken || query.continuationToken) && (
+        <Stack gap={1}>
+          {query.continuationToken && (
+            <Button variant="secondary" size="sm" onClick={() => onReadChunk(undefined)}>
+              First rows
+            </Button>
+          )}
+          {continuationToken && (
+            <Button variant="secondary" size="sm" onClick={() => onReadChunk(continuationToken)}>
+              Next rows
+            </Button>
+          )}
+        </Stack>
+      )}
     </>
   );
 }
@@ -122,6 +122,8 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(target.rawSql, scopedVars, this.interpolateVariable),
       format: target.format,
+      chunkSize: target.chunkSize,
+      continuationToken: target.continuationToken,
     };
   }
 
@@ -59,6 +59,10 @@ export interface SQLQuery extends DataQuery {
   sql?: SQLExpression;
   editorMode?: EditorMode;
   rawQuery?: boolean;
+  /** Returns table results in frames of at most chunkSize rows */
+  chunkSize?: number;
+  /** Continues a chunked table query after the rows already read */
+  continuationToken?: string;
 }
 
 export interface NameValue {
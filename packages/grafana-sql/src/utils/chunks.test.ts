@@ -0,0 +1,19 @@
+import { toDataFrame } from '@grafana/data';
+
+import { getContinuationToken } from './chunks';
+
+describe('getContinuationToken', () => {
+  it('returns the token of the chunk of the query', () => {
+    const frames = [
+      toDataFrame({ refId: 'A', fields: [], meta: { custom: { offset: 0, continuationToken: 'next' } } }),
+      toDataFrame({ refId: 'B', fields: [], meta: { custom: { continuationToken: 'other' } } }),
+    ];
+    expect(getContinuationToken(frames, 'A')).toBe('next');
+  });
+
+  it('returns undefined when all rows were read', () => {
+    const frames = [toDataFrame({ refId: 'A', fields: [], meta: { custom: { offset: 0 } } })];
+    expect(getContinuationToken(frames, 'A')).toBeUndefined();
+    expect(getContinuationToken(undefined, 'A')).toBeUndefined();
+  });
+});
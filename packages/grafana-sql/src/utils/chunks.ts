@@ -0,0 +1,10 @@
+import { DataFrame } from '@grafana/data';
+
+/**
+ * Returns the continuation token of a chunked table query, set on the returned chunk when
+ * more rows are available.
+ */
+export function getContinuationToken(frames: DataFrame[] | undefined, refId: string): string | undefined {
+  const chunks = frames?.filter((frame) => frame.refId === refId) ?? [];
+  return chunks[chunks.length - 1]?.meta?.custom?.continuationToken;
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /admin/lockouts admin_lockouts adminGetLockouts
+//
+// Return the usernames and IP addresses that are locked out by brute force login protection.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminGetLockoutsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetLockouts(c *contextmodel.ReqContext) response.Response {
+	lockouts, err := hs.loginAttemptService.GetLockouts(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get lockouts", err)
+	}
+	return response.JSON(http.StatusOK, lockouts)
+}
+
+// swagger:route DELETE /admin/lockouts/users/{username} admin_lockouts adminDeleteUserLockout
+//
+// Clear the failed login attempts of a username, which lifts its lockout.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminDeleteUserLockout(c *contextmodel.ReqContext) response.Response {
+	username := web.Params(c.Req)[":username"]
+	if err := hs.loginAttemptService.Reset(c.Req.Context(), username); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to clear login attempts", err)
+	}
+	return response.Success("Login attempts cleared")
+}
+
+// swagger:route DELETE /admin/lockouts/ips/{ip} admin_lockouts adminDeleteIPLockout
+//
+// Clear the failed login attempts made from an IP address, which lifts its lockout.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminDeleteIPLockout(c *contextmodel.ReqContext) response.Response {
+	ipAddress := web.Params(c.Req)[":ip"]
+	if err := hs.loginAttemptService.ResetIP(c.Req.Context(), ipAddress); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to clear login attempts", err)
+	}
+	return response.Success("Login attempts cleared")
+}
+
+// swagger:parameters adminDeleteUserLockout
+type AdminDeleteUserLockoutParams struct {
+	// in:path
+	// required:true
+	Username string `json:"username"`
+}
+
+// swagger:parameters adminDeleteIPLockout
+type AdminDeleteIPLockoutParams struct {
+	// in:path
+	// required:true
+	IP string `json:"ip"`
+}
+
+// swagger:response adminGetLockoutsResponse
+type AdminGetLockoutsResponse struct {
+	// in:body
+	Body []*loginattempt.Lockout `json:"body"`
+}
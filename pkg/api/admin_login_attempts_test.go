@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAdminAPI_Lockouts(t *testing.T) {
+	lockedUntil := time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC)
+
+	type testCase struct {
+		desc         string
+		method       string
+		url          string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+		expectReset  bool
+		expectIP     bool
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should list lockouts with users:read",
+			method:       http.MethodGet,
+			url:          "/api/admin/lockouts",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersRead, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not list lockouts without users:read",
+			method:       http.MethodGet,
+			url:          "/api/admin/lockouts",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should clear the lockout of a username with users:write",
+			method:       http.MethodDelete,
+			url:          "/api/admin/lockouts/users/alice",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusOK,
+			expectReset:  true,
+		},
+		{
+			desc:         "should clear the lockout of an ip address with users:write",
+			method:       http.MethodDelete,
+			url:          "/api/admin/lockouts/ips/192.168.0.1",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusOK,
+			expectIP:     true,
+		},
+		{
+			desc:         "should not clear lockouts with users:read",
+			method:       http.MethodDelete,
+			url:          "/api/admin/lockouts/users/alice",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersRead, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			loginAttempts := &loginattempttest.MockLoginAttemptService{
+				ExpectedLockouts: []*loginattempt.Lockout{{Username: "alice", Attempts: 5, LockedUntil: lockedUntil}},
+			}
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.loginAttemptService = loginAttempts
+			})
+
+			req := webtest.RequestWithSignedInUser(server.NewRequest(tt.method, tt.url, nil), userWithPermissions(1, tt.permissions))
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.method == http.MethodGet && tt.expectedCode == http.StatusOK {
+				var lockouts []*loginattempt.Lockout
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&lockouts))
+				require.Len(t, lockouts, 1)
+				assert.Equal(t, "alice", lockouts[0].Username)
+				assert.True(t, lockedUntil.Equal(lockouts[0].LockedUntil))
+			}
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.expectReset, loginAttempts.ResetCalled)
+			assert.Equal(t, tt.expectIP, loginAttempts.ResetIPCalled)
+		})
+	}
+}
@@ -457,6 +457,35 @@ func (hs *HTTPServer) AdminRevokeUserAuthToken(c *contextmodel.ReqContext) respo
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route DELETE /admin/users/{user_id}/mfa admin_users adminResetUserMFA
+//
+// Reset multi-factor authentication for user.
+//
+// Removes the TOTP secret and the recovery codes of the user, for example when they lost their authenticator device. The user can set up multi-factor authentication again after logging in.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.password:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResetUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.mfaService.Disable(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset multi-factor authentication", err)
+	}
+
+	return response.Success("Multi-factor authentication reset")
+}
+
 // swagger:parameters adminUpdateUserPassword
 type AdminUpdateUserPasswordParams struct {
 	// in:body
@@ -512,6 +541,13 @@ type AdminRevokeUserAuthTokenParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters adminResetUserMFA
+type AdminResetUserMFAParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
 // swagger:parameters adminCreateUser
 type AdminCreateUserParams struct {
 	// in:body
@@ -72,10 +72,17 @@ func (hs *HTTPServer) registerRoutes() {
 	// not logged in views
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginPost))
+	r.Get("/login/saml", quota(string(auth.QuotaTargetSrv)), hs.SAMLLogin)
 	r.Get("/login/:name", quota(string(auth.QuotaTargetSrv)), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
 
+	// saml service provider endpoints
+	r.Get("/saml/metadata", hs.SAMLMetadata)
+	r.Post("/saml/acs", quota(string(auth.QuotaTargetSrv)), hs.SAMLACS)
+	r.Get("/saml/slo", hs.SAMLSLO)
+	r.Post("/saml/slo", hs.SAMLSLO)
+
 	// authed views
 	r.Get("/", reqSignedIn, hs.Index)
 	r.Get("/profile/", reqSignedInNoAnonymous, hs.Index)
@@ -269,6 +276,13 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RevokeUserAuthToken))
+			userRoute.Post("/revoke-other-auth-tokens", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RevokeOtherUserAuthTokens))
+
+			userRoute.Get("/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserMFAStatus))
+			userRoute.Post("/mfa/enroll", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.EnrollUserMFA))
+			userRoute.Post("/mfa/activate", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.ActivateUserMFA))
+			userRoute.Post("/mfa/recovery-codes", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RegenerateUserMFARecoveryCodes))
+			userRoute.Post("/mfa/disable", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.DisableUserMFA))
 		}, reqSignedInNoAnonymous)
 
 		apiRoute.Group("/users", func(usersRoute routing.RouteRegister) {
@@ -388,6 +402,7 @@ func (hs *HTTPServer) registerRoutes() {
 			datasourceRoute.Delete("/:id", authorize(ac.EvalPermission(datasources.ActionDelete, idScope)), routing.Wrap(hs.DeleteDataSourceById))
 			datasourceRoute.Delete("/uid/:uid", authorize(ac.EvalPermission(datasources.ActionDelete, uidScope)), routing.Wrap(hs.DeleteDataSourceByUID))
 			datasourceRoute.Delete("/name/:name", authorize(ac.EvalPermission(datasources.ActionDelete, nameScope)), routing.Wrap(hs.DeleteDataSourceByName))
+			datasourceRoute.Post("/uid/:uid/cache/clean", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(hs.CleanDataSourceCacheByUID))
 			datasourceRoute.Get("/:id", authorize(ac.EvalPermission(datasources.ActionRead, idScope)), routing.Wrap(hs.GetDataSourceById))
 			datasourceRoute.Get("/uid/:uid", authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(hs.GetDataSourceByUID))
 			datasourceRoute.Get("/name/:name", authorize(ac.EvalPermission(datasources.ActionRead, nameScope)), routing.Wrap(hs.GetDataSourceByName))
@@ -606,6 +621,10 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/datasources/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersNotifications)), routing.Wrap(hs.AdminProvisioningReloadNotifications))
 		adminRoute.Post("/provisioning/alerting/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningReloadAlerting))
+
+		adminRoute.Get("/lockouts", authorize(ac.EvalPermission(ac.ActionUsersRead, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminGetLockouts))
+		adminRoute.Delete("/lockouts/users/:username", authorize(ac.EvalPermission(ac.ActionUsersWrite, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminDeleteUserLockout))
+		adminRoute.Delete("/lockouts/ips/:ip", authorize(ac.EvalPermission(ac.ActionUsersWrite, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminDeleteIPLockout))
 	}, reqSignedIn)
 
 	// Administering users
@@ -624,6 +643,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Post("/:id/logout", authorize(ac.EvalPermission(ac.ActionUsersLogout, userIDScope)), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", authorize(ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", authorize(ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, userIDScope)), routing.Wrap(hs.AdminRevokeUserAuthToken))
+		adminUserRoute.Delete("/:id/mfa", authorize(ac.EvalPermission(ac.ActionUsersPasswordUpdate, userIDScope)), routing.Wrap(hs.AdminResetUserMFA))
 	}, reqSignedIn)
 
 	// rendering
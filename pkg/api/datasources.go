@@ -277,6 +277,43 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	})
 }
 
+// swagger:route POST /datasources/uid/{uid}/cache/clean datasources cleanDataSourceCacheByUID
+//
+// Remove the cached query and resource responses of a data source.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled
+// you need to have a permission with action: `datasources:write` and scopes: `datasources:*`, `datasources:uid:*` and `datasources:uid:kLtEtcRGk` (single data source).
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CleanDataSourceCacheByUID(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+
+	if uid == "" {
+		return response.Error(400, "Missing datasource uid", nil)
+	}
+
+	ds, err := hs.getRawDataSourceByUID(c.Req.Context(), uid, c.SignedInUser.GetOrgID())
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", nil)
+		}
+		return response.Error(500, "Failed to clean data source cache", err)
+	}
+
+	if hs.cacheInvalidator != nil {
+		if err := hs.cacheInvalidator.InvalidateDataSource(c.Req.Context(), ds.OrgID, ds.UID); err != nil {
+			return response.Error(500, "Failed to clean data source cache", err)
+		}
+	}
+
+	return response.Success("Data source cache cleaned")
+}
+
 // swagger:route DELETE /datasources/name/{name} datasources deleteDataSourceByName
 //
 // Delete an existing data source by name.
@@ -1011,6 +1048,13 @@ type CallDatasourceResourceWithUIDParams struct {
 	DatasourceUID string `json:"uid"`
 }
 
+// swagger:parameters cleanDataSourceCacheByUID
+type CleanDataSourceCacheByUIDParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"uid"`
+}
+
 // swagger:parameters deleteDataSourceByUID
 type DeleteDataSourceByUIDParams struct {
 	// in:path
@@ -466,6 +466,22 @@ func TestAPI_datasources_AccessControl(t *testing.T) {
 			permission:   []ac.Permission{},
 			expectedCode: http.StatusForbidden,
 		},
+		{
+			desc:   "should be able to clean datasource cache with correct permission",
+			urls:   []string{"/api/datasources/uid/1/cache/clean"},
+			method: http.MethodPost,
+			permission: []ac.Permission{
+				{Action: datasources.ActionWrite, Scope: datasources.ScopeProvider.GetResourceScopeUID("1")},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to clean datasource cache without correct permission",
+			urls:         []string{"/api/datasources/uid/1/cache/clean"},
+			method:       http.MethodPost,
+			permission:   []ac.Permission{},
+			expectedCode: http.StatusForbidden,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+const SAMLRequestIDCookieName = "saml_request_id"
+
+// SAMLLogin redirects the user to the identity provider with a new authentication request.
+func (hs *HTTPServer) SAMLLogin(reqCtx *contextmodel.ReqContext) {
+	req := &authn.Request{HTTPRequest: reqCtx.Req, Resp: reqCtx.Resp}
+	redirect, err := hs.authnService.RedirectURL(reqCtx.Req.Context(), authn.ClientSAML, req)
+	if err != nil {
+		reqCtx.Redirect(hs.redirectURLWithErrorCookie(reqCtx, err))
+		return
+	}
+
+	cookies.WriteCookie(reqCtx.Resp, SAMLRequestIDCookieName, redirect.Extra[authn.KeySAMLRequestID], hs.Cfg.OAuthCookieMaxAge, hs.samlCookieOptions)
+	reqCtx.Redirect(redirect.URL)
+}
+
+// SAMLACS is the assertion consumer service the identity provider posts its responses to.
+func (hs *HTTPServer) SAMLACS(reqCtx *contextmodel.ReqContext) {
+	req := &authn.Request{HTTPRequest: reqCtx.Req, Resp: reqCtx.Resp}
+	identity, err := hs.authnService.Login(reqCtx.Req.Context(), authn.ClientSAML, req)
+	// NOTE: always delete the request cookie, even if login failed
+	cookies.DeleteCookie(reqCtx.Resp, SAMLRequestIDCookieName, hs.samlCookieOptions)
+
+	if err != nil {
+		reqCtx.Redirect(hs.redirectURLWithErrorCookie(reqCtx, err))
+		return
+	}
+
+	metrics.MApiLoginSAML.Inc()
+	authn.HandleLoginRedirect(reqCtx.Req, reqCtx.Resp, hs.Cfg, identity, hs.ValidateRedirectTo)
+}
+
+// SAMLMetadata serves the service provider metadata to register Grafana with the identity provider.
+func (hs *HTTPServer) SAMLMetadata(reqCtx *contextmodel.ReqContext) {
+	metadata, err := hs.samlService.Metadata()
+	if err != nil {
+		reqCtx.JsonApiErr(http.StatusNotFound, "SAML metadata not available", err)
+		return
+	}
+
+	reqCtx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	reqCtx.Resp.WriteHeader(http.StatusOK)
+	if _, err := reqCtx.Resp.Write(metadata); err != nil {
+		hs.log.Error("Failed to write SAML metadata", "error", err)
+	}
+}
+
+// SAMLSLO completes a single logout initiated by Grafana once the identity provider responds.
+func (hs *HTTPServer) SAMLSLO(reqCtx *contextmodel.ReqContext) {
+	if err := reqCtx.Req.ParseForm(); err != nil || reqCtx.Req.Form.Get("SAMLResponse") == "" {
+		hs.redirectWithError(reqCtx, errors.New("logout requests initiated by the identity provider are not supported"))
+		return
+	}
+
+	if err := hs.samlService.ValidateLogoutResponse(reqCtx.Req); err != nil {
+		hs.redirectWithError(reqCtx, err)
+		return
+	}
+
+	reqCtx.Redirect(hs.Cfg.AppSubURL + "/login")
+}
+
+// samlCookieOptions relaxes the SameSite mode of the request cookie so that it is sent along
+// with the cross-site POST of the identity provider. Browsers only accept SameSite=None on secure cookies.
+func (hs *HTTPServer) samlCookieOptions() cookies.CookieOptions {
+	options := hs.CookieOptionsFromCfg()
+	if options.Secure {
+		options.SameSiteDisabled = false
+		options.SameSiteMode = http.SameSiteNoneMode
+	}
+	return options
+}
@@ -29,10 +29,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/licensing"
-	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	loginservice "github.com/grafana/grafana/pkg/services/login"
-	"github.com/grafana/grafana/pkg/services/login/authinfotest"
 	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/services/saml/samltest"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -111,6 +111,7 @@ func TestLoginErrorCookieAPIEndpoint(t *testing.T) {
 		Cfg:              cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
 		License:          &licensing.OSSLicensingService{},
+		samlService:      &samltest.FakeService{},
 		SocialService:    &mockSocialService{},
 		SecretsService:   secretsService,
 		Features:         featuremgmt.WithFeatures(),
@@ -158,6 +159,7 @@ func TestLoginViewRedirect(t *testing.T) {
 		Cfg:              cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
 		License:          &licensing.OSSLicensingService{},
+		samlService:      &samltest.FakeService{},
 		SocialService:    &mockSocialService{},
 		Features:         featuremgmt.WithFeatures(),
 		log:              log.NewNopLogger(),
@@ -330,6 +332,7 @@ func TestLoginPostRedirect(t *testing.T) {
 		Cfg:          setting.NewCfg(),
 		HooksService: &hooks.HooksService{},
 		License:      &licensing.OSSLicensingService{},
+		samlService:  &samltest.FakeService{},
 		authnService: &authntest.FakeService{
 			ExpectedIdentity: &authn.Identity{ID: "user:42", SessionToken: &usertoken.UserToken{}},
 		},
@@ -494,6 +497,7 @@ func TestLoginOAuthRedirect(t *testing.T) {
 		Cfg:              cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
 		License:          &licensing.OSSLicensingService{},
+		samlService:      &samltest.FakeService{},
 		SocialService:    mock,
 		Features:         featuremgmt.WithFeatures(),
 	}
@@ -519,10 +523,11 @@ func TestLoginInternal(t *testing.T) {
 	fakeViewIndex(t)
 	sc := setupScenarioContext(t, "/login")
 	hs := &HTTPServer{
-		Cfg:      setting.NewCfg(),
-		License:  &licensing.OSSLicensingService{},
-		log:      log.New("test"),
-		Features: featuremgmt.WithFeatures(),
+		Cfg:         setting.NewCfg(),
+		License:     &licensing.OSSLicensingService{},
+		samlService: &samltest.FakeService{},
+		log:         log.New("test"),
+		Features:    featuremgmt.WithFeatures(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
@@ -584,6 +589,7 @@ func TestAuthProxyLoginWithEnableLoginTokenAndEnabledOauthAutoLogin(t *testing.T
 		Cfg:              sc.cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: sc.cfg},
 		License:          &licensing.OSSLicensingService{},
+		samlService:      &samltest.FakeService{},
 		AuthTokenService: authtest.NewFakeUserAuthTokenService(),
 		log:              log.New("hello"),
 		SocialService:    mock,
@@ -624,6 +630,7 @@ func setupAuthProxyLoginTest(t *testing.T, enableLoginToken bool) *scenarioConte
 		Cfg:              sc.cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: sc.cfg},
 		License:          &licensing.OSSLicensingService{},
+		samlService:      &samltest.FakeService{},
 		AuthTokenService: authtest.NewFakeUserAuthTokenService(),
 		log:              log.New("hello"),
 		SocialService:    &mockSocialService{},
@@ -653,21 +660,18 @@ func TestLogoutSaml(t *testing.T) {
 	fakeSetIndexViewData(t)
 	fakeViewIndex(t)
 	sc := setupScenarioContextSamlLogout(t, "/logout")
-	license := licensingtest.NewFakeLicensing()
-	license.On("FeatureEnabled", "saml").Return(true)
 
 	hs := &HTTPServer{
-		Cfg:              sc.cfg,
-		SettingsProvider: &setting.OSSImpl{Cfg: sc.cfg},
-		License:          license,
-		SocialService:    &mockSocialService{},
-		Features:         featuremgmt.WithFeatures(),
-		authInfoService: &authinfotest.FakeService{
-			ExpectedUserAuth: &loginservice.UserAuth{AuthModule: loginservice.SAMLAuthModule},
+		Cfg:           sc.cfg,
+		log:           log.NewNopLogger(),
+		SocialService: &mockSocialService{},
+		Features:      featuremgmt.WithFeatures(),
+		authnService: &authntest.FakeService{
+			ExpectedRedirect: &authn.Redirect{URL: "https://idp.example.com/slo?SAMLRequest=request"},
 		},
+		samlService: &samltest.FakeService{ExpectedEnabled: true, ExpectedInfo: saml.Info{SingleLogout: true}},
 	}
 
-	assert.Equal(t, true, hs.samlSingleLogoutEnabled())
 	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
 		c.SignedInUser = &user.SignedInUser{
 			UserID: 1,
@@ -678,6 +682,7 @@ func TestLogoutSaml(t *testing.T) {
 	sc.m.Get(sc.url, sc.defaultHandler)
 	sc.fakeReqNoAssertions("GET", sc.url).exec()
 	require.Equal(t, 302, sc.resp.Code)
+	assert.Equal(t, "https://idp.example.com/slo?SAMLRequest=request", sc.resp.Header().Get("Location"))
 }
 
 type mockSocialService struct {
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -145,7 +146,7 @@ func (hs *HTTPServer) SignUpStep2(c *contextmodel.ReqContext) response.Response
 		apiResponse["code"] = "redirect-to-select-org"
 	}
 
-	err = hs.loginUserWithUser(usr, c)
+	err = hs.loginUserWithUser(usr, c, login.PasswordAuthModule)
 	if err != nil {
 		return response.Error(500, "failed to login user", err)
 	}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /user/mfa signed_in_user getUserMFAStatus
+//
+// Get the multi-factor authentication status of the signed in user.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUserMFAStatusResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetUserMFAStatus(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	status, err := hs.mfaService.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /user/mfa/enroll signed_in_user enrollUserMFA
+//
+// Generate a new TOTP secret for the signed in user.
+//
+// The secret has to be activated with a code generated by an authenticator app before it is used.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: enrollUserMFAResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) EnrollUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	enrollment, err := hs.mfaService.Enroll(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set up multi-factor authentication", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// swagger:route POST /user/mfa/activate signed_in_user activateUserMFA
+//
+// Activate the TOTP secret of the signed in user.
+//
+// Returns the recovery codes of the user, they are not shown again.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: userMFARecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) ActivateUserMFA(c *contextmodel.ReqContext) response.Response {
+	cmd := UserMFACodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	codes, err := hs.mfaService.Activate(c.Req.Context(), userID, cmd.Code)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to activate multi-factor authentication", err)
+	}
+	return response.JSON(http.StatusOK, UserMFARecoveryCodes{RecoveryCodes: codes})
+}
+
+// swagger:route POST /user/mfa/recovery-codes signed_in_user regenerateUserMFARecoveryCodes
+//
+// Replace the recovery codes of the signed in user.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: userMFARecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RegenerateUserMFARecoveryCodes(c *contextmodel.ReqContext) response.Response {
+	cmd := UserMFACodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	if errResponse := hs.verifyUserMFACode(c, userID, cmd.Code); errResponse != nil {
+		return errResponse
+	}
+
+	codes, err := hs.mfaService.RegenerateRecoveryCodes(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, UserMFARecoveryCodes{RecoveryCodes: codes})
+}
+
+// swagger:route POST /user/mfa/disable signed_in_user disableUserMFA
+//
+// Remove the TOTP secret and the recovery codes of the signed in user.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DisableUserMFA(c *contextmodel.ReqContext) response.Response {
+	cmd := UserMFACodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	if errResponse := hs.verifyUserMFACode(c, userID, cmd.Code); errResponse != nil {
+		return errResponse
+	}
+
+	if err := hs.mfaService.Disable(c.Req.Context(), userID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable multi-factor authentication", err)
+	}
+	return response.Success("Multi-factor authentication disabled")
+}
+
+// verifyUserMFACode checks a code of the signed in user. Invalid codes count as failed login
+// attempts, so they can't be guessed with the session of a user.
+func (hs *HTTPServer) verifyUserMFACode(c *contextmodel.ReqContext, userID int64, code string) response.Response {
+	login := c.SignedInUser.GetLogin()
+	ip := loginattempt.ClientIP(c.Req, hs.Cfg.BruteForceLoginProtection.TrustedProxies)
+
+	ok, err := hs.loginAttemptService.Validate(c.Req.Context(), login, ip)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to verify authentication code", err)
+	}
+	if !ok {
+		return response.Err(mfa.ErrTooManyAttempts.Errorf("too many incorrect codes for user %d", userID))
+	}
+
+	if err := hs.mfaService.Verify(c.Req.Context(), userID, code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			_ = hs.loginAttemptService.Add(c.Req.Context(), login, ip)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to verify authentication code", err)
+	}
+	return nil
+}
+
+// UserMFACodeCommand holds a TOTP code, or a recovery code.
+type UserMFACodeCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type UserMFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// swagger:parameters activateUserMFA regenerateUserMFARecoveryCodes disableUserMFA
+type UserMFACodeParams struct {
+	// in:body
+	// required:true
+	Body UserMFACodeCommand `json:"body"`
+}
+
+// swagger:response getUserMFAStatusResponse
+type GetUserMFAStatusResponse struct {
+	// in: body
+	Body mfa.Status `json:"body"`
+}
+
+// swagger:response enrollUserMFAResponse
+type EnrollUserMFAResponse struct {
+	// in: body
+	Body mfa.Enrollment `json:"body"`
+}
+
+// swagger:response userMFARecoveryCodesResponse
+type UserMFARecoveryCodesResponse struct {
+	// in: body
+	Body UserMFARecoveryCodes `json:"body"`
+}
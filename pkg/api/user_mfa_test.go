@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestUserMFAAPI_VerifyCode(t *testing.T) {
+	type testCase struct {
+		desc         string
+		url          string
+		valid        bool
+		verifyErr    error
+		expectedCode int
+		expectAdd    bool
+	}
+
+	tests := []testCase{
+		{
+			desc:         "should disable multi-factor authentication with a valid code",
+			url:          "/api/user/mfa/disable",
+			valid:        true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should count an invalid code when disabling multi-factor authentication",
+			url:          "/api/user/mfa/disable",
+			valid:        true,
+			verifyErr:    mfa.ErrInvalidCode.Errorf("invalid code"),
+			expectedCode: http.StatusUnauthorized,
+			expectAdd:    true,
+		},
+		{
+			desc:         "should count an invalid code when regenerating recovery codes",
+			url:          "/api/user/mfa/recovery-codes",
+			valid:        true,
+			verifyErr:    mfa.ErrInvalidCode.Errorf("invalid code"),
+			expectedCode: http.StatusUnauthorized,
+			expectAdd:    true,
+		},
+		{
+			desc:         "should not verify codes of a locked out user",
+			url:          "/api/user/mfa/recovery-codes",
+			valid:        false,
+			expectedCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: tt.valid}
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.loginAttemptService = loginAttempts
+				hs.mfaService = &mfatest.FakeService{ExpectedErr: tt.verifyErr, ExpectedRecoveryCodes: []string{"abcde-fghij"}}
+			})
+
+			req := server.NewRequest(http.MethodPost, tt.url, strings.NewReader(`{"code":"123456"}`))
+			req.Header.Set("Content-Type", "application/json")
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, Login: "alice"}))
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.True(t, loginAttempts.ValidateCalled)
+			assert.Equal(t, tt.expectAdd, loginAttempts.AddCalled)
+		})
+	}
+}
@@ -76,6 +76,42 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /user/revoke-other-auth-tokens signed_in_user revokeOtherUserAuthTokens
+//
+// Revoke the other auth tokens of the actual User.
+//
+// Revokes all auth tokens (devices) of the actual user except the one of the current session. The user will be signed out everywhere else.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeOtherUserAuthTokens(c *contextmodel.ReqContext) response.Response {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	if c.UserToken == nil {
+		return response.Error(http.StatusBadRequest, "Request is not authenticated with a user auth token", nil)
+	}
+
+	if err := hs.AuthTokenService.RevokeOtherUserTokens(c.Req.Context(), userID, c.UserToken.Id); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to revoke user auth tokens", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Other user auth tokens revoked",
+	})
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -185,13 +221,13 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 	}
 
 	result := []*dtos.UserToken{}
+	parser := uaparser.NewFromSaved()
 	for _, token := range tokens {
 		isActive := false
 		if c.UserToken != nil && c.UserToken.Id == token.Id {
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
 		client := parser.Parse(token.UserAgent)
 
 		osVersion := ""
@@ -228,6 +264,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 			OperatingSystemVersion: osVersion,
 			Browser:                client.UserAgent.Family,
 			BrowserVersion:         browserVersion,
+			AuthModule:             token.AuthModule,
 			CreatedAt:              createdAt,
 			SeenAt:                 seenAt,
 		})
@@ -93,17 +93,37 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 		}, mockUser)
 	})
 
+	t.Run("When revoking the other auth tokens of the current user", func(t *testing.T) {
+		var revokedUserID, keptTokenID int64
+		revokeOtherUserAuthTokensScenario(t, "Should revoke all tokens except the current one", &auth.UserToken{Id: 2}, func(sc *scenarioContext) {
+			sc.userAuthTokenService.RevokeOtherUserTokensProvider = func(ctx context.Context, userID, tokenID int64) error {
+				revokedUserID, keptTokenID = userID, tokenID
+				return nil
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, testUserID, revokedUserID)
+			assert.Equal(t, int64(2), keptTokenID)
+		})
+
+		revokeOtherUserAuthTokensScenario(t, "Should not be successful without a session", nil, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	})
+
 	t.Run("When gets auth tokens for a user", func(t *testing.T) {
 		currentToken := &auth.UserToken{Id: 1}
 		mockUser := usertest.NewUserServiceFake()
 		getUserAuthTokensInternalScenario(t, "Should be successful", currentToken, func(sc *scenarioContext) {
 			tokens := []*auth.UserToken{
 				{
-					Id:        1,
-					ClientIp:  "127.0.0.1",
-					UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/72.0.3626.119 Safari/537.36",
-					CreatedAt: time.Now().Unix(),
-					SeenAt:    time.Now().Unix(),
+					Id:         1,
+					ClientIp:   "127.0.0.1",
+					UserAgent:  "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/72.0.3626.119 Safari/537.36",
+					CreatedAt:  time.Now().Unix(),
+					SeenAt:     time.Now().Unix(),
+					AuthModule: "oauth_github",
 				},
 				{
 					Id:        2,
@@ -134,6 +154,7 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "72.0", resultOne.Get("browserVersion").MustString())
 			assert.Equal(t, "Linux", resultOne.Get("os").MustString())
 			assert.Empty(t, resultOne.Get("osVersion").MustString())
+			assert.Equal(t, "oauth_github", resultOne.Get("authModule").MustString())
 
 			resultTwo := result.GetIndex(1)
 			assert.Equal(t, tokens[1].Id, resultTwo.Get("id").MustInt64())
@@ -345,6 +366,30 @@ func revokeUserAuthTokenInternalScenario(t *testing.T, desc string, cmd auth.Rev
 	})
 }
 
+func revokeOtherUserAuthTokensScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.OrgRole = org.RoleAdmin
+			sc.context.UserToken = token
+
+			return hs.RevokeOtherUserAuthTokens(c)
+		})
+		sc.m.Post("/", sc.defaultHandler)
+		fn(sc)
+	})
+}
+
 func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc, userService user.Service) {
 	t.Run(desc, func(t *testing.T) {
 		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
@@ -0,0 +1,192 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// ChainedVariablesKey is the property of a data source query that binds its template
+// variables to the results of other queries in the same request.
+const ChainedVariablesKey = "chainedVariables"
+
+// Formats of the values of a chained variable.
+const (
+	ChainedFormatCSV       = "csv"
+	ChainedFormatPipe      = "pipe"
+	ChainedFormatRegex     = "regex"
+	ChainedFormatSQLString = "sqlstring"
+)
+
+// ChainedVariable binds a template variable of a data source query to the label values or
+// the field values of the results of another query or expression.
+type ChainedVariable struct {
+	// Name of the variable, referenced in the query as $name, ${name} or [[name]]
+	Name string `json:"name"`
+	// RefID of the query or expression providing the values
+	RefID string `json:"refId"`
+	// Label reads the values of the label from every series or number of the results
+	Label string `json:"label,omitempty"`
+	// Field reads the values of the field from the results
+	Field string `json:"field,omitempty"`
+	// Format joins the values, defaults to csv
+	Format string `json:"format,omitempty"`
+}
+
+// parseChainedVariables reads the chained variables of a query and removes them from the
+// query, as data sources do not need to know about them.
+func parseChainedVariables(refID string, query map[string]any) ([]ChainedVariable, error) {
+	raw, ok := query[ChainedVariablesKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(query, ChainedVariablesKey)
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var vars []ChainedVariable
+	if err := json.Unmarshal(b, &vars); err != nil {
+		return nil, fmt.Errorf("invalid chained variables in query '%v': %w", refID, err)
+	}
+
+	for _, v := range vars {
+		if v.Name == "" || v.RefID == "" {
+			return nil, fmt.Errorf("chained variables in query '%v' need a name and a refId", refID)
+		}
+		if (v.Label == "") == (v.Field == "") {
+			return nil, fmt.Errorf("chained variable '%v' in query '%v' needs either a label or a field", v.Name, refID)
+		}
+		switch v.Format {
+		case "", ChainedFormatCSV, ChainedFormatPipe, ChainedFormatRegex, ChainedFormatSQLString:
+		default:
+			return nil, fmt.Errorf("chained variable '%v' in query '%v' has unsupported format '%v'", v.Name, refID, v.Format)
+		}
+	}
+	return vars, nil
+}
+
+// values returns the sorted distinct values of the variable in the results.
+func (v ChainedVariable) values(res mathexp.Results) []string {
+	seen := map[string]bool{}
+	for _, val := range res.Values {
+		if val == nil || val.Type() == parse.TypeNoData {
+			continue
+		}
+		if v.Label != "" {
+			if lv, ok := val.GetLabels()[v.Label]; ok {
+				seen[lv] = true
+			}
+			continue
+		}
+
+		frame := val.AsDataFrame()
+		if frame == nil {
+			continue
+		}
+		for _, field := range frame.Fields {
+			if field.Name != v.Field {
+				continue
+			}
+			for i := 0; i < field.Len(); i++ {
+				if fv, ok := field.ConcreteAt(i); ok {
+					seen[fmt.Sprint(fv)] = true
+				}
+			}
+		}
+	}
+
+	values := make([]string, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// sqlStringReplacer escapes quotes and backslashes, as MySQL reads \' as a quote inside
+// the string.
+var sqlStringReplacer = strings.NewReplacer(`'`, `''`, `\`, `\\`)
+
+// formatChainedValues joins the values the way the format expects them in a query.
+func formatChainedValues(format string, values []string) string {
+	switch format {
+	case ChainedFormatPipe:
+		return strings.Join(values, "|")
+	case ChainedFormatRegex:
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = regexp.QuoteMeta(v)
+		}
+		if len(quoted) == 1 {
+			return quoted[0]
+		}
+		return "(" + strings.Join(quoted, "|") + ")"
+	case ChainedFormatSQLString:
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = "'" + sqlStringReplacer.Replace(v) + "'"
+		}
+		return strings.Join(quoted, ",")
+	default:
+		return strings.Join(values, ",")
+	}
+}
+
+// bindChainedVariables returns the query with the chained variables replaced by the values
+// of the results they reference. It returns false if a variable has no values.
+func bindChainedVariables(query json.RawMessage, chained []ChainedVariable, vars mathexp.Vars) (json.RawMessage, bool, error) {
+	replacers := make([]chainedReplacer, 0, len(chained))
+	for _, v := range chained {
+		values := v.values(vars[v.RefID])
+		if len(values) == 0 {
+			return nil, false, nil
+		}
+		name := regexp.QuoteMeta(v.Name)
+		replacers = append(replacers, chainedReplacer{
+			re:    regexp.MustCompile(`\$\{` + name + `\}|\[\[` + name + `\]\]|\$` + name + `\b`),
+			value: formatChainedValues(v.Format, values),
+		})
+	}
+
+	var model any
+	if err := json.Unmarshal(query, &model); err != nil {
+		return nil, false, err
+	}
+	bound, err := json.Marshal(replaceChainedVariables(model, replacers))
+	return bound, true, err
+}
+
+type chainedReplacer struct {
+	re    *regexp.Regexp
+	value string
+}
+
+// replaceChainedVariables replaces the variables in every string of the query model.
+func replaceChainedVariables(model any, replacers []chainedReplacer) any {
+	switch m := model.(type) {
+	case string:
+		for _, r := range replacers {
+			m = r.re.ReplaceAllLiteralString(m, r.value)
+		}
+		return m
+	case map[string]any:
+		for k, v := range m {
+			m[k] = replaceChainedVariables(v, replacers)
+		}
+		return m
+	case []any:
+		for i, v := range m {
+			m[i] = replaceChainedVariables(v, replacers)
+		}
+		return m
+	default:
+		return model
+	}
+}
@@ -0,0 +1,214 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/config"
+	"github.com/grafana/grafana/pkg/plugins/manager/fakes"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// recordingEndpoint returns the responses of mockEndpoint and records the queries it receives.
+type recordingEndpoint struct {
+	mockEndpoint
+	queries map[string]json.RawMessage
+}
+
+func (re *recordingEndpoint) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	for _, q := range req.Queries {
+		re.queries[q.RefID] = q.JSON
+	}
+	return re.mockEndpoint.QueryData(ctx, req)
+}
+
+func TestChainedQueries(t *testing.T) {
+	series := func(instance string, v float64) *data.Frame {
+		return data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"instance": instance}, []*float64{fp(v)}))
+	}
+
+	newService := func(t *testing.T, features featuremgmt.FeatureToggles, responses map[string]backend.DataResponse) (*Service, *recordingEndpoint) {
+		t.Helper()
+		endpoint := &recordingEndpoint{
+			mockEndpoint: mockEndpoint{Responses: responses},
+			queries:      map[string]json.RawMessage{},
+		}
+		pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+			PluginList: []pluginstore.Plugin{
+				{JSONData: plugins.JSONData{ID: "prometheus"}},
+				{JSONData: plugins.JSONData{ID: "mysql"}},
+			},
+		}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, fakes.NewFakeLicensingService(), &config.Cfg{})
+
+		return &Service{
+			cfg:          setting.NewCfg(),
+			dataService:  endpoint,
+			pCtxProvider: pCtxProvider,
+			features:     features,
+			tracer:       tracing.InitializeTracerForTest(),
+			metrics:      newMetrics(nil),
+		}, endpoint
+	}
+
+	queries := func(chained string) []Query {
+		return []Query{
+			{
+				RefID:      "B",
+				DataSource: &datasources.DataSource{OrgID: 1, UID: "sql", Type: "mysql"},
+				JSON: json.RawMessage(`{
+					"rawSql": "SELECT * FROM hosts WHERE name IN ($instance)",
+					"chainedVariables": ` + chained + `
+				}`),
+				TimeRange: AbsoluteTimeRange{},
+			},
+			{
+				RefID:      "A",
+				DataSource: &datasources.DataSource{OrgID: 1, UID: "prom", Type: "prometheus"},
+				JSON:       json.RawMessage(`{ "expr": "up" }`),
+				TimeRange:  AbsoluteTimeRange{},
+			},
+		}
+	}
+
+	for name, features := range map[string]featuremgmt.FeatureToggles{
+		"sequential":             featuremgmt.WithFeatures(),
+		"grouped by data source": featuremgmt.WithFeatures(featuremgmt.FlagSseGroupByDatasource),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("binds label values of another query", func(t *testing.T) {
+				s, endpoint := newService(t, features, map[string]backend.DataResponse{
+					"A": {Frames: data.Frames{series("b", 1), series("a", 2), series("a'", 3), series(`c\'`, 4)}},
+					"B": {Frames: data.Frames{series("a", 1)}},
+				})
+
+				pl, err := s.BuildPipeline(&Request{Queries: queries(`[{"name":"instance","refId":"A","label":"instance","format":"sqlstring"}]`), User: &user.SignedInUser{}})
+				require.NoError(t, err)
+				require.Equal(t, "A", pl[0].RefID())
+				require.Equal(t, []string{"A"}, pl[1].NeedsVars())
+
+				res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+				require.NoError(t, err)
+				require.NoError(t, res.Responses["B"].Error)
+				require.JSONEq(t, `{"rawSql":"SELECT * FROM hosts WHERE name IN ('a','a''','b','c\\\\''')"}`, string(endpoint.queries["B"]))
+			})
+
+			t.Run("binds field values of a table", func(t *testing.T) {
+				table := data.NewFrame("",
+					data.NewField("host", nil, []string{"b", "a"}),
+					data.NewField("region", nil, []string{"eu", "us"}))
+				s, endpoint := newService(t, features, map[string]backend.DataResponse{
+					"A": {Frames: data.Frames{table}},
+					"B": {Frames: data.Frames{series("a", 1)}},
+				})
+
+				pl, err := s.BuildPipeline(&Request{Queries: queries(`[{"name":"instance","refId":"A","field":"host","format":"sqlstring"}]`), User: &user.SignedInUser{}})
+				require.NoError(t, err)
+
+				res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+				require.NoError(t, err)
+				require.NoError(t, res.Responses["A"].Error)
+				require.Equal(t, table, res.Responses["A"].Frames[0])
+				require.NoError(t, res.Responses["B"].Error)
+				require.JSONEq(t, `{"rawSql":"SELECT * FROM hosts WHERE name IN ('a','b')"}`, string(endpoint.queries["B"]))
+			})
+
+			t.Run("binds field values of a long series", func(t *testing.T) {
+				long := data.NewFrame("",
+					data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(1, 0)}),
+					data.NewField("host", nil, []string{"b", "a"}),
+					data.NewField("value", nil, []float64{1, 2}))
+				s, endpoint := newService(t, features, map[string]backend.DataResponse{
+					"A": {Frames: data.Frames{long}},
+					"B": {Frames: data.Frames{series("a", 1)}},
+				})
+
+				pl, err := s.BuildPipeline(&Request{Queries: queries(`[{"name":"instance","refId":"A","field":"host","format":"sqlstring"}]`), User: &user.SignedInUser{}})
+				require.NoError(t, err)
+
+				res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+				require.NoError(t, err)
+				require.NoError(t, res.Responses["A"].Error)
+				require.NoError(t, res.Responses["B"].Error)
+				require.JSONEq(t, `{"rawSql":"SELECT * FROM hosts WHERE name IN ('a','b')"}`, string(endpoint.queries["B"]))
+			})
+
+			t.Run("skips the query when the variable has no values", func(t *testing.T) {
+				s, endpoint := newService(t, features, map[string]backend.DataResponse{
+					"A": {Frames: data.Frames{}},
+				})
+
+				pl, err := s.BuildPipeline(&Request{Queries: queries(`[{"name":"instance","refId":"A","label":"instance"}]`), User: &user.SignedInUser{}})
+				require.NoError(t, err)
+				res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+				require.NoError(t, err)
+				require.NoError(t, res.Responses["B"].Error)
+				require.NotContains(t, endpoint.queries, "B")
+			})
+		})
+	}
+
+	t.Run("invalid bindings are rejected", func(t *testing.T) {
+		s, _ := newService(t, featuremgmt.WithFeatures(), nil)
+		for name, chained := range map[string]string{
+			"missing query":      `[{"name":"instance","refId":"C","label":"instance"}]`,
+			"own results":        `[{"name":"instance","refId":"B","label":"instance"}]`,
+			"no label or field":  `[{"name":"instance","refId":"A"}]`,
+			"label and field":    `[{"name":"instance","refId":"A","label":"instance","field":"host"}]`,
+			"unsupported format": `[{"name":"instance","refId":"A","label":"instance","format":"glob"}]`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := s.BuildPipeline(&Request{Queries: queries(chained), User: &user.SignedInUser{}})
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestBindChainedVariables(t *testing.T) {
+	number := func(v float64) mathexp.Value {
+		n := mathexp.NewNumber("id", data.Labels{"host": "web"})
+		n.SetValue(fp(v))
+		return n
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{number(8), number(7), number(8)}}}
+
+	for format, expected := range map[string]string{
+		"":                     "7,8",
+		ChainedFormatPipe:      "7|8",
+		ChainedFormatRegex:     "(7|8)",
+		ChainedFormatSQLString: "'7','8'",
+	} {
+		t.Run("format "+format, func(t *testing.T) {
+			chained := []ChainedVariable{{Name: "id", RefID: "A", Field: "id", Format: format}}
+			bound, ok, err := bindChainedVariables(json.RawMessage(`{"expr":"up{id=~\"$id\"}","targets":["${id}","[[id]]","$idx"]}`), chained, vars)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			var model map[string]any
+			require.NoError(t, json.Unmarshal(bound, &model))
+			require.Equal(t, `up{id=~"`+expected+`"}`, model["expr"])
+			require.Equal(t, []any{expected, expected, "$idx"}, model["targets"])
+		})
+	}
+
+	t.Run("regex values are escaped", func(t *testing.T) {
+		require.Equal(t, `web\.1`, formatChainedValues(ChainedFormatRegex, []string{"web.1"}))
+	})
+}
@@ -77,17 +77,21 @@ func (gm *MathCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Va
 
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
-	Reducer      string
-	VarToReduce  string
+	Reducer     string
+	VarToReduce string
+	// Quantile is the quantile used by the histogram_quantile reducer.
+	Quantile     float64
 	refID        string
 	seriesMapper mathexp.ReduceMapper
 }
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
-	if err != nil {
-		return nil, err
+	if !mathexp.IsHistogramReduceFunc(reducer) {
+		_, err := mathexp.GetReduceFunc(reducer)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &ReduceCommand{
@@ -120,10 +124,18 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	}
 
 	var mapper mathexp.ReduceMapper = nil
+	var quantile *float64
 	settings, ok := rn.Query["settings"]
 	if ok {
 		switch s := settings.(type) {
 		case map[string]any:
+			if rawQuantile, ok := s["quantile"]; ok {
+				q, ok := rawQuantile.(float64)
+				if !ok {
+					return nil, fmt.Errorf("setting quantile must be a number, got %T", rawQuantile)
+				}
+				quantile = &q
+			}
 			mode, ok := s["mode"]
 			if ok && mode != "" {
 				switch mode {
@@ -148,7 +160,18 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	if strings.EqualFold(redFunc, mathexp.ReducerHistogramQuantile) && (quantile == nil || *quantile < 0 || *quantile > 1) {
+		return nil, fmt.Errorf("setting quantile must be a number between 0 and 1 when reducer is '%s'", mathexp.ReducerHistogramQuantile)
+	}
+
+	cmd, err := NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	if err != nil {
+		return nil, err
+	}
+	if quantile != nil {
+		cmd.Quantile = *quantile
+	}
+	return cmd, nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -174,6 +197,12 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 				return newRes, err
 			}
 			newRes.Values = append(newRes.Values, num)
+		case mathexp.Histogram:
+			num, err := v.Reduce(gr.refID, gr.Reducer, gr.Quantile)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, num)
 		case mathexp.Number: // if incoming vars is just a number, any reduce op is just a noop, add it as it is
 			value := v.GetFloat64Value()
 			if gr.seriesMapper != nil {
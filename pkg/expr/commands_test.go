@@ -99,6 +99,27 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_HistogramQuantile(t *testing.T) {
+	unmarshal := func(q string) (*ReduceCommand, error) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+		return UnmarshalReduceCommand(&rawNode{RefID: "B", Query: qmap})
+	}
+
+	cmd, err := unmarshal(`{ "expression" : "$A", "reducer": "histogram_quantile", "settings": { "quantile": 0.99 } }`)
+	require.NoError(t, err)
+	require.Equal(t, 0.99, cmd.Quantile)
+
+	_, err = unmarshal(`{ "expression" : "$A", "reducer": "histogram_quantile" }`)
+	require.Error(t, err)
+
+	_, err = unmarshal(`{ "expression" : "$A", "reducer": "histogram_quantile", "settings": { "quantile": 2 } }`)
+	require.Error(t, err)
+
+	_, err = unmarshal(`{ "expression" : "$A", "reducer": "histogram_quantile", "settings": { "quantile": "0.5" } }`)
+	require.Error(t, err)
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
@@ -210,6 +231,40 @@ func TestReduceExecute(t *testing.T) {
 	})
 }
 
+func TestReduceExecute_Histogram(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	frame := data.NewFrame("",
+		data.NewField("xMax", nil, []time.Time{ts, ts}),
+		data.NewField("yMin", data.Labels{"job": "api"}, []float64{0, 1}),
+		data.NewField("yMax", nil, []float64{1, 2}),
+		data.NewField("count", nil, []float64{2, 2}),
+	).SetMeta(&data.FrameMeta{Type: mathexp.HistogramFrameType})
+	h, err := mathexp.HistogramFromFrame(frame)
+	require.NoError(t, err)
+
+	vars := map[string]mathexp.Results{
+		"A": {Values: mathexp.Values{h}},
+	}
+
+	cmd, err := NewReduceCommand("B", mathexp.ReducerHistogramQuantile, "A", nil)
+	require.NoError(t, err)
+	cmd.Quantile = 0.75
+
+	results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Len(t, results.Values, 1)
+	n := results.Values[0].Value().(*mathexp.Number)
+	require.Equal(t, 1.5, *n.GetFloat64Value())
+	require.Equal(t, data.Labels{"job": "api"}, n.GetLabels())
+
+	t.Run("series reducers are not supported for histograms", func(t *testing.T) {
+		cmd, err := NewReduceCommand("B", "mean", "A", nil)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
+
 func randomReduceFunc() string {
 	res := mathexp.GetSupportedReduceFuncs()
 	return res[rand.Intn(len(res))]
@@ -66,7 +66,7 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 	if groupByDSFlag {
 		dsNodes := []*DSNode{}
 		for _, node := range *dp {
-			if node.NodeType() != TypeDatasourceNode {
+			if node.NodeType() != TypeDatasourceNode || len(node.NeedsVars()) > 0 {
 				continue
 			}
 			dsNodes = append(dsNodes, node.(*DSNode))
@@ -76,7 +76,7 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 	}
 
 	for _, node := range *dp {
-		if groupByDSFlag && node.NodeType() == TypeDatasourceNode {
+		if groupByDSFlag && node.NodeType() == TypeDatasourceNode && len(node.NeedsVars()) == 0 {
 			continue // already executed via executeDSNodesGrouped
 		}
 
@@ -255,9 +255,25 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 	for nodeIt.Next() {
 		node := nodeIt.Node().(Node)
 
+		if dsNode, ok := node.(*DSNode); ok {
+			// datasource queries depend on the results their chained variables are bound to
+			for _, neededVar := range dsNode.NeedsVars() {
+				neededNode, ok := registry[neededVar]
+				if !ok {
+					return fmt.Errorf("unable to find dependent node '%v'", neededVar)
+				}
+				if neededNode.ID() == dsNode.ID() {
+					return fmt.Errorf("query '%v' cannot bind variables to its own results", neededVar)
+				}
+				if neededDSNode, ok := neededNode.(*DSNode); ok {
+					neededDSNode.chainSource = true
+				}
+				dp.SetEdge(dp.NewEdge(neededNode, dsNode))
+			}
+			continue
+		}
+
 		if node.NodeType() != TypeCMDNode {
-			// datasource node, nothing to do for now. Although if we want expression results to be
-			// used as datasource query params some day this will need change
 			continue
 		}
 
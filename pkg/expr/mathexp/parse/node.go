@@ -405,6 +405,10 @@ const (
 	TypeVariantSet
 	// TypeNoData is a no data response without a known data type.
 	TypeNoData
+	// TypeHistogramSet is a collection of labelled native histograms.
+	TypeHistogramSet
+	// TypeTableData is a collection of unconverted table or long frames.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -422,6 +426,10 @@ func (f ReturnType) String() string {
 		return "variant"
 	case TypeNoData:
 		return "noData"
+	case TypeHistogramSet:
+		return "histogramSet"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
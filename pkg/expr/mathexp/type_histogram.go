@@ -0,0 +1,220 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/util/frametype"
+)
+
+// HistogramFrameType is the frame type used by data sources for native (sparse) histograms.
+// Each row of the frame is one bucket of the histogram sampled at time xMax.
+const HistogramFrameType = frametype.HeatmapCells
+
+const (
+	histogramTimeIdx = iota
+	histogramMinIdx
+	histogramMaxIdx
+	histogramCountIdx
+)
+
+// Histogram holds a labelled native histogram sampled over time. The frame has
+// the fields xMax (time), yMin, yMax and count, one row per bucket.
+type Histogram struct{ Frame *data.Frame }
+
+// IsHistogramFrame returns true if the frame holds a native histogram.
+func IsHistogramFrame(frame *data.Frame) bool {
+	if frame == nil || frame.Meta == nil || frame.Meta.Type != HistogramFrameType {
+		return false
+	}
+	if len(frame.Fields) <= histogramCountIdx {
+		return false
+	}
+	return frame.Fields[histogramTimeIdx].Type() == data.FieldTypeTime &&
+		frame.Fields[histogramMinIdx].Type() == data.FieldTypeFloat64 &&
+		frame.Fields[histogramMaxIdx].Type() == data.FieldTypeFloat64 &&
+		frame.Fields[histogramCountIdx].Type() == data.FieldTypeFloat64
+}
+
+// HistogramFromFrame returns a Histogram holding the frame.
+func HistogramFromFrame(frame *data.Frame) (Histogram, error) {
+	if !IsHistogramFrame(frame) {
+		return Histogram{}, fmt.Errorf("frame is not a histogram, expected %s frame with xMax, yMin, yMax and count fields", HistogramFrameType)
+	}
+	return Histogram{Frame: frame}, nil
+}
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (h Histogram) Type() parse.ReturnType { return parse.TypeHistogramSet }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (h Histogram) Value() any { return &h }
+
+// GetLabels returns the series labels, which are stored on the yMin field.
+func (h Histogram) GetLabels() data.Labels { return h.Frame.Fields[histogramMinIdx].Labels }
+
+func (h Histogram) SetLabels(ls data.Labels) { h.Frame.Fields[histogramMinIdx].Labels = ls }
+
+func (h Histogram) GetMeta() any {
+	return h.Frame.Meta.Custom
+}
+
+func (h Histogram) SetMeta(v any) {
+	m := h.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		h.Frame.SetMeta(m)
+	}
+	m.Custom = v
+}
+
+func (h Histogram) AddNotice(notice data.Notice) {
+	m := h.Frame.Meta
+	if m == nil {
+		m = &data.FrameMeta{}
+		h.Frame.SetMeta(m)
+	}
+	m.Notices = append(m.Notices, notice)
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (h Histogram) AsDataFrame() *data.Frame { return h.Frame }
+
+// HistogramBucket is a single bucket of a histogram sample.
+type HistogramBucket struct {
+	Lower float64
+	Upper float64
+	Count float64
+}
+
+// HistogramSample holds the buckets of a histogram at one point in time, sorted by
+// their lower boundary.
+type HistogramSample struct {
+	Time    time.Time
+	Buckets []HistogramBucket
+}
+
+// Samples groups the buckets of the histogram by time, sorted from oldest to newest.
+func (h Histogram) Samples() []HistogramSample {
+	byTime := map[time.Time]*HistogramSample{}
+	times := make([]time.Time, 0)
+
+	rows := h.Frame.Fields[histogramTimeIdx].Len()
+	for i := 0; i < rows; i++ {
+		t := h.Frame.Fields[histogramTimeIdx].At(i).(time.Time)
+		sample, ok := byTime[t]
+		if !ok {
+			sample = &HistogramSample{Time: t}
+			byTime[t] = sample
+			times = append(times, t)
+		}
+		sample.Buckets = append(sample.Buckets, HistogramBucket{
+			Lower: h.Frame.Fields[histogramMinIdx].At(i).(float64),
+			Upper: h.Frame.Fields[histogramMaxIdx].At(i).(float64),
+			Count: h.Frame.Fields[histogramCountIdx].At(i).(float64),
+		})
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	samples := make([]HistogramSample, 0, len(times))
+	for _, t := range times {
+		sample := byTime[t]
+		sort.SliceStable(sample.Buckets, func(i, j int) bool { return sample.Buckets[i].Lower < sample.Buckets[j].Lower })
+		samples = append(samples, *sample)
+	}
+	return samples
+}
+
+// Count returns the total number of observations in the sample.
+func (s HistogramSample) Count() float64 {
+	var count float64
+	for _, b := range s.Buckets {
+		count += b.Count
+	}
+	return count
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the sample, using linear
+// interpolation within the bucket holding the quantile like the PromQL
+// histogram_quantile function. It returns NaN if the sample has no observations.
+func (s HistogramSample) Quantile(q float64) float64 {
+	switch {
+	case math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+
+	total := s.Count()
+	if total == 0 || math.IsNaN(total) {
+		return math.NaN()
+	}
+
+	rank := q * total
+	var cumulative float64
+	for _, b := range s.Buckets {
+		if b.Count == 0 {
+			continue
+		}
+		if cumulative+b.Count >= rank {
+			return b.Lower + (b.Upper-b.Lower)*((rank-cumulative)/b.Count)
+		}
+		cumulative += b.Count
+	}
+
+	return s.Buckets[len(s.Buckets)-1].Upper
+}
+
+const (
+	// ReducerHistogramQuantile reduces a histogram to the quantile of its latest sample.
+	ReducerHistogramQuantile = "histogram_quantile"
+	// ReducerHistogramCount reduces a histogram to the number of observations in its latest sample.
+	ReducerHistogramCount = "histogram_count"
+)
+
+// IsHistogramReduceFunc returns true if rFunc is a reduction function for histograms.
+func IsHistogramReduceFunc(rFunc string) bool {
+	switch strings.ToLower(rFunc) {
+	case ReducerHistogramQuantile, ReducerHistogramCount:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reduce turns the latest sample of the Histogram into a Number based on the given
+// reduction function. The quantile is only used by the histogram_quantile reducer.
+func (h Histogram) Reduce(refID, rFunc string, quantile float64) (Number, error) {
+	var l data.Labels
+	if h.GetLabels() != nil {
+		l = h.GetLabels().Copy()
+	}
+	number := NewNumber(refID, l)
+
+	samples := h.Samples()
+	var f float64
+	switch strings.ToLower(rFunc) {
+	case ReducerHistogramQuantile:
+		f = math.NaN()
+		if len(samples) > 0 {
+			f = samples[len(samples)-1].Quantile(quantile)
+		}
+	case ReducerHistogramCount:
+		f = math.NaN()
+		if len(samples) > 0 {
+			f = samples[len(samples)-1].Count()
+		}
+	default:
+		return number, fmt.Errorf("invalid expression '%s': reduction %v is not supported for histograms", refID, rFunc)
+	}
+	number.SetValue(&f)
+	return number, nil
+}
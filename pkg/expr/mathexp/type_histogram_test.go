@@ -0,0 +1,60 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	t1 := time.Unix(1700000000, 0)
+	t2 := t1.Add(time.Minute)
+
+	frame := data.NewFrame("",
+		data.NewField("xMax", nil, []time.Time{t2, t2, t1, t1}),
+		data.NewField("yMin", data.Labels{"job": "api"}, []float64{1, 0, 0, 1}),
+		data.NewField("yMax", nil, []float64{2, 1, 1, 2}),
+		data.NewField("count", nil, []float64{6, 2, 0, 0}),
+		data.NewField("yLayout", nil, []int8{0, 0, 0, 0}),
+	).SetMeta(&data.FrameMeta{Type: HistogramFrameType})
+
+	h, err := HistogramFromFrame(frame)
+	require.NoError(t, err)
+
+	samples := h.Samples()
+	require.Len(t, samples, 2)
+	require.Equal(t, t1, samples[0].Time)
+	require.Equal(t, t2, samples[1].Time)
+	require.Equal(t, []HistogramBucket{{0, 1, 2}, {1, 2, 6}}, samples[1].Buckets)
+
+	t.Run("quantile", func(t *testing.T) {
+		require.Equal(t, 0.5, samples[1].Quantile(0.125))
+		require.Equal(t, 1.5, samples[1].Quantile(0.625))
+		require.Equal(t, 2.0, samples[1].Quantile(1))
+		require.True(t, math.IsInf(samples[1].Quantile(-1), -1))
+		require.True(t, math.IsInf(samples[1].Quantile(2), 1))
+		require.True(t, math.IsNaN(samples[0].Quantile(0.5)))
+	})
+
+	t.Run("reduce uses the latest sample", func(t *testing.T) {
+		n, err := h.Reduce("B", ReducerHistogramCount, 0)
+		require.NoError(t, err)
+		require.Equal(t, 8.0, *n.GetFloat64Value())
+		require.Equal(t, data.Labels{"job": "api"}, n.GetLabels())
+
+		n, err = h.Reduce("B", ReducerHistogramQuantile, 0.625)
+		require.NoError(t, err)
+		require.Equal(t, 1.5, *n.GetFloat64Value())
+
+		_, err = h.Reduce("B", "mean", 0)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects frames that are not histograms", func(t *testing.T) {
+		_, err := HistogramFromFrame(data.NewFrame("", data.NewField("time", nil, []time.Time{t1})))
+		require.Error(t, err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -149,6 +150,9 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+	chained    []ChainedVariable
+	// chainSource is set when chained variables of other queries read the results
+	chainSource bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -156,15 +160,25 @@ func (dn *DSNode) NodeType() NodeType {
 	return TypeDatasourceNode
 }
 
-// NodeType returns the data pipeline node type.
+// NeedsVars returns the refIDs of the results the chained variables of the query are bound to.
 func (dn *DSNode) NeedsVars() []string {
-	return []string{}
+	needs := []string{}
+	for _, v := range dn.chained {
+		if !slices.Contains(needs, v.RefID) {
+			needs = append(needs, v.RefID)
+		}
+	}
+	return needs
 }
 
 func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Request) (*DSNode, error) {
 	if rn.TimeRange == nil {
 		return nil, fmt.Errorf("time range must be specified for refID %s", rn.RefID)
 	}
+	chained, err := parseChainedVariables(rn.RefID, rn.Query)
+	if err != nil {
+		return nil, err
+	}
 	encodedQuery, err := json.Marshal(rn.Query)
 	if err != nil {
 		return nil, err
@@ -183,6 +197,7 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		timeRange:  rn.TimeRange,
 		request:    *req,
 		datasource: rn.DataSource,
+		chained:    chained,
 	}
 
 	var floatIntervalMS float64
@@ -290,7 +305,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				}
 
 				var result mathexp.Results
-				responseType, result, err := convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+				responseType, result, err := dn.convertResults(ctx, dataFrames, s, logger)
 				if err != nil {
 					result.Error = makeConversionError(dn.RefID(), err)
 				}
@@ -304,11 +319,25 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 // Execute runs the node and adds the results to vars. If the node requires
 // other nodes they must have already been executed and their results must
 // already by in vars.
-func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s *Service) (r mathexp.Results, e error) {
+func (dn *DSNode) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, s *Service) (r mathexp.Results, e error) {
 	logger := logger.FromContext(ctx).New("datasourceType", dn.datasource.Type, "queryRefId", dn.refID, "datasourceUid", dn.datasource.UID, "datasourceVersion", dn.datasource.Version)
 	ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 	defer span.End()
 
+	query := dn.query
+	if len(dn.chained) > 0 {
+		bound, ok, err := bindChainedVariables(dn.query, dn.chained, vars)
+		if err != nil {
+			return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
+		}
+		if !ok {
+			// Without values for a variable the query can not select anything
+			logger.Debug("Chained variable has no values, skipping query")
+			return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+		}
+		query = bound
+	}
+
 	pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, dn.datasource.Type, dn.request.User, dn.datasource)
 	if err != nil {
 		return mathexp.Results{}, err
@@ -325,7 +354,7 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 				RefID:         dn.refID,
 				MaxDataPoints: dn.maxDP,
 				Interval:      time.Duration(int64(time.Millisecond) * dn.intervalMS),
-				JSON:          dn.query,
+				JSON:          query,
 				TimeRange:     dn.timeRange.AbsoluteTime(now),
 				QueryType:     dn.queryType,
 			},
@@ -358,13 +387,45 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 
 	var result mathexp.Results
-	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+	responseType, result, err = dn.convertResults(ctx, dataFrames, s, logger)
 	if err != nil {
 		err = makeConversionError(dn.refID, err)
 	}
 	return result, err
 }
 
+// convertResults converts the response frames of the query. Tables and long series read by
+// chained variables can not be converted to numbers or wide series, so they are passed
+// through as they are.
+func (dn *DSNode) convertResults(ctx context.Context, frames data.Frames, s *Service, logger log.Logger) (string, mathexp.Results, error) {
+	if dn.chainSource && hasTableFrames(frames) {
+		vals := make([]mathexp.Value, 0, len(frames))
+		for _, frame := range frames {
+			vals = append(vals, mathexp.TableData{Frame: frame})
+		}
+		return "table", mathexp.Results{Values: vals}, nil
+	}
+	return convertDataFramesToResults(ctx, frames, dn.datasource.Type, s, logger)
+}
+
+// hasTableFrames returns true if any of the frames is a table or a long series.
+func hasTableFrames(frames data.Frames) bool {
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 || mathexp.IsHistogramFrame(frame) {
+			continue
+		}
+		switch frame.TimeSeriesSchema().Type {
+		case data.TimeSeriesTypeLong:
+			return true
+		case data.TimeSeriesTypeNot:
+			if !isNumberTable(frame) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func getResponseFrame(resp *backend.QueryDataResponse, refID string) (data.Frames, error) {
 	response, ok := resp.Responses[refID]
 	if !ok {
@@ -396,6 +457,18 @@ func convertDataFramesToResults(ctx context.Context, frames data.Frames, datasou
 		return fmt.Sprintf("dataplane-%s", dt), result, err
 	}
 
+	if isAllFrameHistograms(frames) {
+		vals := make([]mathexp.Value, 0, len(frames))
+		for _, frame := range frames {
+			h, err := mathexp.HistogramFromFrame(frame)
+			if err != nil {
+				return "", mathexp.Results{}, err
+			}
+			vals = append(vals, h)
+		}
+		return "histogram", mathexp.Results{Values: vals}, nil
+	}
+
 	if isAllFrameVectors(datasourceType, frames) { // Prometheus Specific Handling
 		vals, err := framesToNumbers(frames)
 		if err != nil {
@@ -470,6 +543,16 @@ func convertDataFramesToResults(ctx context.Context, frames data.Frames, datasou
 	}, nil
 }
 
+// isAllFrameHistograms returns true if every frame holds a native histogram.
+func isAllFrameHistograms(frames data.Frames) bool {
+	for _, frame := range frames {
+		if !mathexp.IsHistogramFrame(frame) {
+			return false
+		}
+	}
+	return len(frames) > 0
+}
+
 func isAllFrameVectors(datasourceType string, frames data.Frames) bool {
 	if datasourceType != datasources.DS_PROMETHEUS {
 		return false
@@ -271,4 +271,22 @@ func TestConvertDataFramesToResults(t *testing.T) {
 			}
 		})
 	})
+	t.Run("should convert native histogram frames to histograms", func(t *testing.T) {
+		frames := []*data.Frame{
+			data.NewFrame("",
+				data.NewField("xMax", nil, []time.Time{time.Unix(1, 0)}),
+				data.NewField("yMin", data.Labels{"job": "api"}, []float64{0}),
+				data.NewField("yMax", nil, []float64{1}),
+				data.NewField("count", nil, []float64{2}),
+				data.NewField("yLayout", nil, []int8{0}),
+			).SetMeta(&data.FrameMeta{Type: mathexp.HistogramFrameType}),
+		}
+
+		resultType, res, err := convertDataFramesToResults(context.Background(), frames, datasources.DS_PROMETHEUS, s, &logtest.Fake{})
+		require.NoError(t, err)
+		assert.Equal(t, "histogram", resultType)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.Histogram{}, res.Values[0])
+		require.Equal(t, data.Labels{"job": "api"}, res.Values[0].GetLabels())
+	})
 }
@@ -195,7 +195,10 @@ func (s *SocialAzureAD) Validate(ctx context.Context, settings ssoModels.SSOSett
 		return err
 	}
 
-	// add specific validation rules for AzureAD
+	if info.Enabled && strings.Contains(info.AuthUrl, "/oauth2/v2.0/authorize") {
+		keysetURL := strings.Replace(info.AuthUrl, "/oauth2/v2.0/authorize", "/discovery/v2.0/keys", 1)
+		return s.validateJWKS(ctx, validationHTTPClient(info), keysetURL)
+	}
 
 	return nil
 }
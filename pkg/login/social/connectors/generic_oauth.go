@@ -78,7 +78,11 @@ func (s *SocialGenericOAuth) Validate(ctx context.Context, settings ssoModels.SS
 		return err
 	}
 
-	// add specific validation rules for Generic OAuth
+	for _, key := range []string{loginAttributePathKey, nameAttributePathKey} {
+		if err := validateAttributePath(key, info.Extra[key]); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
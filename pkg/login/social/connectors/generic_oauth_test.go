@@ -958,6 +958,37 @@ func TestSocialGenericOAuth_Validate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "SSOSettings with attribute paths is valid",
+			settings: ssoModels.SSOSettings{
+				Settings: map[string]any{
+					"client_id":            "client-id",
+					"role_attribute_path":  "contains(groups[*], 'admin') && 'Admin' || 'Viewer'",
+					"login_attribute_path": "login",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "fails if role attribute path is not a valid JMESPath expression",
+			settings: ssoModels.SSOSettings{
+				Settings: map[string]any{
+					"client_id":           "client-id",
+					"role_attribute_path": "contains(groups[*], 'admin') &&",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "fails if login attribute path is not a valid JMESPath expression",
+			settings: ssoModels.SSOSettings{
+				Settings: map[string]any{
+					"client_id":            "client-id",
+					"login_attribute_path": "user.[login",
+				},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
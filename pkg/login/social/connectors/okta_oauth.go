@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-jose/go-jose/v3/jwt"
 	"golang.org/x/oauth2"
@@ -71,11 +72,25 @@ func (s *SocialOkta) Validate(ctx context.Context, settings ssoModels.SSOSetting
 		return err
 	}
 
-	// add specific validation rules for Okta
+	if issuer := oktaIssuer(info.AuthUrl); info.Enabled && issuer != "" {
+		return s.validateIssuer(ctx, validationHTTPClient(info), issuer)
+	}
 
 	return nil
 }
 
+// oktaIssuer derives the issuer of the Okta authorization server from its authorize endpoint, e.g.
+// https://example.okta.com/oauth2/default/v1/authorize is issued by https://example.okta.com/oauth2/default
+// and https://example.okta.com/oauth2/v1/authorize by the org authorization server https://example.okta.com.
+func oktaIssuer(authURL string) string {
+	issuer, found := strings.CutSuffix(strings.TrimSuffix(authURL, "/"), "/v1/authorize")
+	if !found {
+		return ""
+	}
+
+	return strings.TrimSuffix(issuer, "/oauth2")
+}
+
 func (s *SocialOkta) Reload(ctx context.Context, settings ssoModels.SSOSettings) error {
 	newInfo, err := CreateOAuthInfoFromKeyValues(settings.Settings)
 	if err != nil {
@@ -225,5 +225,17 @@ func validateInfo(info *social.OAuthInfo) error {
 		return ssosettings.ErrEmptyClientId.Errorf("clientId is empty")
 	}
 
+	attributePaths := []struct{ key, path string }{
+		{"role_attribute_path", info.RoleAttributePath},
+		{"groups_attribute_path", info.GroupsAttributePath},
+		{"email_attribute_path", info.EmailAttributePath},
+		{"team_ids_attribute_path", info.TeamIdsAttributePath},
+	}
+	for _, attr := range attributePaths {
+		if err := validateAttributePath(attr.key, attr.path); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
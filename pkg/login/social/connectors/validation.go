@@ -0,0 +1,95 @@
+package connectors
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+)
+
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+func validateAttributePath(key, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	if _, err := jmespath.Compile(path); err != nil {
+		return ssosettings.ErrInvalidAttributePath.Errorf("%s is not a valid JMESPath expression: %v", key, err)
+	}
+
+	return nil
+}
+
+// validationHTTPClient returns the client used to reach the identity provider while validating
+// new settings, before the provider is reloaded with them.
+func validationHTTPClient(info *social.OAuthInfo) *http.Client {
+	return &http.Client{
+		Timeout: time.Second * 15,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: info.TlsSkipVerify, // #nosec G402 - configurable by the admin
+			},
+			DialContext: (&net.Dialer{
+				Timeout: time.Second * 10,
+			}).DialContext,
+		},
+	}
+}
+
+// validateIssuer fetches the OpenID Connect discovery document of the issuer, checks that it was
+// published by the same issuer and that the key set it points to can be retrieved.
+func (s *SocialBase) validateIssuer(ctx context.Context, client *http.Client, issuer string) error {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	resp, err := s.httpGet(ctx, client, issuer+"/.well-known/openid-configuration")
+	if err != nil {
+		return ssosettings.ErrProviderUnreachable.Errorf("failed to fetch the discovery document of %s: %v", issuer, err)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.Unmarshal(resp.Body, &discovery); err != nil {
+		return ssosettings.ErrProviderUnreachable.Errorf("failed to parse the discovery document of %s: %v", issuer, err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return ssosettings.ErrProviderUnreachable.Errorf("discovery document issuer %q does not match %q", discovery.Issuer, issuer)
+	}
+
+	if discovery.JWKSURI == "" {
+		return ssosettings.ErrProviderUnreachable.Errorf("discovery document of %s does not contain a jwks_uri", issuer)
+	}
+
+	return s.validateJWKS(ctx, client, discovery.JWKSURI)
+}
+
+// validateJWKS checks that the key set used to verify the ID tokens can be retrieved and is not empty.
+func (s *SocialBase) validateJWKS(ctx context.Context, client *http.Client, jwksURL string) error {
+	resp, err := s.httpGet(ctx, client, jwksURL)
+	if err != nil {
+		return ssosettings.ErrProviderUnreachable.Errorf("failed to fetch the key set from %s: %v", jwksURL, err)
+	}
+
+	var jwks keySetJWKS
+	if err := json.Unmarshal(resp.Body, &jwks); err != nil {
+		return ssosettings.ErrProviderUnreachable.Errorf("failed to parse the key set from %s: %v", jwksURL, err)
+	}
+
+	if len(jwks.Keys) == 0 {
+		return ssosettings.ErrProviderUnreachable.Errorf("key set from %s does not contain any keys", jwksURL)
+	}
+
+	return nil
+}
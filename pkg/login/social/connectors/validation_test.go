@@ -0,0 +1,187 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// newIdentityProviderStub serves a discovery document for the issuer at issuerPath and the key set
+// it points to. The issuer published in the discovery document can be overridden with issuer.
+func newIdentityProviderStub(t *testing.T, issuerPath, jwksPath, issuer string, keys []jose.JSONWebKey) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	if issuer == "" {
+		issuer = server.URL + issuerPath
+	}
+
+	mux.HandleFunc(issuerPath+"/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer,
+			"jwks_uri": server.URL + jwksPath,
+		}))
+	})
+	mux.HandleFunc(jwksPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: keys}))
+	})
+
+	return server
+}
+
+func testSigningKeys(t *testing.T) []jose.JSONWebKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return []jose.JSONWebKey{{Key: privateKey.Public(), KeyID: "1", Algorithm: "RS256", Use: "sig"}}
+}
+
+func TestSocialOkta_Validate_Issuer(t *testing.T) {
+	keys := testSigningKeys(t)
+
+	testCases := []struct {
+		name          string
+		issuerPath    string
+		authPath      string
+		issuer        string
+		keys          []jose.JSONWebKey
+		disabled      bool
+		expectedError error
+	}{
+		{
+			name:       "custom authorization server is valid",
+			issuerPath: "/oauth2/default",
+			authPath:   "/oauth2/default/v1/authorize",
+			keys:       keys,
+		},
+		{
+			name:       "org authorization server is valid",
+			issuerPath: "",
+			authPath:   "/oauth2/v1/authorize",
+			keys:       keys,
+		},
+		{
+			name:          "fails if the discovery document cannot be fetched",
+			issuerPath:    "/oauth2/default",
+			authPath:      "/oauth2/other/v1/authorize",
+			keys:          keys,
+			expectedError: ssosettings.ErrProviderUnreachable,
+		},
+		{
+			name:          "fails if the issuer does not match",
+			issuerPath:    "/oauth2/default",
+			authPath:      "/oauth2/default/v1/authorize",
+			issuer:        "https://other.okta.com/oauth2/default",
+			keys:          keys,
+			expectedError: ssosettings.ErrProviderUnreachable,
+		},
+		{
+			name:          "fails if the key set is empty",
+			issuerPath:    "/oauth2/default",
+			authPath:      "/oauth2/default/v1/authorize",
+			keys:          []jose.JSONWebKey{},
+			expectedError: ssosettings.ErrProviderUnreachable,
+		},
+		{
+			name:       "skips the discovery if the provider is disabled",
+			issuerPath: "/oauth2/default",
+			authPath:   "/oauth2/other/v1/authorize",
+			disabled:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newIdentityProviderStub(t, tc.issuerPath, "/keys", tc.issuer, tc.keys)
+			s := NewOktaProvider(&social.OAuthInfo{}, &setting.Cfg{}, &ssosettingstests.MockService{}, featuremgmt.WithFeatures())
+
+			err := s.Validate(context.Background(), ssoModels.SSOSettings{
+				Settings: map[string]any{
+					"client_id": "client-id",
+					"enabled":   !tc.disabled,
+					"auth_url":  server.URL + tc.authPath,
+				},
+			})
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSocialAzureAD_Validate_JWKS(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authPath      string
+		keys          []jose.JSONWebKey
+		expectedError error
+	}{
+		{
+			name:     "key set is reachable",
+			authPath: "/1234/oauth2/v2.0/authorize",
+			keys:     testSigningKeys(t),
+		},
+		{
+			name:          "fails if the key set cannot be fetched",
+			authPath:      "/5678/oauth2/v2.0/authorize",
+			keys:          testSigningKeys(t),
+			expectedError: ssosettings.ErrProviderUnreachable,
+		},
+		{
+			name:          "fails if the key set is empty",
+			authPath:      "/1234/oauth2/v2.0/authorize",
+			keys:          []jose.JSONWebKey{},
+			expectedError: ssosettings.ErrProviderUnreachable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newIdentityProviderStub(t, "/1234/v2.0", "/1234/discovery/v2.0/keys", "", tc.keys)
+			s := NewAzureADProvider(&social.OAuthInfo{}, &setting.Cfg{}, &ssosettingstests.MockService{}, featuremgmt.WithFeatures(), nil)
+
+			err := s.Validate(context.Background(), ssoModels.SSOSettings{
+				Settings: map[string]any{
+					"client_id": "client-id",
+					"enabled":   true,
+					"auth_url":  server.URL + tc.authPath,
+				},
+			})
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOktaIssuer(t *testing.T) {
+	assert.Equal(t, "https://example.okta.com/oauth2/default", oktaIssuer("https://example.okta.com/oauth2/default/v1/authorize"))
+	assert.Equal(t, "https://example.okta.com", oktaIssuer("https://example.okta.com/oauth2/v1/authorize"))
+	assert.Equal(t, "", oktaIssuer("https://example.com/authorize"))
+}
@@ -58,6 +58,11 @@ func ProvideService(cfg *setting.Cfg,
 		}
 
 		for _, ssoSetting := range allSettings {
+			// saml is not an oauth connector, it is loaded by the saml service
+			if ssoSetting.Provider == ssosettings.SAMLProviderName {
+				continue
+			}
+
 			info, err := connectors.CreateOAuthInfoFromKeyValues(ssoSetting.Settings)
 			if err != nil {
 				ss.log.Error("Failed to create OAuthInfo for provider", "error", err, "provider", ssoSetting.Provider)
@@ -34,6 +34,7 @@ type UserToken struct {
 	CreatedAt     int64
 	UpdatedAt     int64
 	RevokedAt     int64
+	AuthModule    string
 	UnhashedToken string
 }
 
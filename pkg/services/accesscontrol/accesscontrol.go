@@ -3,6 +3,7 @@ package accesscontrol
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/registry"
@@ -207,6 +208,17 @@ func GroupScopesByAction(permissions []Permission) map[string][]string {
 	return m
 }
 
+// UngroupScopesByAction will expand scopes grouped by action back into a list of permissions
+func UngroupScopesByAction(scopesByAction map[string][]string) []Permission {
+	permissions := make([]Permission, 0, len(scopesByAction))
+	for action, scopes := range scopesByAction {
+		for _, scope := range scopes {
+			permissions = append(permissions, Permission{Action: action, Scope: scope})
+		}
+	}
+	return permissions
+}
+
 // Reduce will reduce a list of permissions to its minimal form, grouping scopes by action
 func Reduce(ps []Permission) map[string][]string {
 	reduced := make(map[string][]string)
@@ -376,6 +388,30 @@ func Intersect(p1, p2 []Permission) map[string][]string {
 	return res
 }
 
+// IntersectTokenPermissions returns the permissions that are also granted by the permissions of a token,
+// grouping scopes by action. A token permission without scope grants all scopes of the action.
+func IntersectTokenPermissions(permissions []Permission, tokenPermissions map[string][]string) map[string][]string {
+	unscoped := make([]Permission, 0)
+	scoped := make([]Permission, 0, len(permissions))
+	for _, p := range permissions {
+		tokenScopes, ok := tokenPermissions[p.Action]
+		if !ok {
+			continue
+		}
+		if slices.Contains(tokenScopes, "") {
+			unscoped = append(unscoped, p)
+		} else {
+			scoped = append(scoped, p)
+		}
+	}
+
+	res := GroupScopesByAction(unscoped)
+	for action, scopes := range Intersect(scoped, UngroupScopesByAction(tokenPermissions)) {
+		res[action] = scopes
+	}
+	return res
+}
+
 func ValidateScope(scope string) bool {
 	prefix, last := scope[:len(scope)-1], scope[len(scope)-1]
 	// verify that last char is either ':' or '/' if last character of scope is '*'
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	// this import is needed for github.com/grafana/grafana/pkg/web hack_wrap to work
@@ -247,6 +248,53 @@ func TestIntersect(t *testing.T) {
 	}
 }
 
+func TestIntersectTokenPermissions(t *testing.T) {
+	permissions := []Permission{
+		{Action: "dashboards:read", Scope: "dashboards:*"},
+		{Action: "dashboards:read", Scope: "folders:*"},
+		{Action: "dashboards:write", Scope: "folders:uid:abc"},
+		{Action: "users:read", Scope: "global.users:*"},
+		{Action: "orgs:read"},
+	}
+
+	tests := []struct {
+		name             string
+		tokenPermissions map[string][]string
+		want             map[string][]string
+	}{
+		{
+			name:             "no token permission",
+			tokenPermissions: map[string][]string{},
+			want:             map[string][]string{},
+		},
+		{
+			name:             "scoped token permissions",
+			tokenPermissions: map[string][]string{"dashboards:read": {"folders:uid:abc"}, "dashboards:write": {"folders:*"}},
+			want:             map[string][]string{"dashboards:read": {"folders:uid:abc"}, "dashboards:write": {"folders:uid:abc"}},
+		},
+		{
+			name:             "token permissions without scope grant all scopes of the action",
+			tokenPermissions: map[string][]string{"dashboards:read": {""}, "users:read": {""}, "orgs:read": {""}},
+			want:             map[string][]string{"dashboards:read": {"dashboards:*", "folders:*"}, "users:read": {"global.users:*"}, "orgs:read": {""}},
+		},
+		{
+			name:             "token permissions for actions the entity does not have",
+			tokenPermissions: map[string][]string{"dashboards:create": {""}, "teams:read": {"teams:*"}},
+			want:             map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IntersectTokenPermissions(permissions, tt.tokenPermissions)
+			require.Len(t, got, len(tt.want))
+			for action, scopes := range tt.want {
+				assert.ElementsMatch(t, scopes, got[action])
+			}
+		})
+	}
+}
+
 func Test_intersectScopes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -63,6 +63,10 @@ func (api *AccessControlAPI) getUserPermissions(c *contextmodel.ReqContext) resp
 		return response.JSON(http.StatusInternalServerError, err)
 	}
 
+	if c.SignedInUser.TokenPermissions != nil {
+		return response.JSON(http.StatusOK, ac.IntersectTokenPermissions(permissions, c.SignedInUser.TokenPermissions))
+	}
+
 	return response.JSON(http.StatusOK, ac.GroupScopesByAction(permissions))
 }
 
@@ -68,10 +68,11 @@ func TestAPI_getUserActions(t *testing.T) {
 
 func TestAPI_getUserPermissions(t *testing.T) {
 	type testCase struct {
-		desc           string
-		permissions    []ac.Permission
-		expectedOutput util.DynMap
-		expectedCode   int
+		desc             string
+		permissions      []ac.Permission
+		tokenPermissions map[string][]string
+		expectedOutput   util.DynMap
+		expectedCode     int
 	}
 
 	tests := []testCase{
@@ -88,6 +89,23 @@ func TestAPI_getUserPermissions(t *testing.T) {
 				}},
 			expectedCode: http.StatusOK,
 		},
+		{
+			desc: "Should only get the permissions granted to the token",
+			permissions: []ac.Permission{
+				{Action: datasources.ActionRead, Scope: datasources.ScopeAll},
+				{Action: datasources.ActionQuery, Scope: datasources.ScopeAll},
+				{Action: datasources.ActionWrite, Scope: datasources.ScopeAll},
+			},
+			tokenPermissions: map[string][]string{
+				datasources.ActionRead:  {datasources.ScopeProvider.GetResourceScope("aabbccdd")},
+				datasources.ActionQuery: {""},
+			},
+			expectedOutput: util.DynMap{
+				datasources.ActionRead:  []any{datasources.ScopeProvider.GetResourceScope("aabbccdd")},
+				datasources.ActionQuery: []any{datasources.ScopeAll},
+			},
+			expectedCode: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,8 +119,9 @@ func TestAPI_getUserPermissions(t *testing.T) {
 
 			req := server.NewGetRequest(url)
 			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
-				OrgID:       1,
-				Permissions: map[int64]map[string][]string{},
+				OrgID:            1,
+				Permissions:      map[int64]map[string][]string{},
+				TokenPermissions: tt.tokenPermissions,
 			})
 			res, err := server.Send(req)
 			defer func() { require.NoError(t, res.Body.Close()) }()
@@ -189,6 +189,48 @@ func TestAuthorizeInOrgMiddleware(t *testing.T) {
 			ctxSignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: {"users:read": {"users:*"}}}},
 			expectedStatus:  http.StatusForbidden,
 		},
+		{
+			name: "should return 403 when token permissions do not intersect with service account permissions",
+			orgIDGetter: func(c *contextmodel.ReqContext) (int64, error) {
+				return 1, nil
+			},
+			evaluator:     accesscontrol.EvalPermission("users:read", "users:*"),
+			accessControl: ac,
+			acService: &actest.FakeService{
+				ExpectedPermissions: []accesscontrol.Permission{{Action: "users:read", Scope: "users:*"}},
+			},
+			userCache: &usertest.FakeUserService{},
+			ctxSignedInUser: &user.SignedInUser{
+				UserID:           1,
+				OrgID:            1,
+				IsServiceAccount: true,
+				Permissions:      map[int64]map[string][]string{1: {}},
+				TokenPermissions: map[string][]string{"dashboards:read": {"dashboards:*"}},
+			},
+			teamService:    &teamtest.FakeService{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "should restrict fetched service account permissions to token permissions",
+			orgIDGetter: func(c *contextmodel.ReqContext) (int64, error) {
+				return accesscontrol.GlobalOrgID, nil
+			},
+			evaluator:     accesscontrol.EvalPermission("users:read", "users:*"),
+			accessControl: ac,
+			acService: &actest.FakeService{
+				ExpectedPermissions: []accesscontrol.Permission{{Action: "users:read", Scope: "users:*"}},
+			},
+			userCache: &usertest.FakeUserService{},
+			ctxSignedInUser: &user.SignedInUser{
+				UserID:           1,
+				OrgID:            1,
+				IsServiceAccount: true,
+				Permissions:      map[int64]map[string][]string{1: {}},
+				TokenPermissions: map[string][]string{"users:read": {"users:1"}},
+			},
+			teamService:    &teamtest.FakeService{},
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			name: "unable to get target org",
 			orgIDGetter: func(c *contextmodel.ReqContext) (int64, error) {
@@ -225,6 +225,12 @@ func makeTmpUser(ctx context.Context, service Service, cache userCache,
 		},
 	}
 
+	// Permissions of token restricted entities are intersected with the token
+	// permissions, so reloading them must never grant the full entity permissions
+	if u, ok := reqUser.(*user.SignedInUser); ok {
+		tmpUser.TokenPermissions = u.TokenPermissions
+	}
+
 	namespace, identifier := reqUser.GetNamespacedID()
 	id, _ := identity.IntIdentifier(namespace, identifier)
 	switch namespace {
@@ -276,7 +282,11 @@ func makeTmpUser(ctx context.Context, service Service, cache userCache,
 			return nil, err
 		}
 
-		tmpUser.Permissions[targetOrgID] = GroupScopesByAction(permissions)
+		if tmpUser.TokenPermissions != nil {
+			tmpUser.Permissions[targetOrgID] = IntersectTokenPermissions(permissions, tmpUser.TokenPermissions)
+		} else {
+			tmpUser.Permissions[targetOrgID] = GroupScopesByAction(permissions)
+		}
 	}
 
 	return tmpUser, nil
@@ -100,6 +100,21 @@ func testIntegrationApiKeyDataAccess(t *testing.T, fn getStore) {
 			assert.Nil(t, key.Expires)
 		})
 
+		t.Run("Add a key restricted to permissions", func(t *testing.T) {
+			permissions := []apikey.Permission{{Action: "dashboards:read", Scope: "folders:uid:abc"}, {Action: "users:create"}}
+			cmd := apikey.AddCommand{OrgID: 1, Name: "restricted", Key: "asd-restricted", Permissions: permissions}
+			_, err := ss.AddAPIKey(context.Background(), &cmd)
+			assert.Nil(t, err)
+
+			key, err := ss.GetAPIKeyByHash(context.Background(), cmd.Key)
+			assert.Nil(t, err)
+			assert.Equal(t, permissions, key.Permissions)
+
+			key, err = ss.GetApiKeyByName(context.Background(), &apikey.GetByNameQuery{KeyName: "non-expiring", OrgID: 1})
+			assert.Nil(t, err)
+			assert.Empty(t, key.Permissions)
+		})
+
 		t.Run("Add an expiring key", func(t *testing.T) {
 			// expires in one hour
 			cmd := apikey.AddCommand{OrgID: 1, Name: "expiring-in-an-hour", Key: "asd2", SecondsToLive: 3600}
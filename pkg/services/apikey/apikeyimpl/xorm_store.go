@@ -125,6 +125,7 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) (res
 			Expires:          expires,
 			ServiceAccountId: cmd.ServiceAccountID,
 			IsRevoked:        &isRevoked,
+			Permissions:      cmd.Permissions,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
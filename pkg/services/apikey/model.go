@@ -28,6 +28,18 @@ type APIKey struct {
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
+
+	// Permissions restricts a service account token to the given subset of the
+	// service account permissions. Empty if the token is not restricted.
+	Permissions []Permission `xorm:"permissions" db:"permissions"`
+}
+
+// Permission is an RBAC action, optionally limited to a scope.
+type Permission struct {
+	// example: dashboards:read
+	Action string `json:"action"`
+	// example: folders:uid:abc
+	Scope string `json:"scope,omitempty"`
 }
 
 func (k APIKey) TableName() string { return "api_key" }
@@ -40,6 +52,7 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	Permissions      []Permission `json:"-"`
 }
 
 type DeleteCommand struct {
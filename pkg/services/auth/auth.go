@@ -58,6 +58,14 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+type CreateTokenCommand struct {
+	User      *user.User
+	ClientIP  net.IP
+	UserAgent string
+	// AuthModule is the authentication method the session was created with, e.g. password or oauth_github
+	AuthModule string
+}
+
 type RotateCommand struct {
 	// token is the un-hashed token
 	UnHashedToken string
@@ -67,13 +75,15 @@ type RotateCommand struct {
 
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
-	CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*UserToken, error)
+	CreateToken(ctx context.Context, cmd *CreateTokenCommand) (*UserToken, error)
 	LookupToken(ctx context.Context, unhashedToken string) (*UserToken, error)
 	// RotateToken will always rotate a valid token
 	RotateToken(ctx context.Context, cmd RotateCommand) (*UserToken, error)
 	TryRotateToken(ctx context.Context, token *UserToken, clientIP net.IP, userAgent string) (bool, *UserToken, error)
 	RevokeToken(ctx context.Context, token *UserToken, soft bool) error
 	RevokeAllUserTokens(ctx context.Context, userID int64) error
+	// RevokeOtherUserTokens revokes all tokens of the user except the one with the given id
+	RevokeOtherUserTokens(ctx context.Context, userID, tokenID int64) error
 	GetUserToken(ctx context.Context, userID, userTokenID int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userID int64) ([]*UserToken, error)
 	ActiveTokenCount(ctx context.Context, userID *int64) (int64, error)
@@ -18,7 +18,6 @@ import (
 	"github.com/grafana/grafana/pkg/models/usertoken"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/quota"
-	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -65,30 +64,31 @@ type UserAuthTokenService struct {
 	singleflight      *singleflight.Group
 }
 
-func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+func (s *UserAuthTokenService) CreateToken(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
 	token, hashedToken, err := generateAndHashToken(s.cfg.SecretKey)
 	if err != nil {
 		return nil, err
 	}
 
 	now := getTime().Unix()
-	clientIPStr := clientIP.String()
-	if len(clientIP) == 0 {
+	clientIPStr := cmd.ClientIP.String()
+	if len(cmd.ClientIP) == 0 {
 		clientIPStr = ""
 	}
 
 	userAuthToken := userAuthToken{
-		UserId:        user.ID,
+		UserId:        cmd.User.ID,
 		AuthToken:     hashedToken,
 		PrevAuthToken: hashedToken,
 		ClientIp:      clientIPStr,
-		UserAgent:     userAgent,
+		UserAgent:     cmd.UserAgent,
 		RotatedAt:     now,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 		SeenAt:        0,
 		RevokedAt:     0,
 		AuthTokenSeen: false,
+		AuthModule:    cmd.AuthModule,
 	}
 
 	err = s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
@@ -103,7 +103,11 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 	userAuthToken.UnhashedToken = token
 
 	ctxLogger := s.log.FromContext(ctx)
-	ctxLogger.Debug("User auth token created", "tokenID", userAuthToken.Id, "userID", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authToken", userAuthToken.AuthToken)
+	ctxLogger.Debug("User auth token created", "tokenID", userAuthToken.Id, "userID", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authModule", userAuthToken.AuthModule, "authToken", userAuthToken.AuthToken)
+
+	if err := s.revokeExceedingTokens(ctx, userAuthToken.UserId); err != nil {
+		return nil, err
+	}
 
 	var userToken auth.UserToken
 	err = userAuthToken.toUserToken(&userToken)
@@ -136,9 +140,11 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 
 	if model.RevokedAt > 0 {
 		ctxLogger.Debug("User token has been revoked", "userID", model.UserId, "tokenID", model.Id, "revokedAt", model.RevokedAt)
+		// tokens are only soft revoked when the user exceeds the maximum number of concurrent sessions
 		return nil, &auth.TokenRevokedError{
-			UserID:  model.UserId,
-			TokenID: model.Id,
+			UserID:                model.UserId,
+			TokenID:               model.Id,
+			MaxConcurrentSessions: s.cfg.LoginMaxConcurrentSessions,
 		}
 	}
 
@@ -455,6 +461,63 @@ func (s *UserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId i
 	})
 }
 
+func (s *UserAuthTokenService) RevokeOtherUserTokens(ctx context.Context, userID, tokenID int64) error {
+	return s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		sql := `DELETE from user_auth_token WHERE user_id = ? AND id <> ?`
+		res, err := dbSession.Exec(sql, userID, tokenID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		s.log.FromContext(ctx).Debug("Other user tokens for user revoked", "userID", userID, "tokenID", tokenID, "count", affected)
+
+		return err
+	})
+}
+
+// revokeExceedingTokens soft revokes the active tokens of the user that exceed the maximum number of
+// concurrent sessions, starting with the ones that were rotated least recently.
+func (s *UserAuthTokenService) revokeExceedingTokens(ctx context.Context, userID int64) error {
+	if s.cfg.LoginMaxConcurrentSessions <= 0 {
+		return nil
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		var tokens []*userAuthToken
+		err := dbSession.Cols("id").Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
+			userID,
+			s.createdAfterParam(),
+			s.rotatedAfterParam()).
+			Desc("rotated_at", "id").
+			Find(&tokens)
+		if err != nil {
+			return err
+		}
+
+		if int64(len(tokens)) <= s.cfg.LoginMaxConcurrentSessions {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(tokens))
+		for _, token := range tokens[s.cfg.LoginMaxConcurrentSessions:] {
+			ids = append(ids, token.Id)
+		}
+
+		affected, err := dbSession.In("id", ids).Cols("revoked_at").Update(&userAuthToken{RevokedAt: getTime().Unix()})
+		if err != nil {
+			return err
+		}
+
+		s.log.FromContext(ctx).Debug("Revoked user tokens exceeding the maximum concurrent sessions", "userID", userID, "count", affected)
+		return nil
+	})
+}
+
 func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
 		if len(userIds) == 0 {
@@ -31,8 +31,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 
 	t.Run("When creating token", func(t *testing.T) {
 		createToken := func() *auth.UserToken {
-			userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-				net.ParseIP("192.168.10.11"), "some user agent")
+			userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 			require.Nil(t, err)
 			require.NotNil(t, userToken)
 			require.False(t, userToken.AuthTokenSeen)
@@ -104,8 +103,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 		userToken = createToken()
 
 		t.Run("When creating an additional token", func(t *testing.T) {
-			userToken2, err := ctx.tokenService.CreateToken(context.Background(), usr,
-				net.ParseIP("192.168.10.11"), "some user agent")
+			userToken2, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 			require.Nil(t, err)
 			require.NotNil(t, userToken2)
 
@@ -151,8 +149,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 				for i := 0; i < 3; i++ {
 					userId := usr.ID + int64(i+1)
 					userIds = append(userIds, userId)
-					_, err := ctx.tokenService.CreateToken(context.Background(), usr,
-						net.ParseIP("192.168.10.11"), "some user agent")
+					_, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 					require.Nil(t, err)
 				}
 
@@ -166,12 +163,54 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 				}
 			})
 		})
+
+		t.Run("When revoking the other tokens of a user", func(t *testing.T) {
+			ctx := createTestContext(t)
+			current, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
+			require.NoError(t, err)
+			other, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
+			require.NoError(t, err)
+
+			err = ctx.tokenService.RevokeOtherUserTokens(context.Background(), usr.ID, current.Id)
+			require.NoError(t, err)
+
+			model, err := ctx.getAuthTokenByID(current.Id)
+			require.NoError(t, err)
+			require.NotNil(t, model)
+
+			model, err = ctx.getAuthTokenByID(other.Id)
+			require.NoError(t, err)
+			require.Nil(t, model)
+		})
+
+		t.Run("When exceeding the maximum concurrent sessions", func(t *testing.T) {
+			ctx := createTestContext(t)
+			ctx.tokenService.cfg.LoginMaxConcurrentSessions = 2
+
+			var tokens []*auth.UserToken
+			for i := 0; i < 3; i++ {
+				token, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, AuthModule: "password"})
+				require.NoError(t, err)
+				tokens = append(tokens, token)
+			}
+
+			active, err := ctx.tokenService.GetUserTokens(context.Background(), usr.ID)
+			require.NoError(t, err)
+			require.Len(t, active, 2)
+			require.Equal(t, tokens[1].Id, active[0].Id)
+			require.Equal(t, tokens[2].Id, active[1].Id)
+			require.Equal(t, "password", active[0].AuthModule)
+
+			_, err = ctx.tokenService.LookupToken(context.Background(), tokens[0].UnhashedToken)
+			var revokedErr *auth.TokenRevokedError
+			require.ErrorAs(t, err, &revokedErr)
+			require.Equal(t, int64(2), revokedErr.MaxConcurrentSessions)
+		})
 	})
 
 	t.Run("expires correctly", func(t *testing.T) {
 		ctx := createTestContext(t)
-		userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-			net.ParseIP("192.168.10.11"), "some user agent")
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 		require.Nil(t, err)
 
 		userToken, err = ctx.tokenService.LookupToken(context.Background(), userToken.UnhashedToken)
@@ -255,8 +294,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 	t.Run("can properly rotate tokens", func(t *testing.T) {
 		getTime = func() time.Time { return now }
 		ctx := createTestContext(t)
-		userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-			net.ParseIP("192.168.10.11"), "some user agent")
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 		require.Nil(t, err)
 
 		prevToken := userToken.AuthToken
@@ -339,8 +377,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 
 	t.Run("keeps prev token valid for 1 minute after it is confirmed", func(t *testing.T) {
 		getTime = func() time.Time { return now }
-		userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-			net.ParseIP("192.168.10.11"), "some user agent")
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 		require.Nil(t, err)
 		require.NotNil(t, userToken)
 
@@ -370,8 +407,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 	})
 
 	t.Run("will not mark token unseen when prev and current are the same", func(t *testing.T) {
-		userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-			net.ParseIP("192.168.10.11"), "some user agent")
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 		require.Nil(t, err)
 		require.NotNil(t, userToken)
 
@@ -392,8 +428,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 	t.Run("TryRotateToken", func(t *testing.T) {
 		t.Run("Should rotate current token and previous token when auth token seen", func(t *testing.T) {
 			getTime = func() time.Time { return now }
-			userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-				net.ParseIP("192.168.10.11"), "some user agent")
+			userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 			require.Nil(t, err)
 			require.NotNil(t, userToken)
 
@@ -444,8 +479,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 
 		t.Run("Should rotate current token, but keep previous token when auth token not seen", func(t *testing.T) {
 			getTime = func() time.Time { return now }
-			userToken, err := ctx.tokenService.CreateToken(context.Background(), usr,
-				net.ParseIP("192.168.10.11"), "some user agent")
+			userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 			require.Nil(t, err)
 			require.NotNil(t, userToken)
 
@@ -472,7 +506,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 
 	t.Run("RotateToken", func(t *testing.T) {
 		var prev string
-		token, err := ctx.tokenService.CreateToken(context.Background(), usr, nil, "")
+		token, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
 		require.NoError(t, err)
 		t.Run("should rotate token when called with current auth token", func(t *testing.T) {
 			prev = token.UnhashedToken
@@ -495,7 +529,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 		})
 
 		t.Run("should return error when token is revoked", func(t *testing.T) {
-			revokedToken, err := ctx.tokenService.CreateToken(context.Background(), usr, nil, "")
+			revokedToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
 			require.NoError(t, err)
 			// mark token as revoked
 			err = ctx.sqlstore.WithDbSession(context.Background(), func(sess *db.Session) error {
@@ -509,7 +543,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 		})
 
 		t.Run("should return error when token has expired", func(t *testing.T) {
-			expiredToken, err := ctx.tokenService.CreateToken(context.Background(), usr, nil, "")
+			expiredToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
 			require.NoError(t, err)
 			// mark token as expired
 			err = ctx.sqlstore.WithDbSession(context.Background(), func(sess *db.Session) error {
@@ -524,10 +558,10 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 
 		t.Run("should only delete revoked tokens that are outside on specified window", func(t *testing.T) {
 			usr := &user.User{ID: 100}
-			token1, err := ctx.tokenService.CreateToken(context.Background(), usr, nil, "")
+			token1, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
 			require.NoError(t, err)
 
-			token2, err := ctx.tokenService.CreateToken(context.Background(), usr, nil, "")
+			token2, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: usr})
 			require.NoError(t, err)
 
 			getTime = func() time.Time {
@@ -568,6 +602,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 			RotatedAt:     4,
 			CreatedAt:     5,
 			UpdatedAt:     6,
+			AuthModule:    "f",
 			UnhashedToken: "e",
 		}
 		utBytes, err := json.Marshal(ut)
@@ -601,6 +636,7 @@ func TestIntegrationUserAuthToken(t *testing.T) {
 			RotatedAt:     4,
 			CreatedAt:     5,
 			UpdatedAt:     6,
+			AuthModule:    "f",
 			UnhashedToken: "e",
 		}
 		uatBytes, err := json.Marshal(uat)
@@ -710,8 +746,7 @@ func TestIntegrationTokenCount(t *testing.T) {
 	user := &user.User{ID: int64(10)}
 
 	createToken := func() *auth.UserToken {
-		userToken, err := ctx.tokenService.CreateToken(context.Background(), user,
-			net.ParseIP("192.168.10.11"), "some user agent")
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &auth.CreateTokenCommand{User: user, ClientIP: net.ParseIP("192.168.10.11"), UserAgent: "some user agent"})
 		require.Nil(t, err)
 		require.NotNil(t, userToken)
 		require.False(t, userToken.AuthTokenSeen)
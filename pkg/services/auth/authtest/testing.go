@@ -11,26 +11,26 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/login"
-	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type FakeUserAuthTokenService struct {
-	CreateTokenProvider          func(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error)
-	RotateTokenProvider          func(ctx context.Context, cmd auth.RotateCommand) (*auth.UserToken, error)
-	TryRotateTokenProvider       func(ctx context.Context, token *auth.UserToken, clientIP net.IP, userAgent string) (bool, *auth.UserToken, error)
-	LookupTokenProvider          func(ctx context.Context, unhashedToken string) (*auth.UserToken, error)
-	RevokeTokenProvider          func(ctx context.Context, token *auth.UserToken, soft bool) error
-	RevokeAllUserTokensProvider  func(ctx context.Context, userID int64) error
-	ActiveTokenCountProvider     func(ctx context.Context, userID *int64) (int64, error)
-	GetUserTokenProvider         func(ctx context.Context, userID, userTokenID int64) (*auth.UserToken, error)
-	GetUserTokensProvider        func(ctx context.Context, userID int64) ([]*auth.UserToken, error)
-	GetUserRevokedTokensProvider func(ctx context.Context, userID int64) ([]*auth.UserToken, error)
-	BatchRevokedTokenProvider    func(ctx context.Context, userIDs []int64) error
+	CreateTokenProvider           func(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error)
+	RotateTokenProvider           func(ctx context.Context, cmd auth.RotateCommand) (*auth.UserToken, error)
+	TryRotateTokenProvider        func(ctx context.Context, token *auth.UserToken, clientIP net.IP, userAgent string) (bool, *auth.UserToken, error)
+	LookupTokenProvider           func(ctx context.Context, unhashedToken string) (*auth.UserToken, error)
+	RevokeTokenProvider           func(ctx context.Context, token *auth.UserToken, soft bool) error
+	RevokeAllUserTokensProvider   func(ctx context.Context, userID int64) error
+	RevokeOtherUserTokensProvider func(ctx context.Context, userID, tokenID int64) error
+	ActiveTokenCountProvider      func(ctx context.Context, userID *int64) (int64, error)
+	GetUserTokenProvider          func(ctx context.Context, userID, userTokenID int64) (*auth.UserToken, error)
+	GetUserTokensProvider         func(ctx context.Context, userID int64) ([]*auth.UserToken, error)
+	GetUserRevokedTokensProvider  func(ctx context.Context, userID int64) ([]*auth.UserToken, error)
+	BatchRevokedTokenProvider     func(ctx context.Context, userIDs []int64) error
 }
 
 func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 	return &FakeUserAuthTokenService{
-		CreateTokenProvider: func(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+		CreateTokenProvider: func(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
 			return &auth.UserToken{
 				UserId:        0,
 				UnhashedToken: "",
@@ -72,8 +72,8 @@ func (s *FakeUserAuthTokenService) Init() error {
 	return nil
 }
 
-func (s *FakeUserAuthTokenService) CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
-	return s.CreateTokenProvider(context.Background(), user, clientIP, userAgent)
+func (s *FakeUserAuthTokenService) CreateToken(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
+	return s.CreateTokenProvider(context.Background(), cmd)
 }
 
 func (s *FakeUserAuthTokenService) RotateToken(ctx context.Context, cmd auth.RotateCommand) (*auth.UserToken, error) {
@@ -97,6 +97,10 @@ func (s *FakeUserAuthTokenService) RevokeAllUserTokens(ctx context.Context, user
 	return s.RevokeAllUserTokensProvider(context.Background(), userId)
 }
 
+func (s *FakeUserAuthTokenService) RevokeOtherUserTokens(ctx context.Context, userID, tokenID int64) error {
+	return s.RevokeOtherUserTokensProvider(context.Background(), userID, tokenID)
+}
+
 func (s *FakeUserAuthTokenService) ActiveTokenCount(ctx context.Context, userID *int64) (int64, error) {
 	return s.ActiveTokenCountProvider(context.Background(), userID)
 }
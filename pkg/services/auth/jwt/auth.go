@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/go-jose/go-jose/v3/jwt"
@@ -37,12 +38,52 @@ func (s *AuthService) init() error {
 		return nil
 	}
 
-	if err := s.initClaimExpectations(); err != nil {
+	// The [auth.jwt] key set is optional when every issuer has its own section.
+	defaultIssuer := s.defaultIssuer()
+	if len(s.Cfg.JWTAuthIssuers) == 0 || hasKeySet(defaultIssuer) {
+		if err := s.initVerifier(&s.verifier, defaultIssuer); err != nil {
+			return err
+		}
+	}
+
+	s.issuers = make(map[string]*verifier, len(s.Cfg.JWTAuthIssuers))
+	for _, issuer := range s.Cfg.JWTAuthIssuers {
+		v := &verifier{}
+		if err := s.initVerifier(v, issuer); err != nil {
+			return fmt.Errorf("failed to configure jwt issuer %q: %w", issuer.Name, err)
+		}
+		s.issuers[issuer.Issuer] = v
+	}
+
+	return nil
+}
+
+// defaultIssuer returns the key set settings of the [auth.jwt] section.
+func (s *AuthService) defaultIssuer() setting.JWTIssuerSettings {
+	return setting.JWTIssuerSettings{
+		JWKSetURL:    s.Cfg.JWTAuthJWKSetURL,
+		JWKSetFile:   s.Cfg.JWTAuthJWKSetFile,
+		KeyFile:      s.Cfg.JWTAuthKeyFile,
+		KeyID:        s.Cfg.JWTAuthKeyID,
+		CacheTTL:     s.Cfg.JWTAuthCacheTTL,
+		ExpectClaims: s.Cfg.JWTAuthExpectClaims,
+	}
+}
+
+func (s *AuthService) initVerifier(v *verifier, issuer setting.JWTIssuerSettings) error {
+	if err := v.initClaimExpectations(issuer.ExpectClaims); err != nil {
 		return err
 	}
-	if err := s.initKeySet(); err != nil {
+	if issuer.Issuer != "" {
+		v.expectRegistered.Issuer = issuer.Issuer
+	}
+	v.audience = issuer.Audience
+
+	keySet, err := s.initKeySet(issuer)
+	if err != nil {
 		return err
 	}
+	v.keySet = keySet
 
 	return nil
 }
@@ -51,10 +92,20 @@ type AuthService struct {
 	Cfg         *setting.Cfg
 	RemoteCache *remotecache.RemoteCache
 
+	// verifier validates the tokens of issuers without their own section.
+	verifier
+	issuers map[string]*verifier
+	log     log.Logger
+}
+
+// verifier validates tokens against one key set and one set of claim expectations.
+type verifier struct {
 	keySet           keySet
-	log              log.Logger
 	expect           map[string]any
 	expectRegistered jwt.Expected
+	// audience holds the audiences of the issuer, the aud claim must contain one of them.
+	// Audiences expected with expect_claims must all be contained in the claim.
+	audience []string
 }
 
 // Sanitize JWT base64 strings to remove paddings everywhere
@@ -74,7 +125,12 @@ func (s *AuthService) Verify(ctx context.Context, strToken string) (JWTClaims, e
 		return nil, err
 	}
 
-	keys, err := s.keySet.Key(ctx, token.Headers[0].KeyID)
+	v, err := s.verifierFor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := v.keySet.Key(ctx, token.Headers[0].KeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -96,13 +152,33 @@ func (s *AuthService) Verify(ctx context.Context, strToken string) (JWTClaims, e
 
 	s.log.Debug("Validating JSON Web Token claims")
 
-	if err = s.validateClaims(claims); err != nil {
+	if err = v.validateClaims(claims); err != nil {
 		return nil, err
 	}
 
 	return claims, nil
 }
 
+// verifierFor returns the verifier of the issuer of the token,
+// falling back to the [auth.jwt] one for issuers without their own section.
+func (s *AuthService) verifierFor(token *jwt.JSONWebToken) (*verifier, error) {
+	if len(s.issuers) > 0 {
+		var claims jwt.Claims
+		if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+			return nil, err
+		}
+		if v, ok := s.issuers[claims.Issuer]; ok {
+			return v, nil
+		}
+	}
+
+	if s.keySet == nil {
+		return nil, ErrIssuerNotConfigured
+	}
+
+	return &s.verifier, nil
+}
+
 // HasSubClaim checks if the provided JWT token contains a non-empty "sub" claim.
 // Returns true if it contains, otherwise returns false.
 func HasSubClaim(jwtToken string) bool {
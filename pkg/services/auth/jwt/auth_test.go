@@ -78,18 +78,7 @@ func TestVerifyUsingJWKSetFile(t *testing.T) {
 	configure := func(t *testing.T, cfg *setting.Cfg) {
 		t.Helper()
 
-		file, err := os.CreateTemp(os.TempDir(), "jwk-*.json")
-		require.NoError(t, err)
-		t.Cleanup(func() {
-			if err := os.Remove(file.Name()); err != nil {
-				panic(err)
-			}
-		})
-
-		require.NoError(t, json.NewEncoder(file).Encode(jwksPublic))
-		require.NoError(t, file.Close())
-
-		cfg.JWTAuthJWKSetFile = file.Name()
+		cfg.JWTAuthJWKSetFile = writeJWKSetFile(t)
 	}
 
 	scenario(t, "verifies a token signed with a key from the set", func(t *testing.T, sc scenarioContext) {
@@ -113,6 +102,100 @@ func TestVerifyUsingJWKSetFile(t *testing.T) {
 	}, configure)
 }
 
+func writeJWKSetFile(t *testing.T) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(os.TempDir(), "jwk-*.json")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := os.Remove(file.Name()); err != nil {
+			panic(err)
+		}
+	})
+
+	require.NoError(t, json.NewEncoder(file).Encode(jwksPublic))
+	require.NoError(t, file.Close())
+
+	return file.Name()
+}
+
+func TestVerifyWithMultipleIssuers(t *testing.T) {
+	configureIssuers := func(t *testing.T, cfg *setting.Cfg) {
+		t.Helper()
+
+		cfg.JWTAuthIssuers = []setting.JWTIssuerSettings{
+			{Name: "pkix", Issuer: "pkix-idp", KeyFile: writePKIXPublicKeyFile(t), ExpectClaims: "{}"},
+			{Name: "jwks", Issuer: "jwks-idp", JWKSetFile: writeJWKSetFile(t), ExpectClaims: "{}", Audience: []string{"grafana", "grafana-dev"}},
+		}
+	}
+
+	scenario(t, "verifies tokens with the key set of their issuer", func(t *testing.T, sc scenarioContext) {
+		verifiedClaims, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, rsaKeys[0], jwt.Claims{
+			Subject: subject,
+			Issuer:  "pkix-idp",
+		}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "pkix-idp", verifiedClaims["iss"])
+
+		verifiedClaims, err = sc.authJWTSvc.Verify(sc.ctx, sign(t, &jwKeys[0], jwt.Claims{
+			Subject:  subject,
+			Issuer:   "jwks-idp",
+			Audience: jwt.Audience{"grafana"},
+		}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "jwks-idp", verifiedClaims["iss"])
+	}, configureIssuers)
+
+	scenario(t, "rejects a token signed with the key of another issuer", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, &jwKeys[0], jwt.Claims{
+			Subject: subject,
+			Issuer:  "pkix-idp",
+		}, nil))
+		require.Error(t, err)
+	}, configureIssuers)
+
+	scenario(t, "verifies a token with any of the audiences of the issuer", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, &jwKeys[0], jwt.Claims{
+			Subject:  subject,
+			Issuer:   "jwks-idp",
+			Audience: jwt.Audience{"other", "grafana-dev"},
+		}, nil))
+		require.NoError(t, err)
+	}, configureIssuers)
+
+	scenario(t, "rejects a token with an unexpected audience", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, &jwKeys[0], jwt.Claims{
+			Subject:  subject,
+			Issuer:   "jwks-idp",
+			Audience: jwt.Audience{"other"},
+		}, nil))
+		require.Error(t, err)
+	}, configureIssuers)
+
+	scenario(t, "rejects a token of an unknown issuer without a default key set", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, rsaKeys[0], jwt.Claims{
+			Subject: subject,
+			Issuer:  "unknown-idp",
+		}, nil))
+		require.ErrorIs(t, err, ErrIssuerNotConfigured)
+	}, configureIssuers)
+
+	scenario(t, "verifies a token of an unknown issuer with the default key set", func(t *testing.T, sc scenarioContext) {
+		_, err := sc.authJWTSvc.Verify(sc.ctx, sign(t, rsaKeys[0], jwt.Claims{
+			Subject: subject,
+			Issuer:  "unknown-idp",
+		}, nil))
+		require.NoError(t, err)
+	}, configureIssuers, configurePKIXPublicKeyFile)
+
+	t.Run("should refuse to start with an issuer without a key set", func(t *testing.T) {
+		_, err := initAuthService(t, func(t *testing.T, cfg *setting.Cfg) {
+			cfg.JWTAuthIssuers = []setting.JWTIssuerSettings{{Name: "none", Issuer: "none-idp", ExpectClaims: "{}"}}
+		})
+		require.ErrorIs(t, err, ErrKeySetIsNotConfigured)
+	})
+}
+
 func TestVerifyUsingJWKSetURL(t *testing.T) {
 	t.Run("should refuse to start with non-https URL", func(t *testing.T) {
 		var err error
@@ -420,6 +503,12 @@ func scenarioRunner(fn scenarioFunc, cbs ...configureFunc) func(t *testing.T) {
 func configurePKIXPublicKeyFile(t *testing.T, cfg *setting.Cfg) {
 	t.Helper()
 
+	cfg.JWTAuthKeyFile = writePKIXPublicKeyFile(t)
+}
+
+func writePKIXPublicKeyFile(t *testing.T) string {
+	t.Helper()
+
 	file, err := os.CreateTemp(os.TempDir(), "public-key-*.pem")
 	require.NoError(t, err)
 	t.Cleanup(func() {
@@ -437,5 +526,5 @@ func configurePKIXPublicKeyFile(t *testing.T, cfg *setting.Cfg) {
 	}))
 	require.NoError(t, file.Close())
 
-	cfg.JWTAuthKeyFile = file.Name()
+	return file.Name()
 }
@@ -29,6 +29,7 @@ var ErrFailedToParsePemFile = errors.New("failed to parse pem-encoded file")
 var ErrKeySetIsNotConfigured = errors.New("key set for jwt verification is not configured")
 var ErrKeySetConfigurationAmbiguous = errors.New("key set configuration is ambiguous: you should set either key_file, jwk_set_file or jwk_set_url")
 var ErrJWTSetURLMustHaveHTTPSScheme = errors.New("jwt_set_url must have https scheme")
+var ErrIssuerNotConfigured = errors.New("no key set is configured for the jwt issuer")
 
 type keySet interface {
 	Key(ctx context.Context, kid string) ([]jose.JSONWebKey, error)
@@ -47,17 +48,26 @@ type keySetHTTP struct {
 	cacheExpiration time.Duration
 }
 
-func (s *AuthService) checkKeySetConfiguration() error {
+func keySetCount(issuer setting.JWTIssuerSettings) int {
 	var count int
-	if s.Cfg.JWTAuthKeyFile != "" {
+	if issuer.KeyFile != "" {
 		count++
 	}
-	if s.Cfg.JWTAuthJWKSetFile != "" {
+	if issuer.JWKSetFile != "" {
 		count++
 	}
-	if s.Cfg.JWTAuthJWKSetURL != "" {
+	if issuer.JWKSetURL != "" {
 		count++
 	}
+	return count
+}
+
+func hasKeySet(issuer setting.JWTIssuerSettings) bool {
+	return keySetCount(issuer) > 0
+}
+
+func checkKeySetConfiguration(issuer setting.JWTIssuerSettings) error {
+	count := keySetCount(issuer)
 
 	if count == 0 {
 		return ErrKeySetIsNotConfigured
@@ -70,17 +80,17 @@ func (s *AuthService) checkKeySetConfiguration() error {
 	return nil
 }
 
-func (s *AuthService) initKeySet() error {
-	if err := s.checkKeySetConfiguration(); err != nil {
-		return err
+func (s *AuthService) initKeySet(issuer setting.JWTIssuerSettings) (keySet, error) {
+	if err := checkKeySetConfiguration(issuer); err != nil {
+		return nil, err
 	}
 
-	if keyFilePath := s.Cfg.JWTAuthKeyFile; keyFilePath != "" {
+	if keyFilePath := issuer.KeyFile; keyFilePath != "" {
 		// nolint:gosec
 		// We can ignore the gosec G304 warning on this one because `fileName` comes from grafana configuration file
 		file, err := os.Open(keyFilePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer func() {
 			if err := file.Close(); err != nil {
@@ -90,50 +100,52 @@ func (s *AuthService) initKeySet() error {
 
 		data, err := io.ReadAll(file)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		block, _ := pem.Decode(data)
 		if block == nil {
-			return ErrFailedToParsePemFile
+			return nil, ErrFailedToParsePemFile
 		}
 
 		var key any
 		switch block.Type {
 		case "PUBLIC KEY":
 			if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "PRIVATE KEY":
 			if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "RSA PUBLIC KEY":
 			if key, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "RSA PRIVATE KEY":
 			if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "EC PRIVATE KEY":
 			if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		default:
-			return fmt.Errorf("unknown pem block type %q", block.Type)
+			return nil, fmt.Errorf("unknown pem block type %q", block.Type)
 		}
 
-		s.keySet = &keySetJWKS{
+		return &keySetJWKS{
 			jose.JSONWebKeySet{
-				Keys: []jose.JSONWebKey{{Key: key, KeyID: s.Cfg.JWTAuthKeyID}},
+				Keys: []jose.JSONWebKey{{Key: key, KeyID: issuer.KeyID}},
 			},
-		}
-	} else if keyFilePath := s.Cfg.JWTAuthJWKSetFile; keyFilePath != "" {
+		}, nil
+	}
+
+	if keyFilePath := issuer.JWKSetFile; keyFilePath != "" {
 		// nolint:gosec
 		// We can ignore the gosec G304 warning on this one because `fileName` comes from grafana configuration file
 		file, err := os.Open(keyFilePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer func() {
 			if err := file.Close(); err != nil {
@@ -143,19 +155,21 @@ func (s *AuthService) initKeySet() error {
 
 		var jwks jose.JSONWebKeySet
 		if err := json.NewDecoder(file).Decode(&jwks); err != nil {
-			return err
+			return nil, err
 		}
 
-		s.keySet = &keySetJWKS{jwks}
-	} else if urlStr := s.Cfg.JWTAuthJWKSetURL; urlStr != "" {
+		return &keySetJWKS{jwks}, nil
+	}
+
+	if urlStr := issuer.JWKSetURL; urlStr != "" {
 		urlParsed, err := url.Parse(urlStr)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if urlParsed.Scheme != "https" && s.Cfg.Env != setting.Dev {
-			return ErrJWTSetURLMustHaveHTTPSScheme
+			return nil, ErrJWTSetURLMustHaveHTTPSScheme
 		}
-		s.keySet = &keySetHTTP{
+		return &keySetHTTP{
 			url: urlStr,
 			log: s.log,
 			client: &http.Client{
@@ -176,12 +190,12 @@ func (s *AuthService) initKeySet() error {
 				Timeout: time.Second * 30,
 			},
 			cacheKey:        fmt.Sprintf("auth-jwt:jwk-%s", urlStr),
-			cacheExpiration: s.Cfg.JWTAuthCacheTTL,
+			cacheExpiration: issuer.CacheTTL,
 			cache:           s.RemoteCache,
-		}
+		}, nil
 	}
 
-	return nil
+	return nil, ErrKeySetIsNotConfigured
 }
 
 func (ks *keySetJWKS) Key(ctx context.Context, keyID string) ([]jose.JSONWebKey, error) {
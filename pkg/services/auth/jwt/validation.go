@@ -4,13 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/go-jose/go-jose/v3/jwt"
 )
 
-func (s *AuthService) initClaimExpectations() error {
-	if err := json.Unmarshal([]byte(s.Cfg.JWTAuthExpectClaims), &s.expect); err != nil {
+func (s *verifier) initClaimExpectations(expectClaims string) error {
+	if err := json.Unmarshal([]byte(expectClaims), &s.expect); err != nil {
 		return err
 	}
 
@@ -52,7 +53,7 @@ func (s *AuthService) initClaimExpectations() error {
 	return nil
 }
 
-func (s *AuthService) validateClaims(claims JWTClaims) error {
+func (s *verifier) validateClaims(claims JWTClaims) error {
 	var registeredClaims jwt.Claims
 	for key, value := range claims {
 		switch key {
@@ -121,6 +122,9 @@ func (s *AuthService) validateClaims(claims JWTClaims) error {
 	if err := registeredClaims.Validate(expectRegistered); err != nil {
 		return err
 	}
+	if len(s.audience) > 0 && !slices.ContainsFunc(s.audience, registeredClaims.Audience.Contains) {
+		return jwt.ErrInvalidAudience
+	}
 
 	for key, expected := range s.expect {
 		value, ok := claims[key]
@@ -34,6 +34,7 @@ const (
 	MetaKeyUsername   = "username"
 	MetaKeyAuthModule = "authModule"
 	MetaKeyIsLogin    = "isLogin"
+	MetaKeyOTP        = "otp"
 )
 
 // ClientParams are hints to the auth service about how to handle the identity management
@@ -170,8 +171,9 @@ func (r *Request) GetMeta(k string) string {
 }
 
 const (
-	KeyOAuthPKCE  = "pkce"
-	KeyOAuthState = "state"
+	KeyOAuthPKCE     = "pkce"
+	KeyOAuthState    = "state"
+	KeySAMLRequestID = "samlRequestId"
 )
 
 type Redirect struct {
@@ -33,6 +33,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/saml"
 	"github.com/grafana/grafana/pkg/services/signingkeys"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -73,6 +74,7 @@ func ProvideService(
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, registerer prometheus.Registerer,
 	signingKeysService signingkeys.Service, oauthServer oauthserver.OAuth2Server,
+	samlService saml.Service,
 ) *Service {
 	s := &Service{
 		log:             log.New("authn.service"),
@@ -93,7 +95,7 @@ func ProvideService(
 	s.RegisterClient(clients.ProvideAPIKey(apikeyService, userService))
 
 	if cfg.LoginCookieName != "" {
-		s.RegisterClient(clients.ProvideSession(cfg, sessionService, features))
+		s.RegisterClient(clients.ProvideSession(cfg, sessionService, orgService, features))
 	}
 
 	var proxyClients []authn.ProxyClient
@@ -112,7 +114,7 @@ func ProvideService(
 
 	// if we have password clients configure check if basic auth or form auth is enabled
 	if len(passwordClients) > 0 {
-		passwordClient := clients.ProvidePassword(loginAttempts, passwordClients...)
+		passwordClient := clients.ProvidePassword(cfg, loginAttempts, passwordClients...)
 		if s.cfg.BasicAuthEnabled {
 			s.RegisterClient(clients.ProvideBasic(passwordClient))
 		}
@@ -144,6 +146,9 @@ func ProvideService(
 		s.RegisterClient(clients.ProvideOAuth(clientName, cfg, oauthTokenService, socialService))
 	}
 
+	// the saml client is registered when saml is disabled as it can be enabled through the sso settings api
+	s.RegisterClient(clients.ProvideSAML(cfg, samlService))
+
 	// FIXME (jguer): move to User package
 	userSyncService := sync.ProvideUserSync(userService, userProtectionService, authInfoService, quotaService)
 	orgUserSyncService := sync.ProvideOrgSync(userService, orgService, accessControlService)
@@ -304,7 +309,12 @@ func (s *Service) Login(ctx context.Context, client string, r *authn.Request) (i
 		s.log.FromContext(ctx).Debug("Failed to parse ip from address", "client", c.Name(), "id", id.ID, "addr", addr, "error", err)
 	}
 
-	sessionToken, err := s.sessionService.CreateToken(ctx, &user.User{ID: intId}, ip, r.HTTPRequest.UserAgent())
+	sessionToken, err := s.sessionService.CreateToken(ctx, &auth.CreateTokenCommand{
+		User:       &user.User{ID: intId},
+		ClientIP:   ip,
+		UserAgent:  r.HTTPRequest.UserAgent(),
+		AuthModule: id.AuthenticatedBy,
+	})
 	if err != nil {
 		s.metrics.failedLogin.WithLabelValues(client).Inc()
 		s.log.FromContext(ctx).Error("Failed to create session", "client", client, "id", id.ID, "err", err)
@@ -359,6 +369,9 @@ func (s *Service) Logout(ctx context.Context, user identity.Requester, sessionTo
 	info, _ := s.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: userID})
 	if info != nil {
 		client := authn.ClientWithPrefix(strings.TrimPrefix(info.AuthModule, "oauth_"))
+		if info.AuthModule == login.SAMLAuthModule {
+			client = authn.ClientSAML
+		}
 
 		c, ok := s.clients[client]
 		if !ok {
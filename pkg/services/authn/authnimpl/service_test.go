@@ -3,7 +3,6 @@ package authnimpl
 import (
 	"context"
 	"errors"
-	"net"
 	"net/http"
 	"net/url"
 	"testing"
@@ -21,7 +20,6 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
-	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -216,11 +214,13 @@ func TestService_Login(t *testing.T) {
 			client:           "fake",
 			expectedClientOK: true,
 			expectedClientIdentity: &authn.Identity{
-				ID: "user:1",
+				ID:              "user:1",
+				AuthenticatedBy: login.PasswordAuthModule,
 			},
 			expectedIdentity: &authn.Identity{
-				ID:           "user:1",
-				SessionToken: &auth.UserToken{UserId: 1},
+				ID:              "user:1",
+				AuthenticatedBy: login.PasswordAuthModule,
+				SessionToken:    &auth.UserToken{UserId: 1, AuthModule: login.PasswordAuthModule},
 			},
 		},
 		{
@@ -247,11 +247,11 @@ func TestService_Login(t *testing.T) {
 					ExpectedIdentity: tt.expectedClientIdentity,
 				})
 				svc.sessionService = &authtest.FakeUserAuthTokenService{
-					CreateTokenProvider: func(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+					CreateTokenProvider: func(ctx context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
 						if tt.expectedSessionErr != nil {
 							return nil, tt.expectedSessionErr
 						}
-						return &auth.UserToken{UserId: user.ID}, nil
+						return &auth.UserToken{UserId: cmd.User.ID, AuthModule: cmd.AuthModule}, nil
 					},
 				}
 			})
@@ -358,6 +358,19 @@ func TestService_Logout(t *testing.T) {
 			},
 			expectedTokenRevoked: true,
 		},
+		{
+			desc:             "should redirect to saml single logout url",
+			identity:         &authn.Identity{ID: authn.NamespacedID(authn.NamespaceUser, 1)},
+			info:             &login.UserAuth{AuthModule: login.SAMLAuthModule},
+			expectedRedirect: &authn.Redirect{URL: "http://idp.com/slo"},
+			client: &authntest.MockClient{
+				NameFunc: func() string { return authn.ClientSAML },
+				LogoutFunc: func(ctx context.Context, _ identity.Requester, _ *login.UserAuth) (*authn.Redirect, bool) {
+					return &authn.Redirect{URL: "http://idp.com/slo"}, true
+				},
+			},
+			expectedTokenRevoked: true,
+		},
 	}
 
 	for _, tt := range tests {
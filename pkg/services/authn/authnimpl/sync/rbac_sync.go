@@ -43,6 +43,13 @@ func (s *RBACSync) SyncPermissionsHook(ctx context.Context, ident *authn.Identit
 	if ident.Permissions == nil {
 		ident.Permissions = make(map[int64]map[string][]string)
 	}
+
+	if ident.TokenPermissions != nil {
+		// Tokens limited to a subset of permissions never grant more than the entity has
+		ident.Permissions[ident.OrgID] = accesscontrol.IntersectTokenPermissions(permissions, ident.TokenPermissions)
+		return nil
+	}
+
 	ident.Permissions[ident.OrgID] = accesscontrol.GroupScopesByAction(permissions)
 	return nil
 }
@@ -51,6 +51,43 @@ func TestRBACSync_SyncPermission(t *testing.T) {
 	}
 }
 
+func TestRBACSync_SyncPermission_TokenPermissions(t *testing.T) {
+	s := &RBACSync{
+		ac: &acmock.Mock{
+			GetUserPermissionsFunc: func(ctx context.Context, siu identity.Requester, o accesscontrol.Options) ([]accesscontrol.Permission, error) {
+				return []accesscontrol.Permission{
+					{Action: "dashboards:read", Scope: "dashboards:*"},
+					{Action: "dashboards:read", Scope: "folders:*"},
+					{Action: "dashboards:write", Scope: "folders:uid:abc"},
+					{Action: accesscontrol.ActionUsersRead, Scope: "global.users:*"},
+				}, nil
+			},
+		},
+		log: log.NewNopLogger(),
+	}
+
+	ident := &authn.Identity{
+		ID:           "service-account:2",
+		OrgID:        1,
+		ClientParams: authn.ClientParams{SyncPermissions: true},
+		TokenPermissions: map[string][]string{
+			"dashboards:read":   {"folders:uid:abc"},
+			"dashboards:write":  {"folders:*"},
+			"dashboards:create": {"folders:*"},
+			"users:read":        {""},
+		},
+	}
+
+	err := s.SyncPermissionsHook(context.Background(), ident, &authn.Request{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"dashboards:read":  {"folders:uid:abc"},
+		"dashboards:write": {"folders:uid:abc"},
+		"users:read":       {"global.users:*"},
+	}, ident.Permissions[1])
+}
+
 func TestRBACSync_SyncCloudRoles(t *testing.T) {
 	type testCase struct {
 		desc           string
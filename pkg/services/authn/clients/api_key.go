@@ -90,7 +90,15 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 		return nil, err
 	}
 
-	return authn.IdentityFromSignedInUser(authn.NamespacedID(authn.NamespaceServiceAccount, usr.UserID), usr, authn.ClientParams{SyncPermissions: true}, login.APIKeyAuthModule), nil
+	identity := authn.IdentityFromSignedInUser(authn.NamespacedID(authn.NamespaceServiceAccount, usr.UserID), usr, authn.ClientParams{SyncPermissions: true}, login.APIKeyAuthModule)
+	if len(apiKey.Permissions) > 0 {
+		identity.TokenPermissions = make(map[string][]string, len(apiKey.Permissions))
+		for _, p := range apiKey.Permissions {
+			identity.TokenPermissions[p.Action] = append(identity.TokenPermissions[p.Action], p.Scope)
+		}
+	}
+
+	return identity, nil
 }
 
 func (s *APIKey) getAPIKey(ctx context.Context, token string) (*apikey.APIKey, error) {
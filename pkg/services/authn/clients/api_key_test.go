@@ -91,6 +91,47 @@ func TestAPIKey_Authenticate(t *testing.T) {
 				AuthenticatedBy: login.APIKeyAuthModule,
 			},
 		},
+		{
+			desc: "should restrict the permissions of a service account token",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				Header: map[string][]string{
+					"Authorization": {"Bearer " + secret},
+				},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				Permissions: []apikey.Permission{
+					{Action: "dashboards:read", Scope: "folders:uid:abc"},
+					{Action: "dashboards:read", Scope: "folders:uid:def"},
+					{Action: "users:read"},
+				},
+			},
+			expectedUser: &user.SignedInUser{
+				UserID:           1,
+				OrgID:            1,
+				IsServiceAccount: true,
+				OrgRole:          org.RoleViewer,
+				Name:             "test",
+			},
+			expectedIdentity: &authn.Identity{
+				ID:             "service-account:1",
+				OrgID:          1,
+				Name:           "test",
+				OrgRoles:       map[int64]org.RoleType{1: org.RoleViewer},
+				IsGrafanaAdmin: boolPtr(false),
+				ClientParams: authn.ClientParams{
+					SyncPermissions: true,
+				},
+				TokenPermissions: map[string][]string{
+					"dashboards:read": {"folders:uid:abc", "folders:uid:def"},
+					"users:read":      {""},
+				},
+				AuthenticatedBy: login.APIKeyAuthModule,
+			},
+		},
 		{
 			desc: "should fail for expired api key",
 			req:  &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{"Authorization": {"Bearer " + secret}}}},
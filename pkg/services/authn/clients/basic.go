@@ -12,6 +12,9 @@ var (
 	errDecodingBasicAuthHeader = errutil.BadRequest("basic-auth.invalid-header", errutil.WithPublicMessage("Invalid Basic Auth Header"))
 )
 
+// otpHeader carries the multi-factor authentication code of the user.
+const otpHeader = "X-Grafana-OTP"
+
 var _ authn.ContextAwareClient = new(Basic)
 
 func ProvideBasic(client authn.PasswordClient) *Basic {
@@ -36,6 +39,9 @@ func (c *Basic) Authenticate(ctx context.Context, r *authn.Request) (*authn.Iden
 		return nil, errDecodingBasicAuthHeader.Errorf("failed to decode basic auth header")
 	}
 
+	if otp := r.HTTPRequest.Header.Get(otpHeader); otp != "" {
+		r.SetMeta(authn.MetaKeyOTP, otp)
+	}
 	return c.client.AuthenticatePassword(ctx, r, username, password)
 }
 
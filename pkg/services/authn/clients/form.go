@@ -25,6 +25,8 @@ type Form struct {
 type loginForm struct {
 	Username string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
+	// OTP is the multi-factor authentication code of the user, if any.
+	OTP string `json:"otp"`
 }
 
 func (c *Form) Name() string {
@@ -36,5 +38,8 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 	if err := web.Bind(r.HTTPRequest, &form); err != nil {
 		return nil, errBadForm.Errorf("failed to parse request: %w", err)
 	}
+	if form.OTP != "" {
+		r.SetMeta(authn.MetaKeyOTP, form.OTP)
+	}
 	return c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
 }
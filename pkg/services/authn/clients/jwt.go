@@ -65,6 +65,8 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 		return nil, errJWTMissingClaim.Errorf("missing mandatory 'sub' claim in JWT")
 	}
 
+	issuer := s.issuerSettings(claims)
+
 	id := &authn.Identity{
 		AuthenticatedBy: login.JWTModule,
 		AuthID:          sub,
@@ -77,11 +79,11 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 			AllowSignUp:     s.cfg.JWTAuthAutoSignUp,
 		}}
 
-	if key := s.cfg.JWTAuthUsernameClaim; key != "" {
+	if key := issuer.UsernameClaim; key != "" {
 		id.Login, _ = claims[key].(string)
 		id.ClientParams.LookUpParams.Login = &id.Login
 	}
-	if key := s.cfg.JWTAuthEmailClaim; key != "" {
+	if key := issuer.EmailClaim; key != "" {
 		id.Email, _ = claims[key].(string)
 		id.ClientParams.LookUpParams.Email = &id.Email
 	}
@@ -90,17 +92,21 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 		id.Name = name
 	}
 
+	if issuer.GroupsAttributePath != "" {
+		id.Groups = s.extractGroups(ctx, issuer.GroupsAttributePath, claims)
+	}
+
 	orgRoles, isGrafanaAdmin, err := getRoles(s.cfg, func() (org.RoleType, *bool, error) {
 		if s.cfg.JWTAuthSkipOrgRoleSync {
 			return "", nil, nil
 		}
 
-		role, grafanaAdmin := s.extractRoleAndAdmin(claims)
-		if s.cfg.JWTAuthRoleAttributeStrict && !role.IsValid() {
+		role, grafanaAdmin := extractRoleAndAdmin(issuer.RoleAttributePath, claims)
+		if issuer.RoleAttributeStrict && !role.IsValid() {
 			return "", nil, errJWTInvalidRole.Errorf("invalid role claim in JWT: %s", role)
 		}
 
-		if !s.cfg.JWTAuthAllowAssignGrafanaAdmin {
+		if !issuer.AllowAssignGrafanaAdmin {
 			return role, nil, nil
 		}
 
@@ -111,6 +117,15 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 		return nil, err
 	}
 
+	// Issuers with their own organization get the role assigned there instead of in the auto assigned one.
+	if issuer.OrgID > 0 {
+		assigned := make(map[int64]org.RoleType, len(orgRoles))
+		for _, role := range orgRoles {
+			assigned[issuer.OrgID] = role
+		}
+		orgRoles = assigned
+	}
+
 	id.OrgRoles = orgRoles
 	id.IsGrafanaAdmin = isGrafanaAdmin
 
@@ -123,6 +138,44 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 	return id, nil
 }
 
+// issuerSettings returns the claim mappings of the issuer of the verified claims,
+// falling back to the [auth.jwt] ones for issuers without their own section.
+func (s *JWT) issuerSettings(claims map[string]any) setting.JWTIssuerSettings {
+	if iss, _ := claims["iss"].(string); iss != "" {
+		for _, issuer := range s.cfg.JWTAuthIssuers {
+			if issuer.Issuer == iss {
+				return issuer
+			}
+		}
+	}
+
+	return setting.JWTIssuerSettings{
+		UsernameClaim:           s.cfg.JWTAuthUsernameClaim,
+		EmailClaim:              s.cfg.JWTAuthEmailClaim,
+		RoleAttributePath:       s.cfg.JWTAuthRoleAttributePath,
+		RoleAttributeStrict:     s.cfg.JWTAuthRoleAttributeStrict,
+		AllowAssignGrafanaAdmin: s.cfg.JWTAuthAllowAssignGrafanaAdmin,
+	}
+}
+
+func (s *JWT) extractGroups(ctx context.Context, groupsAttributePath string, claims map[string]any) []string {
+	val, err := searchClaimsForAttr(groupsAttributePath, claims)
+	if err != nil {
+		s.log.FromContext(ctx).Debug("Failed to search claims for groups", "error", err)
+		return nil
+	}
+
+	values, _ := val.([]any)
+	groups := make([]string, 0, len(values))
+	for _, value := range values {
+		if group, ok := value.(string); ok && group != "" {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
 // remove sensitive query param
 // avoid JWT URL login passing auth_token in URL
 func (s *JWT) stripSensitiveParam(httpRequest *http.Request) {
@@ -170,12 +223,12 @@ func (s *JWT) Priority() uint {
 
 const roleGrafanaAdmin = "GrafanaAdmin"
 
-func (s *JWT) extractRoleAndAdmin(claims map[string]any) (org.RoleType, bool) {
-	if s.cfg.JWTAuthRoleAttributePath == "" {
+func extractRoleAndAdmin(roleAttributePath string, claims map[string]any) (org.RoleType, bool) {
+	if roleAttributePath == "" {
 		return "", false
 	}
 
-	role, err := searchClaimsForStringAttr(s.cfg.JWTAuthRoleAttributePath, claims)
+	role, err := searchClaimsForStringAttr(roleAttributePath, claims)
 	if err != nil || role == "" {
 		return "", false
 	}
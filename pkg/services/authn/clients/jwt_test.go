@@ -87,6 +87,82 @@ func TestAuthenticateJWT(t *testing.T) {
 	assert.EqualValues(t, wantID, id, fmt.Sprintf("%+v", id))
 }
 
+func TestAuthenticateJWT_MultipleIssuers(t *testing.T) {
+	jwtHeaderName := "X-Forwarded-User"
+	cfg := &setting.Cfg{
+		JWTAuthEnabled:           true,
+		JWTAuthHeaderName:        jwtHeaderName,
+		JWTAuthEmailClaim:        "email",
+		JWTAuthUsernameClaim:     "preferred_username",
+		JWTAuthAutoSignUp:        true,
+		JWTAuthRoleAttributePath: "roles",
+		JWTAuthIssuers: []setting.JWTIssuerSettings{{
+			Name:                    "partner",
+			Issuer:                  "https://idp.partner.example",
+			UsernameClaim:           "login",
+			EmailClaim:              "mail",
+			RoleAttributePath:       "contains(groups[*], 'grafana-admins') && 'GrafanaAdmin' || 'Viewer'",
+			AllowAssignGrafanaAdmin: true,
+			GroupsAttributePath:     "groups",
+			OrgID:                   3,
+		}},
+	}
+
+	authenticate := func(t *testing.T, claims jwt.JWTClaims) (*authn.Identity, error) {
+		t.Helper()
+
+		jwtClient := ProvideJWT(&jwt.FakeJWTService{
+			VerifyProvider: func(context.Context, string) (jwt.JWTClaims, error) {
+				return claims, nil
+			},
+		}, cfg)
+		return jwtClient.Authenticate(context.Background(), &authn.Request{
+			OrgID: 1,
+			HTTPRequest: &http.Request{
+				Header: map[string][]string{jwtHeaderName: {"sample-token"}},
+			},
+		})
+	}
+
+	t.Run("uses the claim mappings of the issuer", func(t *testing.T) {
+		id, err := authenticate(t, jwt.JWTClaims{
+			"iss":    "https://idp.partner.example",
+			"sub":    "1234567890",
+			"login":  "eai-doe",
+			"mail":   "eai.doe@partner.example",
+			"groups": []any{"grafana-admins", "ops"},
+			// not mapped for this issuer
+			"email": "other@cor.po",
+			"roles": "Editor",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "eai-doe", id.Login)
+		assert.Equal(t, "eai.doe@partner.example", id.Email)
+		assert.Equal(t, []string{"grafana-admins", "ops"}, id.Groups)
+		assert.Equal(t, map[int64]roletype.RoleType{3: roletype.RoleAdmin}, id.OrgRoles)
+		assert.Equal(t, boolPtr(true), id.IsGrafanaAdmin)
+	})
+
+	t.Run("uses the [auth.jwt] claim mappings for other issuers", func(t *testing.T) {
+		id, err := authenticate(t, jwt.JWTClaims{
+			"iss":                "https://idp.cor.po",
+			"sub":                "1234567890",
+			"preferred_username": "eai-doe",
+			"email":              "eai.doe@cor.po",
+			"groups":             []any{"grafana-admins"},
+			"roles":              "Editor",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "eai-doe", id.Login)
+		assert.Equal(t, "eai.doe@cor.po", id.Email)
+		assert.Empty(t, id.Groups)
+		assert.Equal(t, map[int64]roletype.RoleType{1: roletype.RoleEditor}, id.OrgRoles)
+		assert.Nil(t, id.IsGrafanaAdmin)
+	})
+}
+
 func TestJWTClaimConfig(t *testing.T) {
 	jwtService := &jwt.FakeJWTService{
 		VerifyProvider: func(context.Context, string) (jwt.JWTClaims, error) {
@@ -7,8 +7,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
-	"github.com/grafana/grafana/pkg/web"
 )
 
 var (
@@ -18,11 +18,12 @@ var (
 
 var _ authn.PasswordClient = new(Password)
 
-func ProvidePassword(loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
-	return &Password{loginAttempts, clients, log.New("authn.password")}
+func ProvidePassword(cfg *setting.Cfg, loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
+	return &Password{cfg, loginAttempts, clients, log.New("authn.password")}
 }
 
 type Password struct {
+	cfg           *setting.Cfg
 	loginAttempts loginattempt.Service
 	clients       []authn.PasswordClient
 	log           log.Logger
@@ -31,12 +32,17 @@ type Password struct {
 func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, username, password string) (*authn.Identity, error) {
 	r.SetMeta(authn.MetaKeyUsername, username)
 
-	ok, err := c.loginAttempts.Validate(ctx, username)
+	var ip string
+	if r.HTTPRequest != nil {
+		ip = loginattempt.ClientIP(r.HTTPRequest, c.cfg.BruteForceLoginProtection.TrustedProxies)
+	}
+
+	ok, err := c.loginAttempts.Validate(ctx, username, ip)
 	if err != nil {
 		return nil, err
 	}
 	if !ok {
-		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
+		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user or ip address - login temporarily blocked")
 	}
 
 	if len(password) == 0 {
@@ -57,7 +63,7 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 	}
 
 	if errors.Is(clientErrs, errInvalidPassword) {
-		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		_ = c.loginAttempts.Add(ctx, username, ip)
 	}
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
@@ -0,0 +1,129 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	authidentity "github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const samlRequestIDCookieName = "saml_request_id"
+
+var _ authn.RedirectClient = new(SAML)
+var _ authn.LogoutClient = new(SAML)
+var _ authn.HookClient = new(SAML)
+
+func ProvideSAML(cfg *setting.Cfg, samlService saml.Service) *SAML {
+	return &SAML{log.New(authn.ClientSAML), cfg, samlService}
+}
+
+type SAML struct {
+	log         log.Logger
+	cfg         *setting.Cfg
+	samlService saml.Service
+}
+
+func (c *SAML) Name() string {
+	return authn.ClientSAML
+}
+
+func (c *SAML) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	r.SetMeta(authn.MetaKeyAuthModule, login.SAMLAuthModule)
+
+	if !c.samlService.IsEnabled() {
+		return nil, saml.ErrDisabled.Errorf("saml client is disabled")
+	}
+
+	// responses to logins initiated by the identity provider don't have a request cookie
+	var possibleRequestIDs []string
+	if cookie, err := r.HTTPRequest.Cookie(samlRequestIDCookieName); err == nil && cookie.Value != "" {
+		possibleRequestIDs = []string{cookie.Value}
+	}
+
+	userInfo, err := c.samlService.ParseResponse(ctx, r.HTTPRequest, possibleRequestIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	info := c.samlService.Info()
+
+	// Like for OAuth, looking up existing users by the asserted email is only allowed when configured,
+	// otherwise an identity provider asserting the email of a local user would take over the account.
+	lookupParams := login.UserLookupParams{Login: &userInfo.Login}
+	if c.cfg.OAuthAllowInsecureEmailLookup && userInfo.Email != "" {
+		lookupParams.Email = &userInfo.Email
+	}
+
+	return &authn.Identity{
+		Login:           userInfo.Login,
+		Name:            userInfo.Name,
+		Email:           userInfo.Email,
+		IsGrafanaAdmin:  userInfo.IsGrafanaAdmin,
+		AuthenticatedBy: login.SAMLAuthModule,
+		AuthID:          userInfo.NameID,
+		Groups:          userInfo.Groups,
+		OrgRoles:        userInfo.OrgRoles,
+		ClientParams: authn.ClientParams{
+			SyncUser:        true,
+			SyncTeams:       true,
+			FetchSyncedUser: true,
+			SyncPermissions: true,
+			AllowSignUp:     info.AllowSignUp,
+			SyncOrgRoles:    !info.SkipOrgRoleSync && len(userInfo.OrgRoles) > 0,
+			LookUpParams:    lookupParams,
+		},
+	}, nil
+}
+
+func (c *SAML) RedirectURL(ctx context.Context, r *authn.Request) (*authn.Redirect, error) {
+	req, err := c.samlService.AuthnRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authn.Redirect{
+		URL: req.URL,
+		Extra: map[string]string{
+			authn.KeySAMLRequestID: req.ID,
+		},
+	}, nil
+}
+
+func (c *SAML) Logout(ctx context.Context, user authidentity.Requester, info *login.UserAuth) (*authn.Redirect, bool) {
+	if !c.samlService.IsEnabled() || !c.samlService.Info().SingleLogout {
+		return nil, false
+	}
+
+	redirectURL, err := c.samlService.LogoutRequest(ctx, info.AuthId)
+	if err != nil {
+		namespace, id := user.GetNamespacedID()
+		c.log.FromContext(ctx).Error("Failed to create logout request", "namespace", namespace, "id", id, "error", err)
+		return nil, false
+	}
+
+	return &authn.Redirect{URL: redirectURL}, true
+}
+
+// Hook adds the user to the teams mapped to their groups.
+func (c *SAML) Hook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
+	namespace, identifier := identity.GetNamespacedID()
+	if namespace != authn.NamespaceUser {
+		return nil
+	}
+
+	userID, err := authidentity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return nil
+	}
+
+	if err := c.samlService.SyncTeams(ctx, userID, identity.Groups); err != nil {
+		// the user can sign in with the teams they already belong to
+		c.log.FromContext(ctx).Error("Failed to sync teams", "userId", userID, "error", err)
+	}
+
+	return nil
+}
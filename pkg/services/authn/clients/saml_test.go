@@ -0,0 +1,195 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/services/saml/samltest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSAML_Authenticate(t *testing.T) {
+	isAdmin := true
+	userInfo := &saml.UserInfo{
+		NameID:         "name-id",
+		Login:          "alice",
+		Email:          "alice@example.com",
+		Name:           "Alice",
+		Groups:         []string{"admins"},
+		OrgRoles:       map[int64]org.RoleType{1: org.RoleEditor},
+		IsGrafanaAdmin: &isAdmin,
+	}
+
+	type testCase struct {
+		desc            string
+		service         *samltest.FakeService
+		requestIDCookie string
+		allowEmail      bool
+
+		expectedErr        error
+		expectedIdentity   *authn.Identity
+		expectedRequestIDs []string
+	}
+
+	tests := []testCase{
+		{
+			desc:        "should fail when saml is disabled",
+			service:     &samltest.FakeService{},
+			expectedErr: saml.ErrDisabled,
+		},
+		{
+			desc:            "should fail when the response is invalid",
+			service:         &samltest.FakeService{ExpectedEnabled: true, ExpectedErr: saml.ErrInvalidResponse.Errorf("invalid")},
+			requestIDCookie: "id-1",
+			expectedErr:     saml.ErrInvalidResponse,
+		},
+		{
+			desc: "should return identity for a response to the request of the cookie",
+			service: &samltest.FakeService{
+				ExpectedEnabled:  true,
+				ExpectedInfo:     saml.Info{AllowSignUp: true},
+				ExpectedUserInfo: userInfo,
+			},
+			requestIDCookie:    "id-1",
+			allowEmail:         true,
+			expectedRequestIDs: []string{"id-1"},
+			expectedIdentity: &authn.Identity{
+				Login:           "alice",
+				Name:            "Alice",
+				Email:           "alice@example.com",
+				IsGrafanaAdmin:  &isAdmin,
+				AuthenticatedBy: login.SAMLAuthModule,
+				AuthID:          "name-id",
+				Groups:          []string{"admins"},
+				OrgRoles:        map[int64]org.RoleType{1: org.RoleEditor},
+				ClientParams: authn.ClientParams{
+					SyncUser:        true,
+					SyncTeams:       true,
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					AllowSignUp:     true,
+					SyncOrgRoles:    true,
+					LookUpParams:    login.UserLookupParams{Login: strPtr("alice"), Email: strPtr("alice@example.com")},
+				},
+			},
+		},
+		{
+			desc: "should not sync org roles when skip org role sync is enabled nor look up users by email by default",
+			service: &samltest.FakeService{
+				ExpectedEnabled:  true,
+				ExpectedInfo:     saml.Info{SkipOrgRoleSync: true},
+				ExpectedUserInfo: userInfo,
+			},
+			expectedIdentity: &authn.Identity{
+				Login:           "alice",
+				Name:            "Alice",
+				Email:           "alice@example.com",
+				IsGrafanaAdmin:  &isAdmin,
+				AuthenticatedBy: login.SAMLAuthModule,
+				AuthID:          "name-id",
+				Groups:          []string{"admins"},
+				OrgRoles:        map[int64]org.RoleType{1: org.RoleEditor},
+				ClientParams: authn.ClientParams{
+					SyncUser:        true,
+					SyncTeams:       true,
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					LookUpParams:    login.UserLookupParams{Login: strPtr("alice")},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req := &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{}}}
+			if tt.requestIDCookie != "" {
+				req.HTTPRequest.AddCookie(&http.Cookie{Name: samlRequestIDCookieName, Value: tt.requestIDCookie})
+			}
+
+			cfg := setting.NewCfg()
+			cfg.OAuthAllowInsecureEmailLookup = tt.allowEmail
+			c := ProvideSAML(cfg, tt.service)
+			identity, err := c.Authenticate(context.Background(), req)
+			assert.Equal(t, login.SAMLAuthModule, req.GetMeta(authn.MetaKeyAuthModule))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIdentity, identity)
+			assert.Equal(t, tt.expectedRequestIDs, tt.service.RequestIDs)
+		})
+	}
+}
+
+func TestSAML_RedirectURL(t *testing.T) {
+	c := ProvideSAML(setting.NewCfg(), &samltest.FakeService{
+		ExpectedAuthnRequest: &saml.AuthnRequest{ID: "id-1", URL: "https://idp.example.com/sso?SAMLRequest=request"},
+	})
+
+	redirect, err := c.RedirectURL(context.Background(), &authn.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://idp.example.com/sso?SAMLRequest=request", redirect.URL)
+	assert.Equal(t, "id-1", redirect.Extra[authn.KeySAMLRequestID])
+}
+
+func TestSAML_Logout(t *testing.T) {
+	tests := []struct {
+		desc        string
+		service     *samltest.FakeService
+		expectedOK  bool
+		expectedURL string
+	}{
+		{
+			desc:    "should not redirect when single logout is disabled",
+			service: &samltest.FakeService{ExpectedEnabled: true, ExpectedLogoutURL: "https://idp.example.com/slo"},
+		},
+		{
+			desc: "should not redirect when the logout request fails",
+			service: &samltest.FakeService{
+				ExpectedEnabled: true, ExpectedInfo: saml.Info{SingleLogout: true}, ExpectedErr: errors.New("failed"),
+			},
+		},
+		{
+			desc: "should redirect to the identity provider",
+			service: &samltest.FakeService{
+				ExpectedEnabled: true, ExpectedInfo: saml.Info{SingleLogout: true}, ExpectedLogoutURL: "https://idp.example.com/slo",
+			},
+			expectedOK:  true,
+			expectedURL: "https://idp.example.com/slo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := ProvideSAML(setting.NewCfg(), tt.service)
+			redirect, ok := c.Logout(context.Background(), &user.SignedInUser{UserID: 1}, &login.UserAuth{AuthId: "name-id"})
+
+			assert.Equal(t, tt.expectedOK, ok)
+			if tt.expectedOK {
+				assert.Equal(t, tt.expectedURL, redirect.URL)
+			}
+		})
+	}
+}
+
+func TestSAML_Hook(t *testing.T) {
+	service := &samltest.FakeService{}
+	c := ProvideSAML(setting.NewCfg(), service)
+
+	err := c.Hook(context.Background(), &authn.Identity{ID: "user:2", Groups: []string{"admins"}}, &authn.Request{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins"}, service.SyncedGroups)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -18,12 +19,13 @@ import (
 var _ authn.HookClient = new(Session)
 var _ authn.ContextAwareClient = new(Session)
 
-func ProvideSession(cfg *setting.Cfg, sessionService auth.UserTokenService,
+func ProvideSession(cfg *setting.Cfg, sessionService auth.UserTokenService, orgService org.Service,
 	features featuremgmt.FeatureToggles) *Session {
 	return &Session{
 		cfg:            cfg,
 		features:       features,
 		sessionService: sessionService,
+		orgService:     orgService,
 		log:            log.New(authn.ClientSession),
 	}
 }
@@ -32,6 +34,7 @@ type Session struct {
 	cfg            *setting.Cfg
 	features       featuremgmt.FeatureToggles
 	sessionService auth.UserTokenService
+	orgService     org.Service
 	log            log.Logger
 }
 
@@ -88,7 +91,15 @@ func (s *Session) Priority() uint {
 }
 
 func (s *Session) Hook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
-	if identity.SessionToken == nil || s.features.IsEnabled(ctx, featuremgmt.FlagClientTokenRotation) {
+	if identity.SessionToken == nil {
+		return nil
+	}
+
+	if err := s.validateOrgLifetime(ctx, identity); err != nil {
+		return err
+	}
+
+	if s.features.IsEnabled(ctx, featuremgmt.FlagClientTokenRotation) {
 		return nil
 	}
 
@@ -123,3 +134,40 @@ func (s *Session) Hook(ctx context.Context, identity *authn.Identity, r *authn.R
 
 	return nil
 }
+
+// validateOrgLifetime expires the session when it exceeds the strictest lifetimes configured for the orgs
+// of the user. Switching to another org must not extend the session, so every org of the user is checked.
+func (s *Session) validateOrgLifetime(ctx context.Context, identity *authn.Identity) error {
+	if len(s.cfg.LoginMaxLifetimeByOrg) == 0 && len(s.cfg.LoginMaxInactiveLifetimeByOrg) == 0 {
+		return nil
+	}
+
+	token := identity.SessionToken
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: token.UserId})
+	if err != nil {
+		return err
+	}
+
+	var maxLifetime, maxInactiveLifetime time.Duration
+	for _, o := range orgs {
+		if d, ok := s.cfg.LoginMaxLifetimeByOrg[o.OrgID]; ok && (maxLifetime == 0 || d < maxLifetime) {
+			maxLifetime = d
+		}
+		if d, ok := s.cfg.LoginMaxInactiveLifetimeByOrg[o.OrgID]; ok && (maxInactiveLifetime == 0 || d < maxInactiveLifetime) {
+			maxInactiveLifetime = d
+		}
+	}
+
+	now := time.Now()
+	if maxLifetime > 0 && !time.Unix(token.CreatedAt, 0).Add(maxLifetime).After(now) {
+		s.log.Debug("User token has expired for the orgs of the user", "userID", token.UserId, "tokenID", token.Id, "createdAt", token.CreatedAt)
+		return &auth.TokenExpiredError{UserID: token.UserId, TokenID: token.Id}
+	}
+
+	if maxInactiveLifetime > 0 && !time.Unix(token.RotatedAt, 0).Add(maxInactiveLifetime).After(now) {
+		s.log.Debug("User token has been inactive for too long for the orgs of the user", "userID", token.UserId, "tokenID", token.Id, "rotatedAt", token.RotatedAt)
+		return &auth.TokenExpiredError{UserID: token.UserId, TokenID: token.Id}
+	}
+
+	return nil
+}
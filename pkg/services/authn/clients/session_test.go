@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -29,7 +31,7 @@ func TestSession_Test(t *testing.T) {
 	cfg := setting.NewCfg()
 	cfg.LoginCookieName = ""
 	cfg.LoginMaxLifetime = 20 * time.Second
-	s := ProvideSession(cfg, &authtest.FakeUserAuthTokenService{}, featuremgmt.WithFeatures())
+	s := ProvideSession(cfg, &authtest.FakeUserAuthTokenService{}, &orgtest.FakeOrgService{}, featuremgmt.WithFeatures())
 
 	disabled := s.Test(context.Background(), &authn.Request{HTTPRequest: validHTTPReq})
 	assert.False(t, disabled)
@@ -145,7 +147,7 @@ func TestSession_Authenticate(t *testing.T) {
 			cfg.LoginCookieName = cookieName
 			cfg.TokenRotationIntervalMinutes = 10
 			cfg.LoginMaxLifetime = 20 * time.Second
-			s := ProvideSession(cfg, tt.fields.sessionService, tt.fields.features)
+			s := ProvideSession(cfg, tt.fields.sessionService, &orgtest.FakeOrgService{}, tt.fields.features)
 
 			got, err := s.Authenticate(context.Background(), tt.args.r)
 			require.True(t, (err != nil) == tt.wantErr, err)
@@ -185,7 +187,7 @@ func TestSession_Hook(t *testing.T) {
 				token.UnhashedToken = "new-token"
 				return true, token, nil
 			},
-		}, featuremgmt.WithFeatures())
+		}, &orgtest.FakeOrgService{}, featuremgmt.WithFeatures())
 
 		sampleID := &authn.Identity{
 			SessionToken: &auth.UserToken{
@@ -218,8 +220,49 @@ func TestSession_Hook(t *testing.T) {
 			mockResponseWriter.HeaderStore.Get("set-cookie"), mockResponseWriter.HeaderStore)
 	})
 
+	t.Run("should expire token exceeding the strictest lifetimes of the orgs of the user", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LoginMaxLifetimeByOrg = map[int64]time.Duration{2: time.Hour, 3: 4 * time.Hour}
+		cfg.LoginMaxInactiveLifetimeByOrg = map[int64]time.Duration{3: 30 * time.Minute, 4: time.Hour}
+		orgService := &orgtest.FakeOrgService{}
+		s := ProvideSession(cfg, nil, orgService, featuremgmt.WithFeatures(featuremgmt.FlagClientTokenRotation))
+
+		token := &auth.UserToken{
+			Id:        1,
+			UserId:    1,
+			CreatedAt: time.Now().Add(-2 * time.Hour).Unix(),
+			RotatedAt: time.Now().Add(-20 * time.Minute).Unix(),
+		}
+		userOrgs := func(orgIDs ...int64) []*org.UserOrgDTO {
+			orgs := make([]*org.UserOrgDTO, 0, len(orgIDs))
+			for _, orgID := range orgIDs {
+				orgs = append(orgs, &org.UserOrgDTO{OrgID: orgID})
+			}
+			return orgs
+		}
+
+		orgService.ExpectedUserOrgDTO = userOrgs(1)
+		err := s.Hook(context.Background(), &authn.Identity{OrgID: 1, SessionToken: token}, &authn.Request{})
+		require.NoError(t, err)
+
+		// the session is expired in every org once it exceeds the lifetime of one of the orgs of the user
+		orgService.ExpectedUserOrgDTO = userOrgs(1, 2)
+		err = s.Hook(context.Background(), &authn.Identity{OrgID: 1, SessionToken: token}, &authn.Request{})
+		var expiredErr *auth.TokenExpiredError
+		require.ErrorAs(t, err, &expiredErr)
+		assert.ErrorIs(t, err, auth.ErrInvalidSessionToken)
+
+		orgService.ExpectedUserOrgDTO = userOrgs(3, 4)
+		err = s.Hook(context.Background(), &authn.Identity{OrgID: 4, SessionToken: token}, &authn.Request{})
+		require.NoError(t, err)
+
+		token.RotatedAt = time.Now().Add(-40 * time.Minute).Unix()
+		err = s.Hook(context.Background(), &authn.Identity{OrgID: 4, SessionToken: token}, &authn.Request{})
+		require.ErrorAs(t, err, &expiredErr)
+	})
+
 	t.Run("should not rotate token with feature flag", func(t *testing.T) {
-		s := ProvideSession(setting.NewCfg(), nil, featuremgmt.WithFeatures(featuremgmt.FlagClientTokenRotation))
+		s := ProvideSession(setting.NewCfg(), nil, &orgtest.FakeOrgService{}, featuremgmt.WithFeatures(featuremgmt.FlagClientTokenRotation))
 
 		req := &authn.Request{}
 		identity := &authn.Identity{}
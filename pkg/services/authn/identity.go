@@ -81,6 +81,9 @@ type Identity struct {
 	ClientParams ClientParams
 	// Permissions is the list of permissions the entity has.
 	Permissions map[int64]map[string][]string
+	// TokenPermissions, grouped by action, restricts the permissions of the entity to those also granted here.
+	// Set for service account tokens limited to a subset of the service account permissions.
+	TokenPermissions map[string][]string
 	// IDToken is a signed token representing the identity that can be forwarded to plugins and external services.
 	// Will only be set when featuremgmt.FlagIdForwarding is enabled.
 	IDToken string
@@ -190,21 +193,22 @@ func (i *Identity) SignedInUser() *user.SignedInUser {
 	namespace, id := i.GetNamespacedID()
 
 	u := &user.SignedInUser{
-		OrgID:           i.OrgID,
-		OrgName:         i.OrgName,
-		OrgRole:         i.GetOrgRole(),
-		Login:           i.Login,
-		Name:            i.Name,
-		Email:           i.Email,
-		AuthenticatedBy: i.AuthenticatedBy,
-		IsGrafanaAdmin:  i.GetIsGrafanaAdmin(),
-		IsAnonymous:     namespace == NamespaceAnonymous,
-		IsDisabled:      i.IsDisabled,
-		HelpFlags1:      i.HelpFlags1,
-		LastSeenAt:      i.LastSeenAt,
-		Teams:           i.Teams,
-		Permissions:     i.Permissions,
-		IDToken:         i.IDToken,
+		OrgID:            i.OrgID,
+		OrgName:          i.OrgName,
+		OrgRole:          i.GetOrgRole(),
+		Login:            i.Login,
+		Name:             i.Name,
+		Email:            i.Email,
+		AuthenticatedBy:  i.AuthenticatedBy,
+		IsGrafanaAdmin:   i.GetIsGrafanaAdmin(),
+		IsAnonymous:      namespace == NamespaceAnonymous,
+		IsDisabled:       i.IsDisabled,
+		HelpFlags1:       i.HelpFlags1,
+		LastSeenAt:       i.LastSeenAt,
+		Teams:            i.Teams,
+		Permissions:      i.Permissions,
+		IDToken:          i.IDToken,
+		TokenPermissions: i.TokenPermissions,
 	}
 
 	if namespace == NamespaceAPIKey {
@@ -0,0 +1,25 @@
+package caching
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+type cachingMetrics struct {
+	// requestsTotal counts the lookups of the cache by status, which gives the hit rate of
+	// the query and resource caches.
+	requestsTotal *prometheus.CounterVec
+}
+
+func newCachingMetrics(reg prometheus.Registerer) *cachingMetrics {
+	return &cachingMetrics{
+		requestsTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "caching",
+			Name:      "requests_total",
+			Help:      "counter of query and resource cache lookups by status",
+		}, []string{"kind", "status"}),
+	}
+}
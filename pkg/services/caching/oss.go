@@ -0,0 +1,395 @@
+package caching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	cacheKindQuery    = "query"
+	cacheKindResource = "resource"
+
+	keyPrefix = "caching:"
+	// generations outlive any cached response, so bumping one invalidates every entry of a data source
+	generationExpiry = 30 * 24 * time.Hour
+)
+
+// queryKeysIgnored are query properties that change between identical requests and are
+// left out of cache keys.
+var queryKeysIgnored = []string{"requestId", "queryCachingTTL", "datasourceId"}
+
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, reg prometheus.Registerer) *OSSCachingService {
+	return &OSSCachingService{
+		cfg:     cfg.QueryCaching,
+		cache:   cache,
+		logger:  log.New("caching"),
+		metrics: newCachingMetrics(reg),
+	}
+}
+
+// OSSCachingService stores query and resource responses in the remote cache for the data sources
+// that turn caching on. Cache keys are derived from the queries, the aligned time range, the data
+// source version and, for data sources forwarding user credentials, the user. Other responses are
+// shared by all users of the data source. The zero value does not cache anything.
+type OSSCachingService struct {
+	cfg     setting.QueryCachingSettings
+	cache   remotecache.CacheStorage
+	logger  log.Logger
+	metrics *cachingMetrics
+}
+
+var (
+	_ CachingService   = &OSSCachingService{}
+	_ CacheInvalidator = &OSSCachingService{}
+)
+
+// dataSourceCachingConfig holds the caching options read from the data source JSON data.
+type dataSourceCachingConfig struct {
+	// Enabled turns caching on for the data source. Responses can depend on the permissions of
+	// the user, so data sources are only cached when an admin opts in.
+	Enabled bool `json:"queryCachingEnabled"`
+	// TTLMs overrides the default TTL of query responses, in milliseconds
+	TTLMs int64 `json:"queryCachingTTL"`
+	// Responses of data sources forwarding the identity of the user are cached per user
+	OAuthPassThru bool     `json:"oauthPassThru"`
+	KeepCookies   []string `json:"keepCookies"`
+}
+
+func (c dataSourceCachingConfig) userScoped() bool {
+	return c.OAuthPassThru || len(c.KeepCookies) > 0
+}
+
+func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
+	if !s.enabled() || req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	dsCfg, ok := s.dataSourceConfig(ctx, cacheKindQuery, req.PluginContext)
+	if !ok {
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := s.queryKey(ctx, req, dsCfg)
+	if err != nil {
+		s.logger.Warn("Failed to create query cache key", "error", err)
+		s.setStatus(ctx, cacheKindQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	b, err := s.cache.Get(ctx, key)
+	if err == nil {
+		resp := &backend.QueryDataResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			s.setStatus(ctx, cacheKindQuery, StatusHit)
+			return true, CachedQueryDataResponse{Response: resp}
+		}
+		s.logger.Warn("Failed to read cached query response", "error", err)
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.logger.Warn("Failed to get cached query response", "error", err)
+		s.setStatus(ctx, cacheKindQuery, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	s.setStatus(ctx, cacheKindQuery, StatusMiss)
+	ttl := s.queryTTL(req, dsCfg)
+	return false, CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.QueryDataResponse) {
+			if resp == nil || !cacheableQueryResponse(resp) {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.logger.Warn("Failed to marshal query response", "error", err)
+				return
+			}
+			s.store(ctx, key, b, ttl)
+		},
+	}
+}
+
+func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
+	if !s.enabled() || req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return false, CachedResourceDataResponse{}
+	}
+
+	// Only reads are cached, other requests may change the state of the data source
+	if req.Method != http.MethodGet {
+		return false, CachedResourceDataResponse{}
+	}
+
+	dsCfg, ok := s.dataSourceConfig(ctx, cacheKindResource, req.PluginContext)
+	if !ok {
+		return false, CachedResourceDataResponse{}
+	}
+
+	key, err := s.resourceKey(ctx, req, dsCfg)
+	if err != nil {
+		s.logger.Warn("Failed to create resource cache key", "error", err)
+		s.setStatus(ctx, cacheKindResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	b, err := s.cache.Get(ctx, key)
+	if err == nil {
+		resp := &backend.CallResourceResponse{}
+		if err := json.Unmarshal(b, resp); err == nil {
+			s.setStatus(ctx, cacheKindResource, StatusHit)
+			return true, CachedResourceDataResponse{Response: resp}
+		}
+		s.logger.Warn("Failed to read cached resource response", "error", err)
+	} else if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		s.logger.Warn("Failed to get cached resource response", "error", err)
+		s.setStatus(ctx, cacheKindResource, StatusError)
+		return false, CachedResourceDataResponse{}
+	}
+
+	s.setStatus(ctx, cacheKindResource, StatusMiss)
+
+	// Plugins can stream several responses for one request. Only single responses are
+	// cached, so the entry is removed again when a second response arrives.
+	var mu sync.Mutex
+	responses := 0
+	return false, CachedResourceDataResponse{
+		UpdateCacheFn: func(ctx context.Context, resp *backend.CallResourceResponse) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			responses++
+			if responses > 1 {
+				if responses == 2 {
+					if err := s.cache.Delete(ctx, key); err != nil {
+						s.logger.Warn("Failed to delete cached resource response", "error", err)
+					}
+				}
+				return
+			}
+
+			if resp == nil || resp.Status < 200 || resp.Status > 299 {
+				return
+			}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				s.logger.Warn("Failed to marshal resource response", "error", err)
+				return
+			}
+			s.store(ctx, key, b, s.cfg.ResourcesTTL)
+		},
+	}
+}
+
+// InvalidateDataSource removes the cached responses of the data source by moving it to a new
+// generation, which is part of every cache key.
+func (s *OSSCachingService) InvalidateDataSource(ctx context.Context, orgID int64, dsUID string) error {
+	if s.cache == nil {
+		return nil
+	}
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	return s.cache.Set(ctx, generationKey(orgID, dsUID), []byte(generation), generationExpiry)
+}
+
+func (s *OSSCachingService) enabled() bool {
+	return s.cache != nil && s.cfg.Enabled
+}
+
+// dataSourceConfig returns the caching options of the data source, or false if the request
+// must not use the cache.
+func (s *OSSCachingService) dataSourceConfig(ctx context.Context, kind string, pCtx backend.PluginContext) (dataSourceCachingConfig, bool) {
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		s.setStatus(ctx, kind, StatusBypass)
+		return dataSourceCachingConfig{}, false
+	}
+
+	dsCfg := dataSourceCachingConfig{}
+	if jsonData := pCtx.DataSourceInstanceSettings.JSONData; len(jsonData) > 0 {
+		if err := json.Unmarshal(jsonData, &dsCfg); err != nil {
+			s.logger.Warn("Failed to read data source caching config", "error", err)
+			s.setStatus(ctx, kind, StatusError)
+			return dsCfg, false
+		}
+	}
+
+	if !dsCfg.Enabled {
+		s.setStatus(ctx, kind, StatusDisabled)
+		return dsCfg, false
+	}
+
+	return dsCfg, true
+}
+
+// queryTTL returns the shortest TTL requested by the queries, falling back to the TTL of the
+// data source and then to the configured default.
+func (s *OSSCachingService) queryTTL(req *backend.QueryDataRequest, dsCfg dataSourceCachingConfig) time.Duration {
+	var ttl time.Duration
+	for _, q := range req.Queries {
+		model := struct {
+			TTLMs int64 `json:"queryCachingTTL"`
+		}{}
+		if err := json.Unmarshal(q.JSON, &model); err != nil || model.TTLMs <= 0 {
+			continue
+		}
+		if d := time.Duration(model.TTLMs) * time.Millisecond; ttl == 0 || d < ttl {
+			ttl = d
+		}
+	}
+	if ttl > 0 {
+		return ttl
+	}
+	if dsCfg.TTLMs > 0 {
+		return time.Duration(dsCfg.TTLMs) * time.Millisecond
+	}
+	return s.cfg.TTL
+}
+
+type cacheKeyQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	IntervalMs    int64           `json:"intervalMs"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	Model         json.RawMessage `json:"model"`
+}
+
+func (s *OSSCachingService) queryKey(ctx context.Context, req *backend.QueryDataRequest, dsCfg dataSourceCachingConfig) (string, error) {
+	queries := make([]cacheKeyQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		model, err := normalizeQueryModel(q.JSON)
+		if err != nil {
+			return "", err
+		}
+		queries = append(queries, cacheKeyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			From:          alignTime(q.TimeRange.From, s.cfg.TimeRangeAlignment).UnixMilli(),
+			To:            alignTime(q.TimeRange.To, s.cfg.TimeRangeAlignment).UnixMilli(),
+			IntervalMs:    q.Interval.Milliseconds(),
+			MaxDataPoints: q.MaxDataPoints,
+			Model:         model,
+		})
+	}
+	return s.key(ctx, cacheKindQuery, req.PluginContext, dsCfg, queries)
+}
+
+func (s *OSSCachingService) resourceKey(ctx context.Context, req *backend.CallResourceRequest, dsCfg dataSourceCachingConfig) (string, error) {
+	return s.key(ctx, cacheKindResource, req.PluginContext, dsCfg, struct {
+		Path string `json:"path"`
+		URL  string `json:"url"`
+	}{
+		Path: req.Path,
+		URL:  req.URL,
+	})
+}
+
+// key hashes the request together with the data source version, its generation and the
+// user, when responses depend on the identity of the user.
+func (s *OSSCachingService) key(ctx context.Context, kind string, pCtx backend.PluginContext, dsCfg dataSourceCachingConfig, request any) (string, error) {
+	ds := pCtx.DataSourceInstanceSettings
+
+	generation, err := s.cache.Get(ctx, generationKey(pCtx.OrgID, ds.UID))
+	if err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return "", err
+	}
+
+	scope := ""
+	if dsCfg.userScoped() {
+		if pCtx.User == nil {
+			return "", fmt.Errorf("data source forwards user credentials but the request has no user")
+		}
+		scope = pCtx.User.Login
+	}
+
+	b, err := json.Marshal(struct {
+		OrgID      int64  `json:"orgId"`
+		Datasource string `json:"datasource"`
+		Updated    int64  `json:"updated"`
+		Generation string `json:"generation"`
+		Scope      string `json:"scope"`
+		Request    any    `json:"request"`
+	}{
+		OrgID:      pCtx.OrgID,
+		Datasource: ds.UID,
+		Updated:    ds.Updated.UnixNano(),
+		Generation: string(generation),
+		Scope:      scope,
+		Request:    request,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(b)
+	return keyPrefix + kind + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+func (s *OSSCachingService) store(ctx context.Context, key string, b []byte, ttl time.Duration) {
+	if s.cfg.MaxValueSize > 0 && len(b) > s.cfg.MaxValueSize {
+		s.logger.Debug("Response is too large to be cached", "size", len(b))
+		return
+	}
+	if err := s.cache.Set(ctx, key, b, ttl); err != nil {
+		s.logger.Warn("Failed to cache response", "error", err)
+	}
+}
+
+// setStatus writes the X-Cache response header and counts the request.
+func (s *OSSCachingService) setStatus(ctx context.Context, kind string, status string) {
+	if s.metrics != nil {
+		s.metrics.requestsTotal.WithLabelValues(kind, status).Inc()
+	}
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
+}
+
+// cacheableQueryResponse returns false if any query failed, so errors are retried.
+func cacheableQueryResponse(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil || (r.Status != 0 && r.Status != backend.StatusOK) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeQueryModel drops the properties that do not change the result of the query and
+// re-encodes the model with sorted keys.
+func normalizeQueryModel(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	model := map[string]any{}
+	if err := json.Unmarshal(raw, &model); err != nil {
+		return nil, err
+	}
+	for _, k := range queryKeysIgnored {
+		delete(model, k)
+	}
+	return json.Marshal(model)
+}
+
+func alignTime(t time.Time, alignment time.Duration) time.Time {
+	if alignment <= 0 {
+		return t
+	}
+	return t.Truncate(alignment)
+}
+
+func generationKey(orgID int64, dsUID string) string {
+	return fmt.Sprintf("%sgeneration:%d:%s", keyPrefix, orgID, dsUID)
+}
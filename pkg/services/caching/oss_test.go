@@ -0,0 +1,260 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func newTestService(t *testing.T) (*OSSCachingService, remotecache.FakeCacheStorage) {
+	t.Helper()
+	storage := remotecache.NewFakeCacheStorage()
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{
+		Enabled:            true,
+		TTL:                time.Minute,
+		ResourcesTTL:       time.Minute,
+		TimeRangeAlignment: time.Minute,
+	}
+	return ProvideCachingService(cfg, storage, prometheus.NewRegistry()), storage
+}
+
+func newTestContext(t *testing.T, skipCache bool) (context.Context, http.Header) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	reqCtx := &contextmodel.ReqContext{
+		Context:        &web.Context{Req: req, Resp: web.NewResponseWriter(req.Method, httptest.NewRecorder())},
+		SkipQueryCache: skipCache,
+	}
+	return ctxkey.Set(context.Background(), reqCtx), reqCtx.Resp.Header()
+}
+
+func newQueryRequest(from time.Time, jsonData string, query string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			User:  &backend.User{Login: "admin"},
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:      "ds",
+				JSONData: []byte(jsonData),
+				Updated:  time.Unix(100, 0),
+			},
+		},
+		Queries: []backend.DataQuery{
+			{
+				RefID:     "A",
+				JSON:      []byte(query),
+				TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			},
+		},
+	}
+}
+
+func TestQueryCaching(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 10, 0, time.UTC)
+	response := &backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": backend.DataResponse{Frames: data.Frames{data.NewFrame("test", data.NewField("value", nil, []float64{1}))}},
+		},
+	}
+
+	t.Run("miss, then hit for a request in the same aligned time range", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, header := newTestContext(t, false)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up","requestId":"1"}`))
+		require.False(t, hit)
+		require.Equal(t, StatusMiss, header.Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, response)
+
+		ctx, header = newTestContext(t, false)
+		hit, cr = s.HandleQueryRequest(ctx, newQueryRequest(now.Add(20*time.Second), `{"queryCachingEnabled":true}`, `{"requestId":"2","expr":"up"}`))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, header.Get(XCacheHeader))
+		require.Equal(t, "test", cr.Response.Responses["A"].Frames[0].Name)
+	})
+
+	t.Run("different queries and time ranges do not share entries", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up"}`))
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"down"}`))
+		require.False(t, hit)
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(now.Add(time.Minute), `{"queryCachingEnabled":true}`, `{"expr":"up"}`))
+		require.False(t, hit)
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		s, storage := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up"}`))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{
+			Responses: backend.Responses{"A": backend.ErrDataResponse(backend.StatusBadRequest, "bad query")},
+		})
+		require.Empty(t, storage.Storage)
+	})
+
+	t.Run("skip header bypasses the cache", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, header := newTestContext(t, true)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up"}`))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Equal(t, StatusBypass, header.Get(XCacheHeader))
+	})
+
+	t.Run("data sources must turn caching on", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		for _, jsonData := range []string{`{}`, `{"queryCachingEnabled":false}`} {
+			ctx, header := newTestContext(t, false)
+			hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, jsonData, `{"expr":"up"}`))
+			require.False(t, hit)
+			require.Nil(t, cr.UpdateCacheFn)
+			require.Equal(t, StatusDisabled, header.Get(XCacheHeader))
+		}
+	})
+
+	t.Run("responses of data sources forwarding the user are cached per user", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		req := newQueryRequest(now, `{"queryCachingEnabled":true,"oauthPassThru":true}`, `{"expr":"up"}`)
+		_, cr := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, response)
+
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		require.True(t, hit)
+
+		req.PluginContext.User = &backend.User{Login: "viewer"}
+		hit, _ = s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+	})
+
+	t.Run("invalidating a data source removes its entries", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		req := newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up"}`)
+		_, cr := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, response)
+
+		require.NoError(t, s.InvalidateDataSource(ctx, 1, "ds"))
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		require.False(t, hit)
+	})
+
+	t.Run("TTL is read from the queries, then the data source", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		req := newQueryRequest(now, `{"queryCachingEnabled":true,"queryCachingTTL":30000}`, `{"expr":"up"}`)
+		require.Equal(t, 30*time.Second, s.queryTTL(req, dataSourceCachingConfig{TTLMs: 30000}))
+
+		req.Queries = append(req.Queries, backend.DataQuery{RefID: "B", JSON: []byte(`{"queryCachingTTL":10000}`)})
+		require.Equal(t, 10*time.Second, s.queryTTL(req, dataSourceCachingConfig{TTLMs: 30000}))
+
+		require.Equal(t, time.Minute, s.queryTTL(newQueryRequest(now, `{"queryCachingEnabled":true}`, `{}`), dataSourceCachingConfig{}))
+	})
+
+	t.Run("disabled service does nothing", func(t *testing.T) {
+		s := &OSSCachingService{}
+
+		ctx, header := newTestContext(t, false)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(now, `{"queryCachingEnabled":true}`, `{"expr":"up"}`))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+		require.Empty(t, header.Get(XCacheHeader))
+	})
+}
+
+func TestResourceCaching(t *testing.T) {
+	newResourceRequest := func(method string) *backend.CallResourceRequest {
+		return &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				OrgID: 1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:      "ds",
+					JSONData: []byte(`{"queryCachingEnabled":true}`),
+				},
+			},
+			Method: method,
+			Path:   "labels",
+			URL:    "labels?match=up",
+		}
+	}
+
+	t.Run("miss, then hit", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.False(t, hit)
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`["job"]`)})
+
+		ctx, header := newTestContext(t, false)
+		hit, cr = s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		require.True(t, hit)
+		require.Equal(t, StatusHit, header.Get(XCacheHeader))
+		require.Equal(t, []byte(`["job"]`), cr.Response.Body)
+	})
+
+	t.Run("streamed responses are not cached", func(t *testing.T) {
+		s, storage := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`1`)})
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusOK, Body: []byte(`2`)})
+		require.Empty(t, storage.Storage)
+	})
+
+	t.Run("only GET requests are cached", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		hit, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodPost))
+		require.False(t, hit)
+		require.Nil(t, cr.UpdateCacheFn)
+	})
+
+	t.Run("error responses are not cached", func(t *testing.T) {
+		s, storage := newTestService(t)
+
+		ctx, _ := newTestContext(t, false)
+		_, cr := s.HandleResourceRequest(ctx, newResourceRequest(http.MethodGet))
+		cr.UpdateCacheFn(ctx, &backend.CallResourceResponse{Status: http.StatusInternalServerError})
+		require.Empty(t, storage.Storage)
+	})
+}
+
+func TestNormalizeQueryModel(t *testing.T) {
+	a, err := normalizeQueryModel([]byte(`{"expr":"up","refId":"A","requestId":"1","queryCachingTTL":100}`))
+	require.NoError(t, err)
+	b, err := normalizeQueryModel([]byte(`{"refId":"A","expr":"up","requestId":"2"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, string(a), string(b))
+
+	var model map[string]any
+	require.NoError(t, json.Unmarshal(a, &model))
+	require.Equal(t, map[string]any{"expr": "up", "refId": "A"}, model)
+}
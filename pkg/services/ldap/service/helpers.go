@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/mapstructure"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/services/ldap"
@@ -36,24 +37,33 @@ func readConfig(configFile string) (*ldap.Config, error) {
 		return nil, fmt.Errorf("%v: %w", "Failed to load LDAP config file", err)
 	}
 
-	if len(result.Servers) == 0 {
-		return nil, fmt.Errorf("LDAP enabled but no LDAP servers defined in config file")
+	if err := validateConfig(result); err != nil {
+		return nil, err
 	}
 
-	for _, server := range result.Servers {
+	return result, nil
+}
+
+// validateConfig checks the required options of the LDAP servers and sets the defaults of the optional ones.
+func validateConfig(config *ldap.Config) error {
+	if len(config.Servers) == 0 {
+		return fmt.Errorf("LDAP enabled but no LDAP servers defined in config file")
+	}
+
+	for _, server := range config.Servers {
 		// set default org id
-		err = assertNotEmptyCfg(server.SearchFilter, "search_filter")
+		err := assertNotEmptyCfg(server.SearchFilter, "search_filter")
 		if err != nil {
-			return nil, fmt.Errorf("%v: %w", "Failed to validate SearchFilter section", err)
+			return fmt.Errorf("%v: %w", "Failed to validate SearchFilter section", err)
 		}
 		err = assertNotEmptyCfg(server.SearchBaseDNs, "search_base_dns")
 		if err != nil {
-			return nil, fmt.Errorf("%v: %w", "Failed to validate SearchBaseDNs section", err)
+			return fmt.Errorf("%v: %w", "Failed to validate SearchBaseDNs section", err)
 		}
 
 		for _, groupMap := range server.Groups {
 			if groupMap.OrgRole == "" && groupMap.IsGrafanaAdmin == nil {
-				return nil, fmt.Errorf("LDAP group mapping: organization role or grafana admin status is required")
+				return fmt.Errorf("LDAP group mapping: organization role or grafana admin status is required")
 			}
 
 			if groupMap.OrgId == 0 {
@@ -67,6 +77,30 @@ func readConfig(configFile string) (*ldap.Config, error) {
 		}
 	}
 
+	return nil
+}
+
+// configFromSettings decodes the LDAP servers from the "config" of the SSO settings, which uses the keys of the LDAP config file.
+func configFromSettings(settings map[string]any) (*ldap.Config, error) {
+	result := &ldap.Config{}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "toml",
+		Result:           result,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(settings["config"]); err != nil {
+		return nil, fmt.Errorf("%v: %w", "Failed to decode LDAP config", err)
+	}
+
+	if err := validateConfig(result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
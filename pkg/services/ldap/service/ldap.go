@@ -1,13 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ldap"
 	"github.com/grafana/grafana/pkg/services/ldap/multildap"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -28,6 +32,8 @@ type LDAP interface {
 	User(username string) (*login.ExternalUserInfo, error)
 }
 
+var _ ssosettings.Reloadable = (*LDAPImpl)(nil)
+
 type LDAPImpl struct {
 	client  multildap.IMultiLDAP
 	cfg     *setting.Cfg
@@ -38,7 +44,7 @@ type LDAPImpl struct {
 	loadingMutex *sync.Mutex
 }
 
-func ProvideService(cfg *setting.Cfg) *LDAPImpl {
+func ProvideService(cfg *setting.Cfg, ssoSettings ssosettings.Service, features featuremgmt.FeatureToggles) *LDAPImpl {
 	s := &LDAPImpl{
 		client:       nil,
 		ldapCfg:      nil,
@@ -47,6 +53,10 @@ func ProvideService(cfg *setting.Cfg) *LDAPImpl {
 		loadingMutex: &sync.Mutex{},
 	}
 
+	if features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) {
+		ssoSettings.RegisterReloadable(ssosettings.LDAPProviderName, s)
+	}
+
 	if !cfg.LDAPAuthEnabled {
 		return s
 	}
@@ -86,6 +96,47 @@ func (s *LDAPImpl) ReloadConfig() error {
 	return nil
 }
 
+func (s *LDAPImpl) Validate(ctx context.Context, settings ssoModels.SSOSettings) error {
+	enabled, _ := settings.Settings["enabled"].(bool)
+	if enabled != s.cfg.LDAPAuthEnabled {
+		return ssosettings.ErrReloadNotAllowed.Errorf("LDAP can only be enabled or disabled in the [auth.ldap] section of the configuration")
+	}
+
+	if !enabled {
+		return nil
+	}
+
+	if _, err := configFromSettings(settings.Settings); err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("invalid LDAP config: %v", err)
+	}
+
+	return nil
+}
+
+func (s *LDAPImpl) Reload(ctx context.Context, settings ssoModels.SSOSettings) error {
+	if !s.cfg.LDAPAuthEnabled {
+		return nil
+	}
+
+	config, err := configFromSettings(settings.Settings)
+	if err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("invalid LDAP config: %v", err)
+	}
+
+	s.loadingMutex.Lock()
+	defer s.loadingMutex.Unlock()
+
+	client := multildap.New(config.Servers, s.cfg)
+	if client == nil {
+		return ErrUnableToCreateLDAPClient
+	}
+
+	s.ldapCfg = config
+	s.client = client
+
+	return nil
+}
+
 func (s *LDAPImpl) Client() multildap.IMultiLDAP {
 	return s.client
 }
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func validLDAPSettings(enabled bool) ssoModels.SSOSettings {
+	return ssoModels.SSOSettings{
+		Provider: ssosettings.LDAPProviderName,
+		Settings: map[string]any{
+			"enabled": enabled,
+			"config": map[string]any{
+				"servers": []any{
+					map[string]any{
+						"host":            "ldap.example.com",
+						"port":            float64(389),
+						"search_filter":   "(cn=%s)",
+						"search_base_dns": []any{"dc=grafana,dc=org"},
+						"attributes":      map[string]any{"username": "cn"},
+						"group_mappings": []any{
+							map[string]any{"group_dn": "cn=admins,dc=grafana,dc=org", "org_role": "Admin"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLDAPImpl_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ldapEnabled   bool
+		settings      ssoModels.SSOSettings
+		expectedError error
+	}{
+		{
+			name:        "valid config",
+			ldapEnabled: true,
+			settings:    validLDAPSettings(true),
+		},
+		{
+			name:        "disabled LDAP is not validated",
+			ldapEnabled: false,
+			settings:    ssoModels.SSOSettings{Settings: map[string]any{"enabled": false}},
+		},
+		{
+			name:          "fails if LDAP is enabled at runtime",
+			ldapEnabled:   false,
+			settings:      validLDAPSettings(true),
+			expectedError: ssosettings.ErrReloadNotAllowed,
+		},
+		{
+			name:          "fails if LDAP is disabled at runtime",
+			ldapEnabled:   true,
+			settings:      validLDAPSettings(false),
+			expectedError: ssosettings.ErrReloadNotAllowed,
+		},
+		{
+			name:          "fails without servers",
+			ldapEnabled:   true,
+			settings:      ssoModels.SSOSettings{Settings: map[string]any{"enabled": true, "config": map[string]any{}}},
+			expectedError: ssosettings.ErrInvalidSettings,
+		},
+		{
+			name:        "fails without search filter",
+			ldapEnabled: true,
+			settings: ssoModels.SSOSettings{Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{map[string]any{"host": "ldap.example.com", "search_base_dns": []any{"dc=grafana,dc=org"}}},
+				},
+			}},
+			expectedError: ssosettings.ErrInvalidSettings,
+		},
+		{
+			name:        "fails if a field has the wrong type",
+			ldapEnabled: true,
+			settings: ssoModels.SSOSettings{Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{map[string]any{"host": "ldap.example.com", "port": "not-a-port"}},
+				},
+			}},
+			expectedError: ssosettings.ErrInvalidSettings,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.LDAPAuthEnabled = tc.ldapEnabled
+			s := ProvideService(cfg, &ssosettingstests.MockService{}, featuremgmt.WithFeatures())
+
+			err := s.Validate(context.Background(), tc.settings)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLDAPImpl_Reload(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LDAPAuthEnabled = true
+	s := &LDAPImpl{cfg: cfg, loadingMutex: &sync.Mutex{}}
+
+	err := s.Reload(context.Background(), validLDAPSettings(true))
+	require.NoError(t, err)
+
+	require.NotNil(t, s.Client())
+	require.Len(t, s.Config().Servers, 1)
+
+	server := s.Config().Servers[0]
+	require.Equal(t, "ldap.example.com", server.Host)
+	require.Equal(t, 389, server.Port)
+	require.Equal(t, []string{"dc=grafana,dc=org"}, server.SearchBaseDNs)
+	require.Equal(t, "cn", server.Attr.Username)
+	require.Equal(t, defaultTimeout, server.Timeout)
+	require.Len(t, server.Groups, 1)
+	require.Equal(t, org.RoleAdmin, server.Groups[0].OrgRole)
+	require.Equal(t, int64(1), server.Groups[0].OrgId)
+}
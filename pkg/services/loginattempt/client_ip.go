@@ -0,0 +1,56 @@
+package loginattempt
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the address login attempts of the request are tracked under. The X-Real-IP and
+// X-Forwarded-For headers can be set by any client, so they are only used when the request comes
+// from one of the trusted proxies.
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	addr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	if !containsIP(trustedProxies, addr) {
+		return addr
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	// Every proxy appends the address it received the request from, so the client is the last
+	// address that isn't one of the trusted proxies.
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		addr = hop
+		if !containsIP(trustedProxies, hop) {
+			break
+		}
+	}
+
+	return addr
+}
+
+func containsIP(networks []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
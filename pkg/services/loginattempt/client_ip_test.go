@@ -0,0 +1,73 @@
+package loginattempt
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+	trusted := []*net.IPNet{proxies}
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			desc:       "uses the socket address",
+			remoteAddr: "192.168.0.1:51234",
+			expected:   "192.168.0.1",
+		},
+		{
+			desc:       "ignores headers of untrusted clients",
+			remoteAddr: "192.168.0.1:51234",
+			headers: map[string][]string{
+				"X-Real-Ip":       {"10.0.0.50"},
+				"X-Forwarded-For": {"10.0.0.51"},
+			},
+			expected: "192.168.0.1",
+		},
+		{
+			desc:       "uses X-Real-IP of trusted proxies",
+			remoteAddr: "10.0.0.1:51234",
+			headers:    map[string][]string{"X-Real-Ip": {"203.0.113.7"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "uses the last untrusted address of X-Forwarded-For",
+			remoteAddr: "10.0.0.1:51234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7", "10.0.0.2"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			desc:       "stops at invalid X-Forwarded-For entries",
+			remoteAddr: "10.0.0.1:51234",
+			headers:    map[string][]string{"X-Forwarded-For": {"not-an-ip"}},
+			expected:   "10.0.0.1",
+		},
+		{
+			desc:       "uses IPv6 socket addresses",
+			remoteAddr: "[2001:db8::1]:51234",
+			expected:   "2001:db8::1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := &http.Request{RemoteAddr: tc.remoteAddr, Header: http.Header{}}
+			for key, values := range tc.headers {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+			assert.Equal(t, tc.expected, ClientIP(req, trusted))
+		})
+	}
+}
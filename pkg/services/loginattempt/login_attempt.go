@@ -2,16 +2,22 @@ package loginattempt
 
 import (
 	"context"
+	"time"
 )
 
 type Service interface {
 	// Add adds a new login attempt record for provided username
 	Add(ctx context.Context, username, IPAddress string) error
-	// Validate checks if username has to many login attempts inside a window.
-	// Will return true if provided username do not have too many attempts.
-	Validate(ctx context.Context, username string) (bool, error)
+	// Validate checks if the username from the IP address, the username from all IP addresses, or the IP
+	// address are locked out because of too many failed login attempts. Will return true if none of them
+	// are locked out.
+	Validate(ctx context.Context, username, IPAddress string) (bool, error)
 	// Reset resets all login attempts attached to username
 	Reset(ctx context.Context, username string) error
+	// ResetIP resets all login attempts made from IP address
+	ResetIP(ctx context.Context, IPAddress string) error
+	// GetLockouts returns the usernames and IP addresses that are currently locked out
+	GetLockouts(ctx context.Context) ([]*Lockout, error)
 }
 
 type LoginAttempt struct {
@@ -20,3 +26,13 @@ type LoginAttempt struct {
 	IpAddress string
 	Created   int64
 }
+
+// Lockout is a username blocked from logging in from an IP address or from everywhere, or an IP address
+// blocked from logging in.
+type Lockout struct {
+	Username    string    `json:"username,omitempty"`
+	IPAddress   string    `json:"ipAddress,omitempty"`
+	Attempts    int64     `json:"attempts"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
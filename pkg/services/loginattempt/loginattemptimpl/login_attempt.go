@@ -2,33 +2,42 @@ package loginattemptimpl
 
 import (
 	"context"
+	"net"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
-	maxInvalidLoginAttempts int64 = 5
-	loginAttemptsWindow           = time.Minute * 5
+	reasonUsername  = "username"
+	reasonIPAddress = "ip_address"
 )
 
-func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService) *Service {
+func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService, reg prometheus.Registerer) (*Service, error) {
 	return &Service{
-		&xormStore{db: db, now: time.Now},
-		cfg,
-		lock,
-		log.New("login_attempt"),
-	}
+		store:   &xormStore{db: db, now: time.Now},
+		cfg:     cfg,
+		lock:    lock,
+		logger:  log.New("login_attempt"),
+		metrics: newMetrics(reg),
+		now:     time.Now,
+	}, nil
 }
 
 type Service struct {
-	store  store
-	cfg    *setting.Cfg
-	lock   *serverlock.ServerLockService
-	logger log.Logger
+	store   store
+	cfg     *setting.Cfg
+	lock    *serverlock.ServerLockService
+	logger  log.Logger
+	metrics *metrics
+	now     func() time.Time
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -55,41 +64,158 @@ func (s *Service) Add(ctx context.Context, username, IPAddress string) error {
 
 	_, err := s.store.CreateLoginAttempt(ctx, CreateLoginAttemptCommand{
 		Username:  username,
-		IpAddress: IPAddress,
+		IpAddress: normalizeIP(IPAddress),
 	})
 	return err
 }
 
 func (s *Service) Reset(ctx context.Context, username string) error {
-	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{username})
+	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{Username: username})
+}
+
+func (s *Service) ResetIP(ctx context.Context, IPAddress string) error {
+	return s.store.DeleteLoginAttempts(ctx, DeleteLoginAttemptsCommand{IpAddress: normalizeIP(IPAddress)})
 }
 
-func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
+func (s *Service) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	if s.cfg.DisableBruteForceLoginProtection {
 		return true, nil
 	}
 
-	loginAttemptCountQuery := GetUserLoginAttemptCountQuery{
-		Username: username,
-		Since:    time.Now().Add(-loginAttemptsWindow),
+	ip := normalizeIP(IPAddress)
+	now := s.now()
+	since := now.Add(-s.cfg.BruteForceLoginProtection.MaxLockoutDuration)
+
+	// The username is locked out quickly for the address the failed attempts came from, so attackers
+	// can't easily lock out users logging in from elsewhere, and after more attempts from all
+	// addresses, so guessing the password from many addresses is still limited.
+	userStats, err := s.store.GetLoginAttemptStats(ctx, GetLoginAttemptStatsQuery{Username: username, IpAddress: ip, Since: since})
+	if err != nil {
+		return false, err
 	}
 
-	count, err := s.store.GetUserLoginAttemptCount(ctx, loginAttemptCountQuery)
+	if now.Before(s.lockedUntil(userStats, s.cfg.BruteForceLoginProtection.MaxAttempts)) {
+		s.metrics.blockedAttempts.WithLabelValues(reasonUsername).Inc()
+		return false, nil
+	}
+
+	if ip != "" && s.cfg.BruteForceLoginProtection.MaxAttemptsPerUsername > 0 {
+		usernameStats, err := s.store.GetLoginAttemptStats(ctx, GetLoginAttemptStatsQuery{Username: username, Since: since})
+		if err != nil {
+			return false, err
+		}
+
+		if now.Before(s.lockedUntil(usernameStats, s.cfg.BruteForceLoginProtection.MaxAttemptsPerUsername)) {
+			s.metrics.blockedAttempts.WithLabelValues(reasonUsername).Inc()
+			return false, nil
+		}
+	}
+
+	if ip == "" || s.cfg.BruteForceLoginProtection.MaxAttemptsPerIP <= 0 || s.isAllowed(ip) {
+		return true, nil
+	}
+
+	ipStats, err := s.store.GetLoginAttemptStats(ctx, GetLoginAttemptStatsQuery{IpAddress: ip, Since: since})
 	if err != nil {
 		return false, err
 	}
 
-	if count >= maxInvalidLoginAttempts {
+	if now.Before(s.lockedUntil(ipStats, s.cfg.BruteForceLoginProtection.MaxAttemptsPerIP)) {
+		s.metrics.blockedAttempts.WithLabelValues(reasonIPAddress).Inc()
 		return false, nil
 	}
 
 	return true, nil
 }
 
+func (s *Service) GetLockouts(ctx context.Context) ([]*loginattempt.Lockout, error) {
+	lockouts := make([]*loginattempt.Lockout, 0)
+	if s.cfg.DisableBruteForceLoginProtection {
+		return lockouts, nil
+	}
+
+	now := s.now()
+	since := now.Add(-s.cfg.BruteForceLoginProtection.MaxLockoutDuration)
+
+	limits := map[LoginAttemptGrouping]int64{
+		GroupByUsernameAndIPAddress: s.cfg.BruteForceLoginProtection.MaxAttempts,
+		GroupByUsername:             s.cfg.BruteForceLoginProtection.MaxAttemptsPerUsername,
+		GroupByIPAddress:            s.cfg.BruteForceLoginProtection.MaxAttemptsPerIP,
+	}
+
+	for _, groupBy := range []LoginAttemptGrouping{GroupByUsernameAndIPAddress, GroupByUsername, GroupByIPAddress} {
+		maxAttempts := limits[groupBy]
+		if maxAttempts <= 0 {
+			continue
+		}
+
+		frequent, err := s.store.GetFrequentLoginAttempts(ctx, GetFrequentLoginAttemptsQuery{
+			GroupBy:     groupBy,
+			MinAttempts: maxAttempts,
+			Since:       since,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stats := range frequent {
+			lockedUntil := s.lockedUntil(stats, maxAttempts)
+			if !now.Before(lockedUntil) || (groupBy == GroupByIPAddress && s.isAllowed(stats.IpAddress)) {
+				continue
+			}
+
+			lockouts = append(lockouts, &loginattempt.Lockout{
+				Username:    stats.Username,
+				IPAddress:   stats.IpAddress,
+				Attempts:    stats.Attempts,
+				LastAttempt: time.Unix(stats.LastAttempt, 0),
+				LockedUntil: lockedUntil,
+			})
+		}
+	}
+
+	return lockouts, nil
+}
+
+// lockedUntil returns when the lockout caused by the attempts ends. Reaching maxAttempts locks out
+// for the lockout duration, which doubles with every further attempt up to the max lockout duration.
+func (s *Service) lockedUntil(stats LoginAttemptStats, maxAttempts int64) time.Time {
+	if maxAttempts <= 0 || stats.Attempts < maxAttempts {
+		return time.Time{}
+	}
+
+	settings := s.cfg.BruteForceLoginProtection
+	lockout := settings.LockoutDuration
+	for i := maxAttempts; i < stats.Attempts && lockout < settings.MaxLockoutDuration; i++ {
+		lockout *= 2
+	}
+	if lockout > settings.MaxLockoutDuration {
+		lockout = settings.MaxLockoutDuration
+	}
+
+	return time.Unix(stats.LastAttempt, 0).Add(lockout)
+}
+
+func (s *Service) isAllowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range s.cfg.BruteForceLoginProtection.Allowlist {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *Service) cleanup(ctx context.Context) {
 	err := s.lock.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func(context.Context) {
+		// attempts are kept as long as they can extend a lockout
 		cmd := DeleteOldLoginAttemptsCommand{
-			OlderThan: time.Now().Add(time.Minute * -10),
+			OlderThan: s.now().Add(-s.cfg.BruteForceLoginProtection.MaxLockoutDuration),
 		}
 		if deletedLogs, err := s.store.DeleteOldLoginAttempts(ctx, cmd); err != nil {
 			s.logger.Error("Problem deleting expired login attempts", "error", err.Error())
@@ -102,3 +228,12 @@ func (s *Service) cleanup(ctx context.Context) {
 		s.logger.Error("Failed to lock and execute cleanup of old login attempts", "error", err)
 	}
 }
+
+// normalizeIP strips the brackets around IPv6 addresses and formats valid addresses consistently.
+func normalizeIP(addr string) string {
+	addr = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(addr), "["), "]")
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return addr
+}
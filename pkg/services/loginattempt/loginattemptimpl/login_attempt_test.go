@@ -2,61 +2,124 @@ package loginattemptimpl
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestService_Validate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
 	testCases := []struct {
-		name          string
-		loginAttempts int64
-		disabled      bool
-		expected      bool
-		expectedErr   error
+		name             string
+		userAttempts     int64
+		usernameAttempts int64
+		ipAttempts       int64
+		lastAttempt      time.Time
+		disabled         bool
+		ip               string
+		expected         bool
+		expectedBlocked  string
 	}{
 		{
-			name:          "When brute force protection enabled and user login attempt count is less than max",
-			loginAttempts: maxInvalidLoginAttempts - 1,
-			expected:      true,
-			expectedErr:   nil,
+			name:         "When brute force protection enabled and user login attempt count is less than max",
+			userAttempts: 4,
+			ip:           "192.168.0.2",
+			lastAttempt:  now,
+			expected:     true,
 		},
 		{
-			name:          "When brute force protection enabled and user login attempt count equals max",
-			loginAttempts: maxInvalidLoginAttempts,
-			expected:      false,
-			expectedErr:   nil,
+			name:            "When brute force protection enabled and user login attempt count equals max",
+			userAttempts:    5,
+			ip:              "192.168.0.2",
+			lastAttempt:     now.Add(-30 * time.Second),
+			expected:        false,
+			expectedBlocked: reasonUsername,
 		},
 		{
-			name:          "When brute force protection enabled and user login attempt count is greater than max",
-			loginAttempts: maxInvalidLoginAttempts + 1,
-			expected:      false,
-			expectedErr:   nil,
+			name:         "When the lockout of the user has expired",
+			userAttempts: 5,
+			ip:           "192.168.0.2",
+			lastAttempt:  now.Add(-time.Minute),
+			expected:     true,
+		},
+		{
+			name:            "When the lockout of the user is doubled by further attempts",
+			userAttempts:    7,
+			ip:              "192.168.0.2",
+			lastAttempt:     now.Add(-3 * time.Minute),
+			expected:        false,
+			expectedBlocked: reasonUsername,
+		},
+		{
+			name:         "When the doubled lockout of the user has expired",
+			userAttempts: 7,
+			ip:           "192.168.0.2",
+			lastAttempt:  now.Add(-4 * time.Minute),
+			expected:     true,
+		},
+		{
+			name:            "When the lockout of the user is capped by the max lockout duration",
+			userAttempts:    30,
+			ip:              "192.168.0.2",
+			lastAttempt:     now.Add(-59 * time.Minute),
+			expected:        false,
+			expectedBlocked: reasonUsername,
+		},
+		{
+			name:             "When the username has too many login attempts from all ip addresses",
+			userAttempts:     1,
+			usernameAttempts: 50,
+			lastAttempt:      now,
+			ip:               "192.168.0.1",
+			expected:         false,
+			expectedBlocked:  reasonUsername,
 		},
-
 		{
-			name:          "When brute force protection disabled and user login attempt count is less than max",
-			loginAttempts: maxInvalidLoginAttempts - 1,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
+			name:             "When the username has fewer login attempts from all ip addresses than max",
+			userAttempts:     1,
+			usernameAttempts: 49,
+			lastAttempt:      now,
+			ip:               "192.168.0.1",
+			expected:         true,
 		},
 		{
-			name:          "When brute force protection disabled and user login attempt count equals max",
-			loginAttempts: maxInvalidLoginAttempts,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
+			name:            "When the ip address has too many login attempts",
+			ipAttempts:      50,
+			lastAttempt:     now,
+			ip:              "192.168.0.1",
+			expected:        false,
+			expectedBlocked: reasonIPAddress,
 		},
 		{
-			name:          "When brute force protection disabled and user login attempt count is greater than max",
-			loginAttempts: maxInvalidLoginAttempts + 1,
-			disabled:      true,
-			expected:      true,
-			expectedErr:   nil,
+			name:        "When the ip address is allowed",
+			ipAttempts:  50,
+			lastAttempt: now,
+			ip:          "10.0.0.10",
+			expected:    true,
+		},
+		{
+			name:            "When the user logs in from an allowed ip address",
+			userAttempts:    5,
+			lastAttempt:     now,
+			ip:              "[2001:db8::1]",
+			expected:        false,
+			expectedBlocked: reasonUsername,
+		},
+		{
+			name:         "When brute force protection disabled and user login attempt count equals max",
+			userAttempts: 5,
+			ip:           "192.168.0.2",
+			lastAttempt:  now,
+			disabled:     true,
+			expected:     true,
 		},
 	}
 
@@ -64,31 +127,136 @@ func TestService_Validate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := setting.NewCfg()
 			cfg.DisableBruteForceLoginProtection = tt.disabled
+			cfg.BruteForceLoginProtection = setting.BruteForceLoginProtectionSettings{
+				MaxAttempts:            5,
+				MaxAttemptsPerUsername: 50,
+				MaxAttemptsPerIP:       50,
+				LockoutDuration:        time.Minute,
+				MaxLockoutDuration:     time.Hour,
+				Allowlist:              mustParseNetworks(t, "10.0.0.0/24", "2001:db8::1/128"),
+			}
+
 			service := &Service{
 				store: fakeStore{
-					ExpectedCount: tt.loginAttempts,
-					ExpectedErr:   tt.expectedErr,
+					ExpectedUserStats:     LoginAttemptStats{Attempts: tt.userAttempts, LastAttempt: tt.lastAttempt.Unix()},
+					ExpectedUsernameStats: LoginAttemptStats{Attempts: tt.usernameAttempts, LastAttempt: tt.lastAttempt.Unix()},
+					ExpectedIPStats:       LoginAttemptStats{Attempts: tt.ipAttempts, LastAttempt: tt.lastAttempt.Unix()},
 				},
-				cfg: cfg,
+				cfg:     cfg,
+				metrics: newMetrics(nil),
+				now:     func() time.Time { return now },
 			}
 
-			ok, err := service.Validate(context.Background(), "test")
+			ok, err := service.Validate(context.Background(), "test", tt.ip)
+			require.NoError(t, err)
 			assert.Equal(t, tt.expected, ok)
-			assert.Equal(t, tt.expectedErr, err)
+
+			for _, reason := range []string{reasonUsername, reasonIPAddress} {
+				expected := 0.0
+				if reason == tt.expectedBlocked {
+					expected = 1
+				}
+				assert.Equal(t, expected, testutil.ToFloat64(service.metrics.blockedAttempts.WithLabelValues(reason)), reason)
+			}
 		})
 	}
 }
 
+func TestService_GetLockouts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtection = setting.BruteForceLoginProtectionSettings{
+		MaxAttempts:            5,
+		MaxAttemptsPerUsername: 50,
+		MaxAttemptsPerIP:       50,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+		Allowlist:              mustParseNetworks(t, "10.0.0.0/24"),
+	}
+
+	service := &Service{
+		store: fakeStore{
+			ExpectedFrequentUsers: []LoginAttemptStats{
+				{Username: "locked", IpAddress: "192.168.0.1", Attempts: 6, LastAttempt: now.Add(-time.Minute).Unix()},
+				{Username: "expired", IpAddress: "192.168.0.1", Attempts: 5, LastAttempt: now.Add(-2 * time.Minute).Unix()},
+			},
+			ExpectedFrequentUsernames: []LoginAttemptStats{
+				{Username: "guessed", Attempts: 50, LastAttempt: now.Unix()},
+			},
+			ExpectedFrequentIPs: []LoginAttemptStats{
+				{IpAddress: "192.168.0.1", Attempts: 50, LastAttempt: now.Unix()},
+				{IpAddress: "10.0.0.1", Attempts: 50, LastAttempt: now.Unix()},
+			},
+		},
+		cfg:     cfg,
+		metrics: newMetrics(nil),
+		now:     func() time.Time { return now },
+	}
+
+	lockouts, err := service.GetLockouts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*loginattempt.Lockout{
+		{Username: "locked", IPAddress: "192.168.0.1", Attempts: 6, LastAttempt: now.Add(-time.Minute).Local(), LockedUntil: now.Add(time.Minute).Local()},
+		{Username: "guessed", Attempts: 50, LastAttempt: now.Local(), LockedUntil: now.Add(time.Minute).Local()},
+		{IPAddress: "192.168.0.1", Attempts: 50, LastAttempt: now.Local(), LockedUntil: now.Add(time.Minute).Local()},
+	}, lockouts)
+}
+
+func TestService_isAllowed(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtection.Allowlist = mustParseNetworks(t, "192.168.0.1/32", "10.0.0.0/8", "2001:db8::/32")
+
+	service := &Service{cfg: cfg}
+	assert.True(t, service.isAllowed("192.168.0.1"))
+	assert.False(t, service.isAllowed("192.168.0.2"))
+	assert.True(t, service.isAllowed("10.1.2.3"))
+	assert.True(t, service.isAllowed(normalizeIP("[2001:db8::5]")))
+	assert.False(t, service.isAllowed("not-an-ip"))
+}
+
+func mustParseNetworks(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		networks = append(networks, network)
+	}
+	return networks
+}
+
 var _ store = new(fakeStore)
 
 type fakeStore struct {
-	ExpectedErr         error
-	ExpectedCount       int64
-	ExpectedDeletedRows int64
+	ExpectedErr               error
+	ExpectedUserStats         LoginAttemptStats
+	ExpectedUsernameStats     LoginAttemptStats
+	ExpectedIPStats           LoginAttemptStats
+	ExpectedFrequentUsers     []LoginAttemptStats
+	ExpectedFrequentUsernames []LoginAttemptStats
+	ExpectedFrequentIPs       []LoginAttemptStats
+	ExpectedDeletedRows       int64
 }
 
-func (f fakeStore) GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error) {
-	return f.ExpectedCount, f.ExpectedErr
+func (f fakeStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	if query.Username == "" {
+		return f.ExpectedIPStats, f.ExpectedErr
+	}
+	if query.IpAddress == "" {
+		return f.ExpectedUsernameStats, f.ExpectedErr
+	}
+	return f.ExpectedUserStats, f.ExpectedErr
+}
+
+func (f fakeStore) GetFrequentLoginAttempts(ctx context.Context, query GetFrequentLoginAttemptsQuery) ([]LoginAttemptStats, error) {
+	switch query.GroupBy {
+	case GroupByUsername:
+		return f.ExpectedFrequentUsernames, f.ExpectedErr
+	case GroupByIPAddress:
+		return f.ExpectedFrequentIPs, f.ExpectedErr
+	}
+	return f.ExpectedFrequentUsers, f.ExpectedErr
 }
 
 func (f fakeStore) CreateLoginAttempt(ctx context.Context, command CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error) {
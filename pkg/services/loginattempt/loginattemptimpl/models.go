@@ -9,9 +9,35 @@ type CreateLoginAttemptCommand struct {
 	IpAddress string
 }
 
-type GetUserLoginAttemptCountQuery struct {
-	Username string
-	Since    time.Time
+// GetLoginAttemptStatsQuery counts the attempts of a username, an IP address or a username from an IP address.
+type GetLoginAttemptStatsQuery struct {
+	Username  string
+	IpAddress string
+	Since     time.Time
+}
+
+// LoginAttemptGrouping selects what the attempts of GetFrequentLoginAttemptsQuery are counted by.
+type LoginAttemptGrouping int
+
+const (
+	GroupByUsernameAndIPAddress LoginAttemptGrouping = iota
+	GroupByUsername
+	GroupByIPAddress
+)
+
+// GetFrequentLoginAttemptsQuery lists the usernames with the IP addresses they were tried from,
+// the usernames or the IP addresses, depending on GroupBy, with at least MinAttempts attempts.
+type GetFrequentLoginAttemptsQuery struct {
+	GroupBy     LoginAttemptGrouping
+	MinAttempts int64
+	Since       time.Time
+}
+
+type LoginAttemptStats struct {
+	Username    string `xorm:"username"`
+	IpAddress   string `xorm:"ip_address"`
+	Attempts    int64  `xorm:"attempts"`
+	LastAttempt int64  `xorm:"last_attempt"`
 }
 
 type DeleteOldLoginAttemptsCommand struct {
@@ -19,5 +45,6 @@ type DeleteOldLoginAttemptsCommand struct {
 }
 
 type DeleteLoginAttemptsCommand struct {
-	Username string
+	Username  string
+	IpAddress string
 }
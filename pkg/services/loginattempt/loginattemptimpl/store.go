@@ -2,6 +2,7 @@ package loginattemptimpl
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -17,7 +18,8 @@ type store interface {
 	CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (loginattempt.LoginAttempt, error)
 	DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLoginAttemptsCommand) (int64, error)
 	DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error
-	GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error)
+	GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error)
+	GetFrequentLoginAttempts(ctx context.Context, query GetFrequentLoginAttemptsQuery) ([]LoginAttemptStats, error)
 }
 
 func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (result loginattempt.LoginAttempt, err error) {
@@ -58,27 +60,58 @@ func (xs *xormStore) DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLo
 
 func (xs *xormStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
 	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if cmd.IpAddress != "" {
+			_, err := sess.Exec("DELETE FROM login_attempt WHERE ip_address = ?", cmd.IpAddress)
+			return err
+		}
 		_, err := sess.Exec("DELETE FROM login_attempt WHERE username = ?", cmd.Username)
 		return err
 	})
 }
 
-func (xs *xormStore) GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error) {
-	var total int64
+func (xs *xormStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	stats := LoginAttemptStats{Username: query.Username, IpAddress: query.IpAddress}
 	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
-		var queryErr error
-		loginAttempt := new(loginattempt.LoginAttempt)
-		total, queryErr = dbSession.
-			Where("username = ?", query.Username).
-			And("created >= ?", query.Since.Unix()).
-			Count(loginAttempt)
-
-		if queryErr != nil {
-			return queryErr
+		where, args := []string{"created >= ?"}, []any{query.Since.Unix()}
+		if query.Username != "" {
+			where, args = append(where, "username = ?"), append(args, query.Username)
+		}
+		if query.IpAddress != "" {
+			where, args = append(where, "ip_address = ?"), append(args, query.IpAddress)
+		}
+
+		var result LoginAttemptStats
+		_, err := dbSession.SQL(
+			"SELECT COUNT(*) AS attempts, COALESCE(MAX(created), 0) AS last_attempt FROM login_attempt WHERE "+strings.Join(where, " AND "),
+			args...,
+		).Get(&result)
+		if err != nil {
+			return err
 		}
 
+		stats.Attempts, stats.LastAttempt = result.Attempts, result.LastAttempt
 		return nil
 	})
 
-	return total, err
+	return stats, err
+}
+
+func (xs *xormStore) GetFrequentLoginAttempts(ctx context.Context, query GetFrequentLoginAttemptsQuery) ([]LoginAttemptStats, error) {
+	column := "username, ip_address"
+	switch query.GroupBy {
+	case GroupByUsername:
+		column = "username"
+	case GroupByIPAddress:
+		column = "ip_address"
+	}
+
+	result := make([]LoginAttemptStats, 0)
+	err := xs.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		return dbSession.SQL(
+			"SELECT "+column+", COUNT(*) AS attempts, MAX(created) AS last_attempt FROM login_attempt WHERE created >= ? GROUP BY "+column+" HAVING COUNT(*) >= ?",
+			query.Since.Unix(), query.MinAttempts,
+		).Find(&result)
+	})
+
+	return result, err
 }
@@ -22,29 +22,29 @@ func TestIntegrationLoginAttemptsQuery(t *testing.T) {
 
 	for _, test := range []struct {
 		Name   string
-		Query  GetUserLoginAttemptCountQuery
+		Query  GetLoginAttemptStatsQuery
 		Err    error
 		Result int64
 	}{
 		{
 			"Should return a total count of zero login attempts when comparing since beginning of time + 2min and 1s",
-			GetUserLoginAttemptCountQuery{Username: user, Since: timePlusTwoMinutes.Add(time.Second * 1)}, nil, 0,
+			GetLoginAttemptStatsQuery{Username: user, Since: timePlusTwoMinutes.Add(time.Second * 1)}, nil, 0,
 		},
 		{
 			"Should return a total count of zero login attempts when comparing since beginning of time + 2min and 1s",
-			GetUserLoginAttemptCountQuery{Username: user, Since: timePlusTwoMinutes.Add(time.Second * 1)}, nil, 0,
+			GetLoginAttemptStatsQuery{Username: user, Since: timePlusTwoMinutes.Add(time.Second * 1)}, nil, 0,
 		},
 		{
 			"Should return the total count of login attempts since beginning of time",
-			GetUserLoginAttemptCountQuery{Username: user, Since: beginningOfTime}, nil, 3,
+			GetLoginAttemptStatsQuery{Username: user, Since: beginningOfTime}, nil, 3,
 		},
 		{
 			"Should return the total count of login attempts since beginning of time + 1min",
-			GetUserLoginAttemptCountQuery{Username: user, Since: timePlusOneMinute}, nil, 2,
+			GetLoginAttemptStatsQuery{Username: user, Since: timePlusOneMinute}, nil, 2,
 		},
 		{
 			"Should return the total count of login attempts since beginning of time + 2min",
-			GetUserLoginAttemptCountQuery{Username: user, Since: timePlusTwoMinutes}, nil, 1,
+			GetLoginAttemptStatsQuery{Username: user, Since: timePlusTwoMinutes}, nil, 1,
 		},
 	} {
 		mockTime := beginningOfTime
@@ -73,9 +73,9 @@ func TestIntegrationLoginAttemptsQuery(t *testing.T) {
 		})
 		require.Nil(t, err)
 
-		count, err := s.GetUserLoginAttemptCount(context.Background(), test.Query)
+		stats, err := s.GetLoginAttemptStats(context.Background(), test.Query)
 		require.Equal(t, test.Err, err, test.Name)
-		require.Equal(t, test.Result, count, test.Name)
+		require.Equal(t, test.Result, stats.Attempts, test.Name)
 	}
 }
 
@@ -143,3 +143,69 @@ func TestIntegrationLoginAttemptsDelete(t *testing.T) {
 		require.Equal(t, test.DeletedRows, deletedRows, test.Name)
 	}
 }
+
+func TestIntegrationLoginAttemptsByIPAddress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	beginningOfTime := time.Date(2017, 10, 22, 8, 0, 0, 0, time.Local)
+	mockTime := beginningOfTime
+	s := &xormStore{
+		db:  db.InitTestDB(t),
+		now: func() time.Time { return mockTime },
+	}
+
+	for i, username := range []string{"alice", "bob", "carol", "alice"} {
+		mockTime = beginningOfTime.Add(time.Duration(i) * time.Minute)
+		_, err := s.CreateLoginAttempt(context.Background(), CreateLoginAttemptCommand{
+			Username:  username,
+			IpAddress: "2001:db8::1",
+		})
+		require.NoError(t, err)
+	}
+	_, err := s.CreateLoginAttempt(context.Background(), CreateLoginAttemptCommand{
+		Username:  "alice",
+		IpAddress: "192.168.0.1",
+	})
+	require.NoError(t, err)
+
+	stats, err := s.GetLoginAttemptStats(context.Background(), GetLoginAttemptStatsQuery{IpAddress: "2001:db8::1", Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), stats.Attempts)
+	require.Equal(t, beginningOfTime.Add(3*time.Minute).Unix(), stats.LastAttempt)
+
+	stats, err = s.GetLoginAttemptStats(context.Background(), GetLoginAttemptStatsQuery{IpAddress: "10.0.0.1", Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), stats.Attempts)
+
+	stats, err = s.GetLoginAttemptStats(context.Background(), GetLoginAttemptStatsQuery{Username: "alice", IpAddress: "2001:db8::1", Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Attempts)
+
+	frequent, err := s.GetFrequentLoginAttempts(context.Background(), GetFrequentLoginAttemptsQuery{MinAttempts: 2, Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Len(t, frequent, 1)
+	require.Equal(t, "alice", frequent[0].Username)
+	require.Equal(t, "2001:db8::1", frequent[0].IpAddress)
+	require.Equal(t, int64(2), frequent[0].Attempts)
+
+	frequent, err = s.GetFrequentLoginAttempts(context.Background(), GetFrequentLoginAttemptsQuery{GroupBy: GroupByIPAddress, MinAttempts: 2, Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Len(t, frequent, 1)
+	require.Equal(t, "2001:db8::1", frequent[0].IpAddress)
+	require.Equal(t, int64(4), frequent[0].Attempts)
+
+	frequent, err = s.GetFrequentLoginAttempts(context.Background(), GetFrequentLoginAttemptsQuery{GroupBy: GroupByUsername, MinAttempts: 3, Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Len(t, frequent, 1)
+	require.Equal(t, "alice", frequent[0].Username)
+	require.Equal(t, int64(3), frequent[0].Attempts)
+
+	err = s.DeleteLoginAttempts(context.Background(), DeleteLoginAttemptsCommand{IpAddress: "2001:db8::1"})
+	require.NoError(t, err)
+
+	stats, err = s.GetLoginAttemptStats(context.Background(), GetLoginAttemptStatsQuery{Username: "alice", Since: beginningOfTime})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Attempts)
+}
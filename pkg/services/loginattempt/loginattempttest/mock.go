@@ -9,12 +9,15 @@ import (
 var _ loginattempt.Service = new(MockLoginAttemptService)
 
 type MockLoginAttemptService struct {
-	AddCalled      bool
-	ResetCalled    bool
-	ValidateCalled bool
-
-	ExpectedValid bool
-	ExpectedErr   error
+	AddCalled         bool
+	ResetCalled       bool
+	ResetIPCalled     bool
+	ValidateCalled    bool
+	GetLockoutsCalled bool
+
+	ExpectedValid    bool
+	ExpectedLockouts []*loginattempt.Lockout
+	ExpectedErr      error
 }
 
 func (f *MockLoginAttemptService) Add(ctx context.Context, username, IPAddress string) error {
@@ -27,7 +30,17 @@ func (f *MockLoginAttemptService) Reset(ctx context.Context, username string) er
 	return f.ExpectedErr
 }
 
-func (f *MockLoginAttemptService) Validate(ctx context.Context, username string) (bool, error) {
+func (f *MockLoginAttemptService) ResetIP(ctx context.Context, IPAddress string) error {
+	f.ResetIPCalled = true
+	return f.ExpectedErr
+}
+
+func (f *MockLoginAttemptService) Validate(ctx context.Context, username, IPAddress string) (bool, error) {
 	f.ValidateCalled = true
 	return f.ExpectedValid, f.ExpectedErr
 }
+
+func (f *MockLoginAttemptService) GetLockouts(ctx context.Context) ([]*loginattempt.Lockout, error) {
+	f.GetLockoutsCalled = true
+	return f.ExpectedLockouts, f.ExpectedErr
+}
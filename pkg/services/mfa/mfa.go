@@ -0,0 +1,80 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrDisabled           = errutil.NotFound("mfa.disabled", errutil.WithPublicMessage("Multi-factor authentication is not enabled"))
+	ErrNotEnrolled        = errutil.NotFound("mfa.not-enrolled", errutil.WithPublicMessage("Multi-factor authentication is not set up for this user"))
+	ErrAlreadyEnrolled    = errutil.Conflict("mfa.already-enrolled", errutil.WithPublicMessage("Multi-factor authentication is already set up for this user"))
+	ErrInvalidCode        = errutil.Unauthorized("mfa.invalid-code", errutil.WithPublicMessage("Invalid authentication code"))
+	ErrRequired           = errutil.Unauthorized("mfa.required", errutil.WithPublicMessage("Authentication code required"))
+	ErrTooManyAttempts    = errutil.TooManyRequests("mfa.too-many-attempts", errutil.WithPublicMessage("Too many incorrect authentication codes, try again later"))
+	ErrEnrollmentRequired = errutil.Unauthorized("mfa.enrollment-required").MustTemplate(
+		"Multi-factor authentication must be set up for user",
+		errutil.WithPublic("Multi-factor authentication must be set up to log in"),
+	)
+)
+
+type Service interface {
+	// GetStatus returns the multi-factor authentication status of the user.
+	GetStatus(ctx context.Context, userID int64) (*Status, error)
+	// Enroll generates a new TOTP secret for the user. The secret is used to
+	// verify codes once it has been activated.
+	Enroll(ctx context.Context, userID int64, login string) (*Enrollment, error)
+	// Activate enables the pending TOTP secret of the user when the code was
+	// generated with it and returns a new set of recovery codes.
+	Activate(ctx context.Context, userID int64, code string) ([]string, error)
+	// Verify checks a TOTP code of the user, or consumes one of their recovery codes.
+	Verify(ctx context.Context, userID int64, code string) error
+	// RegenerateRecoveryCodes replaces the recovery codes of the user.
+	RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error)
+	// Disable removes the TOTP secret and the recovery codes of the user.
+	Disable(ctx context.Context, userID int64) error
+}
+
+// Status is the multi-factor authentication status of a user.
+type Status struct {
+	Enabled           bool      `json:"enabled"`
+	Pending           bool      `json:"pending"`
+	RecoveryCodesLeft int       `json:"recoveryCodesLeft"`
+	Updated           time.Time `json:"updated,omitempty"`
+}
+
+// Enrollment holds the TOTP secret a user has to add to their authenticator app.
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+// UserMFA is the TOTP secret of a user. The secret is encrypted with the secrets service.
+type UserMFA struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	UserID       int64 `xorm:"user_id"`
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+	Created      time.Time
+	Updated      time.Time
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// RecoveryCode is a hashed single use code that replaces a TOTP code.
+type RecoveryCode struct {
+	ID      int64 `xorm:"pk autoincr 'id'"`
+	UserID  int64 `xorm:"user_id"`
+	Code    string
+	Salt    string
+	Created time.Time
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_mfa_recovery_code"
+}
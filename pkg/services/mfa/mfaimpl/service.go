@@ -0,0 +1,402 @@
+package mfaimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	authidentity "github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var _ mfa.Service = new(Service)
+
+func ProvideService(
+	cfg *setting.Cfg, db db.DB, secretsService secrets.Service, authnService authn.Service,
+	orgService org.Service, loginAttempts loginattempt.Service,
+) *Service {
+	s := &Service{
+		store:         &xormStore{db: db},
+		cfg:           cfg,
+		secrets:       secretsService,
+		orgService:    orgService,
+		loginAttempts: loginAttempts,
+		logger:        log.New("mfa"),
+		now:           time.Now,
+	}
+
+	if cfg.MFAEnabled {
+		// Run after the user is enabled and before its org roles and permissions are synced.
+		authnService.RegisterPostAuthHook(s.verifyLoginHook, 25)
+	}
+
+	return s
+}
+
+type Service struct {
+	store         store
+	cfg           *setting.Cfg
+	secrets       secrets.Service
+	orgService    org.Service
+	loginAttempts loginattempt.Service
+	logger        log.Logger
+	now           func() time.Time
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	if !s.cfg.MFAEnabled {
+		return nil, mfa.ErrDisabled.Errorf("multi-factor authentication is disabled")
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if errors.Is(err, mfa.ErrNotEnrolled) {
+		return &mfa.Status{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.store.ListRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mfa.Status{
+		Enabled:           m.Enabled,
+		Pending:           !m.Enabled,
+		RecoveryCodesLeft: len(codes),
+		Updated:           m.Updated,
+	}, nil
+}
+
+func (s *Service) Enroll(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	if !s.cfg.MFAEnabled {
+		return nil, mfa.ErrDisabled.Errorf("multi-factor authentication is disabled")
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, mfa.ErrNotEnrolled) {
+		return nil, err
+	}
+	if m != nil && m.Enabled {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already has an active secret", userID)
+	}
+
+	return s.enroll(ctx, userID, login)
+}
+
+func (s *Service) Activate(ctx context.Context, userID int64, code string) ([]string, error) {
+	if !s.cfg.MFAEnabled {
+		return nil, mfa.ErrDisabled.Errorf("multi-factor authentication is disabled")
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Enabled {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already has an active secret", userID)
+	}
+
+	if err := s.activate(ctx, m, code); err != nil {
+		return nil, err
+	}
+
+	return s.RegenerateRecoveryCodes(ctx, userID)
+}
+
+func (s *Service) Verify(ctx context.Context, userID int64, code string) error {
+	if !s.cfg.MFAEnabled {
+		return mfa.ErrDisabled.Errorf("multi-factor authentication is disabled")
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !m.Enabled {
+		return mfa.ErrNotEnrolled.Errorf("secret of user %d is not activated", userID)
+	}
+
+	code = normalizeCode(code)
+	if len(code) == totpDigits {
+		return s.verifyTOTP(ctx, m, code)
+	}
+	return s.useRecoveryCode(ctx, userID, code)
+}
+
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	m, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Enabled {
+		return nil, mfa.ErrNotEnrolled.Errorf("secret of user %d is not activated", userID)
+	}
+
+	plain := make([]string, 0, recoveryCodeCount)
+	codes := make([]*mfa.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(recoveryCodeLength, []byte(recoveryCodeAlphabet)...)
+		if err != nil {
+			return nil, err
+		}
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return nil, err
+		}
+		hashed, err := util.EncodePassword(code, salt)
+		if err != nil {
+			return nil, err
+		}
+
+		plain = append(plain, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		codes = append(codes, &mfa.RecoveryCode{UserID: userID, Code: hashed, Salt: salt, Created: s.now()})
+	}
+
+	if err := s.store.ReplaceRecoveryCodes(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+func (s *Service) Disable(ctx context.Context, userID int64) error {
+	return s.store.Delete(ctx, userID)
+}
+
+// verifyLoginHook asks for a code from the users logging in with their
+// Grafana password, and makes users who are required to use multi-factor
+// authentication set it up before they can log in.
+func (s *Service) verifyLoginHook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
+	if identity.AuthenticatedBy != login.PasswordAuthModule {
+		return nil
+	}
+
+	namespace, id := identity.GetNamespacedID()
+	if namespace != authn.NamespaceUser {
+		return nil
+	}
+
+	userID, err := authidentity.IntIdentifier(namespace, id)
+	if err != nil {
+		return err
+	}
+
+	m, err := s.store.Get(ctx, userID)
+	if err != nil && !errors.Is(err, mfa.ErrNotEnrolled) {
+		return err
+	}
+
+	code := r.GetMeta(authn.MetaKeyOTP)
+	if m != nil && m.Enabled {
+		if code == "" {
+			return mfa.ErrRequired.Errorf("no code provided for user %d", userID)
+		}
+		if err := s.Verify(ctx, userID, code); err != nil {
+			if errors.Is(err, mfa.ErrInvalidCode) {
+				_ = s.loginAttempts.Add(ctx, identity.Login, loginattempt.ClientIP(r.HTTPRequest, s.cfg.BruteForceLoginProtection.TrustedProxies))
+			}
+			return err
+		}
+		return nil
+	}
+
+	required, err := s.isRequired(ctx, identity, userID)
+	if err != nil || !required {
+		return err
+	}
+
+	// The user has been shown a secret on a previous attempt and logs in with a code generated with it.
+	if m != nil && code != "" {
+		if err := s.activate(ctx, m, code); err != nil {
+			if errors.Is(err, mfa.ErrInvalidCode) {
+				_ = s.loginAttempts.Add(ctx, identity.Login, loginattempt.ClientIP(r.HTTPRequest, s.cfg.BruteForceLoginProtection.TrustedProxies))
+			}
+			return err
+		}
+		return nil
+	}
+
+	var enrollment *mfa.Enrollment
+	if m != nil {
+		enrollment, err = s.enrollment(ctx, m, identity.Login)
+	} else {
+		enrollment, err = s.enroll(ctx, userID, identity.Login)
+	}
+	if err != nil {
+		return err
+	}
+
+	return mfa.ErrEnrollmentRequired.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"secret": enrollment.Secret,
+			"url":    enrollment.URL,
+		},
+	})
+}
+
+// isRequired reports whether the user is a Grafana server admin or an admin
+// of any organization when multi-factor authentication is required for admins.
+func (s *Service) isRequired(ctx context.Context, identity *authn.Identity, userID int64) (bool, error) {
+	if !s.cfg.MFARequiredForAdmins {
+		return false, nil
+	}
+	if identity.GetIsGrafanaAdmin() {
+		return true, nil
+	}
+
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		if o.Role == org.RoleAdmin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) enroll(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	err = s.store.Upsert(ctx, &mfa.UserMFA{
+		UserID:  userID,
+		Secret:  base64.StdEncoding.EncodeToString(encrypted),
+		Created: now,
+		Updated: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mfa.Enrollment{Secret: secret, URL: totpURL(s.cfg.MFAIssuer, login, secret)}, nil
+}
+
+func (s *Service) enrollment(ctx context.Context, m *mfa.UserMFA, login string) (*mfa.Enrollment, error) {
+	secret, err := s.decryptSecret(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return &mfa.Enrollment{Secret: secret, URL: totpURL(s.cfg.MFAIssuer, login, secret)}, nil
+}
+
+func (s *Service) activate(ctx context.Context, m *mfa.UserMFA, code string) error {
+	secret, err := s.decryptSecret(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := validateTOTP(secret, normalizeCode(code), s.now(), m.LastUsedStep)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return mfa.ErrInvalidCode.Errorf("invalid code for user %d", m.UserID)
+	}
+
+	m.Enabled = true
+	m.LastUsedStep = step
+	m.Updated = s.now()
+	return s.store.Upsert(ctx, m)
+}
+
+func (s *Service) verifyTOTP(ctx context.Context, m *mfa.UserMFA, code string) error {
+	secret, err := s.decryptSecret(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := validateTOTP(secret, code, s.now(), m.LastUsedStep)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return mfa.ErrInvalidCode.Errorf("invalid code for user %d", m.UserID)
+	}
+
+	// Another request may have used the same code concurrently.
+	updated, err := s.store.UpdateLastUsedStep(ctx, m.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return mfa.ErrInvalidCode.Errorf("code already used for user %d", m.UserID)
+	}
+	return nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	codes, err := s.store.ListRecoveryCodes(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range codes {
+		hashed, err := util.EncodePassword(code, c.Salt)
+		if err != nil {
+			return err
+		}
+		if hashed != c.Code {
+			continue
+		}
+
+		deleted, err := s.store.DeleteRecoveryCode(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			break
+		}
+		s.logger.FromContext(ctx).Info("Recovery code used", "userId", userID, "left", len(codes)-1)
+		return nil
+	}
+
+	return mfa.ErrInvalidCode.Errorf("invalid recovery code for user %d", userID)
+}
+
+func (s *Service) decryptSecret(ctx context.Context, m *mfa.UserMFA) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(m.Secret)
+	if err != nil {
+		return "", err
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// normalizeCode removes the separators users may type, recovery codes are
+// displayed with a dash in the middle.
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
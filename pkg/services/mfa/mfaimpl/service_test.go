@@ -0,0 +1,185 @@
+package mfaimpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func TestIntegrationService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Unix(1700000000, 0)
+	s := setupTestService(t, &now, &orgtest.FakeOrgService{})
+	ctx := context.Background()
+
+	status, err := s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &mfa.Status{}, status)
+
+	enrollment, err := s.Enroll(ctx, 1, "user")
+	require.NoError(t, err)
+
+	status, err = s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Pending)
+
+	_, err = s.Activate(ctx, 1, "000000")
+	assert.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+	code, err := totpCode(enrollment.Secret, timeStep(now))
+	require.NoError(t, err)
+	recoveryCodes, err := s.Activate(ctx, 1, code)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, recoveryCodeCount)
+
+	_, err = s.Enroll(ctx, 1, "user")
+	assert.ErrorIs(t, err, mfa.ErrAlreadyEnrolled)
+
+	// The code used for the activation cannot be used again.
+	assert.ErrorIs(t, s.Verify(ctx, 1, code), mfa.ErrInvalidCode)
+
+	now = now.Add(totpPeriod)
+	code, err = totpCode(enrollment.Secret, timeStep(now))
+	require.NoError(t, err)
+	require.NoError(t, s.Verify(ctx, 1, code))
+
+	require.NoError(t, s.Verify(ctx, 1, recoveryCodes[0]))
+	assert.ErrorIs(t, s.Verify(ctx, 1, recoveryCodes[0]), mfa.ErrInvalidCode)
+
+	status, err = s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesLeft)
+
+	require.NoError(t, s.Disable(ctx, 1))
+	assert.ErrorIs(t, s.Verify(ctx, 1, recoveryCodes[1]), mfa.ErrNotEnrolled)
+}
+
+func TestIntegrationService_verifyLoginHook(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Unix(1700000000, 0)
+	ctx := context.Background()
+	newRequest := func(code string) *authn.Request {
+		r := &authn.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+		if code != "" {
+			r.SetMeta(authn.MetaKeyOTP, code)
+		}
+		return r
+	}
+	newIdentity := func(authenticatedBy string) *authn.Identity {
+		return &authn.Identity{ID: authn.NamespacedID(authn.NamespaceUser, 1), Login: "user", AuthenticatedBy: authenticatedBy}
+	}
+
+	t.Run("should not require a code for users that are not enrolled", func(t *testing.T) {
+		s := setupTestService(t, &now, &orgtest.FakeOrgService{})
+		require.NoError(t, s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest("")))
+	})
+
+	t.Run("should require a code for enrolled users", func(t *testing.T) {
+		s := setupTestService(t, &now, &orgtest.FakeOrgService{})
+		secret := enrollTestUser(t, s, now)
+
+		err := s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest(""))
+		assert.ErrorIs(t, err, mfa.ErrRequired)
+
+		err = s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest("000000"))
+		assert.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+		next := now.Add(totpPeriod)
+		s.now = func() time.Time { return next }
+		code, err := totpCode(secret, timeStep(next))
+		require.NoError(t, err)
+		require.NoError(t, s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest(code)))
+	})
+
+	t.Run("should skip identities not authenticated with a Grafana password", func(t *testing.T) {
+		s := setupTestService(t, &now, &orgtest.FakeOrgService{})
+		enrollTestUser(t, s, now)
+
+		require.NoError(t, s.verifyLoginHook(ctx, newIdentity(login.LDAPAuthModule), newRequest("")))
+	})
+
+	t.Run("should require org admins to enroll when required", func(t *testing.T) {
+		s := setupTestService(t, &now, &orgtest.FakeOrgService{
+			ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleViewer}, {OrgID: 2, Role: org.RoleAdmin}},
+		})
+		s.cfg.MFARequiredForAdmins = true
+
+		err := s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest(""))
+		require.ErrorIs(t, err, mfa.ErrEnrollmentRequired)
+
+		var gfErr errutil.Error
+		require.ErrorAs(t, err, &gfErr)
+		secret, ok := gfErr.PublicPayload["secret"].(string)
+		require.True(t, ok)
+
+		code, err := totpCode(secret, timeStep(now))
+		require.NoError(t, err)
+		require.NoError(t, s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest(code)))
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+	})
+
+	t.Run("should not require viewers to enroll", func(t *testing.T) {
+		s := setupTestService(t, &now, &orgtest.FakeOrgService{
+			ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleViewer}},
+		})
+		s.cfg.MFARequiredForAdmins = true
+
+		require.NoError(t, s.verifyLoginHook(ctx, newIdentity(login.PasswordAuthModule), newRequest("")))
+	})
+}
+
+func setupTestService(t *testing.T, now *time.Time, orgService org.Service) *Service {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.MFAEnabled = true
+	cfg.MFAIssuer = "Grafana"
+
+	return &Service{
+		store:         &xormStore{db: db.InitTestDB(t)},
+		cfg:           cfg,
+		secrets:       fakes.NewFakeSecretsService(),
+		orgService:    orgService,
+		loginAttempts: loginattempttest.FakeLoginAttemptService{},
+		logger:        log.NewNopLogger(),
+		now:           func() time.Time { return *now },
+	}
+}
+
+func enrollTestUser(t *testing.T, s *Service, now time.Time) string {
+	t.Helper()
+
+	enrollment, err := s.Enroll(context.Background(), 1, "user")
+	require.NoError(t, err)
+	code, err := totpCode(enrollment.Secret, timeStep(now))
+	require.NoError(t, err)
+	_, err = s.Activate(context.Background(), 1, code)
+	require.NoError(t, err)
+	return enrollment.Secret
+}
@@ -0,0 +1,125 @@
+package mfaimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+type store interface {
+	// Get returns the TOTP secret of the user, or mfa.ErrNotEnrolled.
+	Get(ctx context.Context, userID int64) (*mfa.UserMFA, error)
+	// Upsert stores the TOTP secret of the user.
+	Upsert(ctx context.Context, m *mfa.UserMFA) error
+	// UpdateLastUsedStep records the time step of the last accepted code, unless a later one was recorded.
+	UpdateLastUsedStep(ctx context.Context, userID, step int64) (bool, error)
+	// Delete removes the TOTP secret and the recovery codes of the user.
+	Delete(ctx context.Context, userID int64) error
+	// ListRecoveryCodes returns the unused recovery codes of the user.
+	ListRecoveryCodes(ctx context.Context, userID int64) ([]*mfa.RecoveryCode, error)
+	// ReplaceRecoveryCodes replaces the recovery codes of the user.
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*mfa.RecoveryCode) error
+	// DeleteRecoveryCode consumes a recovery code, it reports false when it was already used.
+	DeleteRecoveryCode(ctx context.Context, id int64) (bool, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (xs *xormStore) Get(ctx context.Context, userID int64) (*mfa.UserMFA, error) {
+	var m mfa.UserMFA
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("user_id = ?", userID).Get(&m)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return mfa.ErrNotEnrolled.Errorf("no secret found for user %d", userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (xs *xormStore) Upsert(ctx context.Context, m *mfa.UserMFA) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing mfa.UserMFA
+		has, err := sess.Where("user_id = ?", m.UserID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(m)
+			return err
+		}
+		m.ID = existing.ID
+		m.Created = existing.Created
+		_, err = sess.ID(existing.ID).AllCols().Update(m)
+		return err
+	})
+}
+
+func (xs *xormStore) UpdateLastUsedStep(ctx context.Context, userID, step int64) (bool, error) {
+	var updated bool
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?", step, userID, step)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		updated = n > 0
+		return err
+	})
+	return updated, err
+}
+
+func (xs *xormStore) Delete(ctx context.Context, userID int64) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (xs *xormStore) ListRecoveryCodes(ctx context.Context, userID int64) ([]*mfa.RecoveryCode, error) {
+	codes := make([]*mfa.RecoveryCode, 0)
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Find(&codes)
+	})
+	return codes, err
+}
+
+func (xs *xormStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []*mfa.RecoveryCode) error {
+	return xs.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		for _, c := range codes {
+			if _, err := sess.Insert(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (xs *xormStore) DeleteRecoveryCode(ctx context.Context, id int64) (bool, error) {
+	var deleted bool
+	err := xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		deleted = n > 0
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,82 @@
+package mfaimpl
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC uses 8 digits codes, authenticator apps use the last 6.
+	for _, tc := range []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+	} {
+		code, err := totpCode(rfcSecret, timeStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, code, "time %d", tc.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := timeStep(now)
+	code, err := totpCode(rfcSecret, current)
+	require.NoError(t, err)
+
+	t.Run("should accept code of the current step", func(t *testing.T) {
+		step, ok, err := validateTOTP(rfcSecret, code, now, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, current, step)
+	})
+
+	t.Run("should accept code of the previous step", func(t *testing.T) {
+		step, ok, err := validateTOTP(rfcSecret, code, now.Add(totpPeriod), 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, current, step)
+	})
+
+	t.Run("should reject code older than the allowed skew", func(t *testing.T) {
+		_, ok, err := validateTOTP(rfcSecret, code, now.Add(2*totpPeriod), 0)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject code that was already used", func(t *testing.T) {
+		_, ok, err := validateTOTP(rfcSecret, code, now, current)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject code with the wrong length", func(t *testing.T) {
+		_, ok, err := validateTOTP(rfcSecret, code[:5], now, 0)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTPURL(t *testing.T) {
+	u, err := url.Parse(totpURL("Grafana", "admin@example.com", rfcSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Grafana:admin@example.com", u.Path)
+	assert.Equal(t, rfcSecret, u.Query().Get("secret"))
+	assert.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
@@ -0,0 +1,40 @@
+package mfatest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+var _ mfa.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedStatus        *mfa.Status
+	ExpectedEnrollment    *mfa.Enrollment
+	ExpectedRecoveryCodes []string
+	ExpectedErr           error
+}
+
+func (f *FakeService) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	return f.ExpectedStatus, f.ExpectedErr
+}
+
+func (f *FakeService) Enroll(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	return f.ExpectedEnrollment, f.ExpectedErr
+}
+
+func (f *FakeService) Activate(ctx context.Context, userID int64, code string) ([]string, error) {
+	return f.ExpectedRecoveryCodes, f.ExpectedErr
+}
+
+func (f *FakeService) Verify(ctx context.Context, userID int64, code string) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) RegenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	return f.ExpectedRecoveryCodes, f.ExpectedErr
+}
+
+func (f *FakeService) Disable(ctx context.Context, userID int64) error {
+	return f.ExpectedErr
+}
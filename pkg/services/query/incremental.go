@@ -0,0 +1,502 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// defaultIncrementalOverlap is how far back from now the recent edge is always re-queried,
+	// as data sources may still receive samples for that period
+	defaultIncrementalOverlap = 10 * time.Minute
+	// maxIncrementalPieces limits the number of requests sent to the data source for one query
+	maxIncrementalPieces = 8
+	// defaultIncrementalCacheMaxChunks is the number of chunks kept in the cache
+	defaultIncrementalCacheMaxChunks = 10000
+)
+
+// incrementalEligible returns true for the queries of a data source type whose results can
+// be split by time and merged again.
+var incrementalEligible = map[string]func(model map[string]any) bool{
+	datasources.DS_PROMETHEUS: func(model map[string]any) bool {
+		isRange, _ := model["range"].(bool)
+		isInstant, _ := model["instant"].(bool)
+		return isRange && !isInstant
+	},
+	datasources.DS_LOKI: func(model map[string]any) bool {
+		// Log queries are limited by a line count, so only metric queries are split
+		queryType, _ := model["queryType"].(string)
+		expr, _ := model["expr"].(string)
+		return queryType == "range" && !strings.HasPrefix(strings.TrimSpace(expr), "{")
+	},
+	datasources.DS_POSTGRES: sqlTimeSeriesQuery,
+	datasources.DS_MYSQL:    sqlTimeSeriesQuery,
+	datasources.DS_MSSQL:    sqlTimeSeriesQuery,
+}
+
+func sqlTimeSeriesQuery(model map[string]any) bool {
+	format, _ := model["format"].(string)
+	return format == "time_series"
+}
+
+// errNotSplittable is returned when a response holds frames without a time field, which can
+// not be split into chunks.
+var errNotSplittable = errors.New("frames can not be split by time")
+
+type queryDataFn func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error)
+
+// incrementalQuerier splits the time range of long queries into chunks aligned to the chunk
+// size. Chunks ending before the recent edge are complete and cached, so a refresh only
+// queries the chunks missing from the cache and the recent edge, then merges the frames.
+type incrementalQuerier struct {
+	enabled   bool
+	chunkSize time.Duration
+	// concurrency limits the requests sent to the data source at once for one query
+	concurrency int
+	// cache holds the frames of complete chunks, evicting the least recently used
+	// chunks when it is full
+	cache *expirable.LRU[string, data.Frames]
+	now   func() time.Time
+	log   log.Logger
+}
+
+func newIncrementalQuerier(cfg *setting.Cfg, concurrency int) *incrementalQuerier {
+	section := cfg.SectionWithEnvOverrides("query")
+	q := &incrementalQuerier{
+		enabled:     section.Key("incremental_querying").MustBool(false),
+		chunkSize:   section.Key("incremental_chunk_duration").MustDuration(time.Hour),
+		concurrency: concurrency,
+		now:         time.Now,
+		log:         log.New("query_data.incremental"),
+	}
+	ttl := section.Key("incremental_cache_ttl").MustDuration(6 * time.Hour)
+	maxChunks := section.Key("incremental_cache_max_chunks").MustInt(defaultIncrementalCacheMaxChunks)
+	if q.chunkSize <= 0 || ttl <= 0 || maxChunks <= 0 {
+		q.enabled = false
+		maxChunks = 1
+	}
+	q.cache = expirable.NewLRU[string, data.Frames](maxChunks, nil, ttl)
+	return q
+}
+
+// incrementalPlan describes how a query is split.
+type incrementalPlan struct {
+	query backend.DataQuery
+	// key identifies the query, the chunk start is appended to cache each chunk
+	key string
+	// chunks are the starts of the complete chunks covering the beginning of the time range
+	chunks []time.Time
+	// edge is the start of the recent edge, queried on every request
+	edge time.Time
+}
+
+// QueryData runs the incremental queries of the request one by one and all other queries
+// in a single request.
+func (q *incrementalQuerier) QueryData(ctx context.Context, ds *datasources.DataSource, req *backend.QueryDataRequest, queryData queryDataFn) (*backend.QueryDataResponse, error) {
+	plans := make([]incrementalPlan, 0)
+	rest := make([]backend.DataQuery, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		if plan, ok := q.plan(ctx, ds, req.PluginContext, query); ok {
+			plans = append(plans, plan)
+		} else {
+			rest = append(rest, query)
+		}
+	}
+
+	if len(plans) == 0 {
+		return queryData(ctx, req)
+	}
+
+	resp := backend.NewQueryDataResponse()
+	if len(rest) > 0 {
+		restResp, err := queryData(ctx, withQueries(req, rest...))
+		if err != nil {
+			return nil, err
+		}
+		for refID, r := range restResp.Responses {
+			resp.Responses[refID] = r
+		}
+	}
+
+	for _, plan := range plans {
+		r, err := q.execute(ctx, req, plan, queryData)
+		if errors.Is(err, errNotSplittable) {
+			q.log.Debug("Query results can not be split, querying the full time range", "refId", plan.query.RefID)
+			fullResp, err := queryData(ctx, withQueries(req, plan.query))
+			if err != nil {
+				return nil, err
+			}
+			r = fullResp.Responses[plan.query.RefID]
+		} else if err != nil {
+			return nil, err
+		}
+		resp.Responses[plan.query.RefID] = r
+	}
+
+	return resp, nil
+}
+
+// plan returns the chunks of an eligible query, or false if the query should be sent as is.
+func (q *incrementalQuerier) plan(ctx context.Context, ds *datasources.DataSource, pCtx backend.PluginContext, query backend.DataQuery) (incrementalPlan, bool) {
+	// The Prometheus query editor has its own incremental querying, turned on with incrementalQuerying
+	if !q.enabled || ds == nil || ds.JsonData == nil || !ds.JsonData.Get("serverIncrementalQuerying").MustBool(false) {
+		return incrementalPlan{}, false
+	}
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		return incrementalPlan{}, false
+	}
+
+	eligible, ok := incrementalEligible[ds.Type]
+	if !ok {
+		return incrementalPlan{}, false
+	}
+	// $__range and its variants are interpolated with the time range of each chunk,
+	// so the results of a chunk depend on the full time range
+	if bytes.Contains(query.JSON, []byte("__range")) {
+		return incrementalPlan{}, false
+	}
+	model := map[string]any{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil || !eligible(model) {
+		return incrementalPlan{}, false
+	}
+
+	overlap := defaultIncrementalOverlap
+	if v := ds.JsonData.Get("serverIncrementalQueryOverlapWindow").MustString(""); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			overlap = d
+		}
+	}
+
+	from, to := query.TimeRange.From, query.TimeRange.To
+	edge := to
+	if recent := q.now().Add(-overlap); recent.Before(edge) {
+		edge = recent
+	}
+	edge = edge.Truncate(q.chunkSize)
+
+	start := from.Truncate(q.chunkSize)
+	if !start.Before(edge) {
+		return incrementalPlan{}, false // the whole range is recent
+	}
+
+	chunks := make([]time.Time, 0)
+	for t := start; t.Before(edge); t = t.Add(q.chunkSize) {
+		chunks = append(chunks, t)
+	}
+
+	key, err := incrementalKey(ds, pCtx, query, model)
+	if err != nil {
+		q.log.Warn("Failed to create incremental query key", "error", err)
+		return incrementalPlan{}, false
+	}
+
+	return incrementalPlan{
+		query:  query,
+		key:    key,
+		chunks: chunks,
+		edge:   edge,
+	}, true
+}
+
+// incrementalPiece is a part of the time range queried in one request. Pieces covering
+// complete chunks are split into chunks and cached.
+type incrementalPiece struct {
+	from, to time.Time
+	// first and last index of the chunks covered by the piece, or -1 for the recent edge
+	first, last int
+	frames      data.Frames
+}
+
+func (q *incrementalQuerier) execute(ctx context.Context, req *backend.QueryDataRequest, plan incrementalPlan, queryData queryDataFn) (backend.DataResponse, error) {
+	cached := make([]data.Frames, len(plan.chunks))
+	pieces := make([]*incrementalPiece, 0)
+	for i, start := range plan.chunks {
+		if frames, ok := q.cache.Get(plan.key + start.String()); ok {
+			cached[i] = frames
+			continue
+		}
+		// Extend the piece of the previous chunk if it is also missing
+		if n := len(pieces); n > 0 && pieces[n-1].last == i-1 {
+			pieces[n-1].last = i
+			pieces[n-1].to = start.Add(q.chunkSize)
+			continue
+		}
+		pieces = append(pieces, &incrementalPiece{from: start, to: start.Add(q.chunkSize), first: i, last: i})
+	}
+	edge := &incrementalPiece{from: plan.edge, to: plan.query.TimeRange.To, first: -1, last: -1}
+	if edge.from.Before(edge.to) {
+		pieces = append(pieces, edge)
+	}
+
+	if len(pieces) > maxIncrementalPieces {
+		return backend.DataResponse{}, errNotSplittable
+	}
+
+	var (
+		mu      sync.Mutex
+		errResp *backend.DataResponse
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	if q.concurrency > 0 {
+		g.SetLimit(q.concurrency)
+	}
+	for _, piece := range pieces {
+		piece := piece
+		g.Go(func() error {
+			sub := plan.query
+			sub.TimeRange = backend.TimeRange{From: piece.from, To: piece.to}
+			sub.MaxDataPoints = scaleMaxDataPoints(plan.query, piece.from, piece.to)
+
+			resp, err := queryData(gctx, withQueries(req, sub))
+			if err != nil {
+				return err
+			}
+			r := resp.Responses[sub.RefID]
+			if r.Error != nil {
+				mu.Lock()
+				errResp = &r
+				mu.Unlock()
+				return nil
+			}
+			piece.frames = r.Frames
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return backend.DataResponse{}, err
+	}
+	if errResp != nil {
+		return *errResp, nil
+	}
+
+	parts := make([]data.Frames, 0, len(plan.chunks)+1)
+	for _, piece := range pieces {
+		if piece.first < 0 {
+			continue
+		}
+		split, err := splitFrames(piece.frames, plan.chunks[piece.first:piece.last+1], q.chunkSize)
+		if err != nil {
+			return backend.DataResponse{}, err
+		}
+		for i, frames := range split {
+			cached[piece.first+i] = frames
+			q.cache.Add(plan.key+plan.chunks[piece.first+i].String(), frames)
+		}
+	}
+	parts = append(parts, cached...)
+	parts = append(parts, edge.frames)
+
+	frames, err := mergeFrames(parts, plan.query.TimeRange.From, plan.query.TimeRange.To)
+	if err != nil {
+		return backend.DataResponse{}, err
+	}
+	return backend.DataResponse{Frames: frames}, nil
+}
+
+// scaleMaxDataPoints keeps the resolution of a piece of the time range the same as the
+// resolution of the full time range.
+func scaleMaxDataPoints(query backend.DataQuery, from, to time.Time) int64 {
+	full := query.TimeRange.To.Sub(query.TimeRange.From)
+	if full <= 0 || query.MaxDataPoints <= 0 {
+		return query.MaxDataPoints
+	}
+	scaled := int64(float64(query.MaxDataPoints) * float64(to.Sub(from)) / float64(full))
+	if scaled < 1 {
+		return 1
+	}
+	return scaled
+}
+
+// incrementalKey identifies the query, the data source version and the user when the data
+// source forwards the identity of the user.
+func incrementalKey(ds *datasources.DataSource, pCtx backend.PluginContext, query backend.DataQuery, model map[string]any) (string, error) {
+	delete(model, "requestId")
+
+	scope := ""
+	if ds.JsonData.Get("oauthPassThru").MustBool(false) {
+		if pCtx.User == nil {
+			return "", fmt.Errorf("data source forwards user credentials but the request has no user")
+		}
+		scope = pCtx.User.Login
+	}
+
+	b, err := json.Marshal(struct {
+		OrgID         int64          `json:"orgId"`
+		Datasource    string         `json:"datasource"`
+		Updated       int64          `json:"updated"`
+		Scope         string         `json:"scope"`
+		QueryType     string         `json:"queryType"`
+		IntervalMs    int64          `json:"intervalMs"`
+		MaxDataPoints int64          `json:"maxDataPoints"`
+		SpanMs        int64          `json:"spanMs"`
+		Model         map[string]any `json:"model"`
+	}{
+		OrgID:         ds.OrgID,
+		Datasource:    ds.UID,
+		Updated:       ds.Updated.UnixNano(),
+		Scope:         scope,
+		QueryType:     query.QueryType,
+		IntervalMs:    query.Interval.Milliseconds(),
+		MaxDataPoints: query.MaxDataPoints,
+		SpanMs:        query.TimeRange.To.Sub(query.TimeRange.From).Milliseconds(),
+		Model:         model,
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]) + ":", nil
+}
+
+func withQueries(req *backend.QueryDataRequest, queries ...backend.DataQuery) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: req.PluginContext,
+		Headers:       req.Headers,
+		Queries:       queries,
+	}
+}
+
+// splitFrames splits the frames into one set of frames per chunk.
+func splitFrames(frames data.Frames, chunks []time.Time, chunkSize time.Duration) ([]data.Frames, error) {
+	split := make([]data.Frames, len(chunks))
+	for i := range split {
+		split[i] = data.Frames{}
+	}
+
+	for _, frame := range frames {
+		timeIdx := timeFieldIndex(frame)
+		if timeIdx < 0 {
+			return nil, errNotSplittable
+		}
+
+		for i, start := range chunks {
+			end := start.Add(chunkSize)
+			out := copyFrameSchema(frame)
+			for row := 0; row < frame.Rows(); row++ {
+				t, ok := timeAt(frame.Fields[timeIdx], row)
+				if !ok || t.Before(start) || !t.Before(end) {
+					continue
+				}
+				out.AppendRow(frame.RowCopy(row)...)
+			}
+			split[i] = append(split[i], out)
+		}
+	}
+	return split, nil
+}
+
+// mergeFrames appends the rows of the frames of the same series in the order of the parts,
+// dropping rows outside of the time range and rows overlapping the previous part.
+func mergeFrames(parts []data.Frames, from, to time.Time) (data.Frames, error) {
+	merged := data.Frames{}
+	byKey := map[string]*data.Frame{}
+	last := map[string]time.Time{}
+
+	for _, frames := range parts {
+		for _, frame := range frames {
+			timeIdx := timeFieldIndex(frame)
+			if timeIdx < 0 {
+				return nil, errNotSplittable
+			}
+
+			key := frameKey(frame)
+			out, ok := byKey[key]
+			if !ok {
+				out = copyFrameSchema(frame)
+				byKey[key] = out
+				merged = append(merged, out)
+			} else if frame.Meta != nil {
+				// the meta of the most recent part describes the merged frame best
+				out.Meta = copyFrameMeta(frame.Meta)
+			}
+
+			for row := 0; row < frame.Rows(); row++ {
+				t, ok := timeAt(frame.Fields[timeIdx], row)
+				if !ok || t.Before(from) || t.After(to) {
+					continue
+				}
+				if prev, ok := last[key]; ok && !t.After(prev) {
+					continue
+				}
+				out.AppendRow(frame.RowCopy(row)...)
+				last[key] = t
+			}
+		}
+	}
+	return merged, nil
+}
+
+// copyFrameSchema returns an empty frame with the fields, field configs and meta of the frame.
+func copyFrameSchema(frame *data.Frame) *data.Frame {
+	out := frame.EmptyCopy()
+	out.Meta = copyFrameMeta(frame.Meta)
+	for i, field := range frame.Fields {
+		out.Fields[i].Config = field.Config
+	}
+	return out
+}
+
+// copyFrameMeta copies the meta so notices and stats added to a merged frame do not change
+// the cached frames. The custom meta is data source specific and shared as is.
+func copyFrameMeta(meta *data.FrameMeta) *data.FrameMeta {
+	if meta == nil {
+		return nil
+	}
+	out := *meta
+	out.Stats = slices.Clone(meta.Stats)
+	out.Notices = slices.Clone(meta.Notices)
+	return &out
+}
+
+// frameKey identifies the series held by a frame.
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("|")
+		sb.WriteString(field.Name)
+		sb.WriteString(field.Labels.String())
+		sb.WriteString(field.Type().ItemTypeString())
+	}
+	return sb.String()
+}
+
+func timeFieldIndex(frame *data.Frame) int {
+	for i, field := range frame.Fields {
+		if field.Type() == data.FieldTypeTime || field.Type() == data.FieldTypeNullableTime {
+			return i
+		}
+	}
+	return -1
+}
+
+func timeAt(field *data.Field, idx int) (time.Time, bool) {
+	switch v := field.At(idx).(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,285 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeRangeSource returns one frame with a point every minute of the requested time range
+// and records the requested ranges.
+type fakeRangeSource struct {
+	mu       sync.Mutex
+	requests []backend.TimeRange
+	err      error
+}
+
+func (f *fakeRangeSource) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		f.mu.Lock()
+		f.requests = append(f.requests, q.TimeRange)
+		f.mu.Unlock()
+
+		if f.err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: f.err}
+			continue
+		}
+
+		times := []time.Time{}
+		values := []float64{}
+		for t := q.TimeRange.From.Truncate(time.Minute); !t.After(q.TimeRange.To); t = t.Add(time.Minute) {
+			if t.Before(q.TimeRange.From) {
+				continue
+			}
+			times = append(times, t)
+			values = append(values, float64(t.Unix()))
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{
+			data.NewFrame("up",
+				data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+				data.NewField(data.TimeSeriesValueFieldName, data.Labels{"job": "api"}, values)),
+		}}
+	}
+	return resp, nil
+}
+
+func newIncrementalCfg(t *testing.T) *setting.Cfg {
+	t.Helper()
+	cfg := setting.NewCfg()
+	_, err := cfg.Raw.Section("query").NewKey("incremental_querying", "true")
+	require.NoError(t, err)
+	return cfg
+}
+
+func newTestIncrementalQuerier(t *testing.T, now time.Time) *incrementalQuerier {
+	q := newIncrementalQuerier(newIncrementalCfg(t), 4)
+	q.now = func() time.Time { return now }
+	return q
+}
+
+func newIncrementalDataSource(dsType string, jsonData string) *datasources.DataSource {
+	jd, _ := simplejson.NewJson([]byte(jsonData))
+	return &datasources.DataSource{UID: "ds", OrgID: 1, Type: dsType, JsonData: jd}
+}
+
+func newIncrementalRequest(from, to time.Time, model string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{OrgID: 1},
+		Queries: []backend.DataQuery{
+			{
+				RefID:         "A",
+				JSON:          []byte(model),
+				MaxDataPoints: 1000,
+				Interval:      time.Minute,
+				TimeRange:     backend.TimeRange{From: from, To: to},
+			},
+		},
+	}
+}
+
+func TestIncrementalQuerier(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 30, 0, 0, time.UTC)
+	from, to := now.Add(-3*time.Hour), now
+	promQuery := `{"expr":"up","range":true}`
+	enabled := `{"serverIncrementalQuerying":true}`
+
+	t.Run("queries missing chunks and the recent edge, then only the recent edge", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now)
+		ds := newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled)
+		source := &fakeRangeSource{}
+
+		resp, err := q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		// chunks from 09:00 to 12:00 in one request, then the edge from 12:00
+		require.ElementsMatch(t, []backend.TimeRange{
+			{From: time.Date(2023, 10, 1, 9, 0, 0, 0, time.UTC), To: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)},
+			{From: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), To: to},
+		}, source.requests)
+
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 181, frames[0].Rows())
+		first, _ := frames[0].Fields[0].At(0).(time.Time)
+		last, _ := frames[0].Fields[0].At(180).(time.Time)
+		require.Equal(t, from, first)
+		require.Equal(t, to, last)
+
+		source.requests = nil
+		resp, err = q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Equal(t, []backend.TimeRange{{From: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), To: to}}, source.requests)
+		require.Equal(t, 181, resp.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("the recent edge covers the overlap window", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now.Add(-25*time.Minute))
+		ds := newIncrementalDataSource(datasources.DS_PROMETHEUS, `{"serverIncrementalQuerying":true,"serverIncrementalQueryOverlapWindow":"30m"}`)
+		source := &fakeRangeSource{}
+
+		_, err := q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Contains(t, source.requests, backend.TimeRange{From: time.Date(2023, 10, 1, 11, 0, 0, 0, time.UTC), To: to})
+	})
+
+	t.Run("ineligible queries and data sources are sent as is", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now)
+		for name, tc := range map[string]struct {
+			dsType, jsonData, model string
+		}{
+			"not enabled":      {datasources.DS_PROMETHEUS, `{}`, promQuery},
+			"query editor key": {datasources.DS_PROMETHEUS, `{"incrementalQuerying":true}`, promQuery},
+			"range variable":   {datasources.DS_PROMETHEUS, enabled, `{"expr":"increase(up[$__range])","range":true}`},
+			"instant query":    {datasources.DS_PROMETHEUS, enabled, `{"expr":"up","range":true,"instant":true}`},
+			"loki log query":   {datasources.DS_LOKI, enabled, `{"expr":"{job=\"api\"}","queryType":"range"}`},
+			"sql table format": {datasources.DS_MYSQL, enabled, `{"rawSql":"SELECT 1","format":"table"}`},
+			"other type":       {datasources.DS_GRAPHITE, enabled, `{"target":"up"}`},
+		} {
+			t.Run(name, func(t *testing.T) {
+				source := &fakeRangeSource{}
+				_, err := q.QueryData(context.Background(), newIncrementalDataSource(tc.dsType, tc.jsonData), newIncrementalRequest(from, to, tc.model), source.QueryData)
+				require.NoError(t, err)
+				require.Equal(t, []backend.TimeRange{{From: from, To: to}}, source.requests)
+			})
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		q := newIncrementalQuerier(setting.NewCfg(), 4)
+		q.now = func() time.Time { return now }
+		source := &fakeRangeSource{}
+		_, err := q.QueryData(context.Background(), newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled), newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Equal(t, []backend.TimeRange{{From: from, To: to}}, source.requests)
+	})
+
+	t.Run("recent time ranges are sent as is", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now)
+		source := &fakeRangeSource{}
+		_, err := q.QueryData(context.Background(), newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled), newIncrementalRequest(now.Add(-5*time.Minute), now, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Len(t, source.requests, 1)
+	})
+
+	t.Run("evicts the least recently used chunks when the cache is full", func(t *testing.T) {
+		cfg := newIncrementalCfg(t)
+		_, err := cfg.Raw.Section("query").NewKey("incremental_cache_max_chunks", "1")
+		require.NoError(t, err)
+		q := newIncrementalQuerier(cfg, 4)
+		q.now = func() time.Time { return now }
+		ds := newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled)
+		source := &fakeRangeSource{}
+
+		_, err = q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Equal(t, 1, q.cache.Len())
+
+		source.requests = nil
+		_, err = q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []backend.TimeRange{
+			{From: time.Date(2023, 10, 1, 9, 0, 0, 0, time.UTC), To: time.Date(2023, 10, 1, 11, 0, 0, 0, time.UTC)},
+			{From: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC), To: to},
+		}, source.requests)
+	})
+
+	t.Run("limits the requests sent at once to the concurrency", func(t *testing.T) {
+		q := newIncrementalQuerier(newIncrementalCfg(t), 1)
+		q.now = func() time.Time { return now }
+		ds := newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled)
+		source := &fakeRangeSource{}
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		queryData := func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+			return source.QueryData(ctx, req)
+		}
+
+		_, err := q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), queryData)
+		require.NoError(t, err)
+		require.Len(t, source.requests, 2)
+		require.Equal(t, 1, maxInFlight)
+	})
+
+	t.Run("errors are returned and not cached", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now)
+		ds := newIncrementalDataSource(datasources.DS_PROMETHEUS, enabled)
+		source := &fakeRangeSource{err: errNotSplittable}
+
+		resp, err := q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+
+		source.err = nil
+		source.requests = nil
+		_, err = q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, promQuery), source.QueryData)
+		require.NoError(t, err)
+		require.Len(t, source.requests, 2)
+	})
+
+	t.Run("frames without a time field fall back to the full time range", func(t *testing.T) {
+		q := newTestIncrementalQuerier(t, now)
+		ds := newIncrementalDataSource(datasources.DS_POSTGRES, enabled)
+		var requests int
+		queryData := func(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			requests++
+			resp := backend.NewQueryDataResponse()
+			resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}}
+			return resp, nil
+		}
+
+		resp, err := q.QueryData(context.Background(), ds, newIncrementalRequest(from, to, `{"rawSql":"SELECT 1","format":"time_series"}`), queryData)
+		require.NoError(t, err)
+		require.Equal(t, 3, requests)
+		require.Equal(t, 1, resp.Responses["A"].Frames[0].Rows())
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	at := func(min int) time.Time { return time.Date(2023, 10, 1, 0, min, 0, 0, time.UTC) }
+	frame := func(labels data.Labels, mins ...int) *data.Frame {
+		times := make([]time.Time, 0, len(mins))
+		values := make([]float64, 0, len(mins))
+		for _, m := range mins {
+			times = append(times, at(m))
+			values = append(values, float64(m))
+		}
+		return data.NewFrame("", data.NewField("time", nil, times), data.NewField("value", labels, values))
+	}
+
+	a, b := data.Labels{"job": "a"}, data.Labels{"job": "b"}
+	cached := data.Frames{frame(a, 0, 1, 2)}
+	cached[0].SetMeta(&data.FrameMeta{Notices: []data.Notice{{Text: "cached"}}})
+	merged, err := mergeFrames([]data.Frames{
+		cached,
+		{frame(a, 2, 3), frame(b, 3, 4)},
+		{frame(a, 3, 4, 5, 6)},
+	}, at(1), at(5))
+	require.NoError(t, err)
+	require.Len(t, merged, 2)
+	require.Equal(t, 5, merged[0].Rows()) // 1 to 5
+	require.Equal(t, 2, merged[1].Rows())
+	require.Equal(t, 3, cached[0].Rows(), "cached frames are not modified")
+
+	merged[0].AppendNotices(data.Notice{Text: "merged"})
+	require.Len(t, cached[0].Meta.Notices, 1, "cached frame meta is not modified")
+}
@@ -18,9 +18,10 @@ type parsedQuery struct {
 }
 
 type parsedRequest struct {
-	hasExpression bool
-	parsedQueries map[string][]parsedQuery
-	dsTypes       map[string]bool
+	hasExpression     bool
+	hasChainedQueries bool
+	parsedQueries     map[string][]parsedQuery
+	dsTypes           map[string]bool
 }
 
 func (pr parsedRequest) getFlattenedQueries() []parsedQuery {
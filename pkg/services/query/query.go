@@ -44,6 +44,7 @@ func ProvideService(
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
 ) *ServiceImpl {
+	concurrentQueryLimit := cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU())
 	g := &ServiceImpl{
 		cfg:                    cfg,
 		dataSourceCache:        dataSourceCache,
@@ -52,7 +53,8 @@ func ProvideService(
 		pluginClient:           pluginClient,
 		pCtxProvider:           pCtxProvider,
 		log:                    log.New("query_data"),
-		concurrentQueryLimit:   cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
+		concurrentQueryLimit:   concurrentQueryLimit,
+		incremental:            newIncrementalQuerier(cfg, concurrentQueryLimit),
 	}
 	g.log.Info("Query Service initialization")
 	return g
@@ -76,6 +78,7 @@ type ServiceImpl struct {
 	pCtxProvider           *plugincontext.Provider
 	log                    log.Logger
 	concurrentQueryLimit   int
+	incremental            *incrementalQuerier
 }
 
 // Run ServiceImpl.
@@ -93,7 +96,8 @@ func (s *ServiceImpl) QueryData(ctx context.Context, user identity.Requester, sk
 	}
 
 	// If there are expressions, handle them and return
-	if parsedReq.hasExpression {
+	// Expressions and chained queries run in dependency order in the expression pipeline
+	if parsedReq.hasExpression || parsedReq.hasChainedQueries {
 		return s.handleExpressions(ctx, user, parsedReq)
 	}
 	// If there is only one datasource, query it and return
@@ -267,6 +271,9 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
+	if s.incremental != nil {
+		return s.incremental.QueryData(ctx, ds, req, s.pluginClient.QueryData)
+	}
 	return s.pluginClient.QueryData(ctx, req)
 }
 
@@ -301,6 +308,10 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 			req.dsTypes[ds.Type] = true
 		}
 
+		if len(query.Get(expr.ChainedVariablesKey).MustArray()) > 0 {
+			req.hasChainedQueries = true
+		}
+
 		if _, ok := req.parsedQueries[ds.UID]; !ok {
 			req.parsedQueries[ds.UID] = []parsedQuery{}
 		}
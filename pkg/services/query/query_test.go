@@ -69,6 +69,28 @@ func TestParseMetricRequest(t *testing.T) {
 		assert.Len(t, parsedReq.getFlattenedQueries(), 2)
 	})
 
+	t.Run("Test a query with chained variables", func(t *testing.T) {
+		tc := setup(t)
+		mr := metricRequestWithQueries(t, `{
+			"refId": "A",
+			"datasource": {
+				"uid": "gIEkMvIVz",
+				"type": "postgres"
+			}
+		}`, `{
+			"refId": "B",
+			"datasource": {
+				"uid": "sEx6ZvSVk",
+				"type": "testdata"
+			},
+			"chainedVariables": [{"name": "host", "refId": "A", "label": "host"}]
+		}`)
+		parsedReq, err := tc.queryService.parseMetricRequest(context.Background(), tc.signedInUser, true, mr)
+		require.NoError(t, err)
+		assert.False(t, parsedReq.hasExpression)
+		assert.True(t, parsedReq.hasChainedQueries)
+	})
+
 	t.Run("Test a single datasource query with expressions", func(t *testing.T) {
 		tc := setup(t)
 		mr := metricRequestWithQueries(t, `{
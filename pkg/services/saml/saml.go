@@ -0,0 +1,72 @@
+package saml
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrDisabled          = errutil.BadRequest("auth.saml.disabled", errutil.WithPublicMessage("SAML authentication is not enabled"))
+	ErrInternal          = errutil.Internal("auth.saml.internal", errutil.WithPublicMessage("An internal error occurred in the SAML client"))
+	ErrInvalidResponse   = errutil.Unauthorized("auth.saml.response.invalid", errutil.WithPublicMessage("Invalid SAML response"))
+	ErrMissingAttribute  = errutil.Unauthorized("auth.saml.attribute.missing", errutil.WithPublicMessage("The identity provider didn't return the required user attributes"))
+	ErrOrgNotAllowed     = errutil.Unauthorized("auth.saml.org.not-allowed", errutil.WithPublicMessage("User is not a member of one of the allowed organizations"))
+	ErrIDPInitiatedLogin = errutil.Unauthorized("auth.saml.idp-initiated", errutil.WithPublicMessage("Login initiated by the identity provider is not allowed"))
+	ErrInvalidLogout     = errutil.BadRequest("auth.saml.logout.invalid", errutil.WithPublicMessage("Invalid SAML logout response"))
+)
+
+// Service is a SAML 2.0 service provider. Its settings are managed by the SSO settings service.
+type Service interface {
+	// IsEnabled returns true when SAML authentication is enabled and the service provider is configured.
+	IsEnabled() bool
+	// Info returns the login settings of the SAML client.
+	Info() Info
+	// AuthnRequest creates an authentication request for the identity provider.
+	AuthnRequest(ctx context.Context) (*AuthnRequest, error)
+	// ParseResponse validates the SAML response posted by the identity provider and maps
+	// the attributes of the assertion to a user. possibleRequestIDs are the IDs of the
+	// authentication requests the response may answer, it is empty for logins initiated
+	// by the identity provider.
+	ParseResponse(ctx context.Context, r *http.Request, possibleRequestIDs []string) (*UserInfo, error)
+	// LogoutRequest returns the URL of the identity provider that signs out the user with the name ID.
+	LogoutRequest(ctx context.Context, nameID string) (string, error)
+	// ValidateLogoutResponse validates the logout response sent by the identity provider.
+	ValidateLogoutResponse(r *http.Request) error
+	// Metadata returns the XML metadata of the service provider.
+	Metadata() ([]byte, error)
+	// SyncTeams adds the user to the teams mapped to their groups and removes them from
+	// the mapped teams of the groups they don't belong to.
+	SyncTeams(ctx context.Context, userID int64, groups []string) error
+}
+
+// Info holds the login settings of the SAML client.
+type Info struct {
+	Name            string
+	AllowSignUp     bool
+	AutoLogin       bool
+	SingleLogout    bool
+	SkipOrgRoleSync bool
+}
+
+// AuthnRequest is an authentication request for the identity provider.
+type AuthnRequest struct {
+	// ID is the ID of the request, the SAML response has to reference it.
+	ID string
+	// URL is the URL of the identity provider the user is redirected to.
+	URL string
+}
+
+// UserInfo is the user mapped from a SAML assertion.
+type UserInfo struct {
+	NameID         string
+	SessionIndex   string
+	Login          string
+	Email          string
+	Name           string
+	Groups         []string
+	OrgRoles       map[int64]org.RoleType
+	IsGrafanaAdmin *bool
+}
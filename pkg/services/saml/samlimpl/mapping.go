@@ -0,0 +1,232 @@
+package samlimpl
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	crewjamsaml "github.com/crewjam/saml"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// attributeTemplate matches the attributes referenced in assertion_attribute_name, e.g. $__saml{firstName} $__saml{lastName}
+var attributeTemplate = regexp.MustCompile(`\$__saml\{([^}]+)\}`)
+
+// orgMapping maps the members of an organization of the identity provider to a Grafana organization.
+type orgMapping struct {
+	org   string
+	orgID int64
+	// role is empty when the role comes from the role attribute
+	role org.RoleType
+}
+
+// teamMapping maps the members of a group of the identity provider to a Grafana team.
+type teamMapping struct {
+	group  string
+	orgID  int64
+	teamID int64
+}
+
+// parseOrgMapping parses org_mapping entries with the format Org:OrgID[:Role]. Org is * to map all users.
+func parseOrgMapping(entries []string) ([]orgMapping, error) {
+	mappings := make([]orgMapping, 0, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid org_mapping entry %q, expected Org:OrgID[:Role]", entry)
+		}
+
+		orgID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || orgID <= 0 {
+			return nil, fmt.Errorf("invalid org_mapping entry %q, %q is not an organization ID", entry, parts[1])
+		}
+
+		mapping := orgMapping{org: parts[0], orgID: orgID}
+		if len(parts) == 3 {
+			mapping.role = org.RoleType(parts[2])
+			if !mapping.role.IsValid() {
+				return nil, fmt.Errorf("invalid org_mapping entry %q, %q is not a role", entry, parts[2])
+			}
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// parseTeamMapping parses team_mapping entries with the format Group:OrgID:TeamID.
+func parseTeamMapping(entries []string) ([]teamMapping, error) {
+	mappings := make([]teamMapping, 0, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid team_mapping entry %q, expected Group:OrgID:TeamID", entry)
+		}
+
+		orgID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || orgID <= 0 {
+			return nil, fmt.Errorf("invalid team_mapping entry %q, %q is not an organization ID", entry, parts[1])
+		}
+
+		teamID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || teamID <= 0 {
+			return nil, fmt.Errorf("invalid team_mapping entry %q, %q is not a team ID", entry, parts[2])
+		}
+
+		mappings = append(mappings, teamMapping{group: parts[0], orgID: orgID, teamID: teamID})
+	}
+	return mappings, nil
+}
+
+// mapAssertion maps the subject and the attributes of a validated assertion to a user.
+func mapAssertion(cfg *setting.Cfg, settings *Settings, assertion *crewjamsaml.Assertion) (*saml.UserInfo, error) {
+	userInfo := &saml.UserInfo{
+		Login:  firstValue(assertion, settings.AssertionAttributeLogin),
+		Email:  firstValue(assertion, settings.AssertionAttributeEmail),
+		Name:   userName(assertion, settings.AssertionAttributeName),
+		Groups: attributeValues(assertion, settings.AssertionAttributeGroups),
+	}
+
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		userInfo.NameID = assertion.Subject.NameID.Value
+	}
+	if len(assertion.AuthnStatements) > 0 {
+		userInfo.SessionIndex = assertion.AuthnStatements[0].SessionIndex
+	}
+
+	if userInfo.Login == "" {
+		userInfo.Login = userInfo.Email
+	}
+	if userInfo.Login == "" {
+		return nil, saml.ErrMissingAttribute.Errorf("assertion does not have a value for %q or %q", settings.AssertionAttributeLogin, settings.AssertionAttributeEmail)
+	}
+
+	orgs := attributeValues(assertion, settings.AssertionAttributeOrg)
+	if len(settings.AllowedOrganizations) > 0 && !slices.ContainsFunc(orgs, func(o string) bool {
+		return slices.Contains(settings.AllowedOrganizations, o)
+	}) {
+		return nil, saml.ErrOrgNotAllowed.Errorf("user organizations %v are not allowed", orgs)
+	}
+
+	if settings.SkipOrgRoleSync {
+		return userInfo, nil
+	}
+
+	var role org.RoleType
+	if settings.AssertionAttributeRole != "" {
+		roles := attributeValues(assertion, settings.AssertionAttributeRole)
+		role = mapRole(settings, roles)
+		if len(settings.RoleValuesGrafanaAdmin) > 0 {
+			isGrafanaAdmin := containsAny(settings.RoleValuesGrafanaAdmin, roles)
+			userInfo.IsGrafanaAdmin = &isGrafanaAdmin
+		}
+	}
+
+	orgMappings, err := parseOrgMapping(settings.OrgMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo.OrgRoles = make(map[int64]org.RoleType)
+	if len(orgMappings) == 0 {
+		if role != "" {
+			orgID := int64(1)
+			if cfg.AutoAssignOrg && cfg.AutoAssignOrgId > 0 {
+				orgID = int64(cfg.AutoAssignOrgId)
+			}
+			userInfo.OrgRoles[orgID] = role
+		}
+		return userInfo, nil
+	}
+
+	for _, mapping := range orgMappings {
+		if mapping.org != "*" && !slices.Contains(orgs, mapping.org) {
+			continue
+		}
+
+		mappedRole := mapping.role
+		if mappedRole == "" {
+			mappedRole = role
+		}
+		if mappedRole == "" {
+			mappedRole = org.RoleViewer
+		}
+
+		// users in several mapped organizations get the highest role
+		if current, ok := userInfo.OrgRoles[mapping.orgID]; !ok || mappedRole.Includes(current) {
+			userInfo.OrgRoles[mapping.orgID] = mappedRole
+		}
+	}
+
+	return userInfo, nil
+}
+
+// mapRole returns the organization role of the values of the role attribute. Users without a
+// matching value are viewers.
+func mapRole(settings *Settings, roles []string) org.RoleType {
+	switch {
+	case containsAny(settings.RoleValuesGrafanaAdmin, roles), containsAny(settings.RoleValuesAdmin, roles):
+		return org.RoleAdmin
+	case containsAny(settings.RoleValuesEditor, roles):
+		return org.RoleEditor
+	case containsAny(settings.RoleValuesNone, roles):
+		return org.RoleNone
+	default:
+		return org.RoleViewer
+	}
+}
+
+// userName returns the value of the name attribute, or the name built from the template.
+func userName(assertion *crewjamsaml.Assertion, attribute string) string {
+	if !attributeTemplate.MatchString(attribute) {
+		return firstValue(assertion, attribute)
+	}
+
+	name := attributeTemplate.ReplaceAllStringFunc(attribute, func(match string) string {
+		return firstValue(assertion, attributeTemplate.FindStringSubmatch(match)[1])
+	})
+	return strings.TrimSpace(name)
+}
+
+func firstValue(assertion *crewjamsaml.Assertion, attribute string) string {
+	values := attributeValues(assertion, attribute)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// attributeValues returns the values of the attribute with the name or the friendly name.
+func attributeValues(assertion *crewjamsaml.Assertion, attribute string) []string {
+	if attribute == "" {
+		return nil
+	}
+
+	var values []string
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			if attr.Name != attribute && attr.FriendlyName != attribute {
+				continue
+			}
+			for _, value := range attr.Values {
+				if value.Value != "" {
+					values = append(values, value.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+func containsAny(expected []string, values []string) bool {
+	for _, value := range values {
+		if slices.Contains(expected, value) {
+			return true
+		}
+	}
+	return false
+}
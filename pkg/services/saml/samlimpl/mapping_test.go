@@ -0,0 +1,185 @@
+package samlimpl
+
+import (
+	"testing"
+
+	crewjamsaml "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMapAssertion(t *testing.T) {
+	trueVal, falseVal := true, false
+
+	tests := []struct {
+		name            string
+		settings        Settings
+		attributes      []crewjamsaml.Attribute
+		autoAssignOrgID int
+		expectedLogin   string
+		expectedName    string
+		expectedRoles   map[int64]org.RoleType
+		expectedAdmin   *bool
+		expectedErr     error
+	}{
+		{
+			name:          "should use the email as login",
+			settings:      Settings{AssertionAttributeLogin: "login", AssertionAttributeEmail: "mail"},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("mail", "alice@example.com")},
+			expectedLogin: "alice@example.com",
+			expectedRoles: map[int64]org.RoleType{},
+		},
+		{
+			name:        "should fail without login and email",
+			settings:    Settings{AssertionAttributeLogin: "login", AssertionAttributeEmail: "mail"},
+			expectedErr: saml.ErrMissingAttribute,
+		},
+		{
+			name:     "should match the friendly name of attributes and build the name from a template",
+			settings: Settings{AssertionAttributeLogin: "uid", AssertionAttributeName: "$__saml{givenName} $__saml{sn}"},
+			attributes: []crewjamsaml.Attribute{
+				{FriendlyName: "uid", Name: "urn:oid:0.9.2342.19200300.100.1.1", Values: []crewjamsaml.AttributeValue{{Value: "alice"}}},
+				stringAttribute("givenName", "Alice"),
+				stringAttribute("sn", "Liddell"),
+			},
+			expectedLogin: "alice",
+			expectedName:  "Alice Liddell",
+			expectedRoles: map[int64]org.RoleType{},
+		},
+		{
+			name: "should map the role to the auto assigned organization",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role",
+				RoleValuesAdmin: []string{"admin"}, RoleValuesGrafanaAdmin: []string{"superadmin"},
+			},
+			attributes:      []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("role", "admin")},
+			autoAssignOrgID: 2,
+			expectedLogin:   "alice",
+			expectedRoles:   map[int64]org.RoleType{2: org.RoleAdmin},
+			expectedAdmin:   &falseVal,
+		},
+		{
+			name: "should map Grafana admins",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role",
+				RoleValuesEditor: []string{"editor"}, RoleValuesGrafanaAdmin: []string{"superadmin"},
+			},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("role", "editor", "superadmin")},
+			expectedLogin: "alice",
+			expectedRoles: map[int64]org.RoleType{1: org.RoleAdmin},
+			expectedAdmin: &trueVal,
+		},
+		{
+			name: "should map users without a known role to viewers",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role",
+				RoleValuesEditor: []string{"editor"}, RoleValuesNone: []string{"guest"},
+			},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("role", "unknown")},
+			expectedLogin: "alice",
+			expectedRoles: map[int64]org.RoleType{1: org.RoleViewer},
+		},
+		{
+			name: "should map users with the none role",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role",
+				RoleValuesEditor: []string{"editor"}, RoleValuesNone: []string{"guest"},
+			},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("role", "guest")},
+			expectedLogin: "alice",
+			expectedRoles: map[int64]org.RoleType{1: org.RoleNone},
+		},
+		{
+			name: "should map organizations",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role", AssertionAttributeOrg: "org",
+				RoleValuesEditor: []string{"editor"},
+				OrgMapping:       []string{"Engineering:2", "Sales:3:Admin", "Support:4:Viewer", "*:1:Viewer", "Engineering:1:Editor"},
+			},
+			attributes: []crewjamsaml.Attribute{
+				stringAttribute("uid", "alice"),
+				stringAttribute("role", "editor"),
+				stringAttribute("org", "Engineering", "Sales"),
+			},
+			expectedLogin: "alice",
+			expectedRoles: map[int64]org.RoleType{1: org.RoleEditor, 2: org.RoleEditor, 3: org.RoleAdmin},
+		},
+		{
+			name: "should skip role sync",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeRole: "role", SkipOrgRoleSync: true,
+				RoleValuesEditor: []string{"editor"}, OrgMapping: []string{"*:2:Admin"},
+			},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("role", "editor")},
+			expectedLogin: "alice",
+		},
+		{
+			name: "should allow members of allowed organizations",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeOrg: "org", AllowedOrganizations: []string{"Engineering"},
+			},
+			attributes:    []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("org", "Sales", "Engineering")},
+			expectedLogin: "alice",
+			expectedRoles: map[int64]org.RoleType{},
+		},
+		{
+			name: "should reject users outside of the allowed organizations",
+			settings: Settings{
+				AssertionAttributeLogin: "uid", AssertionAttributeOrg: "org", AllowedOrganizations: []string{"Engineering"},
+			},
+			attributes:  []crewjamsaml.Attribute{stringAttribute("uid", "alice"), stringAttribute("org", "Sales")},
+			expectedErr: saml.ErrOrgNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			if tt.autoAssignOrgID > 0 {
+				cfg.AutoAssignOrg = true
+				cfg.AutoAssignOrgId = tt.autoAssignOrgID
+			}
+			assertion := &crewjamsaml.Assertion{
+				Subject:             &crewjamsaml.Subject{NameID: &crewjamsaml.NameID{Value: "name-id"}},
+				AttributeStatements: []crewjamsaml.AttributeStatement{{Attributes: tt.attributes}},
+			}
+
+			userInfo, err := mapAssertion(cfg, &tt.settings, assertion)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "name-id", userInfo.NameID)
+			assert.Equal(t, tt.expectedLogin, userInfo.Login)
+			assert.Equal(t, tt.expectedName, userInfo.Name)
+			assert.Equal(t, tt.expectedRoles, userInfo.OrgRoles)
+			assert.Equal(t, tt.expectedAdmin, userInfo.IsGrafanaAdmin)
+		})
+	}
+}
+
+func TestParseMappings(t *testing.T) {
+	orgMappings, err := parseOrgMapping([]string{"Engineering:2", "*:1:Viewer"})
+	require.NoError(t, err)
+	assert.Equal(t, []orgMapping{{org: "Engineering", orgID: 2}, {org: "*", orgID: 1, role: org.RoleViewer}}, orgMappings)
+
+	teamMappings, err := parseTeamMapping([]string{"admins:1:3"})
+	require.NoError(t, err)
+	assert.Equal(t, []teamMapping{{group: "admins", orgID: 1, teamID: 3}}, teamMappings)
+
+	for _, entry := range []string{"Engineering", ":1", "Engineering:0", "Engineering:1:Owner", "Engineering:1:Admin:extra"} {
+		_, err := parseOrgMapping([]string{entry})
+		assert.Error(t, err, entry)
+	}
+
+	for _, entry := range []string{"admins:1", ":1:2", "admins:x:2", "admins:1:-2"} {
+		_, err := parseTeamMapping([]string{entry})
+		assert.Error(t, err, entry)
+	}
+}
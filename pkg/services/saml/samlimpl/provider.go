@@ -0,0 +1,199 @@
+package samlimpl
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const metadataFetchTimeout = 10 * time.Second
+
+// newServiceProvider builds the SAML service provider from the settings. The metadata
+// of the identity provider is fetched when it is configured with a URL.
+func newServiceProvider(ctx context.Context, cfg *setting.Cfg, settings *Settings, httpClient *http.Client) (*saml.ServiceProvider, error) {
+	if err := validateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	cert, err := loadCertificate(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := loadPrivateKey(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, errors.New("private key does not match the certificate")
+	}
+
+	idpMetadata, err := loadIDPMetadata(ctx, settings, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	rootURL, err := url.Parse(cfg.AppURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root_url: %w", err)
+	}
+
+	sp := &saml.ServiceProvider{
+		Key:                   key,
+		Certificate:           cert,
+		HTTPClient:            httpClient,
+		MetadataURL:           *rootURL.JoinPath("saml", "metadata"),
+		AcsURL:                *rootURL.JoinPath("saml", "acs"),
+		SloURL:                *rootURL.JoinPath("saml", "slo"),
+		IDPMetadata:           idpMetadata,
+		AuthnNameIDFormat:     saml.NameIDFormat(settings.NameIDFormat),
+		MetadataValidDuration: settings.MetadataValidDuration,
+		AllowIDPInitiated:     settings.AllowIDPInitiated,
+		DefaultRedirectURI:    cfg.AppSubURL + "/",
+		LogoutBindings:        []string{saml.HTTPRedirectBinding, saml.HTTPPostBinding},
+	}
+
+	switch settings.SignatureAlgorithm {
+	case signatureRSASHA1:
+		sp.SignatureMethod = dsig.RSASHA1SignatureMethod
+	case signatureRSASHA256:
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	case signatureRSASHA512:
+		sp.SignatureMethod = dsig.RSASHA512SignatureMethod
+	}
+
+	if sp.GetSSOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return nil, errors.New("identity provider metadata does not have a single sign-on service with the HTTP-Redirect binding")
+	}
+
+	if settings.SingleLogout && sp.GetSLOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return nil, errors.New("single_logout is enabled but the identity provider metadata does not have a single logout service with the HTTP-Redirect binding")
+	}
+
+	return sp, nil
+}
+
+func loadCertificate(settings *Settings) (*x509.Certificate, error) {
+	data, err := readPEM(settings.Certificate, settings.CertificatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("certificate is not a PEM encoded certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	return cert, nil
+}
+
+func loadPrivateKey(settings *Settings) (*rsa.PrivateKey, error) {
+	data, err := readPEM(settings.PrivateKey, settings.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an RSA key")
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+	}
+}
+
+// readPEM returns the PEM data of a setting that holds either PEM, base64 encoded PEM, or,
+// when it is empty, the PEM data of the file at path.
+func readPEM(value, path string) ([]byte, error) {
+	if value == "" {
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path comes from the configuration of an admin.
+		return os.ReadFile(path)
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+
+	return base64.StdEncoding.DecodeString(value)
+}
+
+func loadIDPMetadata(ctx context.Context, settings *Settings, httpClient *http.Client) (*saml.EntityDescriptor, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch {
+	case settings.IDPMetadata != "":
+		// the metadata is usually base64 encoded, but XML is accepted as well
+		data = []byte(settings.IDPMetadata)
+		if decoded, errDecode := base64.StdEncoding.DecodeString(strings.TrimSpace(settings.IDPMetadata)); errDecode == nil {
+			data = decoded
+		}
+	case settings.IDPMetadataPath != "":
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path comes from the configuration of an admin.
+		data, err = os.ReadFile(settings.IDPMetadataPath)
+	default:
+		metadataURL, errURL := url.Parse(settings.IDPMetadataURL)
+		if errURL != nil {
+			return nil, fmt.Errorf("invalid idp_metadata_url: %w", errURL)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, metadataFetchTimeout)
+		defer cancel()
+
+		metadata, errFetch := samlsp.FetchMetadata(ctx, httpClient, *metadataURL)
+		if errFetch != nil {
+			return nil, fmt.Errorf("failed to fetch identity provider metadata: %w", errFetch)
+		}
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity provider metadata: %w", err)
+	}
+
+	metadata, err := samlsp.ParseMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity provider metadata: %w", err)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,299 @@
+package samlimpl
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	crewjamsaml "github.com/crewjam/saml"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	ssoModels "github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ saml.Service = (*Service)(nil)
+var _ ssosettings.Reloadable = (*Service)(nil)
+
+var (
+	// The SAML library only reads the maximum issue delay from a package variable,
+	// so it is applied once at startup before any response is processed.
+	defaultMaxIssueDelay = crewjamsaml.MaxIssueDelay
+	setMaxIssueDelayOnce sync.Once
+)
+
+type Service struct {
+	cfg                    *setting.Cfg
+	log                    log.Logger
+	httpClient             *http.Client
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+
+	mutex        sync.RWMutex
+	settings     *Settings
+	sp           *crewjamsaml.ServiceProvider
+	teamMappings []teamMapping
+}
+
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, ssoSettings ssosettings.Service,
+	teamService team.Service, teamPermissionsService accesscontrol.TeamPermissionsService) *Service {
+	s := &Service{
+		cfg:                    cfg,
+		log:                    log.New("saml"),
+		httpClient:             &http.Client{Timeout: metadataFetchTimeout},
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		settings:               &Settings{},
+	}
+
+	ctx := context.Background()
+	settings, err := ssoSettings.GetForProvider(ctx, ssosettings.SAMLProviderName)
+	if err != nil {
+		s.log.Error("Failed to get SAML settings", "error", err)
+	} else if err := s.Reload(ctx, *settings); err != nil {
+		s.log.Error("Failed to configure SAML", "error", err)
+	}
+
+	setMaxIssueDelayOnce.Do(func() {
+		crewjamsaml.MaxIssueDelay = maxIssueDelay(s.settings)
+	})
+
+	if features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) {
+		ssoSettings.RegisterReloadable(ssosettings.SAMLProviderName, s)
+	}
+
+	return s
+}
+
+func (s *Service) Validate(ctx context.Context, settings ssoModels.SSOSettings) error {
+	samlSettings, err := settingsFromKeyValues(settings.Settings)
+	if err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("SSO settings map cannot be converted to SAML settings: %v", err)
+	}
+
+	if !samlSettings.Enabled {
+		return nil
+	}
+
+	if _, err := newServiceProvider(ctx, s.cfg, samlSettings, s.httpClient); err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("invalid SAML settings: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Service) Reload(ctx context.Context, settings ssoModels.SSOSettings) error {
+	samlSettings, err := settingsFromKeyValues(settings.Settings)
+	if err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("SSO settings map cannot be converted to SAML settings: %v", err)
+	}
+
+	var (
+		sp           *crewjamsaml.ServiceProvider
+		teamMappings []teamMapping
+	)
+	if samlSettings.Enabled {
+		sp, err = newServiceProvider(ctx, s.cfg, samlSettings, s.httpClient)
+		if err != nil {
+			return ssosettings.ErrInvalidSettings.Errorf("invalid SAML settings: %v", err)
+		}
+		// validated when the service provider was created
+		teamMappings, _ = parseTeamMapping(samlSettings.TeamMapping)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.sp != nil && maxIssueDelay(samlSettings) != maxIssueDelay(s.settings) {
+		s.log.Warn("Changes to max_issue_delay only take effect after a restart")
+	}
+
+	s.settings = samlSettings
+	s.sp = sp
+	s.teamMappings = teamMappings
+
+	return nil
+}
+
+func maxIssueDelay(settings *Settings) time.Duration {
+	if settings.MaxIssueDelay > 0 {
+		return settings.MaxIssueDelay
+	}
+	return defaultMaxIssueDelay
+}
+
+func (s *Service) IsEnabled() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.settings.Enabled && s.sp != nil
+}
+
+func (s *Service) Info() saml.Info {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return saml.Info{
+		Name:            s.settings.Name,
+		AllowSignUp:     s.settings.AllowSignUp,
+		AutoLogin:       s.settings.AutoLogin,
+		SingleLogout:    s.settings.SingleLogout,
+		SkipOrgRoleSync: s.settings.SkipOrgRoleSync,
+	}
+}
+
+func (s *Service) AuthnRequest(ctx context.Context) (*saml.AuthnRequest, error) {
+	sp, _, err := s.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(crewjamsaml.HTTPRedirectBinding), crewjamsaml.HTTPRedirectBinding, crewjamsaml.HTTPPostBinding)
+	if err != nil {
+		return nil, saml.ErrInternal.Errorf("failed to create authentication request: %w", err)
+	}
+
+	redirectURL, err := req.Redirect("", sp)
+	if err != nil {
+		return nil, saml.ErrInternal.Errorf("failed to create authentication request URL: %w", err)
+	}
+
+	return &saml.AuthnRequest{ID: req.ID, URL: redirectURL.String()}, nil
+}
+
+func (s *Service) ParseResponse(ctx context.Context, r *http.Request, possibleRequestIDs []string) (*saml.UserInfo, error) {
+	sp, settings, err := s.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, saml.ErrInvalidResponse.Errorf("failed to parse form: %w", err)
+	}
+
+	if len(possibleRequestIDs) == 0 {
+		if !settings.AllowIDPInitiated {
+			return nil, saml.ErrIDPInitiatedLogin.Errorf("response does not match an authentication request and allow_idp_initiated is disabled")
+		}
+		if settings.RelayState != "" && r.PostForm.Get("RelayState") != settings.RelayState {
+			return nil, saml.ErrIDPInitiatedLogin.Errorf("relay state of the response does not match relay_state")
+		}
+	}
+
+	assertion, err := sp.ParseResponse(r, possibleRequestIDs)
+	if err != nil {
+		// InvalidResponseError hides the reason of the failure in its message
+		var invalidErr *crewjamsaml.InvalidResponseError
+		if errors.As(err, &invalidErr) && invalidErr.PrivateErr != nil {
+			return nil, saml.ErrInvalidResponse.Errorf("%w: %w", err, invalidErr.PrivateErr)
+		}
+		return nil, saml.ErrInvalidResponse.Errorf("%w", err)
+	}
+
+	return mapAssertion(s.cfg, settings, assertion)
+}
+
+func (s *Service) LogoutRequest(ctx context.Context, nameID string) (string, error) {
+	sp, settings, err := s.provider()
+	if err != nil {
+		return "", err
+	}
+
+	if !settings.SingleLogout {
+		return "", saml.ErrDisabled.Errorf("single logout is disabled")
+	}
+
+	logoutURL, err := sp.MakeRedirectLogoutRequest(nameID, "")
+	if err != nil {
+		return "", saml.ErrInternal.Errorf("failed to create logout request: %w", err)
+	}
+
+	return logoutURL.String(), nil
+}
+
+func (s *Service) ValidateLogoutResponse(r *http.Request) error {
+	sp, _, err := s.provider()
+	if err != nil {
+		return err
+	}
+
+	if err := sp.ValidateLogoutResponseRequest(r); err != nil {
+		return saml.ErrInvalidLogout.Errorf("%w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) Metadata() ([]byte, error) {
+	sp, _, err := s.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, saml.ErrInternal.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+func (s *Service) SyncTeams(ctx context.Context, userID int64, groups []string) error {
+	s.mutex.RLock()
+	mappings := s.teamMappings
+	s.mutex.RUnlock()
+
+	type teamKey struct{ orgID, teamID int64 }
+	// a team can be mapped to several groups, the user is a member when they belong to any of them
+	teams := make(map[teamKey]bool, len(mappings))
+	for _, mapping := range mappings {
+		key := teamKey{mapping.orgID, mapping.teamID}
+		teams[key] = teams[key] || containsAny([]string{mapping.group}, groups)
+	}
+
+	var errs []error
+	for key, shouldBeMember := range teams {
+		isMember, err := s.teamService.IsTeamMember(key.orgID, key.teamID, userID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		permission := ""
+		switch {
+		case shouldBeMember && !isMember:
+			permission = "Member"
+		case !shouldBeMember && isMember:
+			// an empty permission removes the user from the team
+		default:
+			continue
+		}
+
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, key.orgID, accesscontrol.User{ID: userID}, strconv.FormatInt(key.teamID, 10), permission); err != nil {
+			s.log.FromContext(ctx).Error("Failed to sync team membership", "userId", userID, "orgId", key.orgID, "teamId", key.teamID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Service) provider() (*crewjamsaml.ServiceProvider, *Settings, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if !s.settings.Enabled || s.sp == nil {
+		return nil, nil, saml.ErrDisabled.Errorf("saml is not enabled")
+	}
+
+	return s.sp, s.settings, nil
+}
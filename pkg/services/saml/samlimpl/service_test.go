@@ -0,0 +1,330 @@
+package samlimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	crewjamsaml "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/services/saml/samltest"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Login(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	idp.Session.UserCommonName = "Alice Liddell"
+	idp.Session.CustomAttributes = []crewjamsaml.Attribute{
+		stringAttribute("groups", "admins", "developers"),
+		stringAttribute("role", "editor"),
+	}
+	s := setupTestService(t, idp, map[string]any{
+		"assertion_attribute_name":   "cn",
+		"assertion_attribute_groups": "groups",
+		"assertion_attribute_role":   "role",
+		"role_values_editor":         "editor developer",
+	})
+
+	req, err := s.AuthnRequest(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, req.ID)
+
+	t.Run("should map the user of a valid response", func(t *testing.T) {
+		form := idp.Login(t, req.URL)
+
+		userInfo, err := s.ParseResponse(context.Background(), acsRequest(form), []string{req.ID})
+		require.NoError(t, err)
+
+		assert.Equal(t, "alice", userInfo.NameID)
+		assert.Equal(t, "index", userInfo.SessionIndex)
+		assert.Equal(t, "alice", userInfo.Login)
+		assert.Equal(t, "alice@example.com", userInfo.Email)
+		assert.Equal(t, "Alice Liddell", userInfo.Name)
+		assert.Equal(t, []string{"admins", "developers"}, userInfo.Groups)
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleEditor}, userInfo.OrgRoles)
+		assert.Nil(t, userInfo.IsGrafanaAdmin)
+	})
+
+	t.Run("should reject a response to another request", func(t *testing.T) {
+		form := idp.Login(t, req.URL)
+
+		_, err := s.ParseResponse(context.Background(), acsRequest(form), []string{"id-other"})
+		assert.ErrorIs(t, err, saml.ErrInvalidResponse)
+	})
+
+	t.Run("should reject a tampered response", func(t *testing.T) {
+		form := idp.Login(t, req.URL)
+		response, err := base64.StdEncoding.DecodeString(form.Get("SAMLResponse"))
+		require.NoError(t, err)
+		// change the encrypted assertion covered by the signature of the response
+		i := strings.LastIndex(string(response), "<xenc:CipherValue>") + len("<xenc:CipherValue>")
+		if response[i] == 'A' {
+			response[i] = 'B'
+		} else {
+			response[i] = 'A'
+		}
+		form.Set("SAMLResponse", base64.StdEncoding.EncodeToString(response))
+
+		_, err = s.ParseResponse(context.Background(), acsRequest(form), []string{req.ID})
+		assert.ErrorIs(t, err, saml.ErrInvalidResponse)
+	})
+
+	t.Run("should reject a login initiated by the identity provider", func(t *testing.T) {
+		form := idp.InitiateLogin(t, "")
+
+		_, err := s.ParseResponse(context.Background(), acsRequest(form), nil)
+		assert.ErrorIs(t, err, saml.ErrIDPInitiatedLogin)
+	})
+}
+
+func TestService_IDPInitiatedLogin(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	s := setupTestService(t, idp, map[string]any{
+		"allow_idp_initiated": true,
+		"relay_state":         "grafana",
+	})
+
+	t.Run("should accept a login with the configured relay state", func(t *testing.T) {
+		form := idp.InitiateLogin(t, "grafana")
+
+		userInfo, err := s.ParseResponse(context.Background(), acsRequest(form), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", userInfo.Login)
+	})
+
+	t.Run("should reject a login with another relay state", func(t *testing.T) {
+		form := idp.InitiateLogin(t, "other")
+
+		_, err := s.ParseResponse(context.Background(), acsRequest(form), nil)
+		assert.ErrorIs(t, err, saml.ErrIDPInitiatedLogin)
+	})
+}
+
+func TestService_SingleLogout(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+
+	t.Run("should fail when single logout is disabled", func(t *testing.T) {
+		s := setupTestService(t, idp, nil)
+
+		_, err := s.LogoutRequest(context.Background(), "alice")
+		assert.ErrorIs(t, err, saml.ErrDisabled)
+	})
+
+	t.Run("should sign out the user from the identity provider", func(t *testing.T) {
+		s := setupTestService(t, idp, map[string]any{"single_logout": true})
+
+		logoutURL, err := s.LogoutRequest(context.Background(), "alice")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(logoutURL, idp.Server.URL+"/slo?"))
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Get(logoutURL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.Equal(t, []string{"alice"}, idp.LoggedOut())
+
+		location := resp.Header.Get("Location")
+		require.True(t, strings.HasPrefix(location, "http://localhost:3000/saml/slo?"))
+		require.NoError(t, s.ValidateLogoutResponse(httptest.NewRequest(http.MethodGet, location, nil)))
+
+		t.Run("should reject a tampered logout response", func(t *testing.T) {
+			u, err := url.Parse(location)
+			require.NoError(t, err)
+			query := u.Query()
+			query.Set("SAMLResponse", base64.StdEncoding.EncodeToString([]byte("invalid")))
+			u.RawQuery = query.Encode()
+
+			err = s.ValidateLogoutResponse(httptest.NewRequest(http.MethodGet, u.String(), nil))
+			assert.ErrorIs(t, err, saml.ErrInvalidLogout)
+		})
+	})
+}
+
+func TestService_Metadata(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	s := setupTestService(t, idp, map[string]any{
+		"idp_metadata":     "",
+		"idp_metadata_url": idp.Server.URL + "/metadata",
+	})
+
+	metadata, err := s.Metadata()
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="http://localhost:3000/saml/metadata"`)
+	assert.Contains(t, string(metadata), `Location="http://localhost:3000/saml/acs"`)
+	assert.Contains(t, string(metadata), `Location="http://localhost:3000/saml/slo"`)
+}
+
+func TestService_Reload(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	s := setupTestService(t, idp, map[string]any{"name": "Okta", "auto_login": true})
+	require.True(t, s.IsEnabled())
+	assert.Equal(t, saml.Info{Name: "Okta", AllowSignUp: true, AutoLogin: true}, s.Info())
+
+	err := s.Reload(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": false}})
+	require.NoError(t, err)
+	assert.False(t, s.IsEnabled())
+
+	_, err = s.AuthnRequest(context.Background())
+	assert.ErrorIs(t, err, saml.ErrDisabled)
+
+	err = s.Reload(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": true}})
+	assert.ErrorIs(t, err, ssosettings.ErrInvalidSettings)
+	assert.False(t, s.IsEnabled())
+}
+
+func TestService_Validate(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	s := setupTestService(t, idp, nil)
+	otherKeyPair := samltest.NewKeyPair(t)
+
+	tests := []struct {
+		name      string
+		overrides map[string]any
+		wantErr   bool
+	}{
+		{name: "valid settings"},
+		{name: "disabled", overrides: map[string]any{"enabled": false, "certificate": ""}},
+		{name: "metadata from a file", overrides: map[string]any{"idp_metadata": "", "idp_metadata_path": idp.MetadataFile(t)}},
+		{name: "signature algorithm", overrides: map[string]any{"signature_algorithm": "rsa-sha512"}},
+		{name: "missing certificate", overrides: map[string]any{"certificate": ""}, wantErr: true},
+		{name: "key of another certificate", overrides: map[string]any{"private_key": otherKeyPair.KeyPEM}, wantErr: true},
+		{name: "missing metadata", overrides: map[string]any{"idp_metadata": ""}, wantErr: true},
+		{name: "invalid metadata", overrides: map[string]any{"idp_metadata": "<invalid"}, wantErr: true},
+		{name: "unsupported signature algorithm", overrides: map[string]any{"signature_algorithm": "ecdsa-sha256"}, wantErr: true},
+		{name: "invalid duration", overrides: map[string]any{"max_issue_delay": "ninety seconds"}, wantErr: true},
+		{name: "invalid org mapping", overrides: map[string]any{"org_mapping": "Engineering:one"}, wantErr: true},
+		{name: "invalid team mapping", overrides: map[string]any{"team_mapping": "admins:1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := testSettings(t, idp)
+			for k, v := range tt.overrides {
+				settings[k] = v
+			}
+
+			err := s.Validate(context.Background(), models.SSOSettings{Settings: settings})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ssosettings.ErrInvalidSettings)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_SyncTeams(t *testing.T) {
+	idp := samltest.NewIdentityProvider(t)
+	teams := &fakeTeamService{FakeService: teamtest.NewFakeService(), members: map[int64]bool{2: true, 3: true}}
+	permissions := &fakeTeamPermissionsService{permissions: map[int64]string{}}
+	s := setupTestService(t, idp, map[string]any{
+		"team_mapping": "admins:1:1, admins:1:2, editors:1:2, viewers:1:3",
+	})
+	s.teamService = teams
+	s.teamPermissionsService = permissions
+
+	err := s.SyncTeams(context.Background(), 10, []string{"admins", "others"})
+	require.NoError(t, err)
+
+	// team 1 is added, team 2 is unchanged and the user is removed from team 3
+	assert.Equal(t, map[int64]string{1: "Member", 3: ""}, permissions.permissions)
+}
+
+type fakeTeamService struct {
+	*teamtest.FakeService
+	members map[int64]bool
+}
+
+func (f *fakeTeamService) IsTeamMember(orgID int64, teamID int64, userID int64) (bool, error) {
+	return f.members[teamID], nil
+}
+
+type fakeTeamPermissionsService struct {
+	actest.FakePermissionsService
+	// permissions of the user by team ID
+	permissions map[int64]string
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	teamID, err := strconv.ParseInt(resourceID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	f.permissions[teamID] = permission
+	return &accesscontrol.ResourcePermission{}, nil
+}
+
+func setupTestService(t *testing.T, idp *samltest.IdentityProvider, overrides map[string]any) *Service {
+	t.Helper()
+
+	settings := testSettings(t, idp)
+	for k, v := range overrides {
+		settings[k] = v
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+
+	ssoSettings := ssosettingstests.NewMockService(t)
+	ssoSettings.On("GetForProvider", mock.Anything, ssosettings.SAMLProviderName).Return(&models.SSOSettings{
+		Provider: ssosettings.SAMLProviderName,
+		Settings: settings,
+	}, nil)
+	ssoSettings.On("RegisterReloadable", ssosettings.SAMLProviderName, mock.Anything)
+
+	s := ProvideService(cfg, featuremgmt.WithFeatures(featuremgmt.FlagSsoSettingsApi), ssoSettings,
+		teamtest.NewFakeService(), &fakeTeamPermissionsService{permissions: map[int64]string{}})
+	require.True(t, s.IsEnabled())
+
+	idp.RegisterServiceProvider(s.sp.Metadata())
+	return s
+}
+
+func testSettings(t *testing.T, idp *samltest.IdentityProvider) map[string]any {
+	t.Helper()
+
+	keyPair := samltest.NewKeyPair(t)
+	return map[string]any{
+		"enabled":                   true,
+		"allow_sign_up":             true,
+		"certificate":               base64.StdEncoding.EncodeToString([]byte(keyPair.CertificatePEM)),
+		"private_key":               keyPair.KeyPEM,
+		"idp_metadata":              idp.MetadataBase64(t),
+		"max_issue_delay":           "90s",
+		"metadata_valid_duration":   "48h",
+		"assertion_attribute_login": "uid",
+		"assertion_attribute_email": "eduPersonPrincipalName",
+	}
+}
+
+func acsRequest(form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/saml/acs", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func stringAttribute(name string, values ...string) crewjamsaml.Attribute {
+	attr := crewjamsaml.Attribute{Name: name, NameFormat: "urn:oasis:names:tc:SAML:2.0:attrname-format:basic"}
+	for _, value := range values {
+		attr.Values = append(attr.Values, crewjamsaml.AttributeValue{Type: "xs:string", Value: value})
+	}
+	return attr
+}
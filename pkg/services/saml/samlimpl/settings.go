@@ -0,0 +1,139 @@
+package samlimpl
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	signatureRSASHA1   = "rsa-sha1"
+	signatureRSASHA256 = "rsa-sha256"
+	signatureRSASHA512 = "rsa-sha512"
+)
+
+// Settings are the settings of the [auth.saml] section, or of the saml provider of the SSO settings API.
+type Settings struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	Name              string `mapstructure:"name"`
+	SingleLogout      bool   `mapstructure:"single_logout"`
+	AllowSignUp       bool   `mapstructure:"allow_sign_up"`
+	AutoLogin         bool   `mapstructure:"auto_login"`
+	AllowIDPInitiated bool   `mapstructure:"allow_idp_initiated"`
+	RelayState        string `mapstructure:"relay_state"`
+
+	Certificate        string `mapstructure:"certificate"`
+	CertificatePath    string `mapstructure:"certificate_path"`
+	PrivateKey         string `mapstructure:"private_key"`
+	PrivateKeyPath     string `mapstructure:"private_key_path"`
+	SignatureAlgorithm string `mapstructure:"signature_algorithm"`
+
+	IDPMetadata     string `mapstructure:"idp_metadata"`
+	IDPMetadataPath string `mapstructure:"idp_metadata_path"`
+	IDPMetadataURL  string `mapstructure:"idp_metadata_url"`
+
+	MaxIssueDelay         time.Duration `mapstructure:"max_issue_delay"`
+	MetadataValidDuration time.Duration `mapstructure:"metadata_valid_duration"`
+	NameIDFormat          string        `mapstructure:"name_id_format"`
+
+	AssertionAttributeName   string `mapstructure:"assertion_attribute_name"`
+	AssertionAttributeLogin  string `mapstructure:"assertion_attribute_login"`
+	AssertionAttributeEmail  string `mapstructure:"assertion_attribute_email"`
+	AssertionAttributeGroups string `mapstructure:"assertion_attribute_groups"`
+	AssertionAttributeRole   string `mapstructure:"assertion_attribute_role"`
+	AssertionAttributeOrg    string `mapstructure:"assertion_attribute_org"`
+
+	AllowedOrganizations []string `mapstructure:"allowed_organizations"`
+	OrgMapping           []string `mapstructure:"org_mapping"`
+	TeamMapping          []string `mapstructure:"team_mapping"`
+
+	RoleValuesNone         []string `mapstructure:"role_values_none"`
+	RoleValuesEditor       []string `mapstructure:"role_values_editor"`
+	RoleValuesAdmin        []string `mapstructure:"role_values_admin"`
+	RoleValuesGrafanaAdmin []string `mapstructure:"role_values_grafana_admin"`
+	SkipOrgRoleSync        bool     `mapstructure:"skip_org_role_sync"`
+}
+
+func settingsFromKeyValues(settingsKV map[string]any) (*Settings, error) {
+	splitStringHook := func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() == reflect.String && to.Kind() == reflect.Slice {
+			strData, ok := data.(string)
+			if !ok {
+				return nil, fmt.Errorf("failed to convert %v to string", data)
+			}
+			return util.SplitString(strData), nil
+		}
+		return data, nil
+	}
+
+	emptyStrToDurationHook := func(from reflect.Type, to reflect.Type, data any) (any, error) {
+		if from.Kind() == reflect.String && to == reflect.TypeOf(time.Duration(0)) && data == "" {
+			return time.Duration(0), nil
+		}
+		return data, nil
+	}
+
+	var settings Settings
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			splitStringHook,
+			emptyStrToDurationHook,
+			mapstructure.StringToTimeDurationHookFunc(),
+		),
+		Result:           &settings,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(settingsKV); err != nil {
+		return nil, err
+	}
+
+	if settings.Name == "" {
+		settings.Name = "SAML"
+	}
+
+	return &settings, nil
+}
+
+func validateSettings(settings *Settings) error {
+	if settings.Certificate == "" && settings.CertificatePath == "" {
+		return fmt.Errorf("one of certificate or certificate_path is required")
+	}
+	if settings.PrivateKey == "" && settings.PrivateKeyPath == "" {
+		return fmt.Errorf("one of private_key or private_key_path is required")
+	}
+	if settings.IDPMetadata == "" && settings.IDPMetadataPath == "" && settings.IDPMetadataURL == "" {
+		return fmt.Errorf("one of idp_metadata, idp_metadata_path or idp_metadata_url is required")
+	}
+
+	switch settings.SignatureAlgorithm {
+	case "", signatureRSASHA1, signatureRSASHA256, signatureRSASHA512:
+	default:
+		return fmt.Errorf("unsupported signature_algorithm %q, supported algorithms are %s, %s and %s",
+			settings.SignatureAlgorithm, signatureRSASHA1, signatureRSASHA256, signatureRSASHA512)
+	}
+
+	if settings.MaxIssueDelay < 0 || settings.MetadataValidDuration < 0 {
+		return fmt.Errorf("max_issue_delay and metadata_valid_duration must be positive")
+	}
+
+	if settings.AssertionAttributeLogin == "" && settings.AssertionAttributeEmail == "" {
+		return fmt.Errorf("one of assertion_attribute_login or assertion_attribute_email is required")
+	}
+
+	if _, err := parseOrgMapping(settings.OrgMapping); err != nil {
+		return err
+	}
+	if _, err := parseTeamMapping(settings.TeamMapping); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package samltest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/services/saml"
+)
+
+var _ saml.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedEnabled      bool
+	ExpectedInfo         saml.Info
+	ExpectedAuthnRequest *saml.AuthnRequest
+	ExpectedUserInfo     *saml.UserInfo
+	ExpectedLogoutURL    string
+	ExpectedMetadata     []byte
+	ExpectedErr          error
+
+	// RequestIDs are the possible request IDs of the last parsed response
+	RequestIDs []string
+	// SyncedGroups are the groups of the last team sync
+	SyncedGroups []string
+}
+
+func (f *FakeService) IsEnabled() bool {
+	return f.ExpectedEnabled
+}
+
+func (f *FakeService) Info() saml.Info {
+	return f.ExpectedInfo
+}
+
+func (f *FakeService) AuthnRequest(ctx context.Context) (*saml.AuthnRequest, error) {
+	return f.ExpectedAuthnRequest, f.ExpectedErr
+}
+
+func (f *FakeService) ParseResponse(ctx context.Context, r *http.Request, possibleRequestIDs []string) (*saml.UserInfo, error) {
+	f.RequestIDs = possibleRequestIDs
+	return f.ExpectedUserInfo, f.ExpectedErr
+}
+
+func (f *FakeService) LogoutRequest(ctx context.Context, nameID string) (string, error) {
+	return f.ExpectedLogoutURL, f.ExpectedErr
+}
+
+func (f *FakeService) ValidateLogoutResponse(r *http.Request) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) Metadata() ([]byte, error) {
+	return f.ExpectedMetadata, f.ExpectedErr
+}
+
+func (f *FakeService) SyncTeams(ctx context.Context, userID int64, groups []string) error {
+	f.SyncedGroups = groups
+	return f.ExpectedErr
+}
@@ -0,0 +1,263 @@
+package samltest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"html"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/logger"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/require"
+)
+
+var formInput = regexp.MustCompile(`<input type="hidden" name="(\w+)" value="([^"]*)" />`)
+
+// KeyPair is an RSA key and a self-signed certificate.
+type KeyPair struct {
+	Key         *rsa.PrivateKey
+	Certificate *x509.Certificate
+	// KeyPEM and CertificatePEM are the PEM encoded key and certificate.
+	KeyPEM         string
+	CertificatePEM string
+}
+
+// NewKeyPair generates an RSA key and a self-signed certificate.
+func NewKeyPair(t testing.TB) *KeyPair {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &KeyPair{
+		Key:            key,
+		Certificate:    cert,
+		KeyPEM:         string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		CertificatePEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// IdentityProvider is a SAML identity provider for tests. It signs in the user of Session
+// without prompting for credentials.
+type IdentityProvider struct {
+	*saml.IdentityProvider
+	Server *httptest.Server
+	// Session is the user the identity provider signs in
+	Session *saml.Session
+
+	mu sync.Mutex
+	sp *saml.EntityDescriptor
+	// loggedOut are the name IDs of the logout requests
+	loggedOut []string
+}
+
+// NewIdentityProvider starts an identity provider serving its metadata on /metadata, single
+// sign-on on /sso and single logout on /slo.
+func NewIdentityProvider(t testing.TB) *IdentityProvider {
+	t.Helper()
+
+	keyPair := NewKeyPair(t)
+	idp := &IdentityProvider{
+		Session: &saml.Session{
+			ID:        "session",
+			NameID:    "alice",
+			Index:     "index",
+			UserName:  "alice",
+			UserEmail: "alice@example.com",
+		},
+	}
+
+	mux := http.NewServeMux()
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Server.Close)
+
+	serverURL, err := url.Parse(idp.Server.URL)
+	require.NoError(t, err)
+
+	idp.IdentityProvider = &saml.IdentityProvider{
+		Key:                     keyPair.Key,
+		Certificate:             keyPair.Certificate,
+		Logger:                  logger.DefaultLogger,
+		MetadataURL:             *serverURL.JoinPath("metadata"),
+		SSOURL:                  *serverURL.JoinPath("sso"),
+		LogoutURL:               *serverURL.JoinPath("slo"),
+		ServiceProviderProvider: idp,
+		SessionProvider:         idp,
+		SignatureMethod:         dsig.RSASHA256SignatureMethod,
+	}
+
+	mux.HandleFunc("/metadata", idp.ServeMetadata)
+	mux.HandleFunc("/sso", idp.ServeSSO)
+	mux.HandleFunc("/slo", idp.ServeSLO)
+
+	return idp
+}
+
+// RegisterServiceProvider sets the metadata of the service provider the identity provider signs users in.
+func (idp *IdentityProvider) RegisterServiceProvider(metadata *saml.EntityDescriptor) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.sp = metadata
+}
+
+// MetadataBase64 returns the base64 encoded metadata of the identity provider.
+func (idp *IdentityProvider) MetadataBase64(t testing.TB) string {
+	t.Helper()
+
+	metadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(metadata)
+}
+
+// MetadataFile writes the metadata of the identity provider to a temporary file and returns its path.
+func (idp *IdentityProvider) MetadataFile(t testing.TB) string {
+	t.Helper()
+
+	metadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+
+	f, err := os.CreateTemp(t.TempDir(), "idp-metadata-*.xml")
+	require.NoError(t, err)
+	_, err = f.Write(metadata)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return f.Name()
+}
+
+// Login follows the authentication request URL and returns the form the identity provider
+// posts to the assertion consumer service of the service provider.
+func (idp *IdentityProvider) Login(t testing.TB, authnRequestURL string) url.Values {
+	t.Helper()
+
+	// nolint:gosec
+	resp, err := http.Get(authnRequestURL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+	return parseForm(body)
+}
+
+// InitiateLogin returns the form of a login initiated by the identity provider.
+func (idp *IdentityProvider) InitiateLogin(t testing.TB, relayState string) url.Values {
+	t.Helper()
+
+	idp.mu.Lock()
+	entityID := idp.sp.EntityID
+	idp.mu.Unlock()
+
+	w := httptest.NewRecorder()
+	idp.ServeIDPInitiated(w, httptest.NewRequest(http.MethodGet, "/sso", nil), entityID, relayState)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	return parseForm(w.Body.Bytes())
+}
+
+// GetServiceProvider returns the metadata of the registered service provider.
+func (idp *IdentityProvider) GetServiceProvider(r *http.Request, serviceProviderID string) (*saml.EntityDescriptor, error) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	if idp.sp == nil || idp.sp.EntityID != serviceProviderID {
+		return nil, os.ErrNotExist
+	}
+	return idp.sp, nil
+}
+
+// GetSession returns Session.
+func (idp *IdentityProvider) GetSession(w http.ResponseWriter, r *http.Request, req *saml.IdpAuthnRequest) *saml.Session {
+	return idp.Session
+}
+
+// ServeSLO handles logout requests with the HTTP-Redirect binding and redirects to the
+// single logout service of the service provider with a signed logout response.
+func (idp *IdentityProvider) ServeSLO(w http.ResponseWriter, r *http.Request) {
+	compressed, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("SAMLRequest"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req saml.LogoutRequest
+	if err := xml.NewDecoder(flate.NewReader(bytes.NewReader(compressed))).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	idp.mu.Lock()
+	if req.NameID != nil {
+		idp.loggedOut = append(idp.loggedOut, req.NameID.Value)
+	}
+	spMetadata := idp.sp
+	idp.mu.Unlock()
+
+	if spMetadata == nil || len(spMetadata.SPSSODescriptors) == 0 || len(spMetadata.SPSSODescriptors[0].SingleLogoutServices) == 0 {
+		http.Error(w, "unknown service provider", http.StatusBadRequest)
+		return
+	}
+
+	// the identity provider signs the response like a service provider with its key
+	signer := &saml.ServiceProvider{
+		EntityID:        idp.Metadata().EntityID,
+		Key:             idp.Key.(*rsa.PrivateKey),
+		Certificate:     idp.Certificate,
+		SignatureMethod: idp.SignatureMethod,
+	}
+	resp, err := signer.MakeLogoutResponse(spMetadata.SPSSODescriptors[0].SingleLogoutServices[0].Location, req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, resp.Redirect(r.URL.Query().Get("RelayState")).String(), http.StatusFound)
+}
+
+// LoggedOut returns the name IDs of the logout requests the identity provider received.
+func (idp *IdentityProvider) LoggedOut() []string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return append([]string(nil), idp.loggedOut...)
+}
+
+func parseForm(body []byte) url.Values {
+	form := url.Values{}
+	for _, match := range formInput.FindAllSubmatch(body, -1) {
+		if value := html.UnescapeString(string(match[2])); value != "" {
+			form.Set(string(match[1]), value)
+		}
+	}
+	return form
+}
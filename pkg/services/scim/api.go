@@ -0,0 +1,299 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	basePath = "/api/scim/v2"
+
+	contentType = "application/scim+json"
+
+	// defaultCount is the number of resources returned when the client does not set count.
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// API is a SCIM 2.0 server (RFC 7644) identity providers use to provision the
+// users and teams of the organization of a service account.
+type API struct {
+	cfg                    *setting.Cfg
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	authTokenService       auth.UserTokenService
+	accesscontrolService   accesscontrol.Service
+	logger                 log.Logger
+}
+
+func ProvideAPI(
+	cfg *setting.Cfg,
+	routeRegister routing.RouteRegister,
+	acEvaluator accesscontrol.AccessControl,
+	userService user.Service,
+	orgService org.Service,
+	teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService,
+	authTokenService auth.UserTokenService,
+	accesscontrolService accesscontrol.Service,
+) *API {
+	api := &API{
+		cfg:                    cfg,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		authTokenService:       authTokenService,
+		accesscontrolService:   accesscontrolService,
+		logger:                 log.New("scim"),
+	}
+
+	if cfg.SCIMEnabled {
+		api.registerRoutes(routeRegister, acEvaluator)
+	}
+	return api
+}
+
+func (api *API) registerRoutes(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+	teamIDScope := accesscontrol.Scope("teams", "id", accesscontrol.Parameter(":id"))
+
+	router.Group(basePath, func(scimRoute routing.RouteRegister) {
+		scimRoute.Get("/ServiceProviderConfig", routing.Wrap(api.getServiceProviderConfig))
+		scimRoute.Get("/ResourceTypes", routing.Wrap(api.getResourceTypes))
+
+		scimRoute.Get("/Users", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead, accesscontrol.ScopeUsersAll)), routing.Wrap(api.listUsers))
+		scimRoute.Post("/Users", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersAdd, accesscontrol.ScopeUsersAll)), routing.Wrap(api.createUser))
+		scimRoute.Get("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead, accesscontrol.ScopeUsersAll)), routing.Wrap(api.getUser))
+		scimRoute.Put("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite, accesscontrol.ScopeUsersAll)), routing.Wrap(api.replaceUser))
+		scimRoute.Patch("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite, accesscontrol.ScopeUsersAll)), routing.Wrap(api.patchUser))
+		scimRoute.Delete("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRemove, accesscontrol.ScopeUsersAll)), routing.Wrap(api.deleteUser))
+
+		scimRoute.Get("/Groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead, accesscontrol.ScopeTeamsAll)), routing.Wrap(api.listGroups))
+		scimRoute.Post("/Groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsCreate)), routing.Wrap(api.createGroup))
+		scimRoute.Get("/Groups/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead, teamIDScope)), routing.Wrap(api.getGroup))
+		scimRoute.Put("/Groups/:id", authorize(accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite, teamIDScope),
+			accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite, teamIDScope),
+		)), routing.Wrap(api.replaceGroup))
+		scimRoute.Patch("/Groups/:id", authorize(accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite, teamIDScope),
+			accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite, teamIDScope),
+		)), routing.Wrap(api.patchGroup))
+		scimRoute.Delete("/Groups/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsDelete, teamIDScope)), routing.Wrap(api.deleteGroup))
+	}, requireServiceAccount, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+// requireServiceAccount only lets requests authenticated with a service account token through.
+func requireServiceAccount(c *contextmodel.ReqContext) {
+	if !c.IsSignedIn || c.SignedInUser == nil {
+		errorResponse(http.StatusUnauthorized, "", "authentication required").WriteTo(c)
+		return
+	}
+	namespace, _ := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceServiceAccount {
+		errorResponse(http.StatusForbidden, "", "SCIM requests must be authenticated with a service account token").WriteTo(c)
+	}
+}
+
+func (api *API) getServiceProviderConfig(c *contextmodel.ReqContext) response.Response {
+	supported := func(v bool) map[string]any { return map[string]any{"supported": v} }
+	return scimJSON(http.StatusOK, map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with a Grafana service account token",
+		}},
+		"meta": Meta{ResourceType: "ServiceProviderConfig", Location: api.location("ServiceProviderConfig", "")},
+	})
+}
+
+func (api *API) getResourceTypes(c *contextmodel.ReqContext) response.Response {
+	resourceTypes := []any{
+		map[string]any{
+			"schemas":  []string{SchemaResourceType},
+			"id":       ResourceTypeUser,
+			"name":     ResourceTypeUser,
+			"endpoint": "/Users",
+			"schema":   SchemaUser,
+			"meta":     Meta{ResourceType: "ResourceType", Location: api.location("ResourceTypes", ResourceTypeUser)},
+		},
+		map[string]any{
+			"schemas":  []string{SchemaResourceType},
+			"id":       ResourceTypeGroup,
+			"name":     ResourceTypeGroup,
+			"endpoint": "/Groups",
+			"schema":   SchemaGroup,
+			"meta":     Meta{ResourceType: "ResourceType", Location: api.location("ResourceTypes", ResourceTypeGroup)},
+		},
+	}
+	return scimJSON(http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resourceTypes),
+		StartIndex:   1,
+		ItemsPerPage: len(resourceTypes),
+		Resources:    resourceTypes,
+	})
+}
+
+// location returns the URL of a resource.
+func (api *API) location(endpoint, id string) string {
+	location := strings.TrimSuffix(api.cfg.AppURL, "/") + basePath + "/" + endpoint
+	if id != "" {
+		location += "/" + id
+	}
+	return location
+}
+
+type listParams struct {
+	filter     filter
+	startIndex int
+	count      int
+}
+
+func parseListParams(c *contextmodel.ReqContext) (*listParams, response.Response) {
+	query := c.Req.URL.Query()
+	params := &listParams{startIndex: 1, count: defaultCount}
+
+	if v := query.Get("startIndex"); v != "" {
+		startIndex, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "startIndex must be an integer")
+		}
+		// Values less than 1 are interpreted as 1.
+		if startIndex > 1 {
+			params.startIndex = startIndex
+		}
+	}
+
+	if v := query.Get("count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "count must be an integer")
+		}
+		params.count = min(max(count, 0), maxCount)
+	}
+
+	if v := query.Get("filter"); v != "" {
+		f, err := parseFilter(v)
+		if err != nil {
+			return nil, errorResponse(http.StatusBadRequest, ErrorTypeInvalidFilter, err.Error())
+		}
+		params.filter = f
+	}
+
+	return params, nil
+}
+
+// page returns the page of the resources selected by the list parameters.
+func (p *listParams) page(resources []any) []any {
+	start := p.startIndex - 1
+	if start >= len(resources) {
+		return []any{}
+	}
+	end := min(start+p.count, len(resources))
+	return resources[start:end]
+}
+
+// matches reports whether the resource matches the filter of the list parameters.
+func (p *listParams) matches(resource any) (bool, error) {
+	if p.filter == nil {
+		return true, nil
+	}
+	m, err := toMap(resource)
+	if err != nil {
+		return false, err
+	}
+	return p.filter.matches(m), nil
+}
+
+func listResponse(params *listParams, total int, resources []any) response.Response {
+	return scimJSON(http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// bindSCIM decodes the body of the request, clients may send it either as
+// application/json or application/scim+json.
+func bindSCIM(c *contextmodel.ReqContext, v any) response.Response {
+	if c.Req.Body == nil {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidSyntax, "missing request body")
+	}
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidSyntax, "invalid request body: "+err.Error())
+	}
+	return nil
+}
+
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	return m, json.Unmarshal(data, &m)
+}
+
+func fromMap(m map[string]any, v any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func parseID(c *contextmodel.ReqContext) (int64, response.Response) {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return 0, errorResponse(http.StatusNotFound, "", "resource not found")
+	}
+	return id, nil
+}
+
+func scimJSON(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", contentType)
+}
+
+func errorResponse(status int, scimType, detail string) *response.NormalResponse {
+	return scimJSON(status, ErrorResponse{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// internalError logs the error and returns a response without its details.
+func (api *API) internalError(c *contextmodel.ReqContext, message string, err error) response.Response {
+	api.logger.FromContext(c.Req.Context()).Error(message, "error", err)
+	return errorResponse(http.StatusInternalServerError, "", message)
+}
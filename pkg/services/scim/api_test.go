@@ -0,0 +1,381 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeTeamPermissionsService struct {
+	actest.FakePermissionsService
+	permissions map[int64]string
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	f.permissions[user.ID] = permission
+	return &accesscontrol.ResourcePermission{}, nil
+}
+
+type testEnv struct {
+	server      *webtest.Server
+	users       *usertest.FakeUserService
+	orgs        *orgtest.FakeOrgService
+	teams       *teamtest.FakeService
+	teamPerms   *fakeTeamPermissionsService
+	authTokens  *authtest.FakeUserAuthTokenService
+	revokedUser int64
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.SCIMEnabled = true
+	cfg.SCIMDefaultOrgRole = string(org.RoleViewer)
+	cfg.AppURL = "http://localhost:3000/"
+
+	env := &testEnv{
+		users:      usertest.NewUserServiceFake(),
+		orgs:       orgtest.NewOrgServiceFake(),
+		teams:      teamtest.NewFakeService(),
+		teamPerms:  &fakeTeamPermissionsService{permissions: map[int64]string{}},
+		authTokens: authtest.NewFakeUserAuthTokenService(),
+	}
+	env.authTokens.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+		env.revokedUser = userID
+		return nil
+	}
+
+	router := routing.NewRouteRegister()
+	ProvideAPI(cfg, router, acimpl.ProvideAccessControl(cfg), env.users, env.orgs, env.teams, env.teamPerms, env.authTokens, actest.FakeService{})
+	env.server = webtest.NewServer(t, router)
+	return env
+}
+
+func (env *testEnv) send(t *testing.T, method, path, body string, usr *user.SignedInUser) (*http.Response, map[string]any) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := webtest.RequestWithSignedInUser(env.server.NewRequest(method, path, reader), usr)
+	req.Header.Set("Content-Type", contentType)
+	res, err := env.server.Send(req)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	result := map[string]any{}
+	if len(data) > 0 {
+		require.NoError(t, json.Unmarshal(data, &result))
+	}
+	return res, result
+}
+
+func serviceAccount(permissions ...accesscontrol.Permission) *user.SignedInUser {
+	return &user.SignedInUser{
+		UserID:           10,
+		OrgID:            1,
+		IsServiceAccount: true,
+		OrgRole:          org.RoleNone,
+		Permissions:      map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(permissions)},
+	}
+}
+
+var (
+	usersReadPermission   = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll}
+	usersAddPermission    = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersAdd, Scope: accesscontrol.ScopeUsersAll}
+	usersWritePermission  = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersWrite, Scope: accesscontrol.ScopeUsersAll}
+	usersRemovePermission = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersRemove, Scope: accesscontrol.ScopeUsersAll}
+	teamsReadPermission   = accesscontrol.Permission{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll}
+	teamsCreatePermission = accesscontrol.Permission{Action: accesscontrol.ActionTeamsCreate}
+	teamsWritePermissions = []accesscontrol.Permission{
+		{Action: accesscontrol.ActionTeamsRead, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsWrite, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsPermissionsWrite, Scope: accesscontrol.ScopeTeamsAll},
+	}
+)
+
+func orgUser(id int64, login, email string, disabled bool) *org.OrgUserDTO {
+	return &org.OrgUserDTO{OrgID: 1, UserID: id, Login: login, Email: email, Name: login, IsDisabled: disabled}
+}
+
+func TestAPI_Authentication(t *testing.T) {
+	env := setupTestEnv(t)
+
+	t.Run("should reject users", func(t *testing.T) {
+		usr := serviceAccount(usersReadPermission)
+		usr.IsServiceAccount = false
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/Users", "", usr)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Equal(t, []any{SchemaError}, body["schemas"])
+	})
+
+	t.Run("should reject service accounts without permissions", func(t *testing.T) {
+		res, _ := env.send(t, http.MethodGet, "/api/scim/v2/Users", "", serviceAccount())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("should return the service provider configuration", func(t *testing.T) {
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/ServiceProviderConfig", "", serviceAccount())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, contentType, res.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]any{"supported": true}, body["patch"])
+	})
+}
+
+func TestAPI_ListUsers(t *testing.T) {
+	env := setupTestEnv(t)
+	env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{
+		TotalCount: 2,
+		OrgUsers: []*org.OrgUserDTO{
+			orgUser(1, "alice", "alice@example.com", false),
+			orgUser(2, "bob", "bob@example.com", true),
+		},
+	}
+
+	t.Run("should list users", func(t *testing.T) {
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/Users", "", serviceAccount(usersReadPermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.EqualValues(t, 2, body["totalResults"])
+		resources := body["Resources"].([]any)
+		require.Len(t, resources, 2)
+		alice := resources[0].(map[string]any)
+		assert.Equal(t, "1", alice["id"])
+		assert.Equal(t, "alice", alice["userName"])
+		assert.Equal(t, true, alice["active"])
+		assert.Equal(t, "http://localhost:3000/api/scim/v2/Users/1", alice["meta"].(map[string]any)["location"])
+	})
+
+	t.Run("should filter users", func(t *testing.T) {
+		res, body := env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=active+eq+false`, "", serviceAccount(usersReadPermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.EqualValues(t, 1, body["totalResults"])
+		assert.Equal(t, "bob", body["Resources"].([]any)[0].(map[string]any)["userName"])
+	})
+
+	t.Run("should page filtered users", func(t *testing.T) {
+		res, body := env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=userName+pr&startIndex=2&count=1`, "", serviceAccount(usersReadPermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.EqualValues(t, 2, body["totalResults"])
+		assert.EqualValues(t, 2, body["startIndex"])
+		assert.EqualValues(t, 1, body["itemsPerPage"])
+		assert.Equal(t, "bob", body["Resources"].([]any)[0].(map[string]any)["userName"])
+	})
+
+	t.Run("should reject invalid filters", func(t *testing.T) {
+		res, body := env.send(t, http.MethodGet, `/api/scim/v2/Users?filter=userName+like+%22alice%22`, "", serviceAccount(usersReadPermission))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, ErrorTypeInvalidFilter, body["scimType"])
+	})
+}
+
+func TestAPI_GetUser(t *testing.T) {
+	env := setupTestEnv(t)
+	env.teams.ExpectedTeamsByUser = []*team.TeamDTO{{ID: 3, Name: "devs"}}
+
+	t.Run("should return 404 for users outside the organization", func(t *testing.T) {
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{}
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/Users/1", "", serviceAccount(usersReadPermission))
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "404", body["status"])
+	})
+
+	t.Run("should return the user with their groups", func(t *testing.T) {
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", false)}}
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/Users/1", "", serviceAccount(usersReadPermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []any{map[string]any{"value": "alice@example.com", "type": "work", "primary": true}}, body["emails"])
+		assert.Equal(t, "devs", body["groups"].([]any)[0].(map[string]any)["display"])
+	})
+}
+
+func TestAPI_CreateUser(t *testing.T) {
+	t.Run("should require userName", func(t *testing.T) {
+		env := setupTestEnv(t)
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Users", `{"schemas": ["`+SchemaUser+`"]}`, serviceAccount(usersAddPermission))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, ErrorTypeInvalidValue, body["scimType"])
+	})
+
+	t.Run("should create the user in the organization", func(t *testing.T) {
+		env := setupTestEnv(t)
+		var created *user.CreateUserCommand
+		env.users.ExpectedError = user.ErrUserNotFound
+		env.users.CreateFn = func(ctx context.Context, cmd *user.CreateUserCommand) (*user.User, error) {
+			created = cmd
+			return &user.User{ID: 1, Login: cmd.Login}, nil
+		}
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", true)}}
+
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Users",
+			`{"userName": "alice", "name": {"givenName": "Alice", "familyName": "Liddell"}, "emails": [{"value": "alice@example.com", "primary": true}], "active": false}`,
+			serviceAccount(usersAddPermission, usersReadPermission))
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "http://localhost:3000/api/scim/v2/Users/1", res.Header.Get("Location"))
+		assert.Equal(t, "1", body["id"])
+
+		require.NotNil(t, created)
+		assert.Equal(t, "alice", created.Login)
+		assert.Equal(t, "alice@example.com", created.Email)
+		assert.Equal(t, "Alice Liddell", created.Name)
+		assert.EqualValues(t, 1, created.OrgID)
+		assert.True(t, created.SkipOrgSetup)
+		assert.True(t, created.IsDisabled)
+	})
+
+	t.Run("should return conflict for users already in the organization", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedUser = &user.User{ID: 1, Login: "alice"}
+		env.orgs.ExpectedError = org.ErrOrgUserAlreadyAdded
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Users", `{"userName": "alice"}`, serviceAccount(usersAddPermission))
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, ErrorTypeUniqueness, body["scimType"])
+	})
+}
+
+func TestAPI_PatchUser(t *testing.T) {
+	t.Run("should deactivate the user and revoke their sessions", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedUser = &user.User{ID: 1, Login: "alice"}
+		env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1}}
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", false)}}
+		var disabled *user.DisableUserCommand
+		env.users.DisableFn = func(ctx context.Context, cmd *user.DisableUserCommand) error {
+			disabled = cmd
+			return nil
+		}
+
+		res, _ := env.send(t, http.MethodPatch, "/api/scim/v2/Users/1",
+			`{"schemas": ["`+SchemaPatchOp+`"], "Operations": [{"op": "replace", "value": {"active": "False"}}]}`,
+			serviceAccount(usersWritePermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NotNil(t, disabled)
+		assert.True(t, disabled.IsDisabled)
+		assert.EqualValues(t, 1, env.revokedUser)
+	})
+
+	t.Run("should not change users belonging to other organizations", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedUser = &user.User{ID: 1, Login: "alice"}
+		env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1}, {OrgID: 2}}
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", false)}}
+
+		res, body := env.send(t, http.MethodPatch, "/api/scim/v2/Users/1",
+			`{"Operations": [{"op": "replace", "path": "userName", "value": "alice2"}]}`,
+			serviceAccount(usersWritePermission))
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Equal(t, ErrorTypeMutability, body["scimType"])
+	})
+
+	t.Run("should reject invalid operations", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", false)}}
+
+		res, body := env.send(t, http.MethodPatch, "/api/scim/v2/Users/1",
+			`{"Operations": [{"op": "remove"}]}`,
+			serviceAccount(usersWritePermission))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, ErrorTypeNoTarget, body["scimType"])
+	})
+}
+
+func TestAPI_DeleteUser(t *testing.T) {
+	env := setupTestEnv(t)
+	env.orgs.ExpectedSearchOrgUsersResult = &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{orgUser(1, "alice", "alice@example.com", false)}}
+	env.orgs.ExpectedOrgListResponse = orgtest.OrgListResponse{{OrgID: 1, Response: nil}, {OrgID: 1, Response: org.ErrLastOrgAdmin}}
+
+	res, _ := env.send(t, http.MethodDelete, "/api/scim/v2/Users/1", "", serviceAccount(usersRemovePermission))
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	res, body := env.send(t, http.MethodDelete, "/api/scim/v2/Users/1", "", serviceAccount(usersRemovePermission))
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, ErrorTypeMutability, body["scimType"])
+}
+
+func TestAPI_Groups(t *testing.T) {
+	t.Run("should create the team with its members", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedUser = &user.User{ID: 1, Login: "alice"}
+		env.teams.ExpectedTeam = team.Team{ID: 3, Name: "devs"}
+		env.teams.ExpectedTeamDTO = &team.TeamDTO{ID: 3, Name: "devs"}
+
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Groups",
+			`{"schemas": ["`+SchemaGroup+`"], "displayName": "devs", "members": [{"value": "1"}]}`,
+			serviceAccount(append(teamsWritePermissions, teamsCreatePermission)...))
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "3", body["id"])
+		assert.Equal(t, map[int64]string{1: "Member"}, env.teamPerms.permissions)
+	})
+
+	t.Run("should reject unknown members", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedError = user.ErrUserNotFound
+
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Groups",
+			`{"displayName": "devs", "members": [{"value": "1"}]}`,
+			serviceAccount(teamsCreatePermission))
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, ErrorTypeInvalidValue, body["scimType"])
+	})
+
+	t.Run("should return conflict for existing teams", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.teams.ExpectedError = team.ErrTeamNameTaken
+
+		res, body := env.send(t, http.MethodPost, "/api/scim/v2/Groups", `{"displayName": "devs"}`, serviceAccount(teamsCreatePermission))
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, ErrorTypeUniqueness, body["scimType"])
+	})
+
+	t.Run("should patch team members", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.ExpectedUser = &user.User{ID: 2, Login: "bob"}
+		env.teams.ExpectedTeamDTO = &team.TeamDTO{ID: 3, OrgID: 1, Name: "devs"}
+		env.teams.ExpectedMembers = []*team.TeamMemberDTO{{UserID: 1, Login: "alice"}}
+
+		res, _ := env.send(t, http.MethodPatch, "/api/scim/v2/Groups/3",
+			`{"Operations": [{"op": "remove", "path": "members[value eq \"1\"]"}, {"op": "add", "path": "members", "value": [{"value": "2"}]}]}`,
+			serviceAccount(teamsWritePermissions...))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[int64]string{1: "", 2: "Member"}, env.teamPerms.permissions)
+	})
+
+	t.Run("should exclude members", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.teams.ExpectedTeamDTO = &team.TeamDTO{ID: 3, OrgID: 1, Name: "devs"}
+		env.teams.ExpectedMembers = []*team.TeamMemberDTO{{UserID: 1, Login: "alice"}}
+
+		res, body := env.send(t, http.MethodGet, "/api/scim/v2/Groups/3?excludedAttributes=members", "", serviceAccount(teamsReadPermission))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "devs", body["displayName"])
+		assert.NotContains(t, body, "members")
+	})
+
+	t.Run("should delete the team", func(t *testing.T) {
+		env := setupTestEnv(t)
+		res, _ := env.send(t, http.MethodDelete, "/api/scim/v2/Groups/3", "", serviceAccount(accesscontrol.Permission{Action: accesscontrol.ActionTeamsDelete, Scope: "teams:id:3"}))
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+}
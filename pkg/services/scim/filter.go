@@ -0,0 +1,439 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filter is a parsed SCIM filter expression, see RFC 7644, section 3.4.2.2.
+// Filters are evaluated against the JSON representation of a resource.
+type filter interface {
+	matches(resource map[string]any) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right filter
+}
+
+func (f logicalFilter) matches(resource map[string]any) bool {
+	if f.and {
+		return f.left.matches(resource) && f.right.matches(resource)
+	}
+	return f.left.matches(resource) || f.right.matches(resource)
+}
+
+type notFilter struct {
+	filter filter
+}
+
+func (f notFilter) matches(resource map[string]any) bool {
+	return !f.filter.matches(resource)
+}
+
+type compareFilter struct {
+	path  string
+	op    string
+	value any
+}
+
+func (f compareFilter) matches(resource map[string]any) bool {
+	values := lookup(resource, f.path)
+	if f.op == "pr" {
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if f.op == "ne" {
+		return !compareFilter{path: f.path, op: "eq", value: f.value}.matches(resource)
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches resources with an element of a multi-valued
+// attribute matching the filter, for example emails[type eq "work"].
+type valuePathFilter struct {
+	attr   string
+	filter filter
+}
+
+func (f valuePathFilter) matches(resource map[string]any) bool {
+	for _, v := range lookup(resource, f.attr) {
+		if m, ok := v.(map[string]any); ok && f.filter.matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilter parses a filter expression. Attribute names are matched case
+// insensitively and may be prefixed with the schema URN.
+func parseFilter(s string) (filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+// equalityValue returns the value compared to the attribute when the filter
+// requires it to be equal to a string, for example userName eq "admin".
+func equalityValue(f filter, attr string) (string, bool) {
+	switch f := f.(type) {
+	case compareFilter:
+		if f.op != "eq" || !strings.EqualFold(attributeName(f.path), attr) {
+			return "", false
+		}
+		v, ok := f.value.(string)
+		return v, ok
+	case logicalFilter:
+		if !f.and {
+			return "", false
+		}
+		if v, ok := equalityValue(f.left, attr); ok {
+			return v, true
+		}
+		return equalityValue(f.right, attr)
+	}
+	return "", false
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenOpenParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenCloseParen, ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{tokenOpenBracket, "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{tokenCloseBracket, "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string %s", s[i:end+1])
+			}
+			tokens = append(tokens, token{tokenString, value})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[end])); end++ {
+			}
+			tokens = append(tokens, token{tokenWord, s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenWord && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != kind {
+		return fmt.Errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filter, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return notFilter{f}, nil
+	}
+
+	if p.tokens[p.pos].kind == tokenOpenParen {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	attr := p.tokens[p.pos]
+	if attr.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute, got %q", attr.text)
+	}
+	p.pos++
+
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOpenBracket {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attr: attr.text, filter: f}, nil
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenWord {
+		return nil, fmt.Errorf("expected operator after %q", attr.text)
+	}
+	op := strings.ToLower(p.tokens[p.pos].text)
+	p.pos++
+
+	switch op {
+	case "pr":
+		return compareFilter{path: attr.text, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("expected value after %q", op)
+	}
+	value, err := parseValue(p.tokens[p.pos])
+	if err != nil {
+		return nil, err
+	}
+	p.pos++
+	return compareFilter{path: attr.text, op: op, value: value}, nil
+}
+
+func parseValue(t token) (any, error) {
+	if t.kind == tokenString {
+		return t.text, nil
+	}
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("expected value, got %q", t.text)
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", t.text)
+	}
+	return n, nil
+}
+
+// attributeName strips the schema URN from an attribute path, for example
+// urn:ietf:params:scim:schemas:core:2.0:User:userName.
+func attributeName(path string) string {
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		return path[strings.LastIndex(path, ":")+1:]
+	}
+	return path
+}
+
+// lookup returns the values of the attribute path in the resource, the values
+// of multi-valued attributes are flattened.
+func lookup(resource map[string]any, path string) []any {
+	values := []any{resource}
+	for _, name := range strings.Split(attributeName(path), ".") {
+		var next []any
+		for _, v := range values {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			_, value, ok := getKey(m, name)
+			if !ok {
+				continue
+			}
+			if list, ok := value.([]any); ok {
+				next = append(next, list...)
+			} else {
+				next = append(next, value)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// getKey returns the value of the key of the map matching name case insensitively.
+func getKey(m map[string]any, name string) (string, any, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+func compare(actual any, op string, expected any) bool {
+	switch expected := expected.(type) {
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		a, e := strings.ToLower(a), strings.ToLower(expected)
+		switch op {
+		case "eq":
+			return a == e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == expected
+		case "gt":
+			return a > expected
+		case "ge":
+			return a >= expected
+		case "lt":
+			return a < expected
+		case "le":
+			return a <= expected
+		}
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == expected
+	case nil:
+		return op == "eq" && actual == nil
+	}
+	return false
+}
+
+// isAttributeName reports whether s is a valid attribute name, see RFC 7643, section 2.1.
+func isAttributeName(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) && s[0] != '$' {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '$' {
+			return false
+		}
+	}
+	return true
+}
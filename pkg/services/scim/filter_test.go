@@ -0,0 +1,103 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"userName":    "Alice",
+		"displayName": "Alice Liddell",
+		"active":      true,
+		"emails": []any{
+			map[string]any{"value": "alice@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "alice@home.example.com", "type": "home"},
+		},
+		"meta": map[string]any{"resourceType": "User"},
+	}
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: `userName eq "alice"`, matches: true},
+		{filter: `UserName Eq "ALICE"`, matches: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, matches: true},
+		{filter: `userName eq "bob"`, matches: false},
+		{filter: `userName ne "bob"`, matches: true},
+		{filter: `displayName co "lidd"`, matches: true},
+		{filter: `displayName sw "alice"`, matches: true},
+		{filter: `displayName ew "liddell"`, matches: true},
+		{filter: `displayName gt "Alice A"`, matches: true},
+		{filter: `displayName lt "Alice A"`, matches: false},
+		{filter: `active eq true`, matches: true},
+		{filter: `active eq false`, matches: false},
+		{filter: `emails.value eq "alice@home.example.com"`, matches: true},
+		{filter: `emails[type eq "work" and value co "@example.com"]`, matches: true},
+		{filter: `emails[type eq "other"]`, matches: false},
+		{filter: `meta.resourceType eq "User"`, matches: true},
+		{filter: `title pr`, matches: false},
+		{filter: `displayName pr`, matches: true},
+		{filter: `userName eq "bob" or displayName co "alice"`, matches: true},
+		{filter: `userName eq "bob" or userName eq "carol" and active eq true`, matches: false},
+		{filter: `(userName eq "bob" or userName eq "alice") and active eq true`, matches: true},
+		{filter: `not (userName eq "bob")`, matches: true},
+		{filter: `not (userName eq "alice")`, matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, f.matches(resource))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName like "alice"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`(userName eq "alice"`,
+		`userName eq "alice")`,
+		`emails[type eq "work"`,
+		`not userName eq "alice"`,
+		`userName eq "alice" and`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := parseFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEqualityValue(t *testing.T) {
+	tests := []struct {
+		filter string
+		value  string
+		ok     bool
+	}{
+		{filter: `userName eq "alice"`, value: "alice", ok: true},
+		{filter: `active eq true and userName eq "alice"`, value: "alice", ok: true},
+		{filter: `userName eq "alice" or userName eq "bob"`, ok: false},
+		{filter: `userName co "alice"`, ok: false},
+		{filter: `displayName eq "alice"`, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			value, ok := equalityValue(f, "userName")
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func (api *API) listGroups(c *contextmodel.ReqContext) response.Response {
+	params, errResponse := parseListParams(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	withMembers := !excludesMembers(c)
+
+	query := &team.SearchTeamsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		SignedInUser: c.SignedInUser,
+	}
+
+	// Without filter, the page is loaded from the database when it is aligned on the page size.
+	if params.filter == nil && params.count > 0 && (params.startIndex-1)%params.count == 0 {
+		query.Limit = params.count
+		query.Page = (params.startIndex-1)/params.count + 1
+		result, err := api.teamService.SearchTeams(c.Req.Context(), query)
+		if err != nil {
+			return api.internalError(c, "Failed to search teams", err)
+		}
+		resources := make([]any, 0, len(result.Teams))
+		for _, t := range result.Teams {
+			g, err := api.toGroup(c, t.ID, t.Name, withMembers)
+			if err != nil {
+				return api.internalError(c, "Failed to get team members", err)
+			}
+			resources = append(resources, g)
+		}
+		return listResponse(params, int(result.TotalCount), resources)
+	}
+
+	if params.filter != nil {
+		if v, ok := equalityValue(params.filter, "displayName"); ok {
+			query.Name = v
+		}
+	}
+
+	result, err := api.teamService.SearchTeams(c.Req.Context(), query)
+	if err != nil {
+		return api.internalError(c, "Failed to search teams", err)
+	}
+
+	resources := make([]any, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		// Members are always loaded to evaluate filters on them.
+		g, err := api.toGroup(c, t.ID, t.Name, true)
+		if err != nil {
+			return api.internalError(c, "Failed to get team members", err)
+		}
+		ok, err := params.matches(g)
+		if err != nil {
+			return api.internalError(c, "Failed to filter teams", err)
+		}
+		if !ok {
+			continue
+		}
+		if !withMembers {
+			g.Members = nil
+		}
+		resources = append(resources, g)
+	}
+	return listResponse(params, len(resources), params.page(resources))
+}
+
+func (api *API) getGroup(c *contextmodel.ReqContext) response.Response {
+	teamID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	return api.groupResponse(c, http.StatusOK, teamID, !excludesMembers(c))
+}
+
+func (api *API) createGroup(c *contextmodel.ReqContext) response.Response {
+	var g Group
+	if errResponse := bindSCIM(c, &g); errResponse != nil {
+		return errResponse
+	}
+	if g.DisplayName == "" {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "displayName is required")
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	members, errResponse := api.memberIDs(c, g.Members)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	t, err := api.teamService.CreateTeam(g.DisplayName, "", orgID)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return errorResponse(http.StatusConflict, ErrorTypeUniqueness, fmt.Sprintf("group %s already exists", g.DisplayName))
+		}
+		return api.internalError(c, "Failed to create team", err)
+	}
+
+	if errResponse := api.setMembers(c, t.ID, members); errResponse != nil {
+		return errResponse
+	}
+
+	api.logger.FromContext(c.Req.Context()).Info("Team provisioned", "teamId", t.ID, "orgId", orgID)
+	return api.groupResponse(c, http.StatusCreated, t.ID, true)
+}
+
+func (api *API) replaceGroup(c *contextmodel.ReqContext) response.Response {
+	teamID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	var g Group
+	if errResponse := bindSCIM(c, &g); errResponse != nil {
+		return errResponse
+	}
+
+	current, errResponse := api.getTeam(c, teamID)
+	if errResponse != nil {
+		return errResponse
+	}
+	return api.updateGroup(c, current, &g)
+}
+
+func (api *API) patchGroup(c *contextmodel.ReqContext) response.Response {
+	teamID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	var patch PatchRequest
+	if errResponse := bindSCIM(c, &patch); errResponse != nil {
+		return errResponse
+	}
+
+	current, errResponse := api.getTeam(c, teamID)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	g, err := api.toGroup(c, current.ID, current.Name, true)
+	if err != nil {
+		return api.internalError(c, "Failed to get team members", err)
+	}
+	resource, err := toMap(g)
+	if err != nil {
+		return api.internalError(c, "Failed to patch team", err)
+	}
+	if err := applyPatch(resource, patch.Operations); err != nil {
+		return patchErrorResponse(err)
+	}
+
+	var patched Group
+	if err := fromMap(resource, &patched); err != nil {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, err.Error())
+	}
+	return api.updateGroup(c, current, &patched)
+}
+
+func (api *API) deleteGroup(c *contextmodel.ReqContext) response.Response {
+	teamID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	if err := api.teamService.DeleteTeam(c.Req.Context(), &team.DeleteTeamCommand{OrgID: orgID, ID: teamID}); err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return errorResponse(http.StatusNotFound, "", fmt.Sprintf("group %d not found", teamID))
+		}
+		return api.internalError(c, "Failed to delete team", err)
+	}
+
+	api.logger.FromContext(c.Req.Context()).Info("Team deprovisioned", "teamId", teamID, "orgId", orgID)
+	return response.Empty(http.StatusNoContent)
+}
+
+func (api *API) updateGroup(c *contextmodel.ReqContext, current *team.TeamDTO, g *Group) response.Response {
+	if g.DisplayName == "" {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "displayName is required")
+	}
+
+	members, errResponse := api.memberIDs(c, g.Members)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	if g.DisplayName != current.Name {
+		err := api.teamService.UpdateTeam(c.Req.Context(), &team.UpdateTeamCommand{
+			ID:    current.ID,
+			OrgID: current.OrgID,
+			Name:  g.DisplayName,
+			Email: current.Email,
+		})
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				return errorResponse(http.StatusConflict, ErrorTypeUniqueness, fmt.Sprintf("group %s already exists", g.DisplayName))
+			}
+			return api.internalError(c, "Failed to update team", err)
+		}
+	}
+
+	if errResponse := api.setMembers(c, current.ID, members); errResponse != nil {
+		return errResponse
+	}
+	return api.groupResponse(c, http.StatusOK, current.ID, true)
+}
+
+// memberIDs returns the IDs of the users referenced by the members, users
+// that do not exist are reported as invalid values.
+func (api *API) memberIDs(c *contextmodel.ReqContext, members []Member) (map[int64]bool, response.Response) {
+	ids := make(map[int64]bool, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return nil, errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("invalid member %q", m.Value))
+		}
+		if ids[id] {
+			continue
+		}
+		if _, err := api.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: id}); err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				return nil, errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("user %d not found", id))
+			}
+			return nil, api.internalError(c, "Failed to get user", err)
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// setMembers adds and removes team members so that the users of the team are
+// exactly the ones given. Existing members keep their permission.
+func (api *API) setMembers(c *contextmodel.ReqContext, teamID int64, members map[int64]bool) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	current, err := api.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+		OrgID:        orgID,
+		TeamID:       teamID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return api.internalError(c, "Failed to get team members", err)
+	}
+
+	existing := make(map[int64]bool, len(current))
+	for _, m := range current {
+		existing[m.UserID] = true
+		if !members[m.UserID] {
+			if err := api.setMemberPermission(c, teamID, m.UserID, ""); err != nil {
+				return api.internalError(c, "Failed to remove team member", err)
+			}
+		}
+	}
+
+	for userID := range members {
+		if existing[userID] {
+			continue
+		}
+		if err := api.setMemberPermission(c, teamID, userID, "Member"); err != nil {
+			return api.internalError(c, "Failed to add team member", err)
+		}
+	}
+	return nil
+}
+
+func (api *API) setMemberPermission(c *contextmodel.ReqContext, teamID, userID int64, permission string) error {
+	_, err := api.teamPermissionsService.SetUserPermission(
+		c.Req.Context(), c.SignedInUser.GetOrgID(), accesscontrol.User{ID: userID}, strconv.FormatInt(teamID, 10), permission,
+	)
+	return err
+}
+
+func (api *API) getTeam(c *contextmodel.ReqContext, teamID int64) (*team.TeamDTO, response.Response) {
+	t, err := api.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		ID:           teamID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, errorResponse(http.StatusNotFound, "", fmt.Sprintf("group %d not found", teamID))
+		}
+		return nil, api.internalError(c, "Failed to get team", err)
+	}
+	return t, nil
+}
+
+func (api *API) groupResponse(c *contextmodel.ReqContext, status int, teamID int64, withMembers bool) response.Response {
+	t, errResponse := api.getTeam(c, teamID)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	g, err := api.toGroup(c, t.ID, t.Name, withMembers)
+	if err != nil {
+		return api.internalError(c, "Failed to get team members", err)
+	}
+	return scimJSON(status, g).SetHeader("Location", g.Meta.Location)
+}
+
+func (api *API) toGroup(c *contextmodel.ReqContext, teamID int64, name string, withMembers bool) (*Group, error) {
+	id := strconv.FormatInt(teamID, 10)
+	g := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          id,
+		DisplayName: name,
+		Meta: &Meta{
+			ResourceType: ResourceTypeGroup,
+			Location:     api.location("Groups", id),
+		},
+	}
+	if !withMembers {
+		return g, nil
+	}
+
+	members, err := api.teamService.GetTeamMembers(c.Req.Context(), &team.GetTeamMembersQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		TeamID:       teamID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		userID := strconv.FormatInt(m.UserID, 10)
+		g.Members = append(g.Members, Member{Value: userID, Display: m.Login, Ref: api.location("Users", userID)})
+	}
+	return g, nil
+}
+
+// excludesMembers reports whether the client asked not to return group
+// members, identity providers use it to avoid loading large groups.
+func excludesMembers(c *contextmodel.ReqContext) bool {
+	for _, attr := range strings.Split(c.Req.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(attributeName(strings.TrimSpace(attr)), "members") {
+			return true
+		}
+	}
+	return false
+}
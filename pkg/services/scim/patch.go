@@ -0,0 +1,302 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+// patchPath is a parsed PATCH operation path, see RFC 7644, section 3.5.2.
+// For example emails[type eq "work"].value has the attribute emails, a
+// filter and the sub-attribute value.
+type patchPath struct {
+	attr   string
+	filter filter
+	sub    string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	path = attributeName(path)
+	p := &patchPath{}
+
+	if open := strings.Index(path, "["); open >= 0 {
+		end := strings.LastIndex(path, "]")
+		if end < open {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		f, err := parseFilter(path[open+1 : end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		p.attr = path[:open]
+		p.filter = f
+		rest := path[end+1:]
+		if rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			p.sub = rest[1:]
+		}
+	} else if attr, sub, ok := strings.Cut(path, "."); ok {
+		p.attr, p.sub = attr, sub
+	} else {
+		p.attr = path
+	}
+
+	if !isAttributeName(p.attr) || p.sub != "" && !isAttributeName(p.sub) {
+		return nil, fmt.Errorf("invalid path %q", path)
+	}
+	return p, nil
+}
+
+// patchError is returned for operations that cannot be applied, with the SCIM error type to report.
+type patchError struct {
+	scimType string
+	err      error
+}
+
+func (e *patchError) Error() string {
+	return e.err.Error()
+}
+
+// applyPatch applies the operations to the JSON representation of a resource.
+func applyPatch(resource map[string]any, ops []PatchOperation) error {
+	for _, op := range ops {
+		if err := applyOperation(resource, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(resource map[string]any, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return &patchError{ErrorTypeInvalidSyntax, fmt.Errorf("unsupported operation %q", op.Op)}
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return &patchError{ErrorTypeNoTarget, fmt.Errorf("remove operation requires a path")}
+		}
+		values, ok := op.Value.(map[string]any)
+		if !ok {
+			return &patchError{ErrorTypeInvalidValue, fmt.Errorf("%s operation without path requires an object value", kind)}
+		}
+		for k, v := range values {
+			path, err := parsePatchPath(k)
+			if err != nil {
+				return &patchError{ErrorTypeInvalidPath, err}
+			}
+			apply(resource, kind, path, v)
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return &patchError{ErrorTypeInvalidPath, err}
+	}
+	if path.filter != nil && kind == "replace" && !hasMatch(resource, path) && equalityTemplate(path.filter) == nil {
+		return &patchError{ErrorTypeNoTarget, fmt.Errorf("no value matches path %q", op.Path)}
+	}
+	apply(resource, kind, path, op.Value)
+	return nil
+}
+
+func apply(resource map[string]any, kind string, path *patchPath, value any) {
+	key, current, exists := getKey(resource, path.attr)
+	if !exists {
+		key = path.attr
+	}
+
+	if path.filter != nil {
+		list, _ := current.([]any)
+		matched := false
+		result := make([]any, 0, len(list))
+		for _, item := range list {
+			m, ok := item.(map[string]any)
+			if !ok || !path.filter.matches(m) {
+				result = append(result, item)
+				continue
+			}
+			matched = true
+			switch {
+			case kind == "remove" && path.sub == "":
+				continue
+			case kind == "remove":
+				if k, _, ok := getKey(m, path.sub); ok {
+					delete(m, k)
+				}
+			case path.sub != "":
+				setKey(m, path.sub, value)
+			default:
+				if values, ok := value.(map[string]any); ok {
+					for k, v := range values {
+						setKey(m, k, v)
+					}
+				}
+			}
+			result = append(result, m)
+		}
+		// Add the element described by the filter, for example the work email of emails[type eq "work"].value.
+		if !matched && kind != "remove" {
+			if m := equalityTemplate(path.filter); m != nil {
+				if path.sub != "" {
+					m[path.sub] = value
+				} else if values, ok := value.(map[string]any); ok {
+					for k, v := range values {
+						setKey(m, k, v)
+					}
+				}
+				result = append(result, m)
+			}
+		}
+		resource[key] = result
+		return
+	}
+
+	if path.sub != "" {
+		switch c := current.(type) {
+		case map[string]any:
+			if kind == "remove" {
+				if k, _, ok := getKey(c, path.sub); ok {
+					delete(c, k)
+				}
+				return
+			}
+			setKey(c, path.sub, value)
+		case []any:
+			for _, item := range c {
+				if m, ok := item.(map[string]any); ok {
+					if kind == "remove" {
+						if k, _, ok := getKey(m, path.sub); ok {
+							delete(m, k)
+						}
+						continue
+					}
+					setKey(m, path.sub, value)
+				}
+			}
+		default:
+			if kind != "remove" {
+				resource[key] = map[string]any{path.sub: value}
+			}
+		}
+		return
+	}
+
+	switch kind {
+	case "remove":
+		// Some clients remove members by value rather than with a filter.
+		if list, ok := current.([]any); ok {
+			if values, ok := value.([]any); ok && len(values) > 0 {
+				resource[key] = removeValues(list, values)
+				return
+			}
+		}
+		delete(resource, key)
+	case "add":
+		if list, ok := current.([]any); ok {
+			if values, ok := value.([]any); ok {
+				resource[key] = appendValues(list, values)
+			} else {
+				resource[key] = appendValues(list, []any{value})
+			}
+			return
+		}
+		if c, ok := current.(map[string]any); ok {
+			if values, ok := value.(map[string]any); ok {
+				for k, v := range values {
+					setKey(c, k, v)
+				}
+				return
+			}
+		}
+		resource[key] = value
+	default:
+		resource[key] = value
+	}
+}
+
+func setKey(m map[string]any, name string, value any) {
+	if k, _, ok := getKey(m, name); ok {
+		m[k] = value
+		return
+	}
+	m[name] = value
+}
+
+func hasMatch(resource map[string]any, path *patchPath) bool {
+	return valuePathFilter{attr: path.attr, filter: path.filter}.matches(resource)
+}
+
+// equalityTemplate returns the element a filter made of equality comparisons
+// matches, or nil for other filters.
+func equalityTemplate(f filter) map[string]any {
+	switch f := f.(type) {
+	case compareFilter:
+		if f.op != "eq" || strings.Contains(f.path, ".") {
+			return nil
+		}
+		return map[string]any{attributeName(f.path): f.value}
+	case logicalFilter:
+		if !f.and {
+			return nil
+		}
+		left, right := equalityTemplate(f.left), equalityTemplate(f.right)
+		if left == nil || right == nil {
+			return nil
+		}
+		for k, v := range right {
+			left[k] = v
+		}
+		return left
+	}
+	return nil
+}
+
+// appendValues adds the values to the list, skipping the values already in the
+// list, elements with the same "value" attribute are considered equal.
+func appendValues(list []any, values []any) []any {
+	for _, v := range values {
+		if !containsValue(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func removeValues(list []any, values []any) []any {
+	result := make([]any, 0, len(list))
+	for _, item := range list {
+		if !containsValue(values, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if sameValue(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameValue(a, b any) bool {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if aok && bok {
+		_, av, aok := getKey(am, "value")
+		_, bv, bok := getKey(bm, "value")
+		return aok && bok && fmt.Sprint(av) == fmt.Sprint(bv)
+	}
+	if aok || bok {
+		return false
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
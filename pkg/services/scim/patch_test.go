@@ -0,0 +1,153 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		ops      string
+		expected string
+	}{
+		{
+			name:     "replace attribute",
+			resource: `{"userName": "alice", "active": true}`,
+			ops:      `[{"op": "Replace", "path": "active", "value": false}]`,
+			expected: `{"userName": "alice", "active": false}`,
+		},
+		{
+			name:     "replace without path",
+			resource: `{"userName": "alice", "active": true}`,
+			ops:      `[{"op": "replace", "value": {"active": false, "displayName": "Alice"}}]`,
+			expected: `{"userName": "alice", "active": false, "displayName": "Alice"}`,
+		},
+		{
+			name:     "replace attribute with schema prefix and different case",
+			resource: `{"userName": "alice"}`,
+			ops:      `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:USERNAME", "value": "bob"}]`,
+			expected: `{"userName": "bob"}`,
+		},
+		{
+			name:     "replace sub-attribute",
+			resource: `{"name": {"givenName": "Alice", "familyName": "Liddell"}}`,
+			ops:      `[{"op": "replace", "path": "name.familyName", "value": "Smith"}]`,
+			expected: `{"name": {"givenName": "Alice", "familyName": "Smith"}}`,
+		},
+		{
+			name:     "replace filtered sub-attribute",
+			resource: `{"emails": [{"value": "alice@example.com", "type": "work"}, {"value": "alice@home.example.com", "type": "home"}]}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@corp.example.com"}]`,
+			expected: `{"emails": [{"value": "alice@corp.example.com", "type": "work"}, {"value": "alice@home.example.com", "type": "home"}]}`,
+		},
+		{
+			name:     "replace filtered sub-attribute without match adds element",
+			resource: `{"userName": "alice"}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@example.com"}]`,
+			expected: `{"userName": "alice", "emails": [{"value": "alice@example.com", "type": "work"}]}`,
+		},
+		{
+			name:     "add members",
+			resource: `{"members": [{"value": "1"}]}`,
+			ops:      `[{"op": "add", "path": "members", "value": [{"value": "1"}, {"value": "2"}]}]`,
+			expected: `{"members": [{"value": "1"}, {"value": "2"}]}`,
+		},
+		{
+			name:     "add members without existing members",
+			resource: `{"displayName": "team"}`,
+			ops:      `[{"op": "add", "path": "members", "value": [{"value": "2"}]}]`,
+			expected: `{"displayName": "team", "members": [{"value": "2"}]}`,
+		},
+		{
+			name:     "remove member with filter",
+			resource: `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:      `[{"op": "remove", "path": "members[value eq \"1\"]"}]`,
+			expected: `{"members": [{"value": "2"}]}`,
+		},
+		{
+			name:     "remove members by value",
+			resource: `{"members": [{"value": "1"}, {"value": "2"}, {"value": "3"}]}`,
+			ops:      `[{"op": "remove", "path": "members", "value": [{"value": "1"}, {"value": "3"}]}]`,
+			expected: `{"members": [{"value": "2"}]}`,
+		},
+		{
+			name:     "remove all members",
+			resource: `{"displayName": "team", "members": [{"value": "1"}]}`,
+			ops:      `[{"op": "remove", "path": "members"}]`,
+			expected: `{"displayName": "team"}`,
+		},
+		{
+			name:     "apply operations in order",
+			resource: `{"displayName": "team", "members": [{"value": "1"}]}`,
+			ops:      `[{"op": "replace", "path": "displayName", "value": "renamed"}, {"op": "remove", "path": "members"}, {"op": "add", "path": "members", "value": [{"value": "3"}]}]`,
+			expected: `{"displayName": "renamed", "members": [{"value": "3"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.resource), &resource))
+			var ops []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.ops), &ops))
+
+			require.NoError(t, applyPatch(resource, ops))
+
+			actual, err := json.Marshal(resource)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ops      string
+		scimType string
+	}{
+		{
+			name:     "unsupported operation",
+			ops:      `[{"op": "move", "path": "userName", "value": "bob"}]`,
+			scimType: ErrorTypeInvalidSyntax,
+		},
+		{
+			name:     "remove without path",
+			ops:      `[{"op": "remove"}]`,
+			scimType: ErrorTypeNoTarget,
+		},
+		{
+			name:     "replace without path and object value",
+			ops:      `[{"op": "replace", "value": "bob"}]`,
+			scimType: ErrorTypeInvalidValue,
+		},
+		{
+			name:     "invalid path",
+			ops:      `[{"op": "replace", "path": "emails[type eq].value", "value": "bob"}]`,
+			scimType: ErrorTypeInvalidPath,
+		},
+		{
+			name:     "replace filtered attribute without match",
+			ops:      `[{"op": "replace", "path": "emails[type co \"work\"].value", "value": "alice@example.com"}]`,
+			scimType: ErrorTypeNoTarget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.ops), &ops))
+
+			err := applyPatch(map[string]any{"userName": "alice"}, ops)
+
+			var pErr *patchError
+			require.ErrorAs(t, err, &pErr)
+			assert.Equal(t, tt.scimType, pErr.scimType)
+		})
+	}
+}
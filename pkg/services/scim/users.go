@@ -0,0 +1,404 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// userSearchAttributes are the attributes whose equality filters are used to
+// narrow down the users loaded from the database.
+var userSearchAttributes = []string{"userName", "emails.value", "emails", "displayName", "name.formatted"}
+
+func (api *API) listUsers(c *contextmodel.ReqContext) response.Response {
+	params, errResponse := parseListParams(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	query := &org.SearchOrgUsersQuery{
+		OrgID:                    c.SignedInUser.GetOrgID(),
+		User:                     c.SignedInUser,
+		DontEnforceAccessControl: true,
+	}
+
+	// Without filter, the page is loaded from the database when it is aligned on the page size.
+	if params.filter == nil && params.count > 0 && (params.startIndex-1)%params.count == 0 {
+		query.Limit = params.count
+		query.Page = (params.startIndex-1)/params.count + 1
+		result, err := api.orgService.SearchOrgUsers(c.Req.Context(), query)
+		if err != nil {
+			return api.internalError(c, "Failed to search users", err)
+		}
+		resources := make([]any, 0, len(result.OrgUsers))
+		for _, u := range result.OrgUsers {
+			resources = append(resources, api.toUser(u, nil))
+		}
+		return listResponse(params, int(result.TotalCount), resources)
+	}
+
+	if params.filter != nil {
+		for _, attr := range userSearchAttributes {
+			if v, ok := equalityValue(params.filter, attr); ok {
+				query.Query = v
+				break
+			}
+		}
+	}
+
+	result, err := api.orgService.SearchOrgUsers(c.Req.Context(), query)
+	if err != nil {
+		return api.internalError(c, "Failed to search users", err)
+	}
+
+	resources := make([]any, 0, len(result.OrgUsers))
+	for _, u := range result.OrgUsers {
+		resource := api.toUser(u, nil)
+		ok, err := params.matches(resource)
+		if err != nil {
+			return api.internalError(c, "Failed to filter users", err)
+		}
+		if ok {
+			resources = append(resources, resource)
+		}
+	}
+	return listResponse(params, len(resources), params.page(resources))
+}
+
+func (api *API) getUser(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+	return api.userResponse(c, http.StatusOK, userID)
+}
+
+// createUser creates the user and adds it to the organization of the service
+// account. Users that already exist in other organizations are added to it.
+func (api *API) createUser(c *contextmodel.ReqContext) response.Response {
+	var u User
+	if errResponse := bindSCIM(c, &u); errResponse != nil {
+		return errResponse
+	}
+	if u.UserName == "" {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "userName is required")
+	}
+
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	var userID int64
+	existing, err := api.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: u.UserName})
+	switch {
+	case err == nil:
+		userID = existing.ID
+	case errors.Is(err, user.ErrUserNotFound):
+		usr, err := api.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        u.UserName,
+			Email:        u.email(),
+			Name:         u.displayName(),
+			OrgID:        orgID,
+			SkipOrgSetup: true,
+			IsDisabled:   u.Active != nil && !*u.Active,
+		})
+		if err != nil {
+			if errors.Is(err, user.ErrUserAlreadyExists) {
+				return errorResponse(http.StatusConflict, ErrorTypeUniqueness, "a user with the same userName or email already exists")
+			}
+			return api.internalError(c, "Failed to create user", err)
+		}
+		userID = usr.ID
+	default:
+		return api.internalError(c, "Failed to get user", err)
+	}
+
+	err = api.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{
+		OrgID:  orgID,
+		UserID: userID,
+		Role:   org.RoleType(api.cfg.SCIMDefaultOrgRole),
+	})
+	if err != nil {
+		if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+			return errorResponse(http.StatusConflict, ErrorTypeUniqueness, fmt.Sprintf("user %s already exists", u.UserName))
+		}
+		return api.internalError(c, "Failed to add user to organization", err)
+	}
+
+	api.logger.FromContext(ctx).Info("User provisioned", "userId", userID, "orgId", orgID)
+	return api.userResponse(c, http.StatusCreated, userID)
+}
+
+func (api *API) replaceUser(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	var u User
+	if errResponse := bindSCIM(c, &u); errResponse != nil {
+		return errResponse
+	}
+
+	current, errResponse := api.getOrgUser(c, userID)
+	if errResponse != nil {
+		return errResponse
+	}
+	return api.updateUser(c, current, &u)
+}
+
+func (api *API) patchUser(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	var patch PatchRequest
+	if errResponse := bindSCIM(c, &patch); errResponse != nil {
+		return errResponse
+	}
+
+	current, errResponse := api.getOrgUser(c, userID)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	resource, err := toMap(api.toUser(current, nil))
+	if err != nil {
+		return api.internalError(c, "Failed to patch user", err)
+	}
+	if err := applyPatch(resource, patch.Operations); err != nil {
+		return patchErrorResponse(err)
+	}
+	// Some identity providers send booleans as strings.
+	if k, v, ok := getKey(resource, "active"); ok {
+		if s, ok := v.(string); ok {
+			active, err := strconv.ParseBool(strings.ToLower(s))
+			if err != nil {
+				return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "active must be a boolean")
+			}
+			resource[k] = active
+		}
+	}
+
+	var u User
+	if err := fromMap(resource, &u); err != nil {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, err.Error())
+	}
+	return api.updateUser(c, current, &u)
+}
+
+// deleteUser removes the user from the organization of the service account,
+// the user is deleted when it does not belong to any other organization.
+func (api *API) deleteUser(c *contextmodel.ReqContext) response.Response {
+	userID, errResponse := parseID(c)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	current, errResponse := api.getOrgUser(c, userID)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	ctx := c.Req.Context()
+	cmd := &org.RemoveOrgUserCommand{UserID: current.UserID, OrgID: current.OrgID, ShouldDeleteOrphanedUser: true}
+	if err := api.orgService.RemoveOrgUser(ctx, cmd); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return errorResponse(http.StatusBadRequest, ErrorTypeMutability, "cannot remove last organization admin")
+		}
+		return api.internalError(c, "Failed to remove user from organization", err)
+	}
+
+	permissionsOrgID := cmd.OrgID
+	if cmd.UserWasDeleted {
+		permissionsOrgID = accesscontrol.GlobalOrgID
+	}
+	if err := api.accesscontrolService.DeleteUserPermissions(ctx, permissionsOrgID, cmd.UserID); err != nil {
+		api.logger.FromContext(ctx).Warn("Failed to delete permissions for user", "userId", cmd.UserID, "orgId", permissionsOrgID, "error", err)
+	}
+
+	api.logger.FromContext(ctx).Info("User deprovisioned", "userId", cmd.UserID, "orgId", cmd.OrgID, "deleted", cmd.UserWasDeleted)
+	return response.Empty(http.StatusNoContent)
+}
+
+// updateUser applies the attributes of the SCIM user to the Grafana user.
+// Deactivated users are disabled and logged out.
+func (api *API) updateUser(c *contextmodel.ReqContext, current *org.OrgUserDTO, u *User) response.Response {
+	ctx := c.Req.Context()
+
+	if u.UserName == "" {
+		return errorResponse(http.StatusBadRequest, ErrorTypeInvalidValue, "userName is required")
+	}
+
+	cmd := &user.UpdateUserCommand{
+		UserID: current.UserID,
+		Login:  u.UserName,
+		Email:  u.email(),
+		Name:   u.displayName(),
+	}
+	if cmd.Email == "" {
+		cmd.Email = current.Email
+	}
+	active := !current.IsDisabled
+	if u.Active != nil {
+		active = *u.Active
+	}
+
+	attributesChanged := cmd.Login != current.Login || cmd.Email != current.Email || cmd.Name != current.Name
+	activeChanged := active == current.IsDisabled
+	if !attributesChanged && !activeChanged {
+		return api.userResponse(c, http.StatusOK, current.UserID)
+	}
+
+	if errResponse := api.checkManagedUser(c, current); errResponse != nil {
+		return errResponse
+	}
+
+	if attributesChanged {
+		for _, loginOrEmail := range []string{cmd.Login, cmd.Email} {
+			existing, err := api.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: loginOrEmail})
+			if err == nil && existing.ID != current.UserID {
+				return errorResponse(http.StatusConflict, ErrorTypeUniqueness, "a user with the same userName or email already exists")
+			}
+			if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+				return api.internalError(c, "Failed to get user", err)
+			}
+		}
+		if err := api.userService.Update(ctx, cmd); err != nil {
+			return api.internalError(c, "Failed to update user", err)
+		}
+	}
+
+	if activeChanged {
+		if err := api.userService.Disable(ctx, &user.DisableUserCommand{UserID: current.UserID, IsDisabled: !active}); err != nil {
+			return api.internalError(c, "Failed to update user", err)
+		}
+		if !active {
+			if err := api.authTokenService.RevokeAllUserTokens(ctx, current.UserID); err != nil {
+				return api.internalError(c, "Failed to log out user", err)
+			}
+		}
+		api.logger.FromContext(ctx).Info("User active state changed", "userId", current.UserID, "active", active)
+	}
+
+	return api.userResponse(c, http.StatusOK, current.UserID)
+}
+
+// checkManagedUser prevents changing the attributes of users the service
+// account's organization does not own: users are shared between
+// organizations and Grafana server admins can only be managed by other admins.
+func (api *API) checkManagedUser(c *contextmodel.ReqContext, current *org.OrgUserDTO) response.Response {
+	ctx := c.Req.Context()
+
+	usr, err := api.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: current.UserID})
+	if err != nil {
+		return api.internalError(c, "Failed to get user", err)
+	}
+	if usr.IsAdmin {
+		return errorResponse(http.StatusForbidden, ErrorTypeMutability, "the attributes of Grafana server admins cannot be changed through SCIM")
+	}
+
+	orgs, err := api.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: current.UserID})
+	if err != nil {
+		return api.internalError(c, "Failed to get user organizations", err)
+	}
+	if len(orgs) > 1 {
+		return errorResponse(http.StatusForbidden, ErrorTypeMutability, "the attributes of users belonging to other organizations cannot be changed through SCIM")
+	}
+	return nil
+}
+
+func (api *API) getOrgUser(c *contextmodel.ReqContext, userID int64) (*org.OrgUserDTO, response.Response) {
+	result, err := api.orgService.SearchOrgUsers(c.Req.Context(), &org.SearchOrgUsersQuery{
+		OrgID:                    c.SignedInUser.GetOrgID(),
+		UserID:                   userID,
+		User:                     c.SignedInUser,
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return nil, api.internalError(c, "Failed to get user", err)
+	}
+	if len(result.OrgUsers) == 0 {
+		return nil, errorResponse(http.StatusNotFound, "", fmt.Sprintf("user %d not found", userID))
+	}
+	return result.OrgUsers[0], nil
+}
+
+func (api *API) userResponse(c *contextmodel.ReqContext, status int, userID int64) response.Response {
+	current, errResponse := api.getOrgUser(c, userID)
+	if errResponse != nil {
+		return errResponse
+	}
+
+	groups, err := api.userGroups(c.Req.Context(), c, userID)
+	if err != nil {
+		return api.internalError(c, "Failed to get user teams", err)
+	}
+
+	u := api.toUser(current, groups)
+	return scimJSON(status, u).SetHeader("Location", u.Meta.Location)
+}
+
+func (api *API) userGroups(ctx context.Context, c *contextmodel.ReqContext, userID int64) ([]Member, error) {
+	teams, err := api.teamService.GetTeamsByUser(ctx, &team.GetTeamsByUserQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		UserID:       userID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]Member, 0, len(teams))
+	for _, t := range teams {
+		id := strconv.FormatInt(t.ID, 10)
+		groups = append(groups, Member{Value: id, Display: t.Name, Ref: api.location("Groups", id)})
+	}
+	return groups, nil
+}
+
+func (api *API) toUser(u *org.OrgUserDTO, groups []Member) *User {
+	id := strconv.FormatInt(u.UserID, 10)
+	active := !u.IsDisabled
+	created, updated := u.Created, u.Updated
+
+	result := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          id,
+		UserName:    u.Login,
+		DisplayName: u.Name,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &Meta{
+			ResourceType: ResourceTypeUser,
+			Created:      &created,
+			LastModified: &updated,
+			Location:     api.location("Users", id),
+		},
+	}
+	if u.Name != "" {
+		result.Name = &Name{Formatted: u.Name}
+	}
+	if u.Email != "" {
+		result.Emails = []Email{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	return result
+}
+
+func patchErrorResponse(err error) response.Response {
+	var pErr *patchError
+	if errors.As(err, &pErr) {
+		return errorResponse(http.StatusBadRequest, pErr.scimType, pErr.Error())
+	}
+	return errorResponse(http.StatusBadRequest, ErrorTypeInvalidSyntax, err.Error())
+}
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/web"
@@ -36,6 +39,8 @@ type TokenDTO struct {
 	HasExpired bool `json:"hasExpired"`
 	// example: false
 	IsRevoked *bool `json:"isRevoked"`
+	// Permissions the token is restricted to. Empty if the token has all permissions of the service account.
+	Permissions []apikey.Permission `json:"permissions,omitempty"`
 }
 
 func hasExpired(expiration *int64) bool {
@@ -104,6 +109,7 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 			HasExpired:             isExpired,
 			LastUsedAt:             token.LastUsedAt,
 			IsRevoked:              token.IsRevoked,
+			Permissions:            token.Permissions,
 		}
 	}
 
@@ -144,21 +150,8 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	// Force affected service account to be the one referenced in the URL
 	cmd.OrgId = c.SignedInUser.GetOrgID()
 
-	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
-		}
-	}
-
-	if api.cfg.SATokenExpirationDayLimit > 0 {
-		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
-		expirationDate := time.Now().Add(time.Duration(cmd.SecondsToLive) * time.Second).Truncate(24 * time.Hour)
-		if expirationDate.After(dayExpireLimit) {
-			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
-		}
+	if resp := api.checkTokenLifetime(cmd.OrgId, cmd.SecondsToLive); resp != nil {
+		return resp
 	}
 
 	newKeyInfo, err := satokengen.New(ServiceID)
@@ -182,6 +175,109 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	return response.JSON(http.StatusOK, result)
 }
 
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken replaces a service account token with a new one
+//
+// The rotated token keeps working until the end of the overlap window.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: rotateTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	form := serviceaccounts.RotateServiceAccountTokenForm{}
+	if err = web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	tokens, err := api.service.ListTokens(c.Req.Context(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &orgID,
+		ServiceAccountID: &saID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to retrieve service account tokens", err)
+	}
+
+	var previous *apikey.APIKey
+	for i := range tokens {
+		if tokens[i].ID == tokenID {
+			previous = &tokens[i]
+			break
+		}
+	}
+	if previous == nil {
+		return response.Err(serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found", tokenID))
+	}
+
+	now := time.Now()
+
+	// Unless specified, the new token lives as long as the rotated one did.
+	var secondsToLive int64
+	if form.SecondsToLive != nil {
+		secondsToLive = *form.SecondsToLive
+	} else if previous.Expires != nil && *previous.Expires > previous.Created.Unix() {
+		secondsToLive = *previous.Expires - previous.Created.Unix()
+	}
+
+	if resp := api.checkTokenLifetime(orgID, secondsToLive); resp != nil {
+		return resp
+	}
+
+	overlap := api.cfg.SATokenRotationOverlap
+	if form.OverlapSeconds != nil {
+		if *form.OverlapSeconds < 0 {
+			return response.Error(http.StatusBadRequest, "Number of overlap seconds should not be negative", nil)
+		}
+		overlap = time.Duration(*form.OverlapSeconds) * time.Second
+	}
+
+	previousExpiration := now.Add(overlap)
+	if previous.Expires != nil && time.Unix(*previous.Expires, 0).Before(previousExpiration) {
+		previousExpiration = time.Unix(*previous.Expires, 0)
+	}
+
+	newKeyInfo, err := satokengen.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	apiKey, err := api.service.RotateServiceAccountToken(c.Req.Context(), saID, tokenID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		Name:                 rotatedTokenName(previous.Name, now),
+		OrgId:                orgID,
+		Key:                  newKeyInfo.HashedKey,
+		SecondsToLive:        secondsToLive,
+		PreviousTokenExpires: previousExpiration.Unix(),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to rotate service account token", err)
+	}
+
+	return response.JSON(http.StatusOK, &RotateTokenResult{
+		ID:                      apiKey.ID,
+		Name:                    apiKey.Name,
+		Key:                     newKeyInfo.ClientSecret,
+		PreviousTokenExpiration: previousExpiration,
+	})
+}
+
 // swagger:route DELETE /serviceaccounts/{serviceAccountId}/tokens/{tokenId} service_accounts deleteToken
 //
 // # DeleteToken deletes service account tokens
@@ -221,6 +317,45 @@ func (api *ServiceAccountsAPI) DeleteToken(c *contextmodel.ReqContext) response.
 	return response.Success("Service account token deleted")
 }
 
+// checkTokenLifetime enforces the configured token lifetime limits and returns
+// an error response if secondsToLive violates one of them.
+func (api *ServiceAccountsAPI) checkTokenLifetime(orgID int64, secondsToLive int64) response.Response {
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	if api.cfg.SATokenExpirationDayLimit > 0 {
+		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
+		expirationDate := time.Now().Add(time.Duration(secondsToLive) * time.Second).Truncate(24 * time.Hour)
+		if expirationDate.After(dayExpireLimit) {
+			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+		}
+	}
+
+	if maxLifetime, ok := api.cfg.SATokenMaxLifetimeByOrg[orgID]; ok {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set in this organization", nil)
+		}
+		if time.Duration(secondsToLive)*time.Second > maxLifetime {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the organization limit", nil)
+		}
+	}
+
+	return nil
+}
+
+var rotatedSuffix = regexp.MustCompile(`-rotated-\d+$`)
+
+// rotatedTokenName derives the name of a rotated token, replacing the suffix of earlier rotations.
+func rotatedTokenName(name string, now time.Time) string {
+	return fmt.Sprintf("%s-rotated-%d", rotatedSuffix.ReplaceAllString(name, ""), now.Unix())
+}
+
 // swagger:parameters listTokens
 type ListTokensParams struct {
 	// in:path
@@ -243,6 +378,34 @@ type DeleteTokenParams struct {
 	ServiceAccountId int64 `json:"serviceAccountId"`
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenForm
+}
+
+// swagger:model
+type RotateTokenResult struct {
+	// example: 8
+	ID int64 `json:"id"`
+	// example: grafana-rotated-1700000000
+	Name string `json:"name"`
+	// example: glsa_yscW25imSKJIuav8zF37RZmnbiDvB05G_fcaaf58a
+	Key string `json:"key"`
+	// example: 2022-03-23T10:31:02Z
+	PreviousTokenExpiration time.Time `json:"previousTokenExpiration"`
+}
+
+// swagger:response rotateTokenResponse
+type RotateTokenResponse struct {
+	// in:body
+	Body *RotateTokenResult
+}
+
 // swagger:response listTokensResponse
 type ListTokensResponse struct {
 	// in:body
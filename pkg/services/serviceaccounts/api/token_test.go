@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -177,3 +178,106 @@ func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	expires := created.Add(24 * time.Hour).Unix()
+	tokens := []apikey.APIKey{{ID: 1, Name: "deploy-rotated-1700000000", Created: created, Expires: &expires}}
+
+	type TestCase struct {
+		desc             string
+		tokenID          int64
+		body             string
+		permissions      []accesscontrol.Permission
+		orgMaxLifetime   time.Duration
+		expectedLifetime int64
+		expectedCode     int
+	}
+
+	tests := []TestCase{
+		{
+			desc:             "should rotate token with the lifetime of the previous token",
+			tokenID:          1,
+			body:             `{}`,
+			permissions:      []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedLifetime: 24 * 3600,
+			expectedCode:     http.StatusOK,
+		},
+		{
+			desc:             "should rotate token with the requested lifetime",
+			tokenID:          1,
+			body:             `{"secondsToLive": 3600, "overlapSeconds": 60}`,
+			permissions:      []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedLifetime: 3600,
+			expectedCode:     http.StatusOK,
+		},
+		{
+			desc:           "should not rotate token beyond the organization limit",
+			tokenID:        1,
+			body:           `{"secondsToLive": 7200}`,
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			orgMaxLifetime: time.Hour,
+			expectedCode:   http.StatusBadRequest,
+		},
+		{
+			desc:         "should not rotate token with negative overlap",
+			tokenID:      1,
+			body:         `{"overlapSeconds": -1}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not rotate token that does not exist",
+			tokenID:      2,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			desc:         "should not rotate token with wrong permission",
+			tokenID:      1,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionRead, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			svc := satests.NewMockServiceAccountService(t)
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.cfg.ApiKeyMaxSecondsToLive = -1
+				a.cfg.SATokenRotationOverlap = time.Hour
+				if tt.orgMaxLifetime > 0 {
+					a.cfg.SATokenMaxLifetimeByOrg = map[int64]time.Duration{1: tt.orgMaxLifetime}
+				}
+				a.service = svc
+			})
+
+			if tt.expectedCode != http.StatusForbidden {
+				svc.On("ListTokens", mock.Anything, mock.Anything).Return(tokens, nil)
+			}
+			if tt.expectedCode == http.StatusOK {
+				svc.On("RotateServiceAccountToken", mock.Anything, int64(1), int64(1), mock.MatchedBy(func(cmd *serviceaccounts.RotateServiceAccountTokenCommand) bool {
+					return cmd.SecondsToLive == tt.expectedLifetime &&
+						strings.HasPrefix(cmd.Name, "deploy-rotated-") && cmd.Name != tokens[0].Name &&
+						cmd.PreviousTokenExpires <= time.Now().Add(time.Hour).Unix()
+				})).Return(&apikey.APIKey{ID: 3}, nil)
+			}
+
+			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/1/tokens/%d/rotate", tt.tokenID), strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestRotatedTokenName(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	assert.Equal(t, "deploy-rotated-1700000000", rotatedTokenName("deploy", now))
+	assert.Equal(t, "deploy-rotated-1700000000", rotatedTokenName("deploy-rotated-1600000000", now))
+}
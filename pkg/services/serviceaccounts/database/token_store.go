@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -56,6 +57,7 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 			Key:              cmd.Key,
 			SecondsToLive:    cmd.SecondsToLive,
 			ServiceAccountID: &serviceAccountId,
+			Permissions:      cmd.Permissions,
 		}
 
 		key, err := s.apiKeyService.AddAPIKey(ctx, addKeyCmd)
@@ -75,6 +77,69 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 	})
 }
 
+// RotateServiceAccountToken adds a new token with the permissions of the rotated token to the service account
+// and shortens the lifetime of the rotated token so that both tokens work until cmd.PreviousTokenExpires.
+func (s *ServiceAccountsStoreImpl) RotateServiceAccountToken(ctx context.Context, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	var apiKey *apikey.APIKey
+
+	return apiKey, s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		previous := apikey.APIKey{}
+		if err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("id=? and org_id=? and service_account_id=?", tokenId, cmd.OrgId, serviceAccountId).Get(&previous)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenId, serviceAccountId)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if previous.IsRevoked != nil && *previous.IsRevoked {
+			return serviceaccounts.ErrCannotRotateRevokedToken.Errorf("service account token with id %d is revoked", tokenId)
+		}
+
+		key, err := s.AddServiceAccountToken(ctx, serviceAccountId, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          cmd.Name,
+			OrgId:         cmd.OrgId,
+			Key:           cmd.Key,
+			SecondsToLive: cmd.SecondsToLive,
+			Permissions:   previous.Permissions,
+		})
+		if err != nil {
+			return err
+		}
+
+		if previous.Expires == nil || *previous.Expires > cmd.PreviousTokenExpires {
+			if err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.Exec("UPDATE api_key SET expires = ? WHERE id=?", cmd.PreviousTokenExpires, previous.ID)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		apiKey = key
+		return nil
+	})
+}
+
+// ListExpiringTokens returns the service account tokens of all organizations which are neither
+// revoked nor expired and expire before the given time.
+func (s *ServiceAccountsStoreImpl) ListExpiringTokens(ctx context.Context, before time.Time) ([]apikey.APIKey, error) {
+	result := make([]apikey.APIKey, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("service_account_id IS NOT NULL").
+			Where("(is_revoked IS NULL OR is_revoked=?)", s.sqlStore.GetDialect().BooleanStr(false)).
+			Where("expires > ? and expires <= ?", time.Now().Unix(), before.Unix()).
+			Asc("expires").
+			Find(&result)
+	})
+	return result, err
+}
+
 func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
 	rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id=?"
 
@@ -3,10 +3,12 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
 )
@@ -176,3 +178,128 @@ func TestStore_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_RotateServiceAccountToken(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, userToCreate)
+
+	addToken := func(t *testing.T, name string, secondsToLive int64) *apikey.APIKey {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         sa.OrgID,
+			Key:           key.HashedKey,
+			SecondsToLive: secondsToLive,
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	rotate := func(t *testing.T, tokenID int64, previousTokenExpires int64) (*apikey.APIKey, error) {
+		key, err := apikeygen.New(sa.OrgID, t.Name())
+		require.NoError(t, err)
+		return store.RotateServiceAccountToken(context.Background(), sa.ID, tokenID, &serviceaccounts.RotateServiceAccountTokenCommand{
+			Name:                 t.Name() + "-rotated",
+			OrgId:                sa.OrgID,
+			Key:                  key.HashedKey,
+			SecondsToLive:        3600,
+			PreviousTokenExpires: previousTokenExpires,
+		})
+	}
+
+	getToken := func(t *testing.T, id int64) apikey.APIKey {
+		keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+			OrgID:            &sa.OrgID,
+			ServiceAccountID: &sa.ID,
+		})
+		require.NoError(t, err)
+		for _, k := range keys {
+			if k.ID == id {
+				return k
+			}
+		}
+		require.FailNow(t, "Key not found")
+		return apikey.APIKey{}
+	}
+
+	t.Run("shortens the lifetime of the previous token", func(t *testing.T) {
+		previous := addToken(t, t.Name(), 0)
+		overlap := time.Now().Add(time.Hour).Unix()
+
+		newKey, err := rotate(t, previous.ID, overlap)
+		require.NoError(t, err)
+		require.Equal(t, t.Name()+"-rotated", newKey.Name)
+		require.NotNil(t, getToken(t, newKey.ID).Expires)
+
+		expires := getToken(t, previous.ID).Expires
+		require.NotNil(t, expires)
+		require.Equal(t, overlap, *expires)
+	})
+
+	t.Run("keeps the permissions of the previous token", func(t *testing.T) {
+		permissions := []apikey.Permission{{Action: "dashboards:read", Scope: "folders:uid:abc"}}
+		key, err := apikeygen.New(sa.OrgID, t.Name())
+		require.NoError(t, err)
+		previous, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:        t.Name(),
+			OrgId:       sa.OrgID,
+			Key:         key.HashedKey,
+			Permissions: permissions,
+		})
+		require.NoError(t, err)
+		require.Equal(t, permissions, getToken(t, previous.ID).Permissions)
+
+		newKey, err := rotate(t, previous.ID, time.Now().Unix())
+		require.NoError(t, err)
+		require.Equal(t, permissions, getToken(t, newKey.ID).Permissions)
+	})
+
+	t.Run("keeps an earlier expiration of the previous token", func(t *testing.T) {
+		previous := addToken(t, t.Name(), 60)
+
+		_, err := rotate(t, previous.ID, time.Now().Add(time.Hour).Unix())
+		require.NoError(t, err)
+		require.Equal(t, *previous.Expires, *getToken(t, previous.ID).Expires)
+	})
+
+	t.Run("rejects revoked tokens", func(t *testing.T) {
+		previous := addToken(t, t.Name(), 0)
+		require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, previous.ID))
+
+		_, err := rotate(t, previous.ID, time.Now().Unix())
+		require.ErrorIs(t, err, serviceaccounts.ErrCannotRotateRevokedToken)
+	})
+
+	t.Run("rejects unknown tokens", func(t *testing.T) {
+		_, err := rotate(t, 9999, time.Now().Unix())
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+	})
+}
+
+func TestStore_ListExpiringTokens(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, userToCreate)
+
+	for name, secondsToLive := range map[string]int64{"no-expiry": 0, "soon": 3600, "later": 30 * 24 * 3600, "revoked": 60} {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         sa.OrgID,
+			Key:           key.HashedKey,
+			SecondsToLive: secondsToLive,
+		})
+		require.NoError(t, err)
+		if name == "revoked" {
+			require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, token.ID))
+		}
+	}
+
+	tokens, err := store.ListExpiringTokens(context.Background(), time.Now().Add(7*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, "soon", tokens[0].Name)
+}
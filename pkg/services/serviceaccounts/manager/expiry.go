@@ -0,0 +1,120 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	tokenExpiryWarningNamespace = "serviceaccounts.token-expiry-warning"
+	tmplTokenExpiring           = "service_account_token_expiring"
+)
+
+// checkExpiringTokens warns about expiring tokens from a single instance, so admins of a
+// high availability setup are not emailed once per instance.
+func (sa *ServiceAccountsService) checkExpiringTokens(ctx context.Context) {
+	err := sa.serverLock.LockAndExecute(ctx, "warn about expiring service account tokens", tokenExpiryLockInterval, func(ctx context.Context) {
+		if err := sa.warnExpiringTokens(ctx); err != nil {
+			sa.backgroundLog.Warn("Failed to warn about expiring tokens", "error", err.Error())
+		}
+	})
+
+	if err != nil {
+		sa.backgroundLog.Error("Failed to lock and execute the token expiry check", "error", err)
+	}
+}
+
+// warnExpiringTokens emails the admins of an organization about service account tokens that
+// expire within the configured warning window. Each token is only warned about once.
+func (sa *ServiceAccountsService) warnExpiringTokens(ctx context.Context) error {
+	tokens, err := sa.store.ListExpiringTokens(ctx, time.Now().Add(sa.tokenExpiryWarning))
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if err := sa.warnExpiringToken(ctx, token); err != nil {
+			if errors.Is(err, notifications.ErrSmtpNotEnabled) {
+				return err
+			}
+			sa.backgroundLog.Warn("Failed to warn about expiring token", "tokenID", token.ID, "orgID", token.OrgID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (sa *ServiceAccountsService) warnExpiringToken(ctx context.Context, token apikey.APIKey) error {
+	if token.Expires == nil || token.ServiceAccountId == nil {
+		return nil
+	}
+
+	_, sent, err := sa.kvStore.Get(ctx, token.OrgID, tokenExpiryWarningNamespace, strconv.FormatInt(token.ID, 10))
+	if err != nil || sent {
+		return err
+	}
+
+	serviceAccount, err := sa.store.RetrieveServiceAccount(ctx, token.OrgID, *token.ServiceAccountId)
+	if err != nil {
+		return err
+	}
+
+	o, err := sa.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: token.OrgID})
+	if err != nil {
+		return err
+	}
+
+	users, err := sa.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{OrgID: token.OrgID, DontEnforceAccessControl: true})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Unix(*token.Expires, 0).UTC()
+	for _, u := range users {
+		if u.Role != string(org.RoleAdmin) || u.IsDisabled || u.Email == "" {
+			continue
+		}
+
+		name := u.Name
+		if name == "" {
+			name = u.Login
+		}
+
+		if err := sa.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+			To:       []string{u.Email},
+			Template: tmplTokenExpiring,
+			Data: map[string]any{
+				"Name":               name,
+				"OrgName":            o.Name,
+				"ServiceAccountName": serviceAccount.Name,
+				"TokenName":          token.Name,
+				"ExpiresAt":          expiresAt.Format(time.RFC1123),
+				"LinkUrl":            fmt.Sprintf("%sorg/serviceaccounts/%d", sa.appURL, serviceAccount.Id),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	sa.markExpiryWarningSent(ctx, token.OrgID, token.ID)
+	return nil
+}
+
+func (sa *ServiceAccountsService) markExpiryWarningSent(ctx context.Context, orgID, tokenID int64) {
+	if err := sa.kvStore.Set(ctx, orgID, tokenExpiryWarningNamespace, strconv.FormatInt(tokenID, 10), time.Now().UTC().Format(time.RFC3339)); err != nil {
+		sa.log.Warn("Failed to store token expiry warning", "tokenID", tokenID, "orgID", orgID, "error", err)
+	}
+}
+
+func (sa *ServiceAccountsService) forgetExpiryWarning(ctx context.Context, orgID, tokenID int64) {
+	if err := sa.kvStore.Del(ctx, orgID, tokenExpiryWarningNamespace, strconv.FormatInt(tokenID, 10)); err != nil {
+		sa.log.Warn("Failed to delete token expiry warning", "tokenID", tokenID, "orgID", orgID, "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+func TestServiceAccountsService_WarnExpiringTokens(t *testing.T) {
+	setup := func() (*ServiceAccountsService, *FakeServiceAccountStore, *notifications.NotificationServiceMock) {
+		storeMock := newServiceAccountStoreFake()
+		storeMock.ExpectedServiceAccountProfileDTO = &serviceaccounts.ServiceAccountProfileDTO{Id: 2, Name: "robot"}
+		emailSender := notifications.MockNotificationService()
+		orgService := orgtest.NewOrgServiceFake()
+		orgService.ExpectedOrg = &org.Org{ID: 1, Name: "Main Org."}
+		orgService.ExpectedOrgUsers = []*org.OrgUserDTO{
+			{Login: "admin", Email: "admin@example.com", Role: string(org.RoleAdmin)},
+			{Login: "editor", Email: "editor@example.com", Role: string(org.RoleEditor)},
+		}
+
+		svc := &ServiceAccountsService{
+			store:              storeMock,
+			log:                log.New("test"),
+			backgroundLog:      log.New("background.test"),
+			kvStore:            kvstore.NewFakeKVStore(),
+			orgService:         orgService,
+			emailSender:        emailSender,
+			appURL:             "http://localhost:3000/",
+			tokenExpiryWarning: 7 * 24 * time.Hour,
+		}
+		return svc, storeMock, emailSender
+	}
+
+	expires := time.Now().Add(time.Hour).Unix()
+	saID := int64(2)
+	token := apikey.APIKey{ID: 10, OrgID: 1, Name: "deploy", Expires: &expires, ServiceAccountId: &saID}
+
+	t.Run("should email org admins once", func(t *testing.T) {
+		svc, storeMock, emailSender := setup()
+		storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+
+		sent := 0
+		emailSender.EmailHandler = func(ctx context.Context, cmd *notifications.SendEmailCommand) error {
+			sent++
+			return nil
+		}
+
+		require.NoError(t, svc.warnExpiringTokens(context.Background()))
+		require.Equal(t, 1, sent)
+		require.Equal(t, []string{"admin@example.com"}, emailSender.Email.To)
+		require.Equal(t, tmplTokenExpiring, emailSender.Email.Template)
+		require.Equal(t, "deploy", emailSender.Email.Data["TokenName"])
+		require.Equal(t, "robot", emailSender.Email.Data["ServiceAccountName"])
+		require.Equal(t, "http://localhost:3000/org/serviceaccounts/2", emailSender.Email.Data["LinkUrl"])
+
+		require.NoError(t, svc.warnExpiringTokens(context.Background()))
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("should only check from one instance", func(t *testing.T) {
+		lock := serverlock.ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+
+		sent := 0
+		for i := 0; i < 2; i++ {
+			// Each instance has its own store so only the lock prevents duplicate emails.
+			svc, storeMock, emailSender := setup()
+			svc.serverLock = lock
+			storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+			emailSender.EmailHandler = func(ctx context.Context, cmd *notifications.SendEmailCommand) error {
+				sent++
+				return nil
+			}
+
+			svc.checkExpiringTokens(context.Background())
+		}
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("should not warn about rotated tokens", func(t *testing.T) {
+		svc, storeMock, emailSender := setup()
+		storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 11}
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), saID, token.ID, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1})
+		require.NoError(t, err)
+
+		storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+		require.NoError(t, svc.warnExpiringTokens(context.Background()))
+		require.Empty(t, emailSender.Email.To)
+	})
+
+	t.Run("should retry when sending fails", func(t *testing.T) {
+		svc, storeMock, emailSender := setup()
+		storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+		emailSender.ShouldError = notifications.ErrSmtpNotEnabled
+
+		require.ErrorIs(t, svc.warnExpiringTokens(context.Background()), notifications.ErrSmtpNotEnabled)
+
+		_, sent, err := svc.kvStore.Get(context.Background(), 1, tokenExpiryWarningNamespace, "10")
+		require.NoError(t, err)
+		require.False(t, sent)
+	})
+}
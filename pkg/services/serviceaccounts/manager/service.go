@@ -8,9 +8,11 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
@@ -23,6 +25,9 @@ import (
 const (
 	metricsCollectionInterval = time.Minute * 30
 	defaultSecretScanInterval = time.Minute * 5
+	tokenExpiryCheckInterval  = time.Hour
+	// Slightly shorter than the check interval so a ticker firing early does not skip a check.
+	tokenExpiryLockInterval = tokenExpiryCheckInterval - 5*time.Minute
 )
 
 type ServiceAccountsService struct {
@@ -30,9 +35,16 @@ type ServiceAccountsService struct {
 	log               log.Logger
 	backgroundLog     log.Logger
 	secretScanService secretscan.Checker
+	serverLock        *serverlock.ServerLockService
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
+
+	kvStore            kvstore.KVStore
+	orgService         org.Service
+	emailSender        notifications.EmailSender
+	appURL             string
+	tokenExpiryWarning time.Duration
 }
 
 func ProvideServiceAccountsService(
@@ -44,6 +56,8 @@ func ProvideServiceAccountsService(
 	userService user.Service,
 	orgService org.Service,
 	accesscontrolService accesscontrol.Service,
+	emailSender notifications.EmailSender,
+	serverLock *serverlock.ServerLockService,
 ) (*ServiceAccountsService, error) {
 	serviceAccountsStore := database.ProvideServiceAccountsStore(
 		cfg,
@@ -57,6 +71,13 @@ func ProvideServiceAccountsService(
 		store:         serviceAccountsStore,
 		log:           log.New("serviceaccounts"),
 		backgroundLog: log.New("serviceaccounts.background"),
+		serverLock:    serverLock,
+
+		kvStore:            kvStore,
+		orgService:         orgService,
+		emailSender:        emailSender,
+		appURL:             cfg.AppURL,
+		tokenExpiryWarning: cfg.SATokenExpiryWarning,
 	}
 
 	if err := RegisterRoles(accesscontrolService); err != nil {
@@ -70,7 +91,7 @@ func ProvideServiceAccountsService(
 		Key("interval").MustDuration(defaultSecretScanInterval)
 	if s.secretScanEnabled {
 		var errSecret error
-		s.secretScanService, errSecret = secretscan.NewService(s.store, cfg)
+		s.secretScanService, errSecret = secretscan.NewService(s.store, store, cfg)
 		if errSecret != nil {
 			s.secretScanEnabled = false
 			s.log.Warn("Failed to initialize secret scan service. secret scan is disabled",
@@ -112,6 +133,17 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	tokenExpiryTicker := time.NewTicker(tokenExpiryCheckInterval)
+
+	if sa.tokenExpiryWarning <= 0 {
+		tokenExpiryTicker.Stop()
+	} else {
+		sa.backgroundLog.Debug("Enabled token expiry warnings and executing first check")
+		sa.checkExpiringTokens(ctx)
+
+		defer tokenExpiryTicker.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -134,6 +166,10 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-tokenExpiryTicker.C:
+			sa.backgroundLog.Debug("Checking for expiring tokens")
+
+			sa.checkExpiringTokens(ctx)
 		}
 	}
 }
@@ -213,6 +249,9 @@ func (sa *ServiceAccountsService) AddServiceAccountToken(ctx context.Context, se
 	if err := validServiceAccountID(serviceAccountID); err != nil {
 		return nil, err
 	}
+	if err := validTokenPermissions(query.Permissions); err != nil {
+		return nil, err
+	}
 	return sa.store.AddServiceAccountToken(ctx, serviceAccountID, query)
 }
 
@@ -226,7 +265,32 @@ func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context,
 	if err := validServiceAccountTokenID(tokenID); err != nil {
 		return err
 	}
-	return sa.store.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID)
+	if err := sa.store.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID); err != nil {
+		return err
+	}
+	sa.forgetExpiryWarning(ctx, orgID, tokenID)
+	return nil
+}
+
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(cmd.OrgId); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+
+	token, err := sa.store.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rotated token expires on purpose, there is no need to warn about it.
+	sa.markExpiryWarningSent(ctx, cmd.OrgId, tokenID)
+	return token, nil
 }
 
 func (sa *ServiceAccountsService) MigrateApiKey(ctx context.Context, orgID, keyID int64) error {
@@ -263,6 +327,19 @@ func validServiceAccountTokenID(tokenID int64) error {
 	}
 	return nil
 }
+
+func validTokenPermissions(permissions []apikey.Permission) error {
+	for _, p := range permissions {
+		if p.Action == "" {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("token permission without action has been specified")
+		}
+		if p.Scope != "" && !accesscontrol.ValidateScope(p.Scope) {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("invalid scope %q has been specified for action %q", p.Scope, p.Action)
+		}
+	}
+	return nil
+}
+
 func validAPIKeyID(apiKeyID int64) error {
 	if apiKeyID == 0 {
 		return serviceaccounts.ErrServiceAccountInvalidAPIKeyID.Errorf("invalid API key ID 0 has been specified")
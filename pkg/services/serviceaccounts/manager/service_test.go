@@ -3,6 +3,7 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -87,6 +88,11 @@ func (f *FakeServiceAccountStore) ListTokens(ctx context.Context, query *service
 	return f.ExpectedAPIKeys, f.ExpectedError
 }
 
+// ListExpiringTokens is a fake listing expiring tokens.
+func (f *FakeServiceAccountStore) ListExpiringTokens(ctx context.Context, before time.Time) ([]apikey.APIKey, error) {
+	return f.ExpectedAPIKeys, f.ExpectedError
+}
+
 // RevokeServiceAccountToken is a fake revoking a service account token.
 func (f *FakeServiceAccountStore) RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
 	return f.ExpectedError
@@ -97,6 +103,11 @@ func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, se
 	return f.ExpectedAPIKey, f.ExpectedError
 }
 
+// RotateServiceAccountToken is a fake rotating a service account token.
+func (f *FakeServiceAccountStore) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedError
+}
+
 // DeleteServiceAccountToken is a fake deleting a service account token.
 func (f *FakeServiceAccountStore) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
 	return f.ExpectedError
@@ -117,7 +128,7 @@ func (f *SecretsCheckerFake) CheckTokens(ctx context.Context) error {
 
 func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 	storeMock := newServiceAccountStoreFake()
-	svc := ServiceAccountsService{storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0}
+	svc := ServiceAccountsService{store: storeMock, log: log.New("test"), backgroundLog: log.New("background.test"), secretScanService: &SecretsCheckerFake{}}
 	testOrgId := 1
 
 	t.Run("should create service account", func(t *testing.T) {
@@ -144,3 +155,29 @@ func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestProvideServiceAccount_AddServiceAccountToken(t *testing.T) {
+	storeMock := newServiceAccountStoreFake()
+	svc := ServiceAccountsService{store: storeMock, log: log.New("test"), backgroundLog: log.New("background.test")}
+
+	t.Run("should add token restricted to permissions", func(t *testing.T) {
+		storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 1}
+		_, err := svc.AddServiceAccountToken(context.Background(), 1, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:        "ci",
+			OrgId:       1,
+			Permissions: []apikey.Permission{{Action: "dashboards:read", Scope: "folders:uid:abc"}, {Action: "users:read"}},
+		})
+		require.NoError(t, err)
+	})
+
+	for _, permission := range []apikey.Permission{{Scope: "folders:uid:abc"}, {Action: "dashboards:read", Scope: "folders:uid*"}} {
+		t.Run("should reject invalid permission", func(t *testing.T) {
+			_, err := svc.AddServiceAccountToken(context.Background(), 1, &serviceaccounts.AddServiceAccountTokenCommand{
+				Name:        "ci",
+				OrgId:       1,
+				Permissions: []apikey.Permission{permission},
+			})
+			require.ErrorIs(t, err, serviceaccounts.ErrInvalidTokenPermissions)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/models/roletype"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -41,6 +42,8 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrInvalidTokenPermissions           = errutil.ValidationFailed("serviceaccounts.ErrInvalidTokenPermissions", errutil.WithPublicMessage("invalid service account token permissions"))
+	ErrCannotRotateRevokedToken          = errutil.BadRequest("serviceaccounts.ErrCannotRotateRevokedToken", errutil.WithPublicMessage("revoked service account tokens can not be rotated"))
 )
 
 type MigrationResult struct {
@@ -106,6 +109,28 @@ type AddServiceAccountTokenCommand struct {
 	OrgId         int64  `json:"-"`
 	Key           string `json:"-"`
 	SecondsToLive int64  `json:"secondsToLive"`
+	// Optional subset of the service account permissions the token is restricted to.
+	Permissions []apikey.Permission `json:"permissions,omitempty"`
+}
+
+// swagger:model
+type RotateServiceAccountTokenForm struct {
+	// Lifetime of the new token. Defaults to the lifetime of the rotated token.
+	// example: 86400
+	SecondsToLive *int64 `json:"secondsToLive"`
+	// How long the rotated token keeps working. Defaults to the configured rotation overlap.
+	// example: 3600
+	OverlapSeconds *int64 `json:"overlapSeconds"`
+}
+
+type RotateServiceAccountTokenCommand struct {
+	Name          string
+	OrgId         int64
+	Key           string
+	SecondsToLive int64
+	// PreviousTokenExpires is the unix time at which the rotated token stops working.
+	// Tokens expiring earlier keep their expiration.
+	PreviousTokenExpires int64
 }
 
 type SearchOrgServiceAccountsQuery struct {
@@ -123,6 +123,22 @@ func (s *ServiceAccountsProxy) ListTokens(ctx context.Context, query *serviceacc
 	return s.proxiedService.ListTokens(ctx, query)
 }
 
+func (s *ServiceAccountsProxy) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if s.isProxyEnabled {
+		sa, err := s.proxiedService.RetrieveServiceAccount(ctx, cmd.OrgId, serviceAccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExternalServiceAccount(sa.Login) {
+			s.log.Error("unable to rotate tokens for external service accounts", "serviceAccountID", serviceAccountID)
+			return nil, extsvcaccounts.ErrCannotCreateToken
+		}
+	}
+
+	return s.proxiedService.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
+
 func (s *ServiceAccountsProxy) MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error {
 	return s.proxiedService.MigrateApiKey(ctx, orgID, keyId)
 }
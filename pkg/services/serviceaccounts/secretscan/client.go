@@ -69,6 +69,10 @@ func newClient(url, version string, dev bool) (*client, error) {
 	}, nil
 }
 
+func (c *client) Name() string {
+	return remoteDetectorName
+}
+
 // checkTokens checks if any leaked tokens exist.
 // Returns list of leaked tokens.
 func (c *client) CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error) {
@@ -0,0 +1,110 @@
+package secretscan
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	remoteDetectorName = "remote"
+	localDetectorName  = "local"
+
+	localTokenType = "grafana_service_account_token"
+)
+
+// Detector finds the leaked tokens among the given service account token hashes.
+type Detector interface {
+	Name() string
+	CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error)
+}
+
+// Source provides content to search for leaked service account tokens.
+type Source interface {
+	// Scan calls fn with the content found at each location of the source.
+	Scan(ctx context.Context, fn func(location string, content []byte) error) error
+}
+
+var tokenPattern = regexp.MustCompile(satokengen.GrafanaPrefix + `sa_[A-Za-z0-9]{32}_[0-9a-f]{8}`)
+
+// localDetector searches sources available without network access,
+// e.g. directories on disk or the dashboards stored in Grafana, for service account tokens.
+type localDetector struct {
+	sources []Source
+	logger  log.Logger
+}
+
+func newLocalDetector(sources ...Source) *localDetector {
+	return &localDetector{
+		sources: sources,
+		logger:  log.New("secretscan.local"),
+	}
+}
+
+func (d *localDetector) Name() string {
+	return localDetectorName
+}
+
+func (d *localDetector) CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error) {
+	active := make(map[string]bool, len(keyHashes))
+	for _, hash := range keyHashes {
+		active[hash] = true
+	}
+
+	// hashing is expensive, only do it once per candidate
+	checked := make(map[string]bool)
+	tokens := make([]Token, 0)
+
+	for _, source := range d.sources {
+		err := source.Scan(ctx, func(location string, content []byte) error {
+			for _, match := range tokenPattern.FindAll(content, -1) {
+				candidate := string(match)
+				if checked[candidate] {
+					continue
+				}
+				checked[candidate] = true
+
+				hash, ok := hashToken(candidate)
+				if !ok || !active[hash] {
+					continue
+				}
+
+				tokens = append(tokens, Token{
+					Type:       localTokenType,
+					URL:        location,
+					Hash:       hash,
+					ReportedAt: time.Now().UTC().Format(time.RFC3339),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			// keep scanning the other sources, e.g. when a configured directory was removed
+			d.logger.Warn("Failed to scan source for leaked tokens", "error", err)
+		}
+	}
+
+	return tokens, nil
+}
+
+// hashToken returns the hash stored for a service account token,
+// or false if the candidate is not a valid token.
+func hashToken(candidate string) (string, bool) {
+	key, err := satokengen.Decode(candidate)
+	if err != nil {
+		return "", false
+	}
+
+	hash, err := key.Hash()
+	if err != nil {
+		return "", false
+	}
+
+	return hash, true
+}
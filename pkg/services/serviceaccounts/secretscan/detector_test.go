@@ -0,0 +1,89 @@
+package secretscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/satokengen"
+)
+
+type staticSource map[string]string
+
+func (s staticSource) Scan(ctx context.Context, fn func(location string, content []byte) error) error {
+	for location, content := range s {
+		if err := fn(location, []byte(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestLocalDetector_CheckTokens(t *testing.T) {
+	ctx := context.Background()
+
+	leaked, err := satokengen.New("sa")
+	require.NoError(t, err)
+	other, err := satokengen.New("sa")
+	require.NoError(t, err)
+
+	t.Run("finds active tokens in a directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "config", ".git"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config", "provisioning.yaml"),
+			[]byte("token: "+leaked.ClientSecret+"\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config", ".git", "ORIG_HEAD"),
+			[]byte(other.ClientSecret), 0o600))
+
+		detector := newLocalDetector(&dirSource{path: dir})
+		tokens, err := detector.CheckTokens(ctx, []string{leaked.HashedKey, other.HashedKey})
+		require.NoError(t, err)
+
+		require.Len(t, tokens, 1)
+		assert.Equal(t, leaked.HashedKey, tokens[0].Hash)
+		assert.Equal(t, filepath.Join(dir, "config", "provisioning.yaml"), tokens[0].URL)
+		assert.Equal(t, localTokenType, tokens[0].Type)
+		assert.NotEmpty(t, tokens[0].ReportedAt)
+	})
+
+	t.Run("ignores tokens that are not active", func(t *testing.T) {
+		detector := newLocalDetector(staticSource{"a": leaked.ClientSecret})
+		tokens, err := detector.CheckTokens(ctx, []string{other.HashedKey})
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("ignores tokens with an invalid checksum", func(t *testing.T) {
+		tampered := leaked.ClientSecret[:len(leaked.ClientSecret)-8] + "00000000"
+		detector := newLocalDetector(staticSource{"a": tampered})
+		tokens, err := detector.CheckTokens(ctx, []string{leaked.HashedKey})
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("reports a token once across locations", func(t *testing.T) {
+		detector := newLocalDetector(
+			staticSource{"a": leaked.ClientSecret},
+			staticSource{"b": "Authorization: Bearer " + leaked.ClientSecret},
+		)
+		tokens, err := detector.CheckTokens(ctx, []string{leaked.HashedKey})
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "a", tokens[0].URL)
+	})
+
+	t.Run("keeps scanning when a directory is missing", func(t *testing.T) {
+		detector := newLocalDetector(
+			&dirSource{path: filepath.Join(t.TempDir(), "missing")},
+			staticSource{"a": leaked.ClientSecret},
+		)
+		tokens, err := detector.CheckTokens(ctx, []string{leaked.HashedKey})
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, "a", tokens[0].URL)
+	})
+}
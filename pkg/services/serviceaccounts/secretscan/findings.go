@@ -0,0 +1,51 @@
+package secretscan
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// Finding records a leaked service account token.
+type Finding struct {
+	ID               int64     `xorm:"pk autoincr 'id'"`
+	OrgID            int64     `xorm:"org_id"`
+	TokenID          int64     `xorm:"token_id"`
+	ServiceAccountID int64     `xorm:"service_account_id"`
+	TokenName        string    `xorm:"token_name"`
+	Detector         string    `xorm:"detector"`
+	Location         string    `xorm:"location"`
+	Revoked          bool      `xorm:"revoked"`
+	Created          time.Time `xorm:"created"`
+}
+
+func (Finding) TableName() string { return "secret_scan_finding" }
+
+type FindingStore interface {
+	// HasFinding returns true if the token has already been found at the location.
+	HasFinding(ctx context.Context, tokenID int64, location string) (bool, error)
+	AddFinding(ctx context.Context, finding *Finding) error
+}
+
+type sqlFindingStore struct {
+	db db.DB
+}
+
+func (s *sqlFindingStore) HasFinding(ctx context.Context, tokenID int64, location string) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("token_id = ? AND location = ?", tokenID, location).Exist(&Finding{})
+		return err
+	})
+	return exists, err
+}
+
+func (s *sqlFindingStore) AddFinding(ctx context.Context, finding *Finding) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		finding.Created = time.Now().UTC()
+		_, err := sess.Insert(finding)
+		return err
+	})
+}
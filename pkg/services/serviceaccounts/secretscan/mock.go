@@ -45,6 +45,10 @@ type MockSecretScanClient struct {
 	checkCalls []any
 }
 
+func (m *MockSecretScanClient) Name() string {
+	return "mock"
+}
+
 func (m *MockSecretScanClient) CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error) {
 	m.checkCalls = append(m.checkCalls, keyHashes)
 
@@ -64,3 +68,24 @@ func (m *MockSecretScanNotifier) Notify(ctx context.Context,
 
 	return m.err
 }
+
+type MockFindingStore struct {
+	findings []*Finding
+	err      error
+}
+
+func (m *MockFindingStore) HasFinding(ctx context.Context, tokenID int64, location string) (bool, error) {
+	for _, finding := range m.findings {
+		if finding.TokenID == tokenID && finding.Location == location {
+			return true, m.err
+		}
+	}
+
+	return false, m.err
+}
+
+func (m *MockFindingStore) AddFinding(ctx context.Context, finding *Finding) error {
+	m.findings = append(m.findings, finding)
+
+	return m.err
+}
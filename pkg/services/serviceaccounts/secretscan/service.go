@@ -2,9 +2,12 @@ package secretscan
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
@@ -17,10 +20,6 @@ type Checker interface {
 	CheckTokens(ctx context.Context) error
 }
 
-type CheckerClient interface {
-	CheckTokens(ctx context.Context, keyHashes []string) ([]Token, error)
-}
-
 type WebHookClient interface {
 	Notify(ctx context.Context, token *Token, tokenName string, revoked bool) error
 }
@@ -33,22 +32,49 @@ type SATokenRetriever interface {
 // Secret Scan Service is grafana's service for checking leaked keys.
 type Service struct {
 	store         SATokenRetriever
-	client        CheckerClient
+	detectors     []Detector
+	findings      FindingStore
 	webHookClient WebHookClient
 	logger        log.Logger
 	webHookNotify bool
 	revoke        bool // whether to revoke leaked tokens
 }
 
-func NewService(store SATokenRetriever, cfg *setting.Cfg) (*Service, error) {
-	secretscanBaseURL := cfg.SectionWithEnvOverrides("secretscan").Key("base_url").MustString(defaultURL)
+func NewService(store SATokenRetriever, sqlStore db.DB, cfg *setting.Cfg) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("secretscan")
 	// URL to send outgoing webhook when a token is leaked.
-	oncallURL := cfg.SectionWithEnvOverrides("secretscan").Key("oncall_url").MustString("")
-	revoke := cfg.SectionWithEnvOverrides("secretscan").Key("revoke").MustBool(true)
+	oncallURL := section.Key("oncall_url").MustString("")
+	revoke := section.Key("revoke").MustBool(true)
 
-	client, err := newClient(secretscanBaseURL, cfg.BuildVersion, cfg.Env == setting.Dev)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create secretscan client: %w", err)
+	detectors := make([]Detector, 0, 2)
+	for _, name := range strings.Split(section.Key("detectors").MustString(remoteDetectorName), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case remoteDetectorName:
+			secretscanBaseURL := section.Key("base_url").MustString(defaultURL)
+			client, err := newClient(secretscanBaseURL, cfg.BuildVersion, cfg.Env == setting.Dev)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create secretscan client: %w", err)
+			}
+			detectors = append(detectors, client)
+		case localDetectorName:
+			sources := make([]Source, 0)
+			for _, path := range strings.Split(section.Key("local_paths").MustString(""), ",") {
+				if path = strings.TrimSpace(path); path != "" {
+					sources = append(sources, &dirSource{path: path})
+				}
+			}
+			if section.Key("local_scan_dashboards").MustBool(true) {
+				sources = append(sources, &dashboardSource{db: sqlStore, appURL: cfg.AppURL})
+			}
+			if section.Key("local_scan_annotations").MustBool(true) {
+				sources = append(sources, &annotationSource{db: sqlStore})
+			}
+			detectors = append(detectors, newLocalDetector(sources...))
+		default:
+			return nil, fmt.Errorf("unknown secretscan detector %q", name)
+		}
 	}
 
 	var webHookClient WebHookClient
@@ -62,7 +88,8 @@ func NewService(store SATokenRetriever, cfg *setting.Cfg) (*Service, error) {
 
 	return &Service{
 		store:         store,
-		client:        client,
+		detectors:     detectors,
+		findings:      &sqlFindingStore{db: sqlStore},
 		webHookClient: webHookClient,
 		logger:        log.New("secretscan"),
 		webHookNotify: oncallURL != "",
@@ -106,41 +133,83 @@ func (s *Service) CheckTokens(ctx context.Context) error {
 		return nil
 	}
 
-	// Check if any leaked tokens exist.
-	secretscanTokens, err := s.client.CheckTokens(ctx, hashes)
+	// A failing detector must not prevent the others from running.
+	var detectorErrs error
+	for _, detector := range s.detectors {
+		// Check if any leaked tokens exist.
+		secretscanTokens, err := detector.CheckTokens(ctx, hashes)
+		if err != nil {
+			detectorErrs = errors.Join(detectorErrs, fmt.Errorf("failed to check tokens with %s detector: %w", detector.Name(), err))
+			continue
+		}
+
+		for _, secretscanToken := range secretscanTokens {
+			secretscanToken := secretscanToken
+			leakedToken, ok := hashMap[secretscanToken.Hash]
+			if !ok {
+				continue
+			}
+
+			s.handleLeakedToken(ctx, detector.Name(), &secretscanToken, leakedToken)
+		}
+	}
+
+	return detectorErrs
+}
+
+// handleLeakedToken revokes, notifies about and records a leaked token.
+// A token that could not be revoked is not recorded, so the next check tries again.
+// Could be done in bulk but we don't expect more than 1 or 2 tokens to be leaked per check.
+func (s *Service) handleLeakedToken(ctx context.Context, detector string, secretscanToken *Token, leakedToken apikey.APIKey) {
+	// Tokens that are only flagged stay active, only notify about them once per location.
+	known, err := s.findings.HasFinding(ctx, leakedToken.ID, secretscanToken.URL)
 	if err != nil {
-		return fmt.Errorf("failed to check tokens: %w", err)
+		s.logger.Warn("Failed to look up leaked token finding", "error", err, "token_id", leakedToken.ID)
+	}
+	if known {
+		s.logger.Debug("Leaked token already reported", "url", secretscanToken.URL, "token_id", leakedToken.ID)
+		return
 	}
 
-	// Revoke leaked tokens.
-	// Could be done in bulk but we don't expect more than 1 or 2 tokens to be leaked per check.
-	for _, secretscanToken := range secretscanTokens {
-		secretscanToken := secretscanToken
-		leakedToken := hashMap[secretscanToken.Hash]
-
-		if s.revoke {
-			if err := s.store.RevokeServiceAccountToken(
-				ctx, leakedToken.OrgID, *leakedToken.ServiceAccountId, leakedToken.ID); err != nil {
-				s.logger.Error("Failed to delete leaked token. Revoke manually.",
-					"error", err, "url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
-					"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
-					"serviceAccount", *leakedToken.ServiceAccountId)
-			}
+	revoked := false
+	if s.revoke {
+		if err := s.store.RevokeServiceAccountToken(
+			ctx, leakedToken.OrgID, *leakedToken.ServiceAccountId, leakedToken.ID); err != nil {
+			s.logger.Error("Failed to delete leaked token. Revoke manually.",
+				"error", err, "url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
+				"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
+				"serviceAccount", *leakedToken.ServiceAccountId)
+		} else {
+			revoked = true
 		}
+	}
 
-		if s.webHookNotify {
-			if err := s.webHookClient.Notify(ctx, &secretscanToken, leakedToken.Name, s.revoke); err != nil {
-				s.logger.Warn("Failed to call token leak webhook", "error", err)
-			}
+	if s.webHookNotify {
+		if err := s.webHookClient.Notify(ctx, secretscanToken, leakedToken.Name, revoked); err != nil {
+			s.logger.Warn("Failed to call token leak webhook", "error", err)
 		}
+	}
+
+	s.logger.Warn("Found leaked token",
+		"detector", detector, "url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
+		"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
+		"serviceAccount", *leakedToken.ServiceAccountId, "revoked", revoked)
 
-		s.logger.Warn("Found leaked token",
-			"url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
-			"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
-			"serviceAccount", *leakedToken.ServiceAccountId, "revoked", s.revoke)
+	if s.revoke && !revoked {
+		return
 	}
 
-	return nil
+	if err := s.findings.AddFinding(ctx, &Finding{
+		OrgID:            leakedToken.OrgID,
+		TokenID:          leakedToken.ID,
+		ServiceAccountID: *leakedToken.ServiceAccountId,
+		TokenName:        leakedToken.Name,
+		Detector:         detector,
+		Location:         secretscanToken.URL,
+		Revoked:          revoked,
+	}); err != nil {
+		s.logger.Warn("Failed to record leaked token finding", "error", err, "token_id", leakedToken.ID)
+	}
 }
 
 // filterCheckableTokens returns a list of tokens that can be checked and a map of tokens to their hashes.
@@ -2,6 +2,7 @@ package secretscan
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,7 +139,8 @@ func TestService_CheckTokens(t *testing.T) {
 
 			service := &Service{
 				store:         tokenStore,
-				client:        client,
+				detectors:     []Detector{client},
+				findings:      &MockFindingStore{},
 				webHookClient: notifier,
 				logger:        log.New("secretscan"),
 				webHookNotify: tt.notify,
@@ -170,3 +172,115 @@ func TestService_CheckTokens(t *testing.T) {
 		})
 	}
 }
+
+func TestService_CheckTokens_Findings(t *testing.T) {
+	ctx := context.Background()
+	falseBool := false
+
+	tokenStore := &MockTokenRetriever{keys: []apikey.APIKey{{
+		ID:               1,
+		OrgID:            2,
+		Name:             "test",
+		Key:              "test-hash-1",
+		Role:             "Viewer",
+		ServiceAccountId: new(int64),
+		IsRevoked:        &falseBool,
+	}}}
+	client := &MockSecretScanClient{tokens: []Token{{Hash: "test-hash-1", URL: "https://example.com/leak"}}}
+	notifier := &MockSecretScanNotifier{}
+	findings := &MockFindingStore{}
+
+	service := &Service{
+		store:         tokenStore,
+		detectors:     []Detector{client},
+		findings:      findings,
+		webHookClient: notifier,
+		logger:        log.New("secretscan"),
+		webHookNotify: true,
+		revoke:        false,
+	}
+
+	require.NoError(t, service.CheckTokens(ctx))
+	require.Len(t, findings.findings, 1)
+	assert.Equal(t, int64(1), findings.findings[0].TokenID)
+	assert.Equal(t, "mock", findings.findings[0].Detector)
+	assert.Equal(t, "https://example.com/leak", findings.findings[0].Location)
+	assert.False(t, findings.findings[0].Revoked)
+	assert.Len(t, notifier.notifyCalls, 1)
+
+	// a token that is only flagged is not reported again for the same location
+	require.NoError(t, service.CheckTokens(ctx))
+	assert.Len(t, findings.findings, 1)
+	assert.Len(t, notifier.notifyCalls, 1)
+}
+
+func TestService_CheckTokens_RevokeFailure(t *testing.T) {
+	ctx := context.Background()
+	falseBool := false
+
+	tokenStore := &MockTokenRetriever{keys: []apikey.APIKey{{
+		ID:               1,
+		OrgID:            2,
+		Name:             "test",
+		Key:              "test-hash-1",
+		Role:             "Viewer",
+		ServiceAccountId: new(int64),
+		IsRevoked:        &falseBool,
+	}}, errRevoke: errors.New("revoke failed")}
+	client := &MockSecretScanClient{tokens: []Token{{Hash: "test-hash-1", URL: "https://example.com/leak"}}}
+	notifier := &MockSecretScanNotifier{}
+	findings := &MockFindingStore{}
+
+	service := &Service{
+		store:         tokenStore,
+		detectors:     []Detector{client},
+		findings:      findings,
+		webHookClient: notifier,
+		logger:        log.New("secretscan"),
+		webHookNotify: true,
+		revoke:        true,
+	}
+
+	require.NoError(t, service.CheckTokens(ctx))
+	assert.Empty(t, findings.findings)
+	require.Len(t, notifier.notifyCalls, 1)
+	assert.Equal(t, false, notifier.notifyCalls[0][2])
+
+	// the token is still active, the next check tries to revoke it again
+	tokenStore.errRevoke = nil
+	require.NoError(t, service.CheckTokens(ctx))
+	assert.Len(t, tokenStore.revokeCalls, 2)
+	require.Len(t, findings.findings, 1)
+	assert.True(t, findings.findings[0].Revoked)
+	assert.Equal(t, true, notifier.notifyCalls[1][2])
+}
+
+func TestService_CheckTokens_DetectorFailure(t *testing.T) {
+	ctx := context.Background()
+	falseBool := false
+
+	tokenStore := &MockTokenRetriever{keys: []apikey.APIKey{{
+		ID:               1,
+		OrgID:            2,
+		Name:             "test",
+		Key:              "test-hash-1",
+		Role:             "Viewer",
+		ServiceAccountId: new(int64),
+		IsRevoked:        &falseBool,
+	}}}
+	failing := &MockSecretScanClient{err: errors.New("unavailable")}
+	client := &MockSecretScanClient{tokens: []Token{{Hash: "test-hash-1", URL: "https://example.com/leak"}}}
+
+	service := &Service{
+		store:     tokenStore,
+		detectors: []Detector{failing, client},
+		findings:  &MockFindingStore{},
+		logger:    log.New("secretscan"),
+		revoke:    true,
+	}
+
+	err := service.CheckTokens(ctx)
+	require.ErrorContains(t, err, "unavailable")
+	assert.Len(t, client.checkCalls, 1)
+	assert.Len(t, tokenStore.revokeCalls, 1)
+}
@@ -0,0 +1,138 @@
+package secretscan
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+const (
+	maxScannedFileSize = 10 << 20
+	scanBatchSize      = 100
+)
+
+// dirSource scans the files of a directory, e.g. a git repository checked out on disk.
+type dirSource struct {
+	path string
+}
+
+func (s *dirSource) Scan(ctx context.Context, fn func(location string, content []byte) error) error {
+	return filepath.WalkDir(s.path, func(path string, entry fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// a missing directory fails the source, unreadable entries below it are skipped
+			if path == s.path {
+				return err
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			// git objects are compressed, only the working tree can be scanned
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if info.Size() > maxScannedFileSize {
+			return nil
+		}
+
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path comes from the Grafana configuration file
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		return fn(path, content)
+	})
+}
+
+// dashboardSource scans the JSON model of the dashboards stored in Grafana.
+type dashboardSource struct {
+	db     db.DB
+	appURL string
+}
+
+type scannedDashboard struct {
+	ID   int64  `xorm:"id"`
+	UID  string `xorm:"uid"`
+	Data string `xorm:"data"`
+}
+
+func (s *dashboardSource) Scan(ctx context.Context, fn func(location string, content []byte) error) error {
+	var lastID int64
+	for {
+		rows := make([]scannedDashboard, 0, scanBatchSize)
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL("SELECT id, uid, data FROM dashboard WHERE id > ? AND data LIKE ? ORDER BY id ASC "+
+				s.db.GetDialect().Limit(scanBatchSize), lastID, "%glsa_%").Find(&rows)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan dashboards: %w", err)
+		}
+
+		for _, row := range rows {
+			if err := fn(fmt.Sprintf("%sd/%s", s.appURL, row.UID), []byte(row.Data)); err != nil {
+				return err
+			}
+			lastID = row.ID
+		}
+
+		if len(rows) < scanBatchSize {
+			return nil
+		}
+	}
+}
+
+// annotationSource scans the text of the annotations stored in Grafana.
+type annotationSource struct {
+	db db.DB
+}
+
+type scannedAnnotation struct {
+	ID    int64  `xorm:"id"`
+	OrgID int64  `xorm:"org_id"`
+	Text  string `xorm:"text"`
+}
+
+func (s *annotationSource) Scan(ctx context.Context, fn func(location string, content []byte) error) error {
+	var lastID int64
+	for {
+		rows := make([]scannedAnnotation, 0, scanBatchSize)
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.SQL("SELECT id, org_id, text FROM annotation WHERE id > ? AND text LIKE ? ORDER BY id ASC "+
+				s.db.GetDialect().Limit(scanBatchSize), lastID, "%glsa_%").Find(&rows)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan annotations: %w", err)
+		}
+
+		for _, row := range rows {
+			if err := fn(fmt.Sprintf("annotation %d in organization %d", row.ID, row.OrgID), []byte(row.Text)); err != nil {
+				return err
+			}
+			lastID = row.ID
+		}
+
+		if len(rows) < scanBatchSize {
+			return nil
+		}
+	}
+}
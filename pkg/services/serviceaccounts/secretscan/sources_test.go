@@ -0,0 +1,93 @@
+package secretscan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func TestIntegrationDatabaseSources(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	now := time.Now()
+
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, dash := range []struct{ uid, data string }{
+			{"leaky", `{"panels":[{"options":{"content":"glsa_secret"}}]}`},
+			{"clean", `{"panels":[]}`},
+		} {
+			if _, err := sess.Exec("INSERT INTO dashboard (version, slug, title, data, org_id, created, updated, uid) VALUES (1, ?, ?, ?, 1, ?, ?, ?)",
+				dash.uid, dash.uid, dash.data, now, now, dash.uid); err != nil {
+				return err
+			}
+		}
+		for _, text := range []string{"deployed with glsa_secret", "deployed"} {
+			if _, err := sess.Exec("INSERT INTO annotation (org_id, alert_id, user_id, dashboard_id, panel_id, type, title, text, metric, prev_state, new_state, data, epoch, epoch_end, created, updated) VALUES (2, 0, 0, 0, 0, 1, '', ?, '', '', '', '{}', 0, 0, 0, 0)",
+				text); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	collect := func(source Source) map[string]string {
+		found := map[string]string{}
+		require.NoError(t, source.Scan(ctx, func(location string, content []byte) error {
+			found[location] = string(content)
+			return nil
+		}))
+		return found
+	}
+
+	t.Run("dashboards", func(t *testing.T) {
+		found := collect(&dashboardSource{db: sqlStore, appURL: "http://localhost:3000/"})
+		assert.Equal(t, map[string]string{
+			"http://localhost:3000/d/leaky": `{"panels":[{"options":{"content":"glsa_secret"}}]}`,
+		}, found)
+	})
+
+	t.Run("annotations", func(t *testing.T) {
+		found := collect(&annotationSource{db: sqlStore})
+		require.Len(t, found, 1)
+		for location, content := range found {
+			assert.Contains(t, location, "in organization 2")
+			assert.Equal(t, "deployed with glsa_secret", content)
+		}
+	})
+
+	t.Run("findings", func(t *testing.T) {
+		store := &sqlFindingStore{db: sqlStore}
+
+		exists, err := store.HasFinding(ctx, 1, "http://localhost:3000/d/leaky")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		require.NoError(t, store.AddFinding(ctx, &Finding{
+			OrgID:            1,
+			TokenID:          1,
+			ServiceAccountID: 2,
+			TokenName:        "test",
+			Detector:         localDetectorName,
+			Location:         "http://localhost:3000/d/leaky",
+			Revoked:          true,
+		}))
+
+		exists, err = store.HasFinding(ctx, 1, "http://localhost:3000/d/leaky")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = store.HasFinding(ctx, 1, "http://localhost:3000/d/clean")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
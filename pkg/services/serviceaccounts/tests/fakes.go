@@ -53,6 +53,10 @@ func (f *FakeServiceAccountService) ListTokens(ctx context.Context, query *servi
 	return f.ExpectedServiceAccountTokens, f.ExpectedErr
 }
 
+func (f *FakeServiceAccountService) RotateServiceAccountToken(ctx context.Context, id, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedErr
+}
+
 func (f *FakeServiceAccountService) MigrateApiKey(ctx context.Context, orgID, keyID int64) error {
 	return f.ExpectedErr
 }
@@ -124,6 +124,10 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	}
 
 	addKVStoreMySQLValueTypeLongTextMigration(mg)
+
+	addMFAMigrations(mg)
+
+	addSecretScanMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,28 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addSecretScanMigrations(mg *Migrator) {
+	findingV1 := Table{
+		Name: "secret_scan_finding",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "token_id", Type: DB_BigInt, Nullable: false},
+			{Name: "service_account_id", Type: DB_BigInt, Nullable: false},
+			{Name: "token_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "detector", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "location", Type: DB_Text, Nullable: false},
+			{Name: "revoked", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"token_id"}},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create secret_scan_finding table", NewAddTableMigration(findingV1))
+	mg.AddMigration("add index secret_scan_finding.token_id", NewAddIndexMigration(findingV1, findingV1.Indices[0]))
+	mg.AddMigration("add index secret_scan_finding.org_id", NewAddIndexMigration(findingV1, findingV1.Indices[1]))
+}
@@ -16,4 +16,22 @@ func AddMigration(mg *migrator.Migrator) {
 	}
 
 	mg.AddMigration("create sso_setting table", migrator.NewAddTableMigration(ssoSettingV1))
+
+	var ssoSettingAuditV1 = migrator.Table{
+		Name: "sso_setting_audit",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "provider", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "action", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "changed_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"provider", "created"}},
+		},
+	}
+
+	mg.AddMigration("create sso_setting_audit table", migrator.NewAddTableMigration(ssoSettingAuditV1))
+	mg.AddMigration("add index sso_setting_audit.provider_created", migrator.NewAddIndexMigration(ssoSettingAuditV1, ssoSettingAuditV1.Indices[0]))
 }
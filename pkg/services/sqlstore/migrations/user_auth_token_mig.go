@@ -48,4 +48,17 @@ func addUserAuthTokenMigrations(mg *Migrator) {
 	mg.AddMigration("add index user_auth_token.revoked_at", NewAddIndexMigration(userAuthTokenV1, &Index{
 		Cols: []string{"revoked_at"},
 	}))
+
+	mg.AddMigration(
+		"Add auth_module to the user auth token",
+		NewAddColumnMigration(
+			userAuthTokenV1,
+			&Column{
+				Name:     "auth_module",
+				Type:     DB_NVarchar,
+				Length:   190,
+				Nullable: true,
+			},
+		),
+	)
 }
@@ -70,6 +70,7 @@ func (api *Api) RegisterAPIEndpoints() {
 
 		router.Get("/", auth(ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(api.listAllProvidersSettings))
 		router.Get("/:key", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.getProviderSettings))
+		router.Get("/:key/audit", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.listProviderSettingsAudit))
 		router.Put("/:key", reqWriteAccess, routing.Wrap(api.updateProviderSettings))
 		router.Delete("/:key", reqWriteAccess, routing.Wrap(api.removeProviderSettings))
 	})
@@ -151,6 +152,34 @@ func (api *Api) getProviderSettings(c *contextmodel.ReqContext) response.Respons
 	return response.JSON(http.StatusOK, provider).SetHeader("ETag", etag)
 }
 
+// swagger:route GET /v1/sso-settings/{key}/audit sso_settings listProviderSettingsAudit
+//
+// # List the changes of an SSO Settings entry
+//
+// Returns who changed the SSO Settings for a provider and when, the most recent change first. Secrets are redacted.
+//
+// You need to have a permission with action `settings:read` with scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: listSSOSettingsAuditResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (api *Api) listProviderSettingsAudit(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	entries, err := api.SSOSettingsService.ListAudit(c.Req.Context(), key)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list the changes of the provider settings", err)
+	}
+
+	return response.JSON(http.StatusOK, entries)
+}
+
 // swagger:route PUT /v1/sso-settings/{key} sso_settings updateProviderSettings
 //
 // # Update SSO Settings
@@ -226,6 +255,13 @@ type GetProviderSettingsParams struct {
 	Provider string `json:"key"`
 }
 
+// swagger:parameters listProviderSettingsAudit
+type ListProviderSettingsAuditParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+}
+
 // swagger:parameters updateProviderSettings
 type UpdateProviderSettingsParams struct {
 	// in:path
@@ -254,3 +290,9 @@ type GetSSOSettingsResponse struct {
 	// in: body
 	Body models.SSOSettings `json:"body"`
 }
+
+// swagger:response listSSOSettingsAuditResponse
+type ListSSOSettingsAuditResponse struct {
+	// in: body
+	Body []models.SSOSettingsAudit `json:"body"`
+}
@@ -554,6 +554,103 @@ func getPermissionsForActionAndScope(action, scope string) map[int64]map[string]
 	}
 }
 
+func TestSSOSettingsAPI_ListAudit(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		key                 string
+		action              string
+		scope               string
+		expectedResult      []*models.SSOSettingsAudit
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	entries := []*models.SSOSettingsAudit{
+		{
+			ID:        2,
+			Provider:  social.AzureADProviderName,
+			Action:    models.AuditActionDelete,
+			ChangedBy: "user:1",
+			Settings:  map[string]any{},
+		},
+		{
+			ID:        1,
+			Provider:  social.AzureADProviderName,
+			Action:    models.AuditActionUpsert,
+			ChangedBy: "user:1",
+			Settings:  map[string]any{"client_id": "client-id", "client_secret": "*********"},
+		},
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully lists the changes of the SSO settings",
+			key:                 social.AzureADProviderName,
+			action:              "settings:read",
+			scope:               "settings:auth.azuread:*",
+			expectedResult:      entries,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			key:                 social.AzureADProviderName,
+			action:              "settings:write",
+			scope:               "settings:auth.azuread:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails when scope contains another provider",
+			key:                 social.AzureADProviderName,
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with not found when the provider is not configurable",
+			key:                 social.AzureADProviderName,
+			action:              "settings:read",
+			scope:               "settings:auth.azuread:*",
+			expectedError:       ssosettings.ErrNotConfigurable,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("ListAudit", mock.Anything, tt.key).Return(tt.expectedResult, tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			path := fmt.Sprintf("/api/v1/sso-settings/%s/audit", tt.key)
+			req := server.NewRequest(http.MethodGet, path, nil)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgRole:     org.RoleEditor,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.expectedStatusCode == http.StatusOK {
+				var result []*models.SSOSettingsAudit
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+				require.Len(t, result, len(entries))
+				require.Equal(t, models.AuditActionDelete, result[0].Action)
+				require.Equal(t, "*********", result[1].Settings["client_secret"])
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
 func setupTests(t *testing.T, service ssosettings.Service) *webtest.Server {
 	t.Helper()
 
@@ -147,3 +147,33 @@ func (s *SSOSettingsStore) Delete(ctx context.Context, provider string) error {
 		return err
 	})
 }
+
+func (s *SSOSettingsStore) AddAudit(ctx context.Context, entry *models.SSOSettingsAudit) error {
+	if entry.Provider == "" {
+		return ssosettings.ErrNotFound
+	}
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		entry.Created = time.Now().UTC()
+		if entry.Settings == nil {
+			entry.Settings = map[string]any{}
+		}
+
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *SSOSettingsStore) ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error) {
+	result := make([]*models.SSOSettingsAudit, 0)
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("provider = ?", provider).Desc("created", "id").Find(&result)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -453,6 +453,58 @@ func TestIntegrationDeleteSSOSettings(t *testing.T) {
 	})
 }
 
+func TestIntegrationSSOSettingsAudit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var ssoSettingsStore *SSOSettingsStore
+
+	setup := func() {
+		sqlStore := db.InitTestDB(t)
+		ssoSettingsStore = ProvideStore(sqlStore)
+	}
+
+	t.Run("lists the changes of the specified provider, the most recent first", func(t *testing.T) {
+		setup()
+
+		entries := []*models.SSOSettingsAudit{
+			{Provider: "azuread", Action: models.AuditActionUpsert, ChangedBy: "user:1", Settings: map[string]any{"client_id": "client-id"}},
+			{Provider: "github", Action: models.AuditActionUpsert, ChangedBy: "user:2"},
+			{Provider: "azuread", Action: models.AuditActionDelete, ChangedBy: "user:1"},
+		}
+		for _, entry := range entries {
+			require.NoError(t, ssoSettingsStore.AddAudit(context.Background(), entry))
+		}
+
+		actual, err := ssoSettingsStore.ListAudit(context.Background(), "azuread")
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+
+		require.Equal(t, models.AuditActionDelete, actual[0].Action)
+		require.Empty(t, actual[0].Settings)
+		require.Equal(t, models.AuditActionUpsert, actual[1].Action)
+		require.Equal(t, "user:1", actual[1].ChangedBy)
+		require.EqualValues(t, map[string]any{"client_id": "client-id"}, actual[1].Settings)
+		require.WithinDuration(t, time.Now().UTC(), actual[1].Created, withinDuration)
+	})
+
+	t.Run("returns empty list if no changes are recorded", func(t *testing.T) {
+		setup()
+
+		actual, err := ssoSettingsStore.ListAudit(context.Background(), "okta")
+		require.NoError(t, err)
+		require.Empty(t, actual)
+	})
+
+	t.Run("fails if the provider is empty", func(t *testing.T) {
+		setup()
+
+		err := ssoSettingsStore.AddAudit(context.Background(), &models.SSOSettingsAudit{Action: models.AuditActionUpsert})
+		require.ErrorIs(t, err, ssosettings.ErrNotFound)
+	})
+}
+
 func populateSSOSettings(sqlStore *sqlstore.SQLStore, template models.SSOSettings, providers ...string) error {
 	return sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		for _, provider := range providers {
@@ -13,4 +13,8 @@ var (
 	ErrInvalidProvider = errutil.ValidationFailed("sso.invalidProvider", errutil.WithPublicMessage("Provider is invalid"))
 	ErrInvalidSettings = errutil.ValidationFailed("sso.settings", errutil.WithPublicMessage("Settings field is invalid"))
 	ErrEmptyClientId   = errutil.ValidationFailed("sso.emptyClientId", errutil.WithPublicMessage("ClientId cannot be empty"))
+
+	ErrInvalidAttributePath = errutil.ValidationFailed("sso.invalidAttributePath", errutil.WithPublicMessage("Attribute path is not a valid JMESPath expression"))
+	ErrProviderUnreachable  = errutil.ValidationFailed("sso.providerUnreachable", errutil.WithPublicMessage("Failed to verify the endpoints of the identity provider"))
+	ErrReloadNotAllowed     = errutil.ValidationFailed("sso.reloadNotAllowed", errutil.WithPublicMessage("Setting cannot be changed without a restart"))
 )
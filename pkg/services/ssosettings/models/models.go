@@ -97,3 +97,23 @@ func (s *SSOSettings) UnmarshalJSON(data []byte) error {
 	s.Settings = settings
 	return nil
 }
+
+const (
+	AuditActionUpsert = "upsert"
+	AuditActionDelete = "delete"
+)
+
+// SSOSettingsAudit records a change of the SSO settings of a provider
+type SSOSettingsAudit struct {
+	ID        int64          `xorm:"pk autoincr 'id'" json:"id"`
+	Provider  string         `xorm:"provider" json:"provider"`
+	Action    string         `xorm:"action" json:"action"`
+	ChangedBy string         `xorm:"changed_by" json:"changedBy"`
+	Settings  map[string]any `xorm:"settings" json:"settings"`
+	Created   time.Time      `xorm:"created" json:"created"`
+}
+
+// TableName returns the table name (needed for Xorm)
+func (s SSOSettingsAudit) TableName() string {
+	return "sso_setting_audit"
+}
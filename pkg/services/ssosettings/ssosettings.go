@@ -2,6 +2,7 @@ package ssosettings
 
 import (
 	"context"
+	"slices"
 
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/services/ssosettings/models"
@@ -10,9 +11,17 @@ import (
 var (
 	// ConfigurableOAuthProviders is a list of OAuth providers that can be configured from the API
 	// TODO: make it configurable
-	ConfigurableOAuthProviders = []string{"github", "gitlab", "google", "generic_oauth", "azuread", "okta"}
+	ConfigurableOAuthProviders = []string{"github", "gitlab", "google", "generic_oauth", "grafana_com", "azuread", "okta"}
 
 	AllOAuthProviders = []string{social.GitHubProviderName, social.GitlabProviderName, social.GoogleProviderName, social.GenericOAuthProviderName, social.GrafanaComProviderName, social.AzureADProviderName, social.OktaProviderName}
+
+	// AllProviders is the list of all the SSO providers, OAuth providers, SAML and LDAP
+	AllProviders = append(slices.Clone(AllOAuthProviders), SAMLProviderName, LDAPProviderName)
+)
+
+const (
+	SAMLProviderName = "saml"
+	LDAPProviderName = "ldap"
 )
 
 // Service is a SSO settings service
@@ -37,6 +46,8 @@ type Service interface {
 	RegisterReloadable(provider string, reloadable Reloadable)
 	// Reload reloads the settings for a given provider
 	Reload(ctx context.Context, provider string)
+	// ListAudit returns the recorded changes of the SSO settings for a given provider, the most recent first
+	ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error)
 }
 
 // Reloadable is an interface that can be implemented by a provider to allow it to be validated and reloaded
@@ -64,4 +75,6 @@ type Store interface {
 	List(ctx context.Context) ([]*models.SSOSettings, error)
 	Upsert(ctx context.Context, settings *models.SSOSettings) error
 	Delete(ctx context.Context, provider string) error
+	AddAudit(ctx context.Context, entry *models.SSOSettingsAudit) error
+	ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error)
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
@@ -41,7 +42,8 @@ func ProvideService(cfg *setting.Cfg, sqlStore db.DB, ac ac.AccessControl,
 	secrets secrets.Service, usageStats usagestats.Service) *Service {
 	strategies := []ssosettings.FallbackStrategy{
 		strategies.NewOAuthStrategy(cfg),
-		// register other strategies here, for example SAML
+		strategies.NewSAMLStrategy(cfg),
+		strategies.NewLDAPStrategy(cfg),
 	}
 
 	store := database.ProvideStore(sqlStore)
@@ -100,24 +102,20 @@ func (s *Service) GetForProviderWithRedactedSecrets(ctx context.Context, provide
 		return nil, err
 	}
 
-	for k, v := range storeSettings.Settings {
-		if strVal, ok := v.(string); ok {
-			storeSettings.Settings[k] = setting.RedactedValue(k, strVal)
-		}
-	}
+	storeSettings.Settings = redactSecrets(storeSettings.Settings)
 
 	return storeSettings, nil
 }
 
 func (s *Service) List(ctx context.Context) ([]*models.SSOSettings, error) {
-	result := make([]*models.SSOSettings, 0, len(ssosettings.AllOAuthProviders))
+	result := make([]*models.SSOSettings, 0, len(ssosettings.AllProviders))
 	storedSettings, err := s.store.List(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
-	for _, provider := range ssosettings.AllOAuthProviders {
+	for _, provider := range ssosettings.AllProviders {
 		dbSettings := getSettingByProvider(provider, storedSettings)
 		if dbSettings != nil {
 			// Settings are coming from the database thus secrets are encrypted
@@ -151,11 +149,7 @@ func (s *Service) ListWithRedactedSecrets(ctx context.Context) ([]*models.SSOSet
 	}
 
 	for _, storeSetting := range configurableSettings {
-		for k, v := range storeSetting.Settings {
-			if strVal, ok := v.(string); ok {
-				storeSetting.Settings[k] = setting.RedactedValue(k, strVal)
-			}
-		}
+		storeSetting.Settings = redactSecrets(storeSetting.Settings)
 	}
 
 	return configurableSettings, nil
@@ -181,9 +175,12 @@ func (s *Service) Upsert(ctx context.Context, settings *models.SSOSettings) erro
 		return err
 	}
 
-	secrets := collectSecrets(settings, storedSettings)
+	resolvedSettings, err := resolveSecrets(settings.Settings, storedSettings.Settings)
+	if err != nil {
+		return err
+	}
 
-	settings.Settings, err = s.encryptSecrets(ctx, settings.Settings, storedSettings.Settings)
+	settings.Settings, err = s.encryptSecrets(ctx, resolvedSettings)
 	if err != nil {
 		return err
 	}
@@ -193,8 +190,10 @@ func (s *Service) Upsert(ctx context.Context, settings *models.SSOSettings) erro
 		return err
 	}
 
+	s.audit(ctx, settings.Provider, models.AuditActionUpsert, resolvedSettings)
+
 	go func() {
-		settings.Settings = overrideMaps(storedSettings.Settings, settings.Settings, secrets)
+		settings.Settings = overrideMaps(storedSettings.Settings, resolvedSettings)
 		err = social.Reload(context.Background(), *settings)
 		if err != nil {
 			s.logger.Error("failed to reload the provider", "provider", settings.Provider, "error", err)
@@ -212,11 +211,63 @@ func (s *Service) Delete(ctx context.Context, provider string) error {
 	if !s.isProviderConfigurable(provider) {
 		return ssosettings.ErrNotConfigurable
 	}
-	return s.store.Delete(ctx, provider)
+
+	if err := s.store.Delete(ctx, provider); err != nil {
+		return err
+	}
+
+	s.audit(ctx, provider, models.AuditActionDelete, nil)
+
+	// the provider falls back to the settings from the config file and environment variables
+	s.Reload(ctx, provider)
+
+	return nil
+}
+
+func (s *Service) ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error) {
+	if !s.isProviderConfigurable(provider) {
+		return nil, ssosettings.ErrNotConfigurable
+	}
+
+	return s.store.ListAudit(ctx, provider)
 }
 
 func (s *Service) Reload(ctx context.Context, provider string) {
-	panic("not implemented") // TODO: Implement
+	reloadable, ok := s.reloadables[provider]
+	if !ok {
+		return
+	}
+
+	settings, err := s.GetForProvider(ctx, provider)
+	if err != nil {
+		s.logger.Error("failed to fetch SSO Settings", "provider", provider, "err", err)
+		return
+	}
+
+	if err := reloadable.Reload(ctx, *settings); err != nil {
+		s.logger.Error("failed to reload SSO Settings", "provider", provider, "err", err)
+	}
+}
+
+// audit records who changed the SSO settings of a provider. Failing to record the change doesn't
+// revert it, as it has already been applied.
+func (s *Service) audit(ctx context.Context, provider, action string, settings map[string]any) {
+	entry := &models.SSOSettingsAudit{
+		Provider: provider,
+		Action:   action,
+		Settings: redactSecrets(settings),
+	}
+
+	if usr, err := appcontext.User(ctx); err == nil {
+		namespace, id := usr.GetNamespacedID()
+		entry.ChangedBy = namespace + ":" + id
+	}
+
+	s.logger.FromContext(ctx).Info("SSO settings changed", "provider", provider, "action", action, "changedBy", entry.ChangedBy)
+
+	if err := s.store.AddAudit(ctx, entry); err != nil {
+		s.logger.FromContext(ctx).Error("failed to record the change of the SSO settings", "provider", provider, "action", action, "err", err)
+	}
 }
 
 func (s *Service) RegisterReloadable(provider string, reloadable ssosettings.Reloadable) {
@@ -266,19 +317,28 @@ func (s *Service) getFallbackStrategyFor(provider string) (ssosettings.FallbackS
 	return nil, false
 }
 
-func (s *Service) encryptSecrets(ctx context.Context, settings, storedSettings map[string]any) (map[string]any, error) {
+// encryptSecrets encrypts the secrets of the settings, including the ones nested in maps and lists
+// (e.g. the bind passwords of the LDAP servers), and returns a new map
+func (s *Service) encryptSecrets(ctx context.Context, settings map[string]any) (map[string]any, error) {
 	result := make(map[string]any)
 	for k, v := range settings {
+		nested, err := transformNested(v, func(m map[string]any) (map[string]any, error) {
+			return s.encryptSecrets(ctx, m)
+		})
+		if err != nil {
+			return result, err
+		}
+		if nested != nil {
+			result[k] = nested
+			continue
+		}
+
 		if isSecret(k) && v != "" {
 			strValue, ok := v.(string)
 			if !ok {
 				return result, fmt.Errorf("failed to encrypt %s setting because it is not a string: %v", k, v)
 			}
 
-			if !isNewSecretValue(strValue) {
-				strValue = storedSettings[k].(string)
-			}
-
 			encryptedSecret, err := s.secrets.Encrypt(ctx, []byte(strValue), secrets.WithoutScope())
 			if err != nil {
 				return result, err
@@ -357,6 +417,17 @@ func (s *Service) mergeSSOSettings(dbSettings, systemSettings *models.SSOSetting
 
 func (s *Service) decryptSecrets(ctx context.Context, settings map[string]any) (map[string]any, error) {
 	for k, v := range settings {
+		nested, err := transformNested(v, func(m map[string]any) (map[string]any, error) {
+			return s.decryptSecrets(ctx, m)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if nested != nil {
+			settings[k] = nested
+			continue
+		}
+
 		if isSecret(k) && v != "" {
 			strValue, ok := v.(string)
 			if !ok {
@@ -392,6 +463,13 @@ func (s *Service) isProviderConfigurable(provider string) bool {
 func removeSecrets(settings map[string]any) map[string]any {
 	result := make(map[string]any)
 	for k, v := range settings {
+		if nested, _ := transformNested(v, func(m map[string]any) (map[string]any, error) {
+			return removeSecrets(m), nil
+		}); nested != nil {
+			result[k] = nested
+			continue
+		}
+
 		if isSecret(k) {
 			result[k] = setting.RedactedPassword
 			continue
@@ -401,6 +479,53 @@ func removeSecrets(settings map[string]any) map[string]any {
 	return result
 }
 
+// redactSecrets redacts the sensitive string values of the map, including the ones nested in maps and lists,
+// and returns a new map
+func redactSecrets(settings map[string]any) map[string]any {
+	result := make(map[string]any)
+	for k, v := range settings {
+		if nested, _ := transformNested(v, func(m map[string]any) (map[string]any, error) {
+			return redactSecrets(m), nil
+		}); nested != nil {
+			result[k] = nested
+			continue
+		}
+
+		if strVal, ok := v.(string); ok {
+			result[k] = setting.RedactedValue(k, strVal)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// transformNested applies fn to a nested map or to the maps of a nested list and returns the transformed value.
+// It returns nil if the value is neither a map nor a list.
+func transformNested(value any, fn func(map[string]any) (map[string]any, error)) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return fn(v)
+	case []any:
+		result := make([]any, 0, len(v))
+		for _, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				result = append(result, item)
+				continue
+			}
+
+			transformed, err := fn(m)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, transformed)
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
 // mergeSettings merges two maps in a way that the values from the first map are preserved
 // and the values from the second map are added only if they don't exist in the first map
 func mergeSettings(storedSettings, systemSettings map[string]any) map[string]any {
@@ -419,20 +544,76 @@ func mergeSettings(storedSettings, systemSettings map[string]any) map[string]any
 	return settings
 }
 
-// collectSecrets collects all the secrets from the request and the currently stored settings
-// and returns a new map
-func collectSecrets(settings *models.SSOSettings, storedSettings *models.SSOSettings) map[string]any {
-	secrets := map[string]any{}
-	for k, v := range settings.Settings {
-		if isSecret(k) {
-			if isNewSecretValue(v.(string)) {
-				secrets[k] = v.(string) // use the new value
-				continue
+// resolveSecrets replaces the redacted secrets of the request with the currently stored values
+// and returns a new map. Secrets nested in lists are matched with the stored ones by the host and
+// port of the list item, or by their position for items without a host.
+func resolveSecrets(settings, storedSettings map[string]any) (map[string]any, error) {
+	result := make(map[string]any)
+	for k, v := range settings {
+		switch value := v.(type) {
+		case map[string]any:
+			stored, _ := storedSettings[k].(map[string]any)
+			resolved, err := resolveSecrets(value, stored)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+			continue
+		case []any:
+			stored, _ := storedSettings[k].([]any)
+			resolved := make([]any, 0, len(value))
+			for i, item := range value {
+				m, ok := item.(map[string]any)
+				if !ok {
+					resolved = append(resolved, item)
+					continue
+				}
+
+				resolvedItem, err := resolveSecrets(m, storedListItem(m, stored, i))
+				if err != nil {
+					return nil, err
+				}
+				resolved = append(resolved, resolvedItem)
 			}
-			secrets[k] = storedSettings.Settings[k] // keep the currently stored value
+			result[k] = resolved
+			continue
+		}
+
+		if strValue, ok := v.(string); ok && isSecret(k) && !isNewSecretValue(strValue) {
+			storedValue, ok := storedSettings[k].(string)
+			if !ok {
+				return nil, ssosettings.ErrInvalidSettings.Errorf("no value is stored for the redacted secret %s", k)
+			}
+			result[k] = storedValue // keep the currently stored value
+			continue
 		}
+		result[k] = v
+	}
+	return result, nil
+}
+
+// storedListItem returns the stored item of a list the item was loaded from. Items are reordered,
+// added and removed, and a secret must never be sent to another host, so items with a host are
+// matched by host and port.
+func storedListItem(item map[string]any, stored []any, idx int) map[string]any {
+	if _, ok := item["host"]; !ok {
+		if idx < len(stored) {
+			storedItem, _ := stored[idx].(map[string]any)
+			return storedItem
+		}
+		return nil
 	}
-	return secrets
+
+	for _, s := range stored {
+		storedItem, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		if fmt.Sprint(storedItem["host"]) == fmt.Sprint(item["host"]) && fmt.Sprint(storedItem["port"]) == fmt.Sprint(item["port"]) {
+			return storedItem
+		}
+	}
+	return nil
 }
 
 func overrideMaps(maps ...map[string]any) map[string]any {
@@ -446,7 +627,7 @@ func overrideMaps(maps ...map[string]any) map[string]any {
 }
 
 func isSecret(fieldName string) bool {
-	secretFieldPatterns := []string{"secret"}
+	secretFieldPatterns := []string{"secret", "private_key", "password"}
 
 	for _, v := range secretFieldPatterns {
 		if strings.Contains(strings.ToLower(fieldName), strings.ToLower(v)) {
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -21,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	"github.com/grafana/grafana/pkg/services/ssosettings/models"
 	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -365,6 +367,12 @@ func TestService_List(t *testing.T) {
 					"grafana_com": {
 						"enabled": false,
 					},
+					"saml": {
+						"enabled": false,
+					},
+					"ldap": {
+						"enabled": false,
+					},
 				}
 			},
 			want: []*models.SSOSettings{
@@ -414,6 +422,16 @@ func TestService_List(t *testing.T) {
 					Settings: map[string]any{"enabled": false},
 					Source:   models.System,
 				},
+				{
+					Provider: "saml",
+					Settings: map[string]any{"enabled": false},
+					Source:   models.System,
+				},
+				{
+					Provider: "ldap",
+					Settings: map[string]any{"enabled": false},
+					Source:   models.System,
+				},
 			},
 			wantErr: false,
 		},
@@ -1001,6 +1019,211 @@ func TestService_Upsert(t *testing.T) {
 		settings.Settings["client_secret"] = base64.RawStdEncoding.EncodeToString([]byte("encrypted-client-secret"))
 		require.EqualValues(t, settings, env.store.ActualSSOSettings)
 	})
+
+	t.Run("records the change with redacted secrets", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		provider := social.AzureADProviderName
+		settings := models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"client_id":     "client-id",
+				"client_secret": "client-secret",
+				"enabled":       true,
+			},
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, settings).Return(nil)
+		reloadable.On("Reload", mock.Anything, mock.Anything).Return(nil).Maybe()
+		env.reloadables[provider] = reloadable
+		env.secrets.On("Encrypt", mock.Anything, []byte("client-secret"), mock.Anything).Return([]byte("encrypted-client-secret"), nil).Once()
+
+		ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 1})
+		err := env.service.Upsert(ctx, &settings)
+		require.NoError(t, err)
+
+		require.Len(t, env.store.ActualAuditEntries, 1)
+		entry := env.store.ActualAuditEntries[0]
+		require.Equal(t, provider, entry.Provider)
+		require.Equal(t, models.AuditActionUpsert, entry.Action)
+		require.Equal(t, "user:1", entry.ChangedBy)
+		require.Equal(t, map[string]any{
+			"client_id":     "client-id",
+			"client_secret": setting.RedactedPassword,
+			"enabled":       true,
+		}, entry.Settings)
+	})
+
+	t.Run("keeps the current secrets nested in lists if they have not been updated", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.cfg.SSOSettingsConfigurableProviders[ssosettings.LDAPProviderName] = true
+
+		provider := ssosettings.LDAPProviderName
+		settings := models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "ldap-1", "bind_password": setting.RedactedPassword},
+						map[string]any{"host": "ldap-2", "bind_password": "new-password"},
+					},
+				},
+			},
+		}
+
+		env.store.ExpectedSSOSetting = &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "ldap-1", "bind_password": base64.RawStdEncoding.EncodeToString([]byte("encrypted-password"))},
+					},
+				},
+			},
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything).Return(nil)
+		reloadable.On("Reload", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			defer wg.Done()
+			servers := settings.Settings["config"].(map[string]any)["servers"].([]any)
+			return servers[0].(map[string]any)["bind_password"] == "current-password" &&
+				servers[1].(map[string]any)["bind_password"] == "new-password"
+		})).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-password"), mock.Anything).Return([]byte("current-password"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("current-password"), mock.Anything).Return([]byte("encrypted-current-password"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("new-password"), mock.Anything).Return([]byte("encrypted-new-password"), nil).Once()
+
+		err := env.service.Upsert(context.Background(), &settings)
+		require.NoError(t, err)
+
+		wg.Wait()
+
+		servers := env.store.ActualSSOSettings.Settings["config"].(map[string]any)["servers"].([]any)
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("encrypted-current-password")), servers[0].(map[string]any)["bind_password"])
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("encrypted-new-password")), servers[1].(map[string]any)["bind_password"])
+	})
+
+	t.Run("matches the secrets nested in lists by host and port", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.cfg.SSOSettingsConfigurableProviders[ssosettings.LDAPProviderName] = true
+
+		provider := ssosettings.LDAPProviderName
+		settings := models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "ldap-2", "port": float64(389), "bind_password": setting.RedactedPassword},
+						map[string]any{"host": "ldap-1", "port": float64(389), "bind_password": setting.RedactedPassword},
+					},
+				},
+			},
+		}
+
+		env.store.ExpectedSSOSetting = &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "ldap-1", "port": float64(389), "bind_password": base64.RawStdEncoding.EncodeToString([]byte("encrypted-password-1"))},
+						map[string]any{"host": "ldap-2", "port": float64(389), "bind_password": base64.RawStdEncoding.EncodeToString([]byte("encrypted-password-2"))},
+					},
+				},
+			},
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything).Return(nil)
+		reloadable.On("Reload", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			defer wg.Done()
+			servers := settings.Settings["config"].(map[string]any)["servers"].([]any)
+			return servers[0].(map[string]any)["bind_password"] == "password-2" &&
+				servers[1].(map[string]any)["bind_password"] == "password-1"
+		})).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-password-1"), mock.Anything).Return([]byte("password-1"), nil).Once()
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-password-2"), mock.Anything).Return([]byte("password-2"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("password-1"), mock.Anything).Return([]byte("encrypted-password-1"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("password-2"), mock.Anything).Return([]byte("encrypted-password-2"), nil).Once()
+
+		err := env.service.Upsert(context.Background(), &settings)
+		require.NoError(t, err)
+
+		wg.Wait()
+	})
+
+	t.Run("returns error if the host of a list item with a redacted secret changed", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.cfg.SSOSettingsConfigurableProviders[ssosettings.LDAPProviderName] = true
+
+		provider := ssosettings.LDAPProviderName
+		settings := models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "attacker.example.com", "bind_password": setting.RedactedPassword},
+					},
+				},
+			},
+		}
+
+		env.store.ExpectedSSOSetting = &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"enabled": true,
+				"config": map[string]any{
+					"servers": []any{
+						map[string]any{"host": "ldap-1", "bind_password": base64.RawStdEncoding.EncodeToString([]byte("encrypted-password"))},
+					},
+				},
+			},
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything).Return(nil)
+		env.reloadables[provider] = reloadable
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-password"), mock.Anything).Return([]byte("current-password"), nil).Maybe()
+
+		err := env.service.Upsert(context.Background(), &settings)
+		require.ErrorIs(t, err, ssosettings.ErrInvalidSettings)
+		require.Nil(t, env.store.ActualSSOSettings.Settings)
+	})
+
+	t.Run("returns error if no value is stored for a redacted secret", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		provider := social.AzureADProviderName
+		settings := models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{
+				"client_id":     "client-id",
+				"client_secret": setting.RedactedPassword,
+				"enabled":       true,
+			},
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, settings).Return(nil)
+		env.reloadables[provider] = reloadable
+
+		err := env.service.Upsert(context.Background(), &settings)
+		require.ErrorIs(t, err, ssosettings.ErrInvalidSettings)
+	})
 }
 
 func TestService_Delete(t *testing.T) {
@@ -1014,6 +1237,31 @@ func TestService_Delete(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("records the deletion and reloads the provider with the system settings", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		provider := social.AzureADProviderName
+		env.fallbackStrategy.ExpectedConfigs = map[string]map[string]any{
+			provider: {"client_id": "system-client-id", "enabled": false},
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Reload", mock.Anything, models.SSOSettings{
+			Provider: provider,
+			Source:   models.System,
+			Settings: map[string]any{"client_id": "system-client-id", "enabled": false},
+		}).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+
+		ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 1})
+		err := env.service.Delete(ctx, provider)
+		require.NoError(t, err)
+
+		require.Len(t, env.store.ActualAuditEntries, 1)
+		require.Equal(t, models.AuditActionDelete, env.store.ActualAuditEntries[0].Action)
+		require.Equal(t, "user:1", env.store.ActualAuditEntries[0].ChangedBy)
+	})
+
 	t.Run("SSO settings not found for the specified provider", func(t *testing.T) {
 		env := setupTestEnv(t)
 
@@ -1101,6 +1349,58 @@ func TestService_DoReload(t *testing.T) {
 	})
 }
 
+func TestService_Reload(t *testing.T) {
+	t.Run("successfully reload the settings of the provider", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		provider := social.GitHubProviderName
+		env.store.ExpectedSSOSetting = &models.SSOSettings{
+			Provider: provider,
+			Settings: map[string]any{"client_id": "github_client_id", "enabled": true},
+		}
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Reload", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			return settings.Provider == provider && settings.Settings["client_id"] == "github_client_id"
+		})).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+
+		env.service.Reload(context.Background(), provider)
+	})
+
+	t.Run("does not reload if the settings cannot be fetched", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		provider := social.GitHubProviderName
+		env.store.ExpectedError = errors.New("failed fetching the settings")
+
+		env.reloadables[provider] = ssosettingstests.NewMockReloadable(t)
+
+		env.service.Reload(context.Background(), provider)
+	})
+}
+
+func TestService_ListAudit(t *testing.T) {
+	t.Run("returns the changes of the provider", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		env.store.ExpectedAuditEntries = []*models.SSOSettingsAudit{
+			{Provider: social.GitHubProviderName, Action: models.AuditActionUpsert},
+		}
+
+		entries, err := env.service.ListAudit(context.Background(), social.GitHubProviderName)
+		require.NoError(t, err)
+		require.Equal(t, env.store.ExpectedAuditEntries, entries)
+	})
+
+	t.Run("returns error if the provider is not configurable", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		_, err := env.service.ListAudit(context.Background(), social.GrafanaComProviderName)
+		require.ErrorIs(t, err, ssosettings.ErrNotConfigurable)
+	})
+}
+
 func TestService_decryptSecrets(t *testing.T) {
 	testCases := []struct {
 		name     string
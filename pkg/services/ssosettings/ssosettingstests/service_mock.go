@@ -124,6 +124,36 @@ func (_m *MockService) List(ctx context.Context) ([]*models.SSOSettings, error)
 	return r0, r1
 }
 
+// ListAudit provides a mock function with given fields: ctx, provider
+func (_m *MockService) ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error) {
+	ret := _m.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAudit")
+	}
+
+	var r0 []*models.SSOSettingsAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.SSOSettingsAudit, error)); ok {
+		return rf(ctx, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.SSOSettingsAudit); ok {
+		r0 = rf(ctx, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SSOSettingsAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListWithRedactedSecrets provides a mock function with given fields: ctx
 func (_m *MockService) ListWithRedactedSecrets(ctx context.Context) ([]*models.SSOSettings, error) {
 	ret := _m.Called(ctx)
@@ -16,6 +16,9 @@ type FakeStore struct {
 
 	ActualSSOSettings models.SSOSettings
 
+	ExpectedAuditEntries []*models.SSOSettingsAudit
+	ActualAuditEntries   []*models.SSOSettingsAudit
+
 	GetFn    func(ctx context.Context, provider string) (*models.SSOSettings, error)
 	UpsertFn func(ctx context.Context, settings *models.SSOSettings) error
 }
@@ -52,3 +55,12 @@ func (f *FakeStore) Patch(ctx context.Context, provider string, data map[string]
 func (f *FakeStore) Delete(ctx context.Context, provider string) error {
 	return f.ExpectedError
 }
+
+func (f *FakeStore) AddAudit(ctx context.Context, entry *models.SSOSettingsAudit) error {
+	f.ActualAuditEntries = append(f.ActualAuditEntries, entry)
+	return nil
+}
+
+func (f *FakeStore) ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error) {
+	return f.ExpectedAuditEntries, f.ExpectedError
+}
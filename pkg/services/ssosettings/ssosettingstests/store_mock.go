@@ -14,6 +14,24 @@ type MockStore struct {
 	mock.Mock
 }
 
+// AddAudit provides a mock function with given fields: ctx, entry
+func (_m *MockStore) AddAudit(ctx context.Context, entry *models.SSOSettingsAudit) error {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAudit")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettingsAudit) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, provider
 func (_m *MockStore) Delete(ctx context.Context, provider string) error {
 	ret := _m.Called(ctx, provider)
@@ -92,6 +110,36 @@ func (_m *MockStore) List(ctx context.Context) ([]*models.SSOSettings, error) {
 	return r0, r1
 }
 
+// ListAudit provides a mock function with given fields: ctx, provider
+func (_m *MockStore) ListAudit(ctx context.Context, provider string) ([]*models.SSOSettingsAudit, error) {
+	ret := _m.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAudit")
+	}
+
+	var r0 []*models.SSOSettingsAudit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.SSOSettingsAudit, error)); ok {
+		return rf(ctx, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.SSOSettingsAudit); ok {
+		r0 = rf(ctx, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SSOSettingsAudit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Upsert provides a mock function with given fields: ctx, settings
 func (_m *MockStore) Upsert(ctx context.Context, settings *models.SSOSettings) error {
 	ret := _m.Called(ctx, settings)
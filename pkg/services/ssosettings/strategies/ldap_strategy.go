@@ -0,0 +1,86 @@
+package strategies
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type LDAPStrategy struct {
+	cfg *setting.Cfg
+	log log.Logger
+}
+
+var _ ssosettings.FallbackStrategy = (*LDAPStrategy)(nil)
+
+func NewLDAPStrategy(cfg *setting.Cfg) *LDAPStrategy {
+	return &LDAPStrategy{
+		cfg: cfg,
+		log: log.New("ssosettings.ldap_strategy"),
+	}
+}
+
+func (s *LDAPStrategy) IsMatch(provider string) bool {
+	return provider == ssosettings.LDAPProviderName
+}
+
+// GetProviderConfig returns whether LDAP is enabled and the servers of the LDAP config file. The file is read
+// on every call so the changes made to it are picked up when the settings are reloaded.
+func (s *LDAPStrategy) GetProviderConfig(_ context.Context, _ string) (map[string]any, error) {
+	result := map[string]any{
+		"enabled": s.cfg.LDAPAuthEnabled,
+	}
+
+	if !s.cfg.LDAPAuthEnabled {
+		return result, nil
+	}
+
+	config, err := loadLDAPConfigFile(s.cfg.LDAPConfigFilePath)
+	if err != nil {
+		// a broken config file shouldn't prevent listing the settings of the other providers
+		s.log.Warn("Failed to load the LDAP config file", "file", s.cfg.LDAPConfigFilePath, "error", err)
+		return result, nil
+	}
+	result["config"] = config
+
+	return result, nil
+}
+
+func loadLDAPConfigFile(path string) (map[string]any, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from grafana configuration file
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// interpolate full toml string (it can contain ENV variables)
+	content, err := setting.ExpandVar(string(fileBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded map[string]any
+	if _, err := toml.Decode(content, &decoded); err != nil {
+		return nil, err
+	}
+
+	// convert the tables decoded from toml to the types the settings stored in the database are decoded to
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,73 @@
+package strategies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLDAPStrategy_GetProviderConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "ldap.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+[[servers]]
+host = "ldap.example.com"
+port = 389
+bind_password = "$__env{LDAP_TEST_BIND_PASSWORD}"
+search_filter = "(cn=%s)"
+search_base_dns = ["dc=grafana,dc=org"]
+
+[servers.attributes]
+username = "cn"
+
+[[servers.group_mappings]]
+group_dn = "cn=admins,dc=grafana,dc=org"
+org_role = "Admin"
+`), 0600))
+	t.Setenv("LDAP_TEST_BIND_PASSWORD", "password")
+
+	cfg := setting.NewCfg()
+	cfg.LDAPAuthEnabled = true
+	cfg.LDAPConfigFilePath = configFile
+
+	strategy := NewLDAPStrategy(cfg)
+	require.True(t, strategy.IsMatch(ssosettings.LDAPProviderName))
+	require.False(t, strategy.IsMatch(ssosettings.SAMLProviderName))
+
+	result, err := strategy.GetProviderConfig(context.Background(), ssosettings.LDAPProviderName)
+	require.NoError(t, err)
+
+	require.Equal(t, true, result["enabled"])
+	servers := result["config"].(map[string]any)["servers"].([]any)
+	require.Len(t, servers, 1)
+
+	server := servers[0].(map[string]any)
+	require.Equal(t, "ldap.example.com", server["host"])
+	require.Equal(t, float64(389), server["port"])
+	require.Equal(t, "password", server["bind_password"])
+	require.Equal(t, []any{"dc=grafana,dc=org"}, server["search_base_dns"])
+	require.Equal(t, map[string]any{"username": "cn"}, server["attributes"])
+	require.Len(t, server["group_mappings"], 1)
+
+	t.Run("does not read the config file if LDAP is disabled", func(t *testing.T) {
+		cfg.LDAPAuthEnabled = false
+
+		result, err := strategy.GetProviderConfig(context.Background(), ssosettings.LDAPProviderName)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"enabled": false}, result)
+	})
+
+	t.Run("does not fail if the config file cannot be read", func(t *testing.T) {
+		cfg.LDAPAuthEnabled = true
+		cfg.LDAPConfigFilePath = filepath.Join(t.TempDir(), "missing.toml")
+
+		result, err := strategy.GetProviderConfig(context.Background(), ssosettings.LDAPProviderName)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"enabled": true}, result)
+	})
+}
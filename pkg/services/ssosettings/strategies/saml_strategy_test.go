@@ -0,0 +1,51 @@
+package strategies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSAMLStrategy_GetProviderConfig(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+	[auth.saml]
+	enabled = true
+	single_logout = true
+	idp_metadata_url = https://idp.example.com/metadata
+	assertion_attribute_groups = groups
+	team_mapping = admins:1:2
+	`))
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.Raw = iniFile
+
+	strategy := NewSAMLStrategy(cfg)
+	require.True(t, strategy.IsMatch(ssosettings.SAMLProviderName))
+	require.False(t, strategy.IsMatch("github"))
+
+	result, err := strategy.GetProviderConfig(context.Background(), ssosettings.SAMLProviderName)
+	require.NoError(t, err)
+
+	require.Equal(t, true, result["enabled"])
+	require.Equal(t, true, result["single_logout"])
+	require.Equal(t, "https://idp.example.com/metadata", result["idp_metadata_url"])
+	require.Equal(t, "groups", result["assertion_attribute_groups"])
+	require.Equal(t, "admins:1:2", result["team_mapping"])
+	// defaults
+	require.Equal(t, "SAML", result["name"])
+	require.Equal(t, true, result["allow_sign_up"])
+	require.Equal(t, "mail", result["assertion_attribute_login"])
+	require.Equal(t, "90s", result["max_issue_delay"])
+
+	// the returned map is a copy
+	result["enabled"] = false
+	again, err := strategy.GetProviderConfig(context.Background(), ssosettings.SAMLProviderName)
+	require.NoError(t, err)
+	require.Equal(t, true, again["enabled"])
+}
@@ -27,6 +27,8 @@ type SignedInUser struct {
 	Teams            []int64
 	// Permissions grouped by orgID and actions
 	Permissions map[int64]map[string][]string `json:"-"`
+	// TokenPermissions, grouped by action, is set when the entity authenticated with a token restricted to a subset of its permissions.
+	TokenPermissions map[string][]string `json:"-" xorm:"-"`
 	// IDToken is a signed token representing the identity that can be forwarded to plugins and external services.
 	// Will only be set when featuremgmt.FlagIdForwarding is enabled.
 	IDToken string `json:"-" xorm:"-"`
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -151,6 +153,7 @@ type Cfg struct {
 	// Security
 	DisableInitAdminCreation          bool
 	DisableBruteForceLoginProtection  bool
+	BruteForceLoginProtection         BruteForceLoginProtectionSettings
 	CookieSecure                      bool
 	CookieSameSiteDisabled            bool
 	CookieSameSiteMode                http.SameSite
@@ -238,6 +241,11 @@ type Cfg struct {
 	// stand in until a more complete solution is implemented
 	AuthConfigUIAdminAccess bool
 
+	// Session limits, the per org lifetimes can only shorten the global ones
+	LoginMaxInactiveLifetimeByOrg map[int64]time.Duration
+	LoginMaxLifetimeByOrg         map[int64]time.Duration
+	LoginMaxConcurrentSessions    int64
+
 	// AWS Plugin Auth
 	AWSAllowedAuthProviders []string
 	AWSAssumeRoleEnabled    bool
@@ -247,6 +255,15 @@ type Cfg struct {
 	// Azure Cloud settings
 	Azure *azsettings.AzureSettings
 
+	// Multi-factor authentication settings
+	MFAEnabled           bool
+	MFAIssuer            string
+	MFARequiredForAdmins bool
+
+	// SCIM provisioning settings
+	SCIMEnabled        bool
+	SCIMDefaultOrgRole string
+
 	// Auth proxy settings
 	AuthProxyEnabled          bool
 	AuthProxyHeaderName       string
@@ -280,6 +297,8 @@ type Cfg struct {
 	JWTAuthRoleAttributeStrict     bool
 	JWTAuthAllowAssignGrafanaAdmin bool
 	JWTAuthSkipOrgRoleSync         bool
+	// JWTAuthIssuers holds the settings of the issuers configured in [auth.jwt.issuer.<name>] sections.
+	JWTAuthIssuers []JWTIssuerSettings
 
 	// Extended JWT Auth
 	ExtendedJWTAuthEnabled    bool
@@ -308,6 +327,9 @@ type Cfg struct {
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
 
+	// Query and resource caching
+	QueryCaching QueryCachingSettings
+
 	ViewersCanEdit  bool
 	EditorsCanAdmin bool
 
@@ -332,6 +354,9 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	SATokenMaxLifetimeByOrg   map[int64]time.Duration
+	SATokenRotationOverlap    time.Duration
+	SATokenExpiryWarning      time.Duration
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -1242,6 +1267,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 		Prefix:     prefix,
 		Encryption: encryption,
 	}
+	cfg.readQueryCachingSettings()
 
 	geomapSection := iniFile.Section("geomap")
 	basemapJSON := valueAsString(geomapSection, "default_baselayer_config", "")
@@ -1424,11 +1450,78 @@ func (cfg *Cfg) SectionWithEnvOverrides(s string) *DynamicSection {
 	return &DynamicSection{cfg.Raw.Section(s), cfg.Logger}
 }
 
+// BruteForceLoginProtectionSettings configures how failed login attempts lock out usernames and IP addresses.
+type BruteForceLoginProtectionSettings struct {
+	// MaxAttempts is the number of failed attempts for a username from an IP address before the username
+	// is locked out for that address
+	MaxAttempts int64
+	// MaxAttemptsPerUsername is the number of failed attempts for a username from all IP addresses
+	// before it is locked out everywhere
+	MaxAttemptsPerUsername int64
+	// MaxAttemptsPerIP is the number of failed attempts from an IP address before it is locked out
+	MaxAttemptsPerIP int64
+	// LockoutDuration is the first lockout, it doubles with every further failed attempt
+	LockoutDuration time.Duration
+	// MaxLockoutDuration caps the lockout and is how long failed attempts are remembered
+	MaxLockoutDuration time.Duration
+	// Allowlist holds the IP addresses and CIDR ranges that are never locked out by MaxAttemptsPerIP
+	Allowlist []*net.IPNet
+	// TrustedProxies holds the proxies whose X-Real-IP and X-Forwarded-For headers give the client address
+	TrustedProxies []*net.IPNet
+}
+
+// parseNetworks parses a comma separated list of IP addresses and CIDR ranges.
+func parseNetworks(s string) ([]*net.IPNet, error) {
+	var list []*net.IPNet
+	for _, entry := range util.SplitString(s) {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("could not parse the address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			list = append(list, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the network %q: %w", entry, err)
+		}
+		list = append(list, network)
+	}
+
+	return list, nil
+}
+
 func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	security := iniFile.Section("security")
 	cfg.SecretKey = valueAsString(security, "secret_key", "")
 	cfg.DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	cfg.BruteForceLoginProtection = BruteForceLoginProtectionSettings{
+		MaxAttempts:            security.Key("brute_force_login_protection_max_attempts").MustInt64(5),
+		MaxAttemptsPerUsername: security.Key("brute_force_login_protection_max_attempts_per_username").MustInt64(50),
+		MaxAttemptsPerIP:       security.Key("brute_force_login_protection_max_attempts_per_ip").MustInt64(0),
+		LockoutDuration:        security.Key("brute_force_login_protection_lockout_duration").MustDuration(time.Minute),
+		MaxLockoutDuration:     security.Key("brute_force_login_protection_max_lockout_duration").MustDuration(time.Hour),
+	}
+	if cfg.BruteForceLoginProtection.MaxLockoutDuration < cfg.BruteForceLoginProtection.LockoutDuration {
+		return errors.New("brute_force_login_protection_max_lockout_duration must be greater than brute_force_login_protection_lockout_duration")
+	}
+	allowlist, err := parseNetworks(valueAsString(security, "brute_force_login_protection_allowlist", ""))
+	if err != nil {
+		return fmt.Errorf("invalid brute_force_login_protection_allowlist: %w", err)
+	}
+	cfg.BruteForceLoginProtection.Allowlist = allowlist
+	trustedProxies, err := parseNetworks(valueAsString(security, "brute_force_login_protection_trusted_proxies", ""))
+	if err != nil {
+		return fmt.Errorf("invalid brute_force_login_protection_trusted_proxies: %w", err)
+	}
+	cfg.BruteForceLoginProtection.TrustedProxies = trustedProxies
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1498,6 +1591,35 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	return nil
 }
 
+// readOrgDurations parses a comma separated list of <org id>:<duration> pairs, e.g. "1:1h, 2:30m".
+// The durations can only shorten the limit they override.
+func readOrgDurations(value string, limit time.Duration) (map[int64]time.Duration, error) {
+	durations := make(map[int64]time.Duration)
+	for _, pair := range util.SplitString(value) {
+		orgIDStr, durationStr, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected <org id>:<duration> but got %q", pair)
+		}
+
+		orgID, err := strconv.ParseInt(orgIDStr, 10, 64)
+		if err != nil || orgID < 1 {
+			return nil, fmt.Errorf("invalid org id %q", orgIDStr)
+		}
+
+		duration, err := gtime.ParseDuration(durationStr)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 || duration > limit {
+			return nil, fmt.Errorf("duration of org %d must be positive and at most %s", orgID, limit)
+		}
+
+		durations[orgID] = duration
+	}
+
+	return durations, nil
+}
+
 func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	auth := iniFile.Section("auth")
 
@@ -1518,6 +1640,18 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		return err
 	}
 
+	cfg.LoginMaxInactiveLifetimeByOrg, err = readOrgDurations(valueAsString(auth, "login_maximum_inactive_lifetime_duration_per_org", ""), cfg.LoginMaxInactiveLifetime)
+	if err != nil {
+		return fmt.Errorf("invalid login_maximum_inactive_lifetime_duration_per_org: %w", err)
+	}
+
+	cfg.LoginMaxLifetimeByOrg, err = readOrgDurations(valueAsString(auth, "login_maximum_lifetime_duration_per_org", ""), cfg.LoginMaxLifetime)
+	if err != nil {
+		return fmt.Errorf("invalid login_maximum_lifetime_duration_per_org: %w", err)
+	}
+
+	cfg.LoginMaxConcurrentSessions = auth.Key("max_concurrent_sessions").MustInt64(0)
+
 	cfg.ApiKeyMaxSecondsToLive = auth.Key("api_key_max_seconds_to_live").MustInt64(-1)
 
 	cfg.TokenRotationIntervalMinutes = auth.Key("token_rotation_interval_minutes").MustInt(10)
@@ -1576,6 +1710,17 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	authBasic := iniFile.Section("auth.basic")
 	cfg.BasicAuthEnabled = authBasic.Key("enabled").MustBool(true)
 
+	// multi-factor authentication
+	authMFA := iniFile.Section("auth.mfa")
+	cfg.MFAEnabled = authMFA.Key("enabled").MustBool(false)
+	cfg.MFAIssuer = valueAsString(authMFA, "issuer", "Grafana")
+	cfg.MFARequiredForAdmins = authMFA.Key("require_for_admins").MustBool(false)
+
+	// SCIM provisioning
+	authSCIM := iniFile.Section("auth.scim")
+	cfg.SCIMEnabled = authSCIM.Key("enabled").MustBool(false)
+	cfg.SCIMDefaultOrgRole = valueAsString(authSCIM, "default_org_role", string(roletype.RoleViewer))
+
 	// JWT auth
 	authJWT := iniFile.Section("auth.jwt")
 	cfg.JWTAuthEnabled = authJWT.Key("enabled").MustBool(false)
@@ -1594,6 +1739,10 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.JWTAuthRoleAttributeStrict = authJWT.Key("role_attribute_strict").MustBool(false)
 	cfg.JWTAuthAllowAssignGrafanaAdmin = authJWT.Key("allow_assign_grafana_admin").MustBool(false)
 	cfg.JWTAuthSkipOrgRoleSync = authJWT.Key("skip_org_role_sync").MustBool(false)
+	cfg.JWTAuthIssuers, err = readJWTIssuers(iniFile.Sections())
+	if err != nil {
+		return err
+	}
 
 	// Extended JWT auth
 	authExtendedJWT := cfg.SectionWithEnvOverrides("auth.extended_jwt")
@@ -1707,6 +1856,31 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+
+	maxLifetime := time.Duration(math.MaxInt64)
+	if cfg.SATokenExpirationDayLimit > 0 {
+		maxLifetime = time.Duration(cfg.SATokenExpirationDayLimit) * 24 * time.Hour
+	}
+
+	var err error
+	cfg.SATokenMaxLifetimeByOrg, err = readOrgDurations(valueAsString(serviceAccount, "token_max_lifetime_per_org", ""), maxLifetime)
+	if err != nil {
+		return fmt.Errorf("invalid token_max_lifetime_per_org: %w", err)
+	}
+
+	cfg.SATokenRotationOverlap, err = gtime.ParseDuration(valueAsString(serviceAccount, "token_rotation_overlap", "24h"))
+	if err != nil {
+		return fmt.Errorf("invalid token_rotation_overlap: %w", err)
+	}
+	if cfg.SATokenRotationOverlap < 0 {
+		return errors.New("token_rotation_overlap must not be negative")
+	}
+
+	cfg.SATokenExpiryWarning, err = gtime.ParseDuration(valueAsString(serviceAccount, "token_expiry_warning", "0"))
+	if err != nil {
+		return fmt.Errorf("invalid token_expiry_warning: %w", err)
+	}
+
 	return nil
 }
 
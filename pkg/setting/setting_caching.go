@@ -0,0 +1,29 @@
+package setting
+
+import "time"
+
+// QueryCachingSettings configures the caching of data source query and resource responses
+// in the remote cache.
+type QueryCachingSettings struct {
+	Enabled bool
+	// TTL of cached query responses when neither the query nor the data source sets one
+	TTL time.Duration
+	// TTL of cached resource responses
+	ResourcesTTL time.Duration
+	// TimeRangeAlignment truncates the time range of queries when computing cache keys, so
+	// relative time ranges issued moments apart share the same cache entry
+	TimeRangeAlignment time.Duration
+	// MaxValueSize is the largest response, in bytes, that is stored in the cache
+	MaxValueSize int
+}
+
+func (cfg *Cfg) readQueryCachingSettings() {
+	section := cfg.Raw.Section("caching")
+	cfg.QueryCaching = QueryCachingSettings{
+		Enabled:            section.Key("enabled").MustBool(false),
+		TTL:                section.Key("ttl").MustDuration(5 * time.Minute),
+		ResourcesTTL:       section.Key("resources_ttl").MustDuration(5 * time.Minute),
+		TimeRangeAlignment: section.Key("time_range_alignment").MustDuration(time.Minute),
+		MaxValueSize:       section.Key("max_value_mb").MustInt(10) * 1024 * 1024,
+	}
+}
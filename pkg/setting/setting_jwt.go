@@ -0,0 +1,85 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const jwtIssuerSectionPrefix = "auth.jwt.issuer."
+
+// JWTIssuerSettings holds the key set and claim mappings used for the JWTs of one issuer.
+type JWTIssuerSettings struct {
+	Name string
+	// Issuer is matched against the iss claim of the JWT.
+	Issuer string
+
+	JWKSetURL  string
+	JWKSetFile string
+	KeyFile    string
+	KeyID      string
+	CacheTTL   time.Duration
+
+	ExpectClaims string
+	Audience     []string
+
+	UsernameClaim           string
+	EmailClaim              string
+	RoleAttributePath       string
+	RoleAttributeStrict     bool
+	AllowAssignGrafanaAdmin bool
+	GroupsAttributePath     string
+	// OrgID is the organization the role of the user is assigned in, 0 uses auto_assign_org_id.
+	OrgID int64
+}
+
+// readJWTIssuers reads the [auth.jwt.issuer.<name>] sections.
+func readJWTIssuers(sections []*ini.Section) ([]JWTIssuerSettings, error) {
+	issuers := make([]JWTIssuerSettings, 0)
+	seen := make(map[string]string)
+
+	for _, section := range sections {
+		if !strings.HasPrefix(section.Name(), jwtIssuerSectionPrefix) {
+			continue
+		}
+
+		issuer := JWTIssuerSettings{
+			Name:                    strings.TrimPrefix(section.Name(), jwtIssuerSectionPrefix),
+			Issuer:                  valueAsString(section, "issuer", ""),
+			JWKSetURL:               valueAsString(section, "jwk_set_url", ""),
+			JWKSetFile:              valueAsString(section, "jwk_set_file", ""),
+			KeyFile:                 valueAsString(section, "key_file", ""),
+			KeyID:                   section.Key("key_id").MustString(""),
+			CacheTTL:                section.Key("cache_ttl").MustDuration(time.Minute * 60),
+			ExpectClaims:            valueAsString(section, "expect_claims", "{}"),
+			Audience:                util.SplitString(valueAsString(section, "audience", "")),
+			UsernameClaim:           valueAsString(section, "username_claim", ""),
+			EmailClaim:              valueAsString(section, "email_claim", ""),
+			RoleAttributePath:       valueAsString(section, "role_attribute_path", ""),
+			RoleAttributeStrict:     section.Key("role_attribute_strict").MustBool(false),
+			AllowAssignGrafanaAdmin: section.Key("allow_assign_grafana_admin").MustBool(false),
+			GroupsAttributePath:     valueAsString(section, "groups_attribute_path", ""),
+			OrgID:                   section.Key("org_id").MustInt64(0),
+		}
+
+		if issuer.Issuer == "" {
+			return nil, fmt.Errorf("[%s] issuer must be set", section.Name())
+		}
+		if other, ok := seen[issuer.Issuer]; ok {
+			return nil, fmt.Errorf("[%s] issuer %q is already configured in [%s%s]",
+				section.Name(), issuer.Issuer, jwtIssuerSectionPrefix, other)
+		}
+		if issuer.OrgID < 0 {
+			return nil, fmt.Errorf("[%s] org_id must not be negative", section.Name())
+		}
+		seen[issuer.Issuer] = issuer.Name
+
+		issuers = append(issuers, issuer)
+	}
+
+	return issuers, nil
+}
@@ -0,0 +1,84 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadJWTIssuers(t *testing.T) {
+	t.Run("reads issuer sections", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[auth.jwt]
+enabled = true
+
+[auth.jwt.issuer.okta]
+issuer = https://example.okta.com
+jwk_set_url = https://example.okta.com/oauth2/v1/keys
+audience = grafana, grafana-api
+username_claim = preferred_username
+email_claim = email
+role_attribute_path = contains(groups[*], 'admin') && 'Admin' || 'Viewer'
+groups_attribute_path = groups
+org_id = 2
+
+[auth.jwt.issuer.internal]
+issuer = internal-idp
+key_file = /etc/grafana/internal.pem
+cache_ttl = 5m
+`))
+		require.NoError(t, err)
+
+		issuers, err := readJWTIssuers(f.Sections())
+		require.NoError(t, err)
+		require.Len(t, issuers, 2)
+
+		assert.Equal(t, JWTIssuerSettings{
+			Name:                "okta",
+			Issuer:              "https://example.okta.com",
+			JWKSetURL:           "https://example.okta.com/oauth2/v1/keys",
+			CacheTTL:            time.Hour,
+			ExpectClaims:        "{}",
+			Audience:            []string{"grafana", "grafana-api"},
+			UsernameClaim:       "preferred_username",
+			EmailClaim:          "email",
+			RoleAttributePath:   "contains(groups[*], 'admin') && 'Admin' || 'Viewer'",
+			GroupsAttributePath: "groups",
+			OrgID:               2,
+		}, issuers[0])
+
+		assert.Equal(t, "internal", issuers[1].Name)
+		assert.Equal(t, "/etc/grafana/internal.pem", issuers[1].KeyFile)
+		assert.Equal(t, 5*time.Minute, issuers[1].CacheTTL)
+		assert.Empty(t, issuers[1].Audience)
+	})
+
+	for desc, content := range map[string]string{
+		"missing issuer": `
+[auth.jwt.issuer.okta]
+jwk_set_url = https://example.okta.com/oauth2/v1/keys
+`,
+		"duplicate issuer": `
+[auth.jwt.issuer.okta]
+issuer = https://example.okta.com
+[auth.jwt.issuer.other]
+issuer = https://example.okta.com
+`,
+		"negative org id": `
+[auth.jwt.issuer.okta]
+issuer = https://example.okta.com
+org_id = -1
+`,
+	} {
+		t.Run("rejects "+desc, func(t *testing.T) {
+			f, err := ini.Load([]byte(content))
+			require.NoError(t, err)
+
+			_, err = readJWTIssuers(f.Sections())
+			require.Error(t, err)
+		})
+	}
+}
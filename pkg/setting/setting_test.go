@@ -397,6 +397,70 @@ func TestAuthDurationSettings(t *testing.T) {
 	err = readAuthSettings(f, cfg)
 	require.NoError(t, err)
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
+
+	f = ini.Empty()
+	sec, err = f.NewSection("auth")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_maximum_inactive_lifetime_duration_per_org", "1:1h, 2:30m")
+	require.NoError(t, err)
+	_, err = sec.NewKey("login_maximum_lifetime_duration_per_org", "2:12h")
+	require.NoError(t, err)
+	err = readAuthSettings(f, cfg)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]time.Duration{1: time.Hour, 2: 30 * time.Minute}, cfg.LoginMaxInactiveLifetimeByOrg)
+	require.Equal(t, map[int64]time.Duration{2: 12 * time.Hour}, cfg.LoginMaxLifetimeByOrg)
+
+	for _, invalid := range []string{"1", "org:1h", "1:1x", "1:0s", "1:10d"} {
+		f = ini.Empty()
+		sec, err = f.NewSection("auth")
+		require.NoError(t, err)
+		_, err = sec.NewKey("login_maximum_inactive_lifetime_duration_per_org", invalid)
+		require.NoError(t, err)
+		err = readAuthSettings(f, cfg)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestServiceAccountSettings(t *testing.T) {
+	cfg := NewCfg()
+	err := readServiceAccountSettings(ini.Empty(), cfg)
+	require.NoError(t, err)
+	require.Empty(t, cfg.SATokenMaxLifetimeByOrg)
+	require.Equal(t, 24*time.Hour, cfg.SATokenRotationOverlap)
+	require.Zero(t, cfg.SATokenExpiryWarning)
+
+	f := ini.Empty()
+	sec, err := f.NewSection("service_accounts")
+	require.NoError(t, err)
+	_, err = sec.NewKey("token_expiration_day_limit", "90")
+	require.NoError(t, err)
+	_, err = sec.NewKey("token_max_lifetime_per_org", "1:30d, 2:720h")
+	require.NoError(t, err)
+	_, err = sec.NewKey("token_rotation_overlap", "1h")
+	require.NoError(t, err)
+	_, err = sec.NewKey("token_expiry_warning", "7d")
+	require.NoError(t, err)
+	err = readServiceAccountSettings(f, cfg)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]time.Duration{1: 30 * 24 * time.Hour, 2: 720 * time.Hour}, cfg.SATokenMaxLifetimeByOrg)
+	require.Equal(t, time.Hour, cfg.SATokenRotationOverlap)
+	require.Equal(t, 7*24*time.Hour, cfg.SATokenExpiryWarning)
+
+	for key, invalid := range map[string]string{
+		"token_max_lifetime_per_org": "1:91d",
+		"token_rotation_overlap":     "-1h",
+		"token_expiry_warning":       "soon",
+	} {
+		f = ini.Empty()
+		sec, err = f.NewSection("service_accounts")
+		require.NoError(t, err)
+		_, err = sec.NewKey("token_expiration_day_limit", "90")
+		require.NoError(t, err)
+		_, err = sec.NewKey(key, invalid)
+		require.NoError(t, err)
+		err = readServiceAccountSettings(f, cfg)
+		require.Error(t, err, key)
+	}
 }
 
 func TestGetCDNPath(t *testing.T) {
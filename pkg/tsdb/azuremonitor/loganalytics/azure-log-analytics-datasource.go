@@ -316,11 +316,51 @@ func (e *AzureLogAnalyticsDatasource) executeQuery(ctx context.Context, query *A
 		return nil, err
 	}
 
+	// Follow the next links of paged results until all rows or the row limit are read.
+	// Unpaged results are only limited when the data source sets a row limit.
+	limit := dsInfo.Settings.ResultRowLimit()
+	paged := logResponse.NextLink != nil && *logResponse.NextLink != ""
+	truncated := false
+	if (paged || dsInfo.Settings.MaxResultRows > 0) && len(t.Rows) > limit {
+		t.Rows = t.Rows[:limit]
+		truncated = true
+	}
+	for next := logResponse.NextLink; next != nil && *next != ""; {
+		if len(t.Rows) >= limit {
+			truncated = true
+			break
+		}
+		page, err := e.getNextPage(ctx, client, req, query, *next)
+		if err != nil {
+			return nil, err
+		}
+		pageTable, err := page.GetPrimaryResultTable()
+		if err != nil {
+			return nil, err
+		}
+		if truncated, err = t.AppendPage(*pageTable, limit); err != nil {
+			return nil, err
+		}
+		if logResponse.Error == nil {
+			logResponse.Error = page.Error
+		}
+		if len(pageTable.Rows) == 0 {
+			break
+		}
+		next = page.NextLink
+	}
+	if truncated {
+		e.Logger.Warn("Azure Log Analytics query results were truncated", "refId", query.RefID, "limit", limit)
+	}
+
 	frame, err := ResponseTableToFrame(t, query.RefID, query.Query, query.QueryType, query.ResultFormat)
 	if err != nil {
 		return nil, err
 	}
 	frame = appendErrorNotice(frame, logResponse.Error)
+	if truncated && frame != nil {
+		frame.AppendNotices(types.TruncatedResultNotice(limit))
+	}
 	if frame == nil {
 		dataResponse := backend.DataResponse{}
 		return &dataResponse, nil
@@ -511,6 +551,30 @@ func (e *AzureLogAnalyticsDatasource) createRequest(ctx context.Context, queryUR
 	return req, nil
 }
 
+// getNextPage requests the next page of a paged result. The next link must point to the
+// host of the first request, as the client authenticates every request.
+func (e *AzureLogAnalyticsDatasource) getNextPage(ctx context.Context, client *http.Client, firstReq *http.Request, query *AzureLogAnalyticsQuery, nextLink string) (AzureLogAnalyticsResponse, error) {
+	nextURL, err := url.Parse(nextLink)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, fmt.Errorf("invalid next link: %w", err)
+	}
+	if nextURL.IsAbs() && nextURL.Host != firstReq.URL.Host {
+		return AzureLogAnalyticsResponse{}, fmt.Errorf("next link host %q does not match the query host", nextURL.Host)
+	}
+
+	req, err := e.createRequest(ctx, firstReq.URL.String(), query)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, err
+	}
+	req.URL = firstReq.URL.ResolveReference(nextURL)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return AzureLogAnalyticsResponse{}, err
+	}
+	return e.unmarshalResponse(res)
+}
+
 type AzureLogAnalyticsURLResources struct {
 	Resources []AzureLogAnalyticsURLResource `json:"resources"`
 }
@@ -683,6 +747,8 @@ type AzureLogAnalyticsInnerError struct {
 type AzureLogAnalyticsResponse struct {
 	Tables []types.AzureResponseTable `json:"tables"`
 	Error  *AzureLogAnalyticsAPIError `json:"error,omitempty"`
+	// NextLink is set when more pages of results are available
+	NextLink *string `json:"@odata.nextLink,omitempty"`
 }
 
 type AzureCorrelationAPIResponse struct {
@@ -7,13 +7,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-azure-sdk-go/azsettings"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/kinds/dataquery"
@@ -1577,3 +1580,69 @@ func Test_executeQueryErrorWithDifferentLogAnalyticsCreds(t *testing.T) {
 		t.Error("expecting the error to inform of bad credentials")
 	}
 }
+
+func Test_executeQueryFollowsNextLinks(t *testing.T) {
+	fixture, err := os.ReadFile("../testdata/loganalytics/6-log-analytics-response-table.json")
+	require.NoError(t, err)
+
+	newServer := func(t *testing.T, nextLink func(server *httptest.Server) string) *httptest.Server {
+		t.Helper()
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response := map[string]any{}
+			require.NoError(t, json.Unmarshal(fixture, &response))
+			if link := nextLink(server); link != "" && r.URL.Path != "/page2" {
+				response["@odata.nextLink"] = link
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(response))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	query := &AzureLogAnalyticsQuery{
+		RefID:        "A",
+		ResultFormat: dataquery.ResultFormatTable,
+		URL:          "v1/workspaces/ws/query",
+		JSON:         []byte(`{}`),
+		QueryType:    dataquery.AzureQueryTypeAzureLogAnalytics,
+		Query:        "Perf",
+	}
+
+	t.Run("appends the rows of the next pages", func(t *testing.T) {
+		server := newServer(t, func(server *httptest.Server) string { return server.URL + "/page2" })
+		ds := AzureLogAnalyticsDatasource{Logger: log.DefaultLogger}
+		res, err := ds.executeQuery(context.Background(), query, types.DatasourceInfo{Cloud: azsettings.AzurePublic}, server.Client(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, 6, res.Frames[0].Rows())
+		require.Empty(t, res.Frames[0].Meta.Notices)
+	})
+
+	t.Run("stops at the row limit and adds a notice", func(t *testing.T) {
+		server := newServer(t, func(server *httptest.Server) string { return server.URL + "/page2" })
+		ds := AzureLogAnalyticsDatasource{Logger: log.DefaultLogger}
+		dsInfo := types.DatasourceInfo{Cloud: azsettings.AzurePublic, Settings: types.AzureMonitorSettings{MaxResultRows: 4}}
+		res, err := ds.executeQuery(context.Background(), query, dsInfo, server.Client(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, 4, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		require.Contains(t, res.Frames[0].Meta.Notices[0].Text, "truncated to 4 rows")
+	})
+
+	t.Run("limits unpaged results when the data source sets a row limit", func(t *testing.T) {
+		server := newServer(t, func(*httptest.Server) string { return "" })
+		ds := AzureLogAnalyticsDatasource{Logger: log.DefaultLogger}
+		dsInfo := types.DatasourceInfo{Cloud: azsettings.AzurePublic, Settings: types.AzureMonitorSettings{MaxResultRows: 2}}
+		res, err := ds.executeQuery(context.Background(), query, dsInfo, server.Client(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("does not follow next links to other hosts", func(t *testing.T) {
+		server := newServer(t, func(*httptest.Server) string { return "https://example.com/page2" })
+		ds := AzureLogAnalyticsDatasource{Logger: log.DefaultLogger}
+		_, err := ds.executeQuery(context.Background(), query, types.DatasourceInfo{Cloud: azsettings.AzurePublic}, server.Client(), server.URL)
+		require.ErrorContains(t, err, "does not match the query host")
+	})
+}
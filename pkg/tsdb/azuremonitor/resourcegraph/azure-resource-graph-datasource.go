@@ -27,6 +27,10 @@ import (
 // AzureResourceGraphResponse is the json response object from the Azure Resource Graph Analytics API.
 type AzureResourceGraphResponse struct {
 	Data types.AzureResponseTable `json:"data"`
+	// SkipToken is set when more pages of results are available
+	SkipToken       string `json:"$skipToken,omitempty"`
+	TotalRecords    int64  `json:"totalRecords,omitempty"`
+	ResultTruncated string `json:"resultTruncated,omitempty"`
 }
 
 // AzureResourceGraphDatasource calls the Azure Resource Graph API's
@@ -50,6 +54,9 @@ type AzureResourceGraphQuery struct {
 const ArgAPIVersion = "2021-06-01-preview"
 const argQueryProviderName = "/providers/Microsoft.ResourceGraph/resources"
 
+// argMaxPageSize is the largest number of rows the Resource Graph API returns in a page
+const argMaxPageSize = 1000
+
 func (e *AzureResourceGraphDatasource) ResourceRequest(rw http.ResponseWriter, req *http.Request, cli *http.Client) (http.ResponseWriter, error) {
 	return e.Proxy.Do(rw, req, cli)
 }
@@ -133,25 +140,6 @@ func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *
 		return nil, err
 	}
 
-	reqBody, err := json.Marshal(map[string]any{
-		"subscriptions": model.Subscriptions,
-		"query":         query.InterpolatedQuery,
-		"options":       map[string]string{"resultFormat": "table"},
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := e.createRequest(ctx, reqBody, dsURL)
-
-	if err != nil {
-		return nil, err
-	}
-
-	req.URL.Path = path.Join(req.URL.Path, argQueryProviderName)
-	req.URL.RawQuery = params.Encode()
-
 	_, span := tracing.DefaultTracer().Start(ctx, "azure resource graph query", trace.WithAttributes(
 		attribute.String("interpolated_query", query.InterpolatedQuery),
 		attribute.Int64("from", query.TimeRange.From.UnixNano()/int64(time.Millisecond)),
@@ -164,23 +152,71 @@ func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *
 	defer span.End()
 	e.Logger.Debug("azure resource graph query", "traceID", trace.SpanContextFromContext(ctx).TraceID())
 
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	// Follow the skip tokens of paged results until all rows or the row limit are read
+	limit := dsInfo.Settings.ResultRowLimit()
+	var (
+		table     *types.AzureResponseTable
+		truncated bool
+		skipToken string
+		req       *http.Request
+	)
+	for {
+		read := 0
+		if table != nil {
+			read = len(table.Rows)
+		}
+		options := map[string]any{
+			"resultFormat": "table",
+			"$top":         min(argMaxPageSize, limit-read),
+		}
+		if skipToken != "" {
+			options["$skipToken"] = skipToken
+		}
 
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			backend.Logger.Warn("Failed to close response body", "err", err)
+		reqBody, err := json.Marshal(map[string]any{
+			"subscriptions": model.Subscriptions,
+			"query":         query.InterpolatedQuery,
+			"options":       options,
+		})
+		if err != nil {
+			return nil, err
 		}
-	}()
 
-	argResponse, err := e.unmarshalResponse(res)
-	if err != nil {
-		return nil, err
+		req, err = e.createRequest(ctx, reqBody, dsURL)
+		if err != nil {
+			return nil, err
+		}
+		req.URL.Path = path.Join(req.URL.Path, argQueryProviderName)
+		req.URL.RawQuery = params.Encode()
+
+		argResponse, err := e.doRequest(req, client)
+		if err != nil {
+			return nil, err
+		}
+
+		if table == nil {
+			table = &types.AzureResponseTable{Name: argResponse.Data.Name, Columns: argResponse.Data.Columns}
+		}
+		pageTruncated, err := table.AppendPage(argResponse.Data, limit)
+		if err != nil {
+			return nil, err
+		}
+		truncated = truncated || pageTruncated || argResponse.ResultTruncated == "true"
+
+		skipToken = argResponse.SkipToken
+		if skipToken == "" || len(argResponse.Data.Rows) == 0 {
+			break
+		}
+		if len(table.Rows) >= limit {
+			truncated = true
+			break
+		}
+	}
+	if truncated {
+		e.Logger.Warn("Azure Resource Graph query results were truncated", "refId", query.RefID, "limit", limit)
 	}
 
-	frame, err := loganalytics.ResponseTableToFrame(&argResponse.Data, query.RefID, query.InterpolatedQuery, dataquery.AzureQueryType(query.QueryType), dataquery.ResultFormat(query.ResultFormat))
+	frame, err := loganalytics.ResponseTableToFrame(table, query.RefID, query.InterpolatedQuery, dataquery.AzureQueryType(query.QueryType), dataquery.ResultFormat(query.ResultFormat))
 	if err != nil {
 		return nil, err
 	}
@@ -201,12 +237,30 @@ func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *
 		frameWithLink.Meta = &data.FrameMeta{}
 	}
 	frameWithLink.Meta.ExecutedQueryString = req.URL.RawQuery
+	if truncated {
+		frameWithLink.AppendNotices(types.TruncatedResultNotice(limit))
+	}
 
 	dataResponse := backend.DataResponse{}
 	dataResponse.Frames = data.Frames{&frameWithLink}
 	return &dataResponse, nil
 }
 
+func (e *AzureResourceGraphDatasource) doRequest(req *http.Request, client *http.Client) (AzureResourceGraphResponse, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return AzureResourceGraphResponse{}, err
+	}
+
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			backend.Logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	return e.unmarshalResponse(res)
+}
+
 func (e *AzureResourceGraphDatasource) createRequest(ctx context.Context, reqBody []byte, url string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBody))
 	if err != nil {
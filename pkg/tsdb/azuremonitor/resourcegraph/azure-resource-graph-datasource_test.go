@@ -2,8 +2,11 @@ package resourcegraph
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-azure-sdk-go/azsettings"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/stretchr/testify/assert"
@@ -195,3 +199,63 @@ func TestUnmarshalResponse200(t *testing.T) {
 	assert.NoError(t, err2)
 	assert.Equal(t, expectedRes, res)
 }
+
+func TestExecuteQueryFollowsSkipTokens(t *testing.T) {
+	fixture, err := os.ReadFile("../testdata/loganalytics/6-log-analytics-response-table.json")
+	require.NoError(t, err)
+	var laResponse struct {
+		Tables []map[string]any `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(fixture, &laResponse))
+
+	newServer := func(t *testing.T, options *[]map[string]any) *httptest.Server {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Options map[string]any `json:"options"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*options = append(*options, body.Options)
+
+			response := map[string]any{"data": laResponse.Tables[0]}
+			if body.Options["$skipToken"] == nil {
+				response["$skipToken"] = "page2"
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(response))
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	query := &AzureResourceGraphQuery{
+		RefID:             "A",
+		ResultFormat:      "table",
+		JSON:              []byte(`{}`),
+		InterpolatedQuery: "resources",
+	}
+
+	t.Run("appends the rows of the next pages", func(t *testing.T) {
+		var options []map[string]any
+		server := newServer(t, &options)
+		ds := AzureResourceGraphDatasource{Logger: log.DefaultLogger}
+		res, err := ds.executeQuery(context.Background(), query, types.DatasourceInfo{Cloud: azsettings.AzurePublic}, server.Client(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, 6, res.Frames[0].Rows())
+		require.Empty(t, res.Frames[0].Meta.Notices)
+		require.Len(t, options, 2)
+		assert.Equal(t, float64(argMaxPageSize), options[0]["$top"])
+		assert.Equal(t, "page2", options[1]["$skipToken"])
+	})
+
+	t.Run("stops at the row limit and adds a notice", func(t *testing.T) {
+		var options []map[string]any
+		server := newServer(t, &options)
+		ds := AzureResourceGraphDatasource{Logger: log.DefaultLogger}
+		dsInfo := types.DatasourceInfo{Cloud: azsettings.AzurePublic, Settings: types.AzureMonitorSettings{MaxResultRows: 5}}
+		res, err := ds.executeQuery(context.Background(), query, dsInfo, server.Client(), server.URL)
+		require.NoError(t, err)
+		require.Equal(t, 5, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		assert.Equal(t, float64(2), options[1]["$top"])
+	})
+}
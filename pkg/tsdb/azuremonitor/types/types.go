@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana-azure-sdk-go/azcredentials"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/kinds/dataquery"
 )
 
@@ -21,6 +22,10 @@ const (
 	Trace      = "trace"
 )
 
+// DefaultMaxResultRows is the number of rows read from paged Log Analytics and Resource Graph
+// responses unless the data source sets maxResultRows.
+const DefaultMaxResultRows = 50000
+
 var (
 	LegendKeyFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 )
@@ -35,6 +40,15 @@ type AzureMonitorSettings struct {
 	SubscriptionId               string `json:"subscriptionId"`
 	LogAnalyticsDefaultWorkspace string `json:"logAnalyticsDefaultWorkspace"`
 	AppInsightsAppId             string `json:"appInsightsAppId"`
+	MaxResultRows                int    `json:"maxResultRows,omitempty"`
+}
+
+// ResultRowLimit returns the maximum number of rows to read when following result pages.
+func (s AzureMonitorSettings) ResultRowLimit() int {
+	if s.MaxResultRows > 0 {
+		return s.MaxResultRows
+	}
+	return DefaultMaxResultRows
 }
 
 // AzureMonitorCustomizedCloudSettings is the extended Azure Monitor settings for customized cloud
@@ -124,6 +138,36 @@ type AzureResponseTable struct {
 	Rows [][]any `json:"rows"`
 }
 
+// AppendPage appends the rows of the next page of a paged result, keeping at most limit rows.
+// It returns true if rows were dropped.
+func (t *AzureResponseTable) AppendPage(page AzureResponseTable, limit int) (bool, error) {
+	if len(page.Columns) != len(t.Columns) {
+		return false, fmt.Errorf("result page has %d columns, expected %d", len(page.Columns), len(t.Columns))
+	}
+	for i, column := range page.Columns {
+		if column.Name != t.Columns[i].Name {
+			return false, fmt.Errorf("result page has column %q, expected %q", column.Name, t.Columns[i].Name)
+		}
+	}
+
+	rows := page.Rows
+	truncated := false
+	if remaining := limit - len(t.Rows); len(rows) > remaining {
+		rows = rows[:max(remaining, 0)]
+		truncated = true
+	}
+	t.Rows = append(t.Rows, rows...)
+	return truncated, nil
+}
+
+// TruncatedResultNotice tells users that rows beyond the limit were not read.
+func TruncatedResultNotice(limit int) data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Query results were truncated to %d rows. Refine the query or increase the maximum result rows of the data source to read all rows.", limit),
+	}
+}
+
 type AzureMonitorResource struct {
 	ResourceGroup string `json:"resourceGroup"`
 	ResourceName  string `json:"resourceName"`
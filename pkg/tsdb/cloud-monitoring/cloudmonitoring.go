@@ -146,6 +146,7 @@ type datasourceInfo struct {
 	tokenUri           string
 	services           map[string]datasourceService
 	privateKey         string
+	promQLOnly         bool
 }
 
 type datasourceJSONData struct {
@@ -153,6 +154,8 @@ type datasourceJSONData struct {
 	DefaultProject     string `json:"defaultProject"`
 	ClientEmail        string `json:"clientEmail"`
 	TokenURI           string `json:"tokenUri"`
+	// PromQLOnly converts MQL queries to PromQL before running them and rejects the ones that cannot be converted.
+	PromQLOnly bool `json:"promQLOnly"`
 }
 
 type datasourceService struct {
@@ -180,6 +183,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			defaultProject:     jsonData.DefaultProject,
 			clientEmail:        jsonData.ClientEmail,
 			tokenUri:           jsonData.TokenURI,
+			promQLOnly:         jsonData.PromQLOnly,
 			services:           map[string]datasourceService{},
 		}
 
@@ -347,17 +351,35 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	rejected := map[string]backend.DataResponse{}
+	if dsInfo.promQLOnly {
+		rejected, err = promQLOnlyQueries(req)
+		if err != nil {
+			return nil, err
+		}
+		if len(req.Queries) == 0 {
+			return &backend.QueryDataResponse{Responses: rejected}, nil
+		}
+	}
+
 	queries, err := s.buildQueryExecutors(logger, req)
 	if err != nil {
 		return nil, err
 	}
 
+	var resp *backend.QueryDataResponse
 	switch req.Queries[0].QueryType {
 	case string(dataquery.QueryTypeAnnotation):
-		return s.executeAnnotationQuery(ctx, req, *dsInfo, queries)
+		resp, err = s.executeAnnotationQuery(ctx, req, *dsInfo, queries)
 	default:
-		return s.executeTimeSeriesQuery(ctx, req, *dsInfo, queries)
+		resp, err = s.executeTimeSeriesQuery(ctx, req, *dsInfo, queries)
+	}
+	if resp != nil {
+		for refID, dr := range rejected {
+			resp.Responses[refID] = dr
+		}
 	}
+	return resp, err
 }
 
 func (s *Service) executeTimeSeriesQuery(ctx context.Context, req *backend.QueryDataRequest, dsInfo datasourceInfo, queries []cloudMonitoringQueryExecutor) (
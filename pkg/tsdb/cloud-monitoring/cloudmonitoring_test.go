@@ -36,7 +36,7 @@ func TestNewInstanceSettings(t *testing.T) {
 		cli := httpclient.NewProvider()
 		f := newInstanceSettings(cli)
 		dsInfo, err := f(context.Background(), backend.DataSourceInstanceSettings{
-			JSONData: json.RawMessage(`{"authenticationType": "test", "defaultProject": "test", "clientEmail": "test", "tokenUri": "test"}`),
+			JSONData: json.RawMessage(`{"authenticationType": "test", "defaultProject": "test", "clientEmail": "test", "tokenUri": "test", "promQLOnly": true}`),
 		})
 		require.NoError(t, err)
 		assert.NotNil(t, dsInfo)
@@ -45,6 +45,7 @@ func TestNewInstanceSettings(t *testing.T) {
 		assert.Equal(t, "test", dsInfoCasted.defaultProject)
 		assert.Equal(t, "test", dsInfoCasted.clientEmail)
 		assert.Equal(t, "test", dsInfoCasted.tokenUri)
+		assert.True(t, dsInfoCasted.promQLOnly)
 	})
 }
 
@@ -0,0 +1,262 @@
+package cloudmonitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloud-monitoring/kinds/dataquery"
+)
+
+// defaultPromQLStep matches the min step the query editor sets for new PromQL queries.
+const defaultPromQLStep = "10s"
+
+// mqlMigrationResult reports the outcome of migrating a single MQL query to PromQL.
+type mqlMigrationResult struct {
+	PanelID     int64          `json:"panelId,omitempty"`
+	PanelTitle  string         `json:"panelTitle,omitempty"`
+	RefID       string         `json:"refId"`
+	MQL         string         `json:"mql"`
+	Converted   bool           `json:"converted"`
+	Query       map[string]any `json:"query,omitempty"`
+	Unsupported []string       `json:"unsupported,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+}
+
+type mqlMigrationRequest struct {
+	Query map[string]any `json:"query"`
+}
+
+type dashboardMigrationRequest struct {
+	Dashboard map[string]any `json:"dashboard"`
+}
+
+type dashboardMigrationResponse struct {
+	Dashboard map[string]any       `json:"dashboard"`
+	Queries   []mqlMigrationResult `json:"queries"`
+}
+
+// isMQLQuery reports whether a saved query is a Cloud Monitoring MQL query.
+func isMQLQuery(query map[string]any) bool {
+	if _, ok := query["timeSeriesQuery"].(map[string]any); !ok {
+		return false
+	}
+	queryType, ok := query["queryType"].(string)
+	return !ok || queryType == string(dataquery.QueryTypeTimeSeriesQuery)
+}
+
+// migrateMQLQuery converts a saved MQL query into a PromQL query. The returned query keeps all the
+// properties of the original one but the MQL specific ones, and is only set when the conversion succeeded.
+func migrateMQLQuery(query map[string]any) mqlMigrationResult {
+	tsq := query["timeSeriesQuery"].(map[string]any)
+	result := mqlMigrationResult{
+		RefID: toString(query["refId"]),
+		MQL:   toString(tsq["query"]),
+	}
+
+	conversion := mqlToPromQL(result.MQL)
+	result.Converted = conversion.converted()
+	result.Unsupported = conversion.Unsupported
+	result.Warnings = conversion.Warnings
+	if !result.Converted {
+		return result
+	}
+
+	step := conversion.Step
+	if step == "" {
+		if graphPeriod := toString(tsq["graphPeriod"]); mqlDurationExp.MatchString(graphPeriod) {
+			step = graphPeriod
+		} else {
+			step = defaultPromQLStep
+		}
+	}
+
+	migrated := make(map[string]any, len(query))
+	for k, v := range query {
+		migrated[k] = v
+	}
+	delete(migrated, "timeSeriesQuery")
+	migrated["queryType"] = string(dataquery.QueryTypePromQL)
+	migrated["promQLQuery"] = dataquery.PromQLQuery{
+		Expr:        conversion.Expr,
+		ProjectName: toString(tsq["projectName"]),
+		Step:        step,
+	}
+	result.Query = migrated
+	return result
+}
+
+// migrateDashboardMQLQueries converts the MQL queries of every panel of the dashboard, including
+// panels nested in collapsed rows. Queries that cannot be fully converted are left untouched and
+// reported so they can be rewritten by hand.
+func migrateDashboardMQLQueries(dashboard map[string]any, datasourceUID string) []mqlMigrationResult {
+	results := []mqlMigrationResult{}
+	panels, _ := dashboard["panels"].([]any)
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		results = append(results, migratePanelMQLQueries(panel, datasourceUID)...)
+		if rowPanels, ok := panel["panels"].([]any); ok {
+			for _, rp := range rowPanels {
+				if rowPanel, ok := rp.(map[string]any); ok {
+					results = append(results, migratePanelMQLQueries(rowPanel, datasourceUID)...)
+				}
+			}
+		}
+	}
+	return results
+}
+
+func migratePanelMQLQueries(panel map[string]any, datasourceUID string) []mqlMigrationResult {
+	results := []mqlMigrationResult{}
+	targets, _ := panel["targets"].([]any)
+	for i, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok || !isMQLQuery(target) {
+			continue
+		}
+		ref := target["datasource"]
+		if ref == nil {
+			ref = panel["datasource"]
+		}
+		if !isDatasourceRef(ref, datasourceUID) {
+			continue
+		}
+
+		result := migrateMQLQuery(target)
+		if id, ok := panel["id"].(float64); ok {
+			result.PanelID = int64(id)
+		}
+		result.PanelTitle = toString(panel["title"])
+		if result.Converted {
+			targets[i] = result.Query
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// isDatasourceRef reports whether the datasource reference points to the datasource with the given UID.
+// References using a template variable are expected to resolve to it, as only the
+// datasource that owns the dashboard queries is asked to migrate them.
+func isDatasourceRef(ref any, uid string) bool {
+	r, ok := ref.(map[string]any)
+	if !ok {
+		return false
+	}
+	if t := toString(r["type"]); t != "" && t != "stackdriver" {
+		return false
+	}
+	refUID := toString(r["uid"])
+	return refUID == uid || (len(refUID) > 0 && refUID[0] == '$')
+}
+
+// promQLOnlyQueries converts the MQL queries of the request into PromQL when the datasource is
+// configured to run PromQL queries only. Queries that cannot be converted are removed from the
+// request and their error responses returned.
+func promQLOnlyQueries(req *backend.QueryDataRequest) (map[string]backend.DataResponse, error) {
+	rejected := map[string]backend.DataResponse{}
+	queries := make([]backend.DataQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		if q.QueryType != string(dataquery.QueryTypeTimeSeriesQuery) {
+			queries = append(queries, q)
+			continue
+		}
+
+		var rawQuery map[string]any
+		if err := json.Unmarshal(q.JSON, &rawQuery); err != nil {
+			return nil, err
+		}
+		if _, ok := rawQuery["timeSeriesQuery"].(map[string]any); !ok {
+			queries = append(queries, q)
+			continue
+		}
+		result := migrateMQLQuery(rawQuery)
+		if !result.Converted {
+			rejected[q.RefID] = backend.DataResponse{
+				Error: fmt.Errorf("MQL queries are disabled for this datasource and the query cannot be converted to PromQL: %v", result.Unsupported),
+			}
+			continue
+		}
+
+		b, err := json.Marshal(result.Query)
+		if err != nil {
+			return nil, err
+		}
+		q.JSON = b
+		q.QueryType = string(dataquery.QueryTypePromQL)
+		queries = append(queries, q)
+	}
+	req.Queries = queries
+	return rejected, nil
+}
+
+func (s *Service) handleMQLMigration(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeResponse(rw, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+	var migrationReq mqlMigrationRequest
+	if err := json.Unmarshal(body, &migrationReq); err != nil {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid request body %v", err))
+		return
+	}
+	if !isMQLQuery(migrationReq.Query) {
+		writeResponse(rw, http.StatusBadRequest, "query is not an MQL query")
+		return
+	}
+
+	writeJSONResponse(rw, migrateMQLQuery(migrationReq.Query))
+}
+
+func (s *Service) handleDashboardMQLMigration(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeResponse(rw, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("unexpected error %v", err))
+		return
+	}
+	var migrationReq dashboardMigrationRequest
+	if err := json.Unmarshal(body, &migrationReq); err != nil {
+		writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("invalid request body %v", err))
+		return
+	}
+	if migrationReq.Dashboard == nil {
+		writeResponse(rw, http.StatusBadRequest, "request does not contain a dashboard")
+		return
+	}
+
+	uid := ""
+	if settings := httpadapter.PluginConfigFromContext(req.Context()).DataSourceInstanceSettings; settings != nil {
+		uid = settings.UID
+	}
+	queries := migrateDashboardMQLQueries(migrationReq.Dashboard, uid)
+	writeJSONResponse(rw, dashboardMigrationResponse{
+		Dashboard: migrationReq.Dashboard,
+		Queries:   queries,
+	})
+}
+
+func writeJSONResponse(rw http.ResponseWriter, v any) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("error formatting response %v", err))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	writeResponseBytes(rw, http.StatusOK, encoded)
+}
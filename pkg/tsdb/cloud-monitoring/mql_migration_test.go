@@ -0,0 +1,191 @@
+package cloudmonitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloud-monitoring/kinds/dataquery"
+)
+
+const convertibleMQL = "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | group_by [resource.zone], mean(val())"
+
+func mqlQuery(refID string, mql string, ds map[string]any) map[string]any {
+	q := map[string]any{
+		"refId":     refID,
+		"queryType": "timeSeriesQuery",
+		"aliasBy":   "{{zone}}",
+		"timeSeriesQuery": map[string]any{
+			"projectName": "my-project",
+			"query":       mql,
+			"graphPeriod": "5m",
+		},
+	}
+	if ds != nil {
+		q["datasource"] = ds
+	}
+	return q
+}
+
+func TestMigrateMQLQuery(t *testing.T) {
+	t.Run("converts the query keeping the common properties", func(t *testing.T) {
+		result := migrateMQLQuery(mqlQuery("A", convertibleMQL, nil))
+		require.True(t, result.Converted)
+		assert.Equal(t, "A", result.RefID)
+		assert.Equal(t, "{{zone}}", result.Query["aliasBy"])
+		assert.Equal(t, "promQL", result.Query["queryType"])
+		assert.NotContains(t, result.Query, "timeSeriesQuery")
+		assert.Equal(t, dataquery.PromQLQuery{
+			Expr:        `avg by (zone) (compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance"})`,
+			ProjectName: "my-project",
+			Step:        "5m",
+		}, result.Query["promQLQuery"])
+	})
+
+	t.Run("reports unsupported constructs", func(t *testing.T) {
+		result := migrateMQLQuery(mqlQuery("A", "fetch gce_instance::a/b | ratio", nil))
+		assert.False(t, result.Converted)
+		assert.Nil(t, result.Query)
+		assert.Equal(t, []string{`operation "ratio"`}, result.Unsupported)
+	})
+}
+
+func TestMigrateDashboardMQLQueries(t *testing.T) {
+	own := map[string]any{"type": "stackdriver", "uid": "gcm"}
+	other := map[string]any{"type": "stackdriver", "uid": "other"}
+	dashboard := map[string]any{
+		"panels": []any{
+			map[string]any{
+				"id":         float64(1),
+				"title":      "CPU",
+				"datasource": own,
+				"targets": []any{
+					mqlQuery("A", convertibleMQL, nil),
+					mqlQuery("B", "fetch gce_instance::a/b | ratio", nil),
+					mqlQuery("C", convertibleMQL, other),
+				},
+			},
+			map[string]any{
+				"id":   float64(2),
+				"type": "row",
+				"panels": []any{
+					map[string]any{
+						"id":      float64(3),
+						"title":   "Nested",
+						"targets": []any{mqlQuery("A", convertibleMQL, map[string]any{"uid": "${ds}"})},
+					},
+				},
+			},
+		},
+	}
+
+	results := migrateDashboardMQLQueries(dashboard, "gcm")
+	require.Len(t, results, 3)
+	assert.Equal(t, int64(1), results[0].PanelID)
+	assert.True(t, results[0].Converted)
+	assert.Equal(t, "B", results[1].RefID)
+	assert.False(t, results[1].Converted)
+	assert.Equal(t, int64(3), results[2].PanelID)
+	assert.True(t, results[2].Converted)
+
+	targets := dashboard["panels"].([]any)[0].(map[string]any)["targets"].([]any)
+	assert.Equal(t, "promQL", targets[0].(map[string]any)["queryType"])
+	assert.Equal(t, "timeSeriesQuery", targets[1].(map[string]any)["queryType"])
+	assert.Equal(t, "timeSeriesQuery", targets[2].(map[string]any)["queryType"])
+	nested := dashboard["panels"].([]any)[1].(map[string]any)["panels"].([]any)[0].(map[string]any)["targets"].([]any)
+	assert.Equal(t, "promQL", nested[0].(map[string]any)["queryType"])
+}
+
+func TestPromQLOnlyQueries(t *testing.T) {
+	toDataQuery := func(q map[string]any) backend.DataQuery {
+		b, err := json.Marshal(q)
+		require.NoError(t, err)
+		return backend.DataQuery{RefID: q["refId"].(string), QueryType: q["queryType"].(string), JSON: b}
+	}
+	req := &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			toDataQuery(mqlQuery("A", convertibleMQL, nil)),
+			toDataQuery(mqlQuery("B", "fetch gce_instance::a/b | ratio", nil)),
+			{RefID: "C", QueryType: "promQL", JSON: []byte(`{"promQLQuery":{"expr":"up","projectName":"p","step":"10s"}}`)},
+		},
+	}
+
+	rejected, err := promQLOnlyQueries(req)
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.ErrorContains(t, rejected["B"].Error, "cannot be converted to PromQL")
+
+	require.Len(t, req.Queries, 2)
+	assert.Equal(t, "promQL", req.Queries[0].QueryType)
+	q, err := queryModel(req.Queries[0])
+	require.NoError(t, err)
+	assert.Equal(t, `avg by (zone) (compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance"})`, q.PromQLQuery.Expr)
+	assert.Equal(t, "C", req.Queries[1].RefID)
+}
+
+func TestMQLMigrationResources(t *testing.T) {
+	s := &Service{}
+	s.resourceHandler = httpadapter.New(s.newResourceMux())
+
+	callResource := func(path string, body any) *backend.CallResourceResponse {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		sender := &fakeSender{}
+		err = s.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: backend.PluginContext{
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "gcm"},
+			},
+			Method: http.MethodPost,
+			Path:   path,
+			URL:    path,
+			Body:   b,
+		}, sender)
+		require.NoError(t, err)
+		return sender.resp
+	}
+
+	t.Run("migrates a query", func(t *testing.T) {
+		resp := callResource("migrate/mql", mqlMigrationRequest{Query: mqlQuery("A", convertibleMQL, nil)})
+		require.Equal(t, http.StatusOK, resp.Status)
+		var result mqlMigrationResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		assert.True(t, result.Converted)
+	})
+
+	t.Run("rejects queries that are not MQL", func(t *testing.T) {
+		resp := callResource("migrate/mql", mqlMigrationRequest{Query: map[string]any{"refId": "A", "queryType": "promQL"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Status)
+	})
+
+	t.Run("migrates a dashboard", func(t *testing.T) {
+		resp := callResource("migrate/dashboard", dashboardMigrationRequest{Dashboard: map[string]any{
+			"panels": []any{map[string]any{
+				"id":         float64(1),
+				"datasource": map[string]any{"type": "stackdriver", "uid": "gcm"},
+				"targets":    []any{mqlQuery("A", convertibleMQL, nil)},
+			}},
+		}})
+		require.Equal(t, http.StatusOK, resp.Status)
+		var result dashboardMigrationResponse
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Len(t, result.Queries, 1)
+		assert.True(t, result.Queries[0].Converted)
+		target := result.Dashboard["panels"].([]any)[0].(map[string]any)["targets"].([]any)[0].(map[string]any)
+		assert.Equal(t, "promQL", target["queryType"])
+	})
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
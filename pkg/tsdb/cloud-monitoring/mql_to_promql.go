@@ -0,0 +1,455 @@
+package cloudmonitoring
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mqlConversion is the result of translating an MQL query into PromQL. Unsupported lists the
+// MQL constructs that have no PromQL equivalent; the expression is only usable when it is empty.
+// Warnings list constructs that were translated but may not behave exactly as in MQL.
+type mqlConversion struct {
+	Expr        string   `json:"expr"`
+	Step        string   `json:"step,omitempty"`
+	Unsupported []string `json:"unsupported,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+func (c mqlConversion) converted() bool {
+	return len(c.Unsupported) == 0
+}
+
+// defaultMQLWindow is used for aligners that are given without an explicit window and without an
+// "every" operation that would otherwise define the alignment period.
+const defaultMQLWindow = "1m"
+
+var (
+	mqlDurationExp   = regexp.MustCompile(`^\d+(ms|s|m|h|d|w)$`)
+	mqlFuncCallExp   = regexp.MustCompile(`^([a-z_]+)\s*\((.*)\)$`)
+	mqlValueArgExp   = regexp.MustCompile(`^(val\(\d*\)|value\.[\w.]+|[\w.]+)$`)
+	mqlComparisonExp = regexp.MustCompile(`^([\w.]+)\s*(==|!=|=~|!~)\s*(.+)$`)
+	promLabelExp     = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// mqlAligners maps MQL aligners onto PromQL range functions.
+var mqlAligners = map[string]string{
+	"rate":         "rate",
+	"delta":        "increase",
+	"mean":         "avg_over_time",
+	"mean_aligner": "avg_over_time",
+	"max":          "max_over_time",
+	"min":          "min_over_time",
+	"sum":          "sum_over_time",
+	"count":        "count_over_time",
+	"stddev":       "stddev_over_time",
+}
+
+// mqlReducers maps MQL aggregation functions onto PromQL aggregation operators.
+var mqlReducers = map[string]string{
+	"mean":   "avg",
+	"sum":    "sum",
+	"max":    "max",
+	"min":    "min",
+	"count":  "count",
+	"stddev": "stddev",
+}
+
+// mqlToPromQL translates the subset of MQL that has a direct PromQL equivalent: a single fetch of
+// one metric, label filters, alignment, temporal and spatial aggregation and top/bottom selection.
+// Metric and label names are rewritten following the Cloud Monitoring PromQL naming conventions.
+func mqlToPromQL(mql string) mqlConversion {
+	c := mqlConversion{}
+	stages, err := splitMQLPipeline(mql)
+	if err != nil {
+		c.Unsupported = append(c.Unsupported, err.Error())
+		return c
+	}
+
+	var (
+		resourceType string
+		metricType   string
+		matchers     []string
+		expr         string
+		window       string
+	)
+
+	// selector returns the instant vector selector once all the selector stages were seen.
+	selector := func() string {
+		return fmt.Sprintf("%s{%s}", promMetricName(metricType), strings.Join(matchers, ","))
+	}
+
+	for i, stage := range stages {
+		op, args := splitMQLStage(stage)
+		switch op {
+		case "fetch":
+			if i != 0 {
+				c.Unsupported = append(c.Unsupported, "fetch must be the first operation of the query")
+				continue
+			}
+			resource, metric, _ := strings.Cut(args, "::")
+			resourceType = strings.TrimSpace(resource)
+			metricType = unquoteMQL(strings.TrimSpace(metric))
+			if resourceType == "" {
+				c.Unsupported = append(c.Unsupported, "fetch without a monitored resource type")
+				continue
+			}
+			matchers = append(matchers, fmt.Sprintf("monitored_resource=%q", resourceType))
+		case "metric":
+			metricType = unquoteMQL(args)
+		case "filter":
+			if expr != "" {
+				c.Unsupported = append(c.Unsupported, fmt.Sprintf("filter after alignment or aggregation: %s", stage))
+				continue
+			}
+			m, unsupported := mqlFilterToMatchers(args)
+			matchers = append(matchers, m...)
+			c.Unsupported = append(c.Unsupported, unsupported...)
+		case "align":
+			if expr != "" {
+				c.Unsupported = append(c.Unsupported, fmt.Sprintf("align after alignment or aggregation: %s", stage))
+				continue
+			}
+			aligner, alignWindow := args, ""
+			if m := mqlFuncCallExp.FindStringSubmatch(args); m != nil {
+				aligner, alignWindow = m[1], strings.TrimSpace(m[2])
+			}
+			fn, ok := mqlAligners[aligner]
+			if !ok {
+				if aligner == "next_older" {
+					// Gauge values are used as they are, which is what an instant vector selector does.
+					expr = selector()
+					continue
+				}
+				c.Unsupported = append(c.Unsupported, fmt.Sprintf("aligner %q", aligner))
+				continue
+			}
+			if alignWindow == "" {
+				alignWindow = window
+			}
+			expr = fmt.Sprintf("%s(%s[%s])", fn, selector(), windowOrDefault(alignWindow, &c))
+		case "every":
+			if !mqlDurationExp.MatchString(args) {
+				c.Unsupported = append(c.Unsupported, fmt.Sprintf("every with period %q", args))
+				continue
+			}
+			window = args
+			c.Step = args
+		case "group_by":
+			if expr == "" {
+				expr = selector()
+			}
+			grouped, err := mqlGroupBy(args, expr, selector, &c)
+			if err != nil {
+				c.Unsupported = append(c.Unsupported, err.Error())
+				continue
+			}
+			expr = grouped
+		case "top", "bottom":
+			n, err := strconv.Atoi(args)
+			if err != nil || n <= 0 {
+				c.Unsupported = append(c.Unsupported, fmt.Sprintf("%s with arguments %q", op, args))
+				continue
+			}
+			if expr == "" {
+				expr = selector()
+			}
+			expr = fmt.Sprintf("%sk(%d, %s)", op, n, expr)
+		case "within":
+			// The time range of the panel controls the queried range of a PromQL query.
+			c.Warnings = append(c.Warnings, fmt.Sprintf("within is ignored, the dashboard time range is used instead: %s", stage))
+		default:
+			c.Unsupported = append(c.Unsupported, fmt.Sprintf("operation %q", op))
+		}
+	}
+
+	if resourceType == "" && len(c.Unsupported) == 0 {
+		c.Unsupported = append(c.Unsupported, "query does not start with fetch")
+	}
+	if metricType == "" && len(c.Unsupported) == 0 {
+		c.Unsupported = append(c.Unsupported, "query does not select a metric")
+	}
+	if !c.converted() {
+		return c
+	}
+
+	if expr == "" {
+		expr = selector()
+	}
+	c.Expr = expr
+	return c
+}
+
+// mqlGroupBy translates a group_by operation. A window as first argument makes it a temporal
+// aggregation, a label list makes it a spatial aggregation.
+func mqlGroupBy(args string, expr string, selector func() string, c *mqlConversion) (string, error) {
+	parts := splitMQLTopLevel(args, ",")
+	if len(parts) == 0 || len(parts) > 2 {
+		return "", fmt.Errorf("group_by with arguments %q", args)
+	}
+
+	first := strings.TrimSpace(parts[0])
+	reducerExpr := ""
+	if len(parts) == 2 {
+		reducerExpr = strings.TrimSpace(parts[1])
+	}
+
+	reducer, param, err := mqlReducer(reducerExpr, c)
+	if err != nil {
+		return "", err
+	}
+
+	if groupWindow := strings.TrimSuffix(strings.TrimPrefix(first, "sliding("), ")"); mqlDurationExp.MatchString(groupWindow) {
+		if expr != selector() {
+			return "", fmt.Errorf("temporal group_by after alignment or aggregation: group_by %s", args)
+		}
+		fn, ok := mqlAligners[reducer]
+		if !ok || param != "" {
+			return "", fmt.Errorf("temporal group_by with aggregation %q", reducerExpr)
+		}
+		return fmt.Sprintf("%s(%s[%s])", fn, selector(), groupWindow), nil
+	}
+
+	if !strings.HasPrefix(first, "[") || !strings.HasSuffix(first, "]") {
+		return "", fmt.Errorf("group_by with arguments %q", args)
+	}
+	labels := []string{}
+	for _, l := range splitMQLTopLevel(strings.TrimSuffix(strings.TrimPrefix(first, "["), "]"), ",") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		// Columns can be renamed with "name: label", the PromQL result keeps the original label.
+		if _, label, ok := strings.Cut(l, ":"); ok {
+			l = strings.TrimSpace(label)
+		}
+		labels = append(labels, promLabelName(l))
+	}
+
+	op, ok := mqlReducers[reducer]
+	if !ok && reducer != "percentile" {
+		return "", fmt.Errorf("aggregation %q", reducer)
+	}
+	if reducer == "percentile" {
+		q, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "", fmt.Errorf("percentile with argument %q", param)
+		}
+		return fmt.Sprintf("quantile by (%s) (%s, %s)", strings.Join(labels, ","), strconv.FormatFloat(q/100, 'f', -1, 64), expr), nil
+	}
+	return fmt.Sprintf("%s by (%s) (%s)", op, strings.Join(labels, ","), expr), nil
+}
+
+// mqlReducer parses the aggregation part of a group_by, e.g. "[value_mean: mean(val())]",
+// "mean(value.utilization)" or ".mean", and returns the aggregation name and its extra parameter.
+func mqlReducer(s string, c *mqlConversion) (string, string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		columns := splitMQLTopLevel(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"), ",")
+		if len(columns) != 1 {
+			return "", "", fmt.Errorf("group_by producing %d value columns", len(columns))
+		}
+		s = strings.TrimSpace(columns[0])
+		// Value columns can be named with "name: aggregation".
+		if name, rest, ok := strings.Cut(s, ":"); ok && !strings.Contains(name, "(") {
+			s = strings.TrimSpace(rest)
+		}
+	}
+
+	if s == "" || s == ".aggregate" || strings.HasPrefix(s, "aggregate(") {
+		c.Warnings = append(c.Warnings, "default aggregation translated to sum, use avg for gauge metrics")
+		return "sum", "", nil
+	}
+	if strings.HasPrefix(s, ".") {
+		return strings.TrimPrefix(s, "."), "", nil
+	}
+
+	m := mqlFuncCallExp.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", fmt.Errorf("aggregation %q", s)
+	}
+	args := splitMQLTopLevel(m[2], ",")
+	if len(args) == 0 || !mqlValueArgExp.MatchString(strings.TrimSpace(args[0])) {
+		return "", "", fmt.Errorf("aggregation over expression %q", m[2])
+	}
+	switch len(args) {
+	case 1:
+		return m[1], "", nil
+	case 2:
+		return m[1], strings.TrimSpace(args[1]), nil
+	default:
+		return "", "", fmt.Errorf("aggregation %q", s)
+	}
+}
+
+// mqlFilterToMatchers translates a conjunction of label comparisons into PromQL label matchers.
+func mqlFilterToMatchers(filter string) ([]string, []string) {
+	var matchers, unsupported []string
+	for _, term := range splitMQLTopLevel(filter, "&&") {
+		term = trimMQLParens(strings.TrimSpace(term))
+		if strings.Contains(term, "||") {
+			unsupported = append(unsupported, fmt.Sprintf("disjunction in filter: %s", term))
+			continue
+		}
+		if m := mqlComparisonExp.FindStringSubmatch(term); m != nil {
+			op := m[2]
+			if op == "==" {
+				op = "="
+			}
+			matchers = append(matchers, fmt.Sprintf("%s%s%q", promLabelName(m[1]), op, unquoteMQL(strings.TrimSpace(m[3]))))
+			continue
+		}
+		if m := mqlFuncCallExp.FindStringSubmatch(term); m != nil {
+			args := splitMQLTopLevel(m[2], ",")
+			if len(args) == 2 {
+				label := promLabelName(strings.TrimSpace(args[0]))
+				value := regexp.QuoteMeta(unquoteMQL(strings.TrimSpace(args[1])))
+				switch m[1] {
+				case "starts_with":
+					matchers = append(matchers, fmt.Sprintf("%s=~%q", label, value+".*"))
+					continue
+				case "ends_with":
+					matchers = append(matchers, fmt.Sprintf("%s=~%q", label, ".*"+value))
+					continue
+				case "has_substring":
+					matchers = append(matchers, fmt.Sprintf("%s=~%q", label, ".*"+value+".*"))
+					continue
+				}
+			}
+		}
+		unsupported = append(unsupported, fmt.Sprintf("filter condition: %s", term))
+	}
+	return matchers, unsupported
+}
+
+// promMetricName converts a Cloud Monitoring metric type into its PromQL name, e.g.
+// compute.googleapis.com/instance/cpu/utilization becomes compute_googleapis_com:instance_cpu_utilization.
+func promMetricName(metricType string) string {
+	domain, path, ok := strings.Cut(metricType, "/")
+	if !ok {
+		return promLabelExp.ReplaceAllString(metricType, "_")
+	}
+	return promLabelExp.ReplaceAllString(domain, "_") + ":" + promLabelExp.ReplaceAllString(path, "_")
+}
+
+// promLabelName converts an MQL column reference into its PromQL label name.
+func promLabelName(column string) string {
+	switch {
+	case column == "resource.type":
+		return "monitored_resource"
+	case strings.HasPrefix(column, "metadata.system_labels."):
+		column = "metadata_system_" + strings.TrimPrefix(column, "metadata.system_labels.")
+	case strings.HasPrefix(column, "metadata.system."):
+		column = "metadata_system_" + strings.TrimPrefix(column, "metadata.system.")
+	case strings.HasPrefix(column, "metadata.user_labels."):
+		column = "metadata_user_" + strings.TrimPrefix(column, "metadata.user_labels.")
+	case strings.HasPrefix(column, "metadata.user."):
+		column = "metadata_user_" + strings.TrimPrefix(column, "metadata.user.")
+	case strings.HasPrefix(column, "resource."):
+		column = strings.TrimPrefix(column, "resource.")
+	case strings.HasPrefix(column, "metric."):
+		column = strings.TrimPrefix(column, "metric.")
+	}
+	return promLabelExp.ReplaceAllString(column, "_")
+}
+
+func windowOrDefault(window string, c *mqlConversion) string {
+	if window == "" {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("no alignment window given, using %s", defaultMQLWindow))
+		return defaultMQLWindow
+	}
+	return window
+}
+
+// splitMQLPipeline splits a query into its table operations. Joins of several pipelines are not supported.
+func splitMQLPipeline(mql string) ([]string, error) {
+	mql = strings.TrimSpace(mql)
+	if mql == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	if strings.HasPrefix(mql, "{") {
+		return nil, fmt.Errorf("queries joining several tables")
+	}
+	stages := []string{}
+	for _, s := range splitMQLTopLevel(mql, "|") {
+		s = strings.Join(strings.Fields(s), " ")
+		if s != "" {
+			stages = append(stages, s)
+		}
+	}
+	return stages, nil
+}
+
+// splitMQLStage returns the operation name and its arguments.
+func splitMQLStage(stage string) (string, string) {
+	op, args, _ := strings.Cut(stage, " ")
+	return op, strings.TrimSpace(args)
+}
+
+// splitMQLTopLevel splits s on sep, ignoring separators in quotes, parentheses and brackets.
+func splitMQLTopLevel(s string, sep string) []string {
+	var (
+		parts []string
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep+sep):
+			// A doubled separator is an operator, e.g. "||" in a pipeline split on "|".
+			i += 2*len(sep) - 1
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" || len(parts) > 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// trimMQLParens removes parentheses wrapping the whole expression.
+func trimMQLParens(s string) string {
+	for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") && balanced(s[1:len(s)-1]) {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+func balanced(s string) bool {
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func unquoteMQL(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
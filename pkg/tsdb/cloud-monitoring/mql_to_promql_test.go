@@ -0,0 +1,109 @@
+package cloudmonitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMQLToPromQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		mql      string
+		expr     string
+		step     string
+		warnings int
+	}{
+		{
+			name: "fetch with metric shorthand",
+			mql:  "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization",
+			expr: `compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance"}`,
+		},
+		{
+			name: "filters and spatial aggregation",
+			mql: `fetch gce_instance
+| metric 'compute.googleapis.com/instance/cpu/utilization'
+| filter (resource.zone == 'us-central1-a') && metadata.user_labels.env =~ 'prod.*'
+| group_by 1m, [value_utilization_mean: mean(value.utilization)]
+| every 1m
+| group_by [resource.zone], [value_utilization_mean_aggregate: mean(value_utilization_mean)]`,
+			expr: `avg by (zone) (avg_over_time(compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance",zone="us-central1-a",metadata_user_env=~"prod.*"}[1m]))`,
+			step: "1m",
+		},
+		{
+			name: "rate with top",
+			mql:  "fetch k8s_container::kubernetes.io/container/restart_count | align rate(5m) | every 30s | group_by [metric.container_name, resource.namespace_name], sum(val()) | top 5",
+			expr: `topk(5, sum by (container_name,namespace_name) (rate(kubernetes_io:container_restart_count{monitored_resource="k8s_container"}[5m])))`,
+			step: "30s",
+		},
+		{
+			name: "delta uses every as window",
+			mql:  "fetch https_lb_rule::loadbalancing.googleapis.com/https/request_count | every 2m | align delta()",
+			expr: `increase(loadbalancing_googleapis_com:https_request_count{monitored_resource="https_lb_rule"}[2m])`,
+			step: "2m",
+		},
+		{
+			name:     "delta without window",
+			mql:      "fetch https_lb_rule::loadbalancing.googleapis.com/https/request_count | filter starts_with(resource.url_map_name, 'prod') | align delta()",
+			expr:     `increase(loadbalancing_googleapis_com:https_request_count{monitored_resource="https_lb_rule",url_map_name=~"prod.*"}[1m])`,
+			warnings: 1,
+		},
+		{
+			name:     "default aggregation and within",
+			mql:      "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | group_by [resource.zone] | within 1h",
+			expr:     `sum by (zone) (compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance"})`,
+			warnings: 2,
+		},
+		{
+			name: "percentile",
+			mql:  "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | group_by [], percentile(val(), 99)",
+			expr: `quantile by () (0.99, compute_googleapis_com:instance_cpu_utilization{monitored_resource="gce_instance"})`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mqlToPromQL(tt.mql)
+			require.Empty(t, c.Unsupported)
+			assert.Equal(t, tt.expr, c.Expr)
+			assert.Equal(t, tt.step, c.Step)
+			assert.Len(t, c.Warnings, tt.warnings)
+		})
+	}
+}
+
+func TestMQLToPromQLUnsupported(t *testing.T) {
+	tests := []struct {
+		name        string
+		mql         string
+		unsupported string
+	}{
+		{"join", "{ fetch gce_instance::a/b ; fetch gce_instance::a/c } | join", "queries joining several tables"},
+		{"ratio", "fetch gce_instance::a/b | ratio", `operation "ratio"`},
+		{"disjunction", "fetch gce_instance::a/b | filter resource.zone == 'a' || resource.zone == 'b'", "disjunction in filter: resource.zone == 'a' || resource.zone == 'b'"},
+		{"value arithmetic", "fetch gce_instance::a/b | group_by [resource.zone], mean(val() * 100)", `aggregation over expression "val() * 100"`},
+		{"several value columns", "fetch gce_instance::a/b | group_by [resource.zone], [a: mean(val()), b: max(val())]", "group_by producing 2 value columns"},
+		{"filter after alignment", "fetch gce_instance::a/b | align rate(1m) | filter resource.zone == 'a'", "filter after alignment or aggregation: filter resource.zone == 'a'"},
+		{"no metric", "fetch gce_instance", "query does not select a metric"},
+		{"no fetch", "metric 'a/b'", "query does not start with fetch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mqlToPromQL(tt.mql)
+			assert.False(t, c.converted())
+			assert.Empty(t, c.Expr)
+			assert.Contains(t, c.Unsupported, tt.unsupported)
+		})
+	}
+}
+
+func TestPromNames(t *testing.T) {
+	assert.Equal(t, "compute_googleapis_com:instance_cpu_utilization", promMetricName("compute.googleapis.com/instance/cpu/utilization"))
+	assert.Equal(t, "custom_googleapis_com:my_metric_name", promMetricName("custom.googleapis.com/my.metric/name"))
+	assert.Equal(t, "monitored_resource", promLabelName("resource.type"))
+	assert.Equal(t, "metadata_system_region", promLabelName("metadata.system_labels.region"))
+	assert.Equal(t, "metadata_user_team", promLabelName("metadata.user_labels.team"))
+	assert.Equal(t, "response_code", promLabelName("metric.response_code"))
+}
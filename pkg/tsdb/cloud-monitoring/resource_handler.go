@@ -32,6 +32,8 @@ func (s *Service) newResourceMux() *http.ServeMux {
 	mux.HandleFunc("/services/", s.handleResourceReq(cloudMonitor, processServices))
 	mux.HandleFunc("/slo-services/", s.handleResourceReq(cloudMonitor, processSLOs))
 	mux.HandleFunc("/projects", s.handleResourceReq(resourceManager, processProjects))
+	mux.HandleFunc("/migrate/mql", s.handleMQLMigration)
+	mux.HandleFunc("/migrate/dashboard", s.handleDashboardMQLMigration)
 	return mux
 }
 
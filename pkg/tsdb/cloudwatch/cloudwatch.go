@@ -88,6 +88,8 @@ func newExecutor(im instancemgmt.InstanceManager, cfg *setting.Cfg, sessions Ses
 		cfg:      cfg,
 		sessions: sessions,
 		features: features,
+
+		syncLogsQueries: newSyncLogsQueryRunner(),
 	}
 
 	e.resourceHandler = httpadapter.New(e.newResourceMux())
@@ -127,6 +129,7 @@ type cloudWatchExecutor struct {
 	features    featuremgmt.FeatureToggles
 	regionCache sync.Map
 
+	syncLogsQueries *syncLogsQueryRunner
 	resourceHandler backend.CallResourceHandler
 }
 
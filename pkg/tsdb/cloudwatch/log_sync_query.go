@@ -14,7 +14,13 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
 )
 
-const initialAlertPollPeriod = time.Second
+const (
+	initialAlertPollPeriod = time.Second
+	// maxAlertPollPeriod caps the exponential backoff between polls of a running query
+	maxAlertPollPeriod = 10 * time.Second
+	// stopQueryTimeout bounds the request stopping a query that is no longer needed
+	stopQueryTimeout = 5 * time.Second
+)
 
 var executeSyncLogQuery = func(ctx context.Context, e *cloudWatchExecutor, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
@@ -46,7 +52,13 @@ var executeSyncLogQuery = func(ctx context.Context, e *cloudWatchExecutor, req *
 			return nil, err
 		}
 
-		getQueryResultsOutput, err := e.syncQuery(ctx, logsClient, q, logsQuery, instance.Settings.LogsTimeout.Duration)
+		key, err := syncLogsQueryKey(req.PluginContext, logsQuery, q.TimeRange)
+		if err != nil {
+			return nil, err
+		}
+		getQueryResultsOutput, err := e.syncLogsQueries.run(ctx, key, func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			return e.syncQuery(ctx, logsClient, q, logsQuery, instance.Settings.LogsTimeout.Duration)
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -101,11 +113,19 @@ func (e *cloudWatchExecutor) syncQuery(ctx context.Context, logsClient cloudwatc
 		frontend, but because alerts and expressions are executed on the backend the logic needs to be reimplemented here.
 	*/
 
-	ticker := time.NewTicker(initialAlertPollPeriod)
-	defer ticker.Stop()
+	deadline := time.Now().Add(logsTimeout)
+	pollPeriod := initialAlertPollPeriod
+	timer := time.NewTimer(pollPeriod)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.stopSyncQuery(ctx, logsClient, requestParams)
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
 
-	attemptCount := 1
-	for range ticker.C {
 		res, err := e.executeGetQueryResults(ctx, logsClient, requestParams)
 		if err != nil {
 			return nil, fmt.Errorf("CloudWatch Error: %w", err)
@@ -113,12 +133,23 @@ func (e *cloudWatchExecutor) syncQuery(ctx context.Context, logsClient cloudwatc
 		if isTerminated(*res.Status) {
 			return res, err
 		}
-		if time.Duration(attemptCount)*time.Second >= logsTimeout {
+		if !time.Now().Before(deadline) {
+			e.stopSyncQuery(ctx, logsClient, requestParams)
 			return res, fmt.Errorf("time to fetch query results exceeded logs timeout")
 		}
 
-		attemptCount++
+		// Back off exponentially, as slow queries can run for minutes
+		pollPeriod = min(pollPeriod*2, maxAlertPollPeriod, time.Until(deadline))
+		timer.Reset(max(pollPeriod, 0))
 	}
+}
 
-	return nil, nil
+// stopSyncQuery stops a query whose results are no longer needed, so it does not keep using
+// the concurrent query quota of the account.
+func (e *cloudWatchExecutor) stopSyncQuery(ctx context.Context, logsClient cloudwatchlogsiface.CloudWatchLogsAPI, logsQuery models.LogsQuery) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stopQueryTimeout)
+	defer cancel()
+	if _, err := e.executeStopQuery(ctx, logsClient, logsQuery); err != nil {
+		logger.FromContext(ctx).Warn("Failed to stop CloudWatch Logs query", "queryId", logsQuery.QueryId, "error", err)
+	}
 }
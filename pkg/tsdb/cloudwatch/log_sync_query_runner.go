@@ -0,0 +1,130 @@
+package cloudwatch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/grafana/grafana/pkg/tsdb/cloudwatch/models"
+)
+
+// syncLogsResultExpiration is how long the results of a completed query are reused. Alert
+// rules evaluated in the same interval often run the same query for the same time range.
+const syncLogsResultExpiration = 30 * time.Second
+
+// syncLogsQueryRunner runs a single Logs Insights query for identical concurrent requests
+// and reuses the results of completed queries for a short time.
+type syncLogsQueryRunner struct {
+	mu       sync.Mutex
+	inflight map[string]*inflightLogsQuery
+	results  *cache.Cache
+}
+
+type inflightLogsQuery struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	// waiters is the number of requests waiting for the query, the query is cancelled
+	// when it drops to zero
+	waiters int
+	result  *cloudwatchlogs.GetQueryResultsOutput
+	err     error
+}
+
+func newSyncLogsQueryRunner() *syncLogsQueryRunner {
+	return &syncLogsQueryRunner{
+		inflight: map[string]*inflightLogsQuery{},
+		results:  cache.New(syncLogsResultExpiration, syncLogsResultExpiration*5),
+	}
+}
+
+// run returns the results of the query identified by key, starting it unless an identical
+// query is running already. The query is cancelled once every request waiting for it is.
+func (r *syncLogsQueryRunner) run(ctx context.Context, key string,
+	query func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	r.mu.Lock()
+	if v, ok := r.results.Get(key); ok {
+		r.mu.Unlock()
+		return v.(*cloudwatchlogs.GetQueryResultsOutput), nil
+	}
+
+	q, ok := r.inflight[key]
+	if !ok {
+		qctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		q = &inflightLogsQuery{done: make(chan struct{}), cancel: cancel}
+		r.inflight[key] = q
+
+		go func() {
+			defer cancel()
+			result, err := query(qctx)
+
+			r.mu.Lock()
+			q.result, q.err = result, err
+			if r.inflight[key] == q {
+				delete(r.inflight, key)
+			}
+			if err == nil {
+				r.results.SetDefault(key, result)
+			}
+			r.mu.Unlock()
+			close(q.done)
+		}()
+	}
+	q.waiters++
+	r.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return q.result, q.err
+	case <-ctx.Done():
+		r.mu.Lock()
+		q.waiters--
+		if q.waiters == 0 {
+			q.cancel()
+			if r.inflight[key] == q {
+				delete(r.inflight, key)
+			}
+		}
+		r.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// syncLogsQueryKey identifies a query by the data source, region, query and time range.
+func syncLogsQueryKey(pluginCtx backend.PluginContext, logsQuery models.LogsQuery, timeRange backend.TimeRange) (string, error) {
+	logsQuery.RefId = ""
+	logsQuery.Hide = nil
+
+	var uid string
+	var updated time.Time
+	if settings := pluginCtx.DataSourceInstanceSettings; settings != nil {
+		uid, updated = settings.UID, settings.Updated
+	}
+
+	b, err := json.Marshal(struct {
+		OrgID   int64
+		UID     string
+		Updated int64
+		Query   models.LogsQuery
+		From    int64
+		To      int64
+	}{
+		OrgID:   pluginCtx.OrgID,
+		UID:     uid,
+		Updated: updated.UnixNano(),
+		Query:   logsQuery,
+		From:    timeRange.From.UnixNano(),
+		To:      timeRange.To.UnixNano(),
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), nil
+}
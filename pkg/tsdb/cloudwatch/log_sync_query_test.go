@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -347,6 +349,7 @@ func Test_executeSyncLogQuery_handles_RefId_from_input_queries(t *testing.T) {
 			QueryId: aws.String("abcd-efgh-ijkl-mnop"),
 		}, nil)
 		cli.On("GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{Status: aws.String("Running")}, nil)
+		cli.On("StopQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StopQueryOutput{Success: aws.Bool(true)}, nil)
 		im := datasource.NewInstanceManager(func(ctx context.Context, s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 			return DataSource{Settings: models.CloudWatchSettings{LogsTimeout: models.Duration{Duration: time.Millisecond}}}, nil
 		})
@@ -369,6 +372,7 @@ func Test_executeSyncLogQuery_handles_RefId_from_input_queries(t *testing.T) {
 		})
 		assert.Error(t, err)
 		cli.AssertNumberOfCalls(t, "GetQueryResultsWithContext", 1)
+		cli.AssertNumberOfCalls(t, "StopQueryWithContext", 1)
 	})
 
 	t.Run("when getQueryResults returns aws error is returned, it keeps the context", func(t *testing.T) {
@@ -403,3 +407,146 @@ func Test_executeSyncLogQuery_handles_RefId_from_input_queries(t *testing.T) {
 		require.Equal(t, "CloudWatch Error: foo: bar", err.Error())
 	})
 }
+
+func Test_executeSyncLogQuery_stops_cancelled_queries(t *testing.T) {
+	origNewCWClient := NewCWClient
+	t.Cleanup(func() {
+		NewCWClient = origNewCWClient
+	})
+
+	cli := &mockLogsSyncClient{}
+	NewCWLogsClient = func(sess *session.Session) cloudwatchlogsiface.CloudWatchLogsAPI {
+		return cli
+	}
+	cli.On("StartQueryWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{
+		QueryId: aws.String("abcd-efgh-ijkl-mnop"),
+	}, nil)
+	stopped := make(chan struct{})
+	cli.On("StopQueryWithContext", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.StopQueryInput) bool {
+		return *input.QueryId == "abcd-efgh-ijkl-mnop"
+	}), mock.Anything).Run(func(mock.Arguments) { close(stopped) }).Return(&cloudwatchlogs.StopQueryOutput{Success: aws.Bool(true)}, nil)
+	im := datasource.NewInstanceManager(func(ctx context.Context, s backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		return DataSource{Settings: models.CloudWatchSettings{LogsTimeout: models.Duration{Duration: time.Minute}}}, nil
+	})
+	executor := newExecutor(im, newTestConfig(), &fakeSessionCache{}, featuremgmt.WithFeatures())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := executor.QueryData(ctx, &backend.QueryDataRequest{
+		Headers:       map[string]string{headerFromAlert: "some value"},
+		PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+		Queries: []backend.DataQuery{
+			{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(1, 0)},
+				JSON: json.RawMessage(`{
+					"queryMode":    "Logs",
+					"expression": "query string for A"
+				}`),
+			},
+		},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("query was not stopped")
+	}
+	cli.AssertNotCalled(t, "GetQueryResultsWithContext", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_syncLogsQueryRunner(t *testing.T) {
+	result := &cloudwatchlogs.GetQueryResultsOutput{Status: aws.String("Complete")}
+
+	t.Run("identical concurrent queries run once and completed results are reused", func(t *testing.T) {
+		runner := newSyncLogsQueryRunner()
+		release := make(chan struct{})
+		var calls atomic.Int32
+		query := func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			calls.Add(1)
+			<-release
+			return result, nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := runner.run(context.Background(), "key", query)
+				assert.NoError(t, err)
+				assert.Same(t, result, res)
+			}()
+		}
+		require.Eventually(t, func() bool {
+			runner.mu.Lock()
+			defer runner.mu.Unlock()
+			q, ok := runner.inflight["key"]
+			return ok && q.waiters == 3
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		res, err := runner.run(context.Background(), "key", query)
+		require.NoError(t, err)
+		assert.Same(t, result, res)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("errors are not reused", func(t *testing.T) {
+		runner := newSyncLogsQueryRunner()
+		_, err := runner.run(context.Background(), "key", func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			return nil, fmt.Errorf("failed")
+		})
+		require.Error(t, err)
+
+		res, err := runner.run(context.Background(), "key", func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			return result, nil
+		})
+		require.NoError(t, err)
+		assert.Same(t, result, res)
+	})
+
+	t.Run("the query is cancelled when no request waits for it", func(t *testing.T) {
+		runner := newSyncLogsQueryRunner()
+		cancelled := make(chan struct{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := runner.run(ctx, "key", func(ctx context.Context) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("query was not cancelled")
+		}
+	})
+}
+
+func Test_syncLogsQueryKey(t *testing.T) {
+	pluginCtx := backend.PluginContext{OrgID: 1, DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "cw"}}
+	timeRange := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(60, 0)}
+	query := func(refID, expr string) models.LogsQuery {
+		q := models.LogsQuery{QueryString: expr}
+		q.RefId = refID
+		return q
+	}
+
+	a, err := syncLogsQueryKey(pluginCtx, query("A", "fields @message"), timeRange)
+	require.NoError(t, err)
+	b, err := syncLogsQueryKey(pluginCtx, query("B", "fields @message"), timeRange)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	c, err := syncLogsQueryKey(pluginCtx, query("A", "fields @log"), timeRange)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	d, err := syncLogsQueryKey(pluginCtx, query("A", "fields @message"), backend.TimeRange{From: time.Unix(60, 0), To: time.Unix(120, 0)})
+	require.NoError(t, err)
+	assert.NotEqual(t, a, d)
+}
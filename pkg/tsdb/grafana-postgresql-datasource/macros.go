@@ -18,12 +18,15 @@ const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
 type postgresMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
 	timescaledb bool
+	// continuousAggregates maps tables to their continuous aggregates, keyed by bucket width.
+	continuousAggregates map[string]map[string]string
 }
 
-func newPostgresMacroEngine(timescaledb bool) sqleng.SQLMacroEngine {
+func newPostgresMacroEngine(timescaledb bool, continuousAggregates map[string]map[string]string) sqleng.SQLMacroEngine {
 	return &postgresMacroEngine{
-		SQLMacroEngineBase: sqleng.NewSQLMacroEngineBase(),
-		timescaledb:        timescaledb,
+		SQLMacroEngineBase:   sqleng.NewSQLMacroEngineBase(),
+		timescaledb:          timescaledb,
+		continuousAggregates: continuousAggregates,
 	}
 }
 
@@ -154,7 +157,50 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__continuousAggregate":
+		if len(args) == 0 || args[0] == "" {
+			return "", fmt.Errorf("missing table argument for macro %v", name)
+		}
+		return m.continuousAggregate(query, args[0], args[1:])
 	default:
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
 }
+
+// continuousAggregate returns the coarsest continuous aggregate of the table
+// whose bucket width is not larger than the query interval, so the buckets of
+// $__timeGroup still line up with the ones of the aggregate. The aggregates
+// configured on the datasource can be extended or overridden with
+// width=view arguments. The table itself is returned when no aggregate fits.
+func (m *postgresMacroEngine) continuousAggregate(query *backend.DataQuery, table string, mappings []string) (string, error) {
+	views := map[string]string{}
+	for width, view := range m.continuousAggregates[table] {
+		views[width] = view
+	}
+	for _, mapping := range mappings {
+		width, view, ok := strings.Cut(mapping, "=")
+		if !ok || strings.TrimSpace(width) == "" || strings.TrimSpace(view) == "" {
+			return "", fmt.Errorf("invalid continuous aggregate %q, expected width=view", mapping)
+		}
+		views[strings.Trim(strings.TrimSpace(width), `'`)] = strings.TrimSpace(view)
+	}
+
+	selected := table
+	var selectedWidth time.Duration
+	for width, view := range views {
+		d, err := gtime.ParseInterval(width)
+		if err != nil {
+			return "", fmt.Errorf("error parsing continuous aggregate width %v", width)
+		}
+		if d > query.Interval || d < selectedWidth {
+			continue
+		}
+		// keep the choice stable when several views have the same width
+		if d == selectedWidth && selected != table && view > selected {
+			continue
+		}
+		selected = view
+		selectedWidth = d
+	}
+	return selected, nil
+}
@@ -12,9 +12,9 @@ import (
 
 func TestMacroEngine(t *testing.T) {
 	timescaledbEnabled := false
-	engine := newPostgresMacroEngine(timescaledbEnabled)
+	engine := newPostgresMacroEngine(timescaledbEnabled, nil)
 	timescaledbEnabled = true
-	engineTS := newPostgresMacroEngine(timescaledbEnabled)
+	engineTS := newPostgresMacroEngine(timescaledbEnabled, nil)
 	query := &backend.DataQuery{}
 
 	t.Run("Given a time range between 2018-04-12 00:00 and 2018-04-12 00:05", func(t *testing.T) {
@@ -218,8 +218,65 @@ func TestMacroEngine(t *testing.T) {
 	})
 }
 
+func TestMacroEngineContinuousAggregate(t *testing.T) {
+	engine := newPostgresMacroEngine(true, map[string]map[string]string{
+		"metrics": {
+			"1m": "metrics_1m",
+			"1h": "metrics_1h",
+			"1d": "metrics_1d",
+		},
+	})
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(30 * 24 * time.Hour)}
+
+	t.Run("selects the coarsest aggregate that fits the interval", func(t *testing.T) {
+		for interval, expected := range map[time.Duration]string{
+			10 * time.Second:   "metrics",
+			time.Minute:        "metrics_1m",
+			30 * time.Minute:   "metrics_1m",
+			2 * time.Hour:      "metrics_1h",
+			7 * 24 * time.Hour: "metrics_1d",
+		} {
+			query := &backend.DataQuery{Interval: interval}
+			sql, err := engine.Interpolate(query, timeRange, "SELECT avg(value) FROM $__continuousAggregate(metrics) WHERE $__timeFilter(time)")
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("SELECT avg(value) FROM %s WHERE time BETWEEN '2018-04-12T18:00:00Z' AND '2018-05-12T18:00:00Z'", expected), sql)
+		}
+	})
+
+	t.Run("uses the aggregates given as arguments", func(t *testing.T) {
+		query := &backend.DataQuery{Interval: 20 * time.Minute}
+		sql, err := engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate(metrics, '15m'=metrics_15m)")
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM metrics_15m", sql)
+
+		sql, err = engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate(events, 5m=events_5m, 1h=events_1h)")
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM events_5m", sql)
+	})
+
+	t.Run("returns the table when it has no aggregates", func(t *testing.T) {
+		query := &backend.DataQuery{Interval: time.Hour}
+		sql, err := engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate(events)")
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM events", sql)
+	})
+
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		query := &backend.DataQuery{Interval: time.Hour}
+		_, err := engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate()")
+		require.EqualError(t, err, "missing table argument for macro __continuousAggregate")
+
+		_, err = engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate(events, events_5m)")
+		require.EqualError(t, err, `invalid continuous aggregate "events_5m", expected width=view`)
+
+		_, err = engine.Interpolate(query, timeRange, "SELECT * FROM $__continuousAggregate(events, soon=events_5m)")
+		require.EqualError(t, err, "error parsing continuous aggregate width soon")
+	})
+}
+
 func TestMacroEngineConcurrency(t *testing.T) {
-	engine := newPostgresMacroEngine(false)
+	engine := newPostgresMacroEngine(false, nil)
 	query1 := backend.DataQuery{
 		JSON: []byte{},
 	}
@@ -90,7 +90,7 @@ func newPostgres(cfg *setting.Cfg, dsInfo sqleng.DataSourceInfo, cnnstr string,
 	db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
 	db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
 
-	handler, err := sqleng.NewQueryDataHandler(cfg, db, config, &queryResultTransformer, newPostgresMacroEngine(dsInfo.JsonData.Timescaledb),
+	handler, err := sqleng.NewQueryDataHandler(cfg, db, config, &queryResultTransformer, newPostgresMacroEngine(dsInfo.JsonData.Timescaledb, dsInfo.JsonData.ContinuousAggregates),
 		logger)
 	if err != nil {
 		logger.Error("Failed connecting to Postgres", "err", err)
@@ -229,12 +229,27 @@ func (s *Service) generateConnectionString(dsInfo sqleng.DataSourceInfo) (string
 	return connStr, nil
 }
 
+var _ sqleng.SQLPaginator = (*postgresQueryResultTransformer)(nil)
+
 type postgresQueryResultTransformer struct{}
 
 func (t *postgresQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
 	return err
 }
 
+// Paginate reads the rows of the query as a subquery.
+func (t *postgresQueryResultTransformer) Paginate(sql string, offset, limit int64) (string, error) {
+	sql, err := sqleng.TrimQuery(sql, sqleng.StandardComments)
+	if err != nil {
+		return "", err
+	}
+	limitClause := "ALL"
+	if limit >= 0 {
+		limitClause = strconv.FormatInt(limit, 10)
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS grafana_chunk LIMIT %s OFFSET %d", sql, limitClause, offset), nil
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
@@ -1411,3 +1411,22 @@ func postgresTestDBConnString() string {
 	return fmt.Sprintf("user=grafanatest password=grafanatest host=%s port=%s dbname=grafanadstest sslmode=disable",
 		host, port)
 }
+
+func TestPostgresPaginate(t *testing.T) {
+	transformer := &postgresQueryResultTransformer{}
+
+	sql, err := transformer.Paginate("SELECT * FROM t ORDER BY id;", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t ORDER BY id) AS grafana_chunk LIMIT 11 OFFSET 20", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t", 20, -1)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t) AS grafana_chunk LIMIT ALL OFFSET 20", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t; -- all rows", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t) AS grafana_chunk LIMIT 11 OFFSET 20", sql)
+
+	_, err = transformer.Paginate("SELECT 1; SELECT 2", 20, 11)
+	require.ErrorIs(t, err, sqleng.ErrQueryNotPaginable)
+}
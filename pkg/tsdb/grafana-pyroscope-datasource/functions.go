@@ -0,0 +1,26 @@
+package pyroscope
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/profiling"
+)
+
+// topFunctionsToDataFrames reduces the profile tree to the n functions with the biggest self value, see
+// profiling.TopFunctionsToDataFrames.
+func topFunctionsToDataFrames(tree *ProfileTree, n int) []*data.Frame {
+	if tree == nil {
+		return []*data.Frame{}
+	}
+
+	// The same function can show up in many stacks, so self values are summed up by name. The root node is the
+	// synthetic "total" node and does not represent a function.
+	selfByName := make(map[string]int64)
+	walkTree(tree, func(node *ProfileTree) {
+		if node.Level == 0 {
+			return
+		}
+		selfByName[node.Name] += node.Self
+	})
+
+	return profiling.TopFunctionsToDataFrames(selfByName, tree.Value, n)
+}
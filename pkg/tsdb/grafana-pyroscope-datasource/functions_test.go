@@ -0,0 +1,85 @@
+package pyroscope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/dataplane/sdata/timeseries"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_topFunctionsToDataFrames(t *testing.T) {
+	tree := &ProfileTree{
+		Value: 100, Level: 0, Self: 0, Name: "total", Nodes: []*ProfileTree{
+			{Value: 60, Level: 1, Self: 10, Name: "main", Nodes: []*ProfileTree{
+				{Value: 30, Level: 2, Self: 30, Name: "encode"},
+				{Value: 20, Level: 2, Self: 20, Name: "compress"},
+			}},
+			{Value: 40, Level: 1, Self: 15, Name: "worker", Nodes: []*ProfileTree{
+				{Value: 25, Level: 2, Self: 25, Name: "encode"},
+			}},
+		},
+	}
+
+	t.Run("sums self values of the same function and sorts by share", func(t *testing.T) {
+		frames := topFunctionsToDataFrames(tree, 3)
+		require.Len(t, frames, 3)
+
+		expected := []struct {
+			name  string
+			share float64
+		}{
+			{"encode", 0.55},
+			{"compress", 0.2},
+			{"worker", 0.15},
+		}
+		for i, e := range expected {
+			require.Equal(t, data.Labels{"function": e.name}, frames[i].Fields[0].Labels)
+			require.InDelta(t, e.share, frames[i].Fields[0].At(0).(float64), 0.0001)
+			require.Equal(t, data.FrameTypeNumericMulti, frames[i].Meta.Type)
+		}
+	})
+
+	t.Run("empty profile", func(t *testing.T) {
+		require.Empty(t, topFunctionsToDataFrames(nil, 10))
+	})
+}
+
+func Test_queryTopFunctions(t *testing.T) {
+	ds := &PyroscopeDatasource{
+		client: &FakeClient{},
+	}
+	pCtx := backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{}`),
+		},
+	}
+
+	dataQuery := makeDataQuery()
+	dataQuery.QueryType = queryTypeTopFunctions
+	resp := ds.query(context.Background(), pCtx, *dataQuery)
+	require.Nil(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	require.Equal(t, data.Labels{"function": "baz"}, resp.Frames[0].Fields[0].Labels)
+	require.Equal(t, 0.8, resp.Frames[0].Fields[0].At(0))
+}
+
+func Test_seriesToDataFramesIsDataplaneTimeSeries(t *testing.T) {
+	resp := &SeriesResponse{
+		Series: []*Series{
+			{Labels: []*LabelPair{{Name: "foo", Value: "bar"}}, Points: []*Point{{Timestamp: int64(1000), Value: 30}, {Timestamp: int64(2000), Value: 10}}},
+			{Labels: []*LabelPair{{Name: "foo", Value: "baz"}}, Points: []*Point{{Timestamp: int64(1000), Value: 30}}},
+		},
+		Units: "short",
+		Label: "samples",
+	}
+
+	reader, err := timeseries.CollectionReaderFromFrames(seriesToDataFrames(resp))
+	require.NoError(t, err)
+	collection, err := reader.GetCollection(false)
+	require.NoError(t, err)
+	require.Empty(t, collection.RemainderIndices)
+	require.Len(t, collection.Refs, 2)
+}
@@ -11,9 +11,10 @@ package dataquery
 
 // Defines values for PyroscopeQueryType.
 const (
-	PyroscopeQueryTypeBoth    PyroscopeQueryType = "both"
-	PyroscopeQueryTypeMetrics PyroscopeQueryType = "metrics"
-	PyroscopeQueryTypeProfile PyroscopeQueryType = "profile"
+	PyroscopeQueryTypeBoth         PyroscopeQueryType = "both"
+	PyroscopeQueryTypeMetrics      PyroscopeQueryType = "metrics"
+	PyroscopeQueryTypeProfile      PyroscopeQueryType = "profile"
+	PyroscopeQueryTypeTopFunctions PyroscopeQueryType = "topFunctions"
 )
 
 // These are the common properties available to all queries in all datasources.
@@ -82,6 +83,9 @@ type GrafanaPyroscopeDataQuery struct {
 
 	// Specifies the query span selectors.
 	SpanSelector []string `json:"spanSelector,omitempty"`
+
+	// Sets the number of functions returned by a top functions query.
+	TopN *int64 `json:"topN,omitempty"`
 }
 
 // PyroscopeQueryType defines model for PyroscopeQueryType.
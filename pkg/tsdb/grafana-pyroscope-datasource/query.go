@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	"github.com/grafana/grafana/pkg/tsdb/grafana-pyroscope-datasource/kinds/dataquery"
+	"github.com/grafana/grafana/pkg/tsdb/profiling"
 	"github.com/xlab/treeprint"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -34,6 +35,8 @@ const (
 	queryTypeProfile = string(dataquery.PyroscopeQueryTypeProfile)
 	queryTypeMetrics = string(dataquery.PyroscopeQueryTypeMetrics)
 	queryTypeBoth    = string(dataquery.PyroscopeQueryTypeBoth)
+
+	queryTypeTopFunctions = string(dataquery.PyroscopeQueryTypeTopFunctions)
 )
 
 // query processes single Pyroscope query transforming the response to data.Frame packaged in DataResponse
@@ -148,6 +151,28 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 		})
 	}
 
+	if query.QueryType == queryTypeTopFunctions {
+		g.Go(func() error {
+			logger.Debug("Calling GetProfile for top functions", "queryModel", qm, "function", logEntrypoint())
+			prof, err := d.client.GetProfile(gCtx, qm.ProfileTypeId, qm.LabelSelector, query.TimeRange.From.UnixMilli(), query.TimeRange.To.UnixMilli(), qm.MaxNodes)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				logger.Error("Error GetProfile()", "err", err, "function", logEntrypoint())
+				return err
+			}
+
+			var tree *ProfileTree
+			if prof != nil {
+				tree = levelsToTree(prof.Flamebearer.Levels, prof.Flamebearer.Names)
+			}
+			responseMutex.Lock()
+			response.Frames = append(response.Frames, topFunctionsToDataFrames(tree, profiling.TopN(qm.TopN))...)
+			responseMutex.Unlock()
+			return nil
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -425,7 +450,11 @@ func seriesToDataFrames(resp *SeriesResponse) []*data.Frame {
 	for _, series := range resp.Series {
 		// We create separate data frames as the series may not have the same length
 		frame := data.NewFrame("series")
-		frame.Meta = &data.FrameMeta{PreferredVisualization: "graph"}
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			TypeVersion:            data.FrameTypeVersion{0, 1},
+			PreferredVisualization: "graph",
+		}
 
 		fields := make(data.Fields, 0, 2)
 		timeField := data.NewField("time", nil, []time.Time{})
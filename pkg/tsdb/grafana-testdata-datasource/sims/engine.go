@@ -66,6 +66,7 @@ func NewSimulationEngine() (*SimulationEngine, error) {
 		newFlightSimInfo,
 		newSinewaveInfo,
 		newTankSimInfo,
+		newScenarioSimInfo,
 	}
 
 	for _, init := range initializers {
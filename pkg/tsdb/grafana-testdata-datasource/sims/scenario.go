@@ -0,0 +1,373 @@
+package sims
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	signalSine       = "sine"
+	signalStep       = "step"
+	signalRandomWalk = "random_walk"
+	signalCounter    = "counter"
+
+	// maxScenarioSeries limits the total number of series a scenario can produce
+	maxScenarioSeries = 1000
+	// maxRandomWalkSteps limits the number of steps summed to compute a random walk value
+	maxRandomWalkSteps = 10000
+)
+
+// scenarioSim generates series from signals declared in the config. Every value is computed
+// from the time alone, so a scenario produces the same data for the same time range in
+// queries, streams and tests.
+type scenarioSim struct {
+	key    simulationKey
+	cfg    scenarioConfig
+	series []scenarioSeries
+}
+
+var (
+	_ Simulation = (*scenarioSim)(nil)
+)
+
+type scenarioConfig struct {
+	Signals []scenarioSignal `json:"signals"`
+}
+
+type scenarioSignal struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // sine, step, random_walk or counter
+
+	Labels map[string]string `json:"labels,omitempty"`
+	// Cardinality creates this many series for the signal, told apart by the cardinality label
+	Cardinality      int    `json:"cardinality,omitempty"`
+	CardinalityLabel string `json:"cardinalityLabel,omitempty"` // defaults to "series"
+
+	Period    float64   `json:"period,omitempty"`    // seconds: sine period, step duration or random walk step interval
+	Amplitude float64   `json:"amplitude,omitempty"` // sine amplitude or random walk step size
+	Offset    float64   `json:"offset,omitempty"`    // Y shift, or the starting value of walks and counters
+	Noise     float64   `json:"noise,omitempty"`     // random noise to add
+	Levels    []float64 `json:"levels,omitempty"`    // values the step signal cycles through
+	Rate      float64   `json:"rate,omitempty"`      // counter increase per second
+	// ResetEvery resets counters and random walks to the offset every N seconds
+	ResetEvery float64  `json:"resetEvery,omitempty"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Seed       int64    `json:"seed,omitempty"`
+
+	// Gaps removes the values of all series of the signal during the window
+	Gaps *scenarioWindow `json:"gaps,omitempty"`
+	// NaNs replaces the values of all series of the signal with NaN during the window
+	NaNs *scenarioWindow `json:"nans,omitempty"`
+	// Absent removes each series during the window, staggered so the series disappear one
+	// after the other
+	Absent *scenarioWindow `json:"absent,omitempty"`
+}
+
+// scenarioWindow matches the first Duration seconds of every Every seconds.
+type scenarioWindow struct {
+	Every    float64 `json:"every"`
+	Duration float64 `json:"duration"`
+}
+
+type scenarioSeries struct {
+	signal *scenarioSignal
+	labels data.Labels
+	key    string
+	seed   int64
+	// phase staggers the absent window of the series, in seconds
+	phase float64
+}
+
+func (s *scenarioSim) GetState() simulationState {
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+func (s *scenarioSim) SetConfig(vals map[string]any) error {
+	cfg, err := mergeScenarioConfig(s.cfg, vals)
+	if err != nil {
+		return err
+	}
+	series, err := cfg.buildSeries()
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.series = series
+	return nil
+}
+
+func (s *scenarioSim) NewFrame(size int) *data.Frame {
+	fields := make([]*data.Field, 0, len(s.series)+1)
+	fields = append(fields, data.NewField(data.TimeSeriesTimeFieldName, nil, make([]time.Time, size)))
+	for _, series := range s.series {
+		fields = append(fields, data.NewField(series.signal.Name, series.labels, make([]*float64, size)))
+	}
+	return data.NewFrame("", fields...)
+}
+
+func (s *scenarioSim) GetValues(t time.Time) map[string]any {
+	values := make(map[string]any, len(s.series)+1)
+	values[data.TimeSeriesTimeFieldName] = t
+
+	secs := float64(t.UnixMilli()) / 1000
+	for _, series := range s.series {
+		signal := series.signal
+		if signal.Gaps.contains(secs) || signal.Absent.contains(secs+series.phase) {
+			values[series.key] = (*float64)(nil) // set explicitly so streams clear the previous value
+			continue
+		}
+
+		v := math.NaN()
+		if !signal.NaNs.contains(secs) {
+			v = series.value(secs)
+		}
+		values[series.key] = &v
+	}
+	return values
+}
+
+func (s *scenarioSim) Close() error {
+	return nil
+}
+
+// mergeScenarioConfig returns a new config with the properties of vals replacing those of cfg.
+// Decoding into a new value keeps new signals from inheriting the fields of the previous
+// signals, and leaves cfg untouched when the result turns out to be invalid.
+func mergeScenarioConfig(cfg scenarioConfig, vals any) (scenarioConfig, error) {
+	current, err := asStringMap(cfg)
+	if err != nil {
+		return scenarioConfig{}, err
+	}
+	if err := updateConfigObjectFromJSON(&current, vals); err != nil {
+		return scenarioConfig{}, err
+	}
+	merged := scenarioConfig{}
+	err = updateConfigObjectFromJSON(&merged, current)
+	return merged, err
+}
+
+func (w *scenarioWindow) contains(secs float64) bool {
+	if w == nil || w.Every <= 0 || w.Duration <= 0 {
+		return false
+	}
+	return math.Mod(secs, w.Every) < w.Duration
+}
+
+func (cfg *scenarioConfig) buildSeries() ([]scenarioSeries, error) {
+	series := make([]scenarioSeries, 0)
+	for i := range cfg.Signals {
+		signal := &cfg.Signals[i]
+		if err := signal.validate(); err != nil {
+			return nil, fmt.Errorf("invalid signal %d: %w", i, err)
+		}
+
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(signal.Name))
+		seed := signal.Seed ^ int64(h.Sum64())
+
+		count := signal.Cardinality
+		if count < 1 {
+			count = 1
+		}
+		if len(series)+count > maxScenarioSeries {
+			return nil, fmt.Errorf("scenario exceeds the maximum of %d series", maxScenarioSeries)
+		}
+
+		label := signal.CardinalityLabel
+		if label == "" {
+			label = "series"
+		}
+
+		for j := 0; j < count; j++ {
+			labels := data.Labels{}
+			for k, v := range signal.Labels {
+				labels[k] = v
+			}
+			if signal.Cardinality > 1 {
+				labels[label] = fmt.Sprintf("%d", j)
+			}
+			if len(labels) == 0 {
+				labels = nil
+			}
+
+			var phase float64
+			if signal.Absent != nil && signal.Absent.Every > 0 {
+				phase = float64(j) * signal.Absent.Every / float64(count)
+			}
+
+			series = append(series, scenarioSeries{
+				signal: signal,
+				labels: labels,
+				key:    seriesKey(signal.Name, labels),
+				seed:   seed + int64(j),
+				phase:  phase,
+			})
+		}
+	}
+	return series, nil
+}
+
+func (signal *scenarioSignal) validate() error {
+	if signal.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if signal.Cardinality < 0 || signal.Cardinality > maxScenarioSeries {
+		return fmt.Errorf("cardinality must be between 0 and %d", maxScenarioSeries)
+	}
+	if signal.Period < 0 || signal.ResetEvery < 0 {
+		return fmt.Errorf("period and resetEvery can not be negative")
+	}
+	for _, w := range []*scenarioWindow{signal.Gaps, signal.NaNs, signal.Absent} {
+		if w != nil && w.Duration > w.Every {
+			return fmt.Errorf("window duration can not be longer than every")
+		}
+	}
+
+	switch signal.Type {
+	case signalSine, signalStep, signalCounter:
+	case signalRandomWalk:
+		if signal.resetEvery()/signal.period() > maxRandomWalkSteps {
+			return fmt.Errorf("random walk resets after more than %d steps", maxRandomWalkSteps)
+		}
+	default:
+		return fmt.Errorf("unknown signal type: %q", signal.Type)
+	}
+	return nil
+}
+
+func (signal *scenarioSignal) period() float64 {
+	if signal.Period > 0 {
+		return signal.Period
+	}
+	switch signal.Type {
+	case signalRandomWalk:
+		return 1
+	default:
+		return 60
+	}
+}
+
+func (signal *scenarioSignal) resetEvery() float64 {
+	if signal.ResetEvery > 0 {
+		return signal.ResetEvery
+	}
+	if signal.Type == signalRandomWalk {
+		return 600
+	}
+	return 0
+}
+
+func (signal *scenarioSignal) amplitude() float64 {
+	if signal.Amplitude != 0 {
+		return signal.Amplitude
+	}
+	return 1
+}
+
+func (series *scenarioSeries) value(secs float64) float64 {
+	signal := series.signal
+	period := signal.period()
+
+	var v float64
+	switch signal.Type {
+	case signalSine:
+		v = math.Sin(math.Mod(secs, period)/period*2*math.Pi)*signal.amplitude() + signal.Offset
+	case signalStep:
+		levels := signal.Levels
+		if len(levels) == 0 {
+			levels = []float64{0, 1}
+		}
+		v = levels[int64(secs/period)%int64(len(levels))] + signal.Offset
+	case signalRandomWalk:
+		reset := signal.resetEvery()
+		start := int64((secs - math.Mod(secs, reset)) / period)
+		end := int64(secs / period)
+		v = signal.Offset
+		for step := start; step < end; step++ {
+			v += (hashFloat(series.seed, step)*2 - 1) * signal.amplitude()
+			v = signal.clamp(v)
+		}
+	case signalCounter:
+		elapsed := secs
+		if reset := signal.resetEvery(); reset > 0 {
+			elapsed = math.Mod(secs, reset)
+		}
+		v = signal.Offset + signal.Rate*elapsed
+	}
+
+	if signal.Noise > 0 {
+		v += (hashFloat(series.seed, int64(secs*1000))*2 - 1) * signal.Noise
+	}
+	return signal.clamp(v)
+}
+
+func (signal *scenarioSignal) clamp(v float64) float64 {
+	if signal.Min != nil && v < *signal.Min {
+		v = *signal.Min
+	}
+	if signal.Max != nil && v > *signal.Max {
+		v = *signal.Max
+	}
+	return v
+}
+
+// hashFloat returns a pseudo random number in [0, 1) for the seed and index (splitmix64).
+func hashFloat(seed int64, idx int64) float64 {
+	z := uint64(seed) + uint64(idx)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11) / float64(1<<53)
+}
+
+func newScenarioSimInfo() simulationInfo {
+	sf := scenarioConfig{
+		Signals: []scenarioSignal{
+			{
+				Name:        "cpu",
+				Type:        signalSine,
+				Labels:      map[string]string{"job": "api"},
+				Cardinality: 3,
+				Period:      60,
+				Amplitude:   20,
+				Offset:      50,
+				Noise:       2,
+			},
+			{
+				Name:       "requests_total",
+				Type:       signalCounter,
+				Labels:     map[string]string{"job": "api"},
+				Rate:       10,
+				ResetEvery: 300,
+			},
+		},
+	}
+
+	return simulationInfo{
+		Type:         "scenario",
+		Name:         "Scenario",
+		Description:  "Series generated from signals defined in the JSON config",
+		ConfigFields: data.NewFrame(""), // signals are edited in the JSON view
+		OnlyForward:  false,
+		create: func(cfg simulationState) (Simulation, error) {
+			s := &scenarioSim{
+				key: cfg.Key,
+			}
+			var err error
+			s.cfg, err = mergeScenarioConfig(sf, cfg.Config) // default value, override any fields
+			if err != nil {
+				return nil, err
+			}
+			s.series, err = s.cfg.buildSeries()
+			return s, err
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package sims
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioSimulation(t *testing.T) {
+	s, err := NewSimulationEngine()
+	require.NoError(t, err)
+
+	query := func(t *testing.T, uid string, config map[string]any) *data.Frame {
+		t.Helper()
+		sq := &simulationQuery{}
+		sq.Key = simulationKey{
+			Type:   "scenario",
+			TickHZ: 1,
+			UID:    uid,
+		}
+		sq.Config = config
+		sb, err := json.Marshal(map[string]any{
+			"sim": sq,
+		})
+		require.NoError(t, err)
+
+		start := time.Date(2020, time.January, 10, 23, 0, 0, 0, time.UTC)
+		rsp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					RefID: "A",
+					TimeRange: backend.TimeRange{
+						From: start,
+						To:   start.Add(time.Second * 10),
+					},
+					Interval:      time.Second,
+					MaxDataPoints: 10,
+					JSON:          sb,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, rsp.Responses["A"].Frames, 1)
+		return rsp.Responses["A"].Frames[0]
+	}
+
+	t.Run("cardinality creates labeled series", func(t *testing.T) {
+		frame := query(t, "cardinality", map[string]any{
+			"signals": []map[string]any{
+				{"name": "cpu", "type": "sine", "labels": map[string]string{"job": "api"}, "cardinality": 3, "cardinalityLabel": "instance"},
+			},
+		})
+		require.Len(t, frame.Fields, 4)
+		require.Equal(t, 10, frame.Rows())
+		for i, field := range frame.Fields[1:] {
+			require.Equal(t, "cpu", field.Name)
+			require.Equal(t, data.Labels{"job": "api", "instance": string(rune('0' + i))}, field.Labels)
+		}
+	})
+
+	t.Run("counter resets", func(t *testing.T) {
+		frame := query(t, "counter", map[string]any{
+			"signals": []map[string]any{
+				{"name": "requests_total", "type": "counter", "rate": 2, "resetEvery": 5},
+			},
+		})
+		values := make([]float64, frame.Rows())
+		for i := range values {
+			values[i] = *frame.Fields[1].At(i).(*float64)
+		}
+		require.Equal(t, []float64{0, 2, 4, 6, 8, 0, 2, 4, 6, 8}, values)
+	})
+
+	t.Run("gaps and NaNs", func(t *testing.T) {
+		frame := query(t, "gaps", map[string]any{
+			"signals": []map[string]any{
+				{"name": "a", "type": "step", "levels": []float64{1}, "gaps": map[string]any{"every": 5, "duration": 1}},
+				{"name": "b", "type": "step", "levels": []float64{1}, "nans": map[string]any{"every": 5, "duration": 2}},
+			},
+		})
+		require.Nil(t, frame.Fields[1].At(0))
+		require.Equal(t, 1.0, *frame.Fields[1].At(1).(*float64))
+		require.True(t, math.IsNaN(*frame.Fields[2].At(1).(*float64)))
+		require.Equal(t, 1.0, *frame.Fields[2].At(2).(*float64))
+	})
+
+	t.Run("absent series are staggered", func(t *testing.T) {
+		frame := query(t, "absent", map[string]any{
+			"signals": []map[string]any{
+				{"name": "up", "type": "step", "levels": []float64{1}, "cardinality": 2, "absent": map[string]any{"every": 10, "duration": 5}},
+			},
+		})
+		// the first series is absent in the first half of the range, the second in the other half
+		require.Nil(t, frame.Fields[1].At(0))
+		require.NotNil(t, frame.Fields[2].At(0))
+		require.NotNil(t, frame.Fields[1].At(5))
+		require.Nil(t, frame.Fields[2].At(5))
+	})
+
+	t.Run("random walk is deterministic", func(t *testing.T) {
+		config := map[string]any{
+			"signals": []map[string]any{
+				{"name": "walk", "type": "random_walk", "min": -2, "max": 2, "seed": 7},
+			},
+		}
+		first := query(t, "walk-a", config)
+		second := query(t, "walk-b", config)
+		require.Equal(t, first.Fields[1], second.Fields[1])
+		for i := 0; i < first.Rows(); i++ {
+			v := *first.Fields[1].At(i).(*float64)
+			require.True(t, v >= -2 && v <= 2)
+		}
+	})
+
+	t.Run("invalid signals", func(t *testing.T) {
+		_, err := s.Lookup(simulationState{
+			Key:    simulationKey{Type: "scenario", TickHZ: 1, UID: "invalid"},
+			Config: map[string]any{"signals": []map[string]any{{"name": "x", "type": "unknown"}}},
+		})
+		require.Error(t, err)
+
+		_, err = s.Lookup(simulationState{
+			Key:    simulationKey{Type: "scenario", TickHZ: 1, UID: "too-many"},
+			Config: map[string]any{"signals": []map[string]any{{"name": "x", "type": "sine", "cardinality": maxScenarioSeries + 1}}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid config does not replace the running config", func(t *testing.T) {
+		sim, err := s.Lookup(simulationState{
+			Key: simulationKey{Type: "scenario", TickHZ: 1, UID: "update"},
+		})
+		require.NoError(t, err)
+
+		err = sim.SetConfig(map[string]any{"signals": []map[string]any{{"type": "sine"}}})
+		require.Error(t, err)
+		require.Len(t, sim.GetState().Config.(scenarioConfig).Signals, 2)
+	})
+}
@@ -47,9 +47,18 @@ func asStringMap(input any) (map[string]any, error) {
 	return v, err
 }
 
+// seriesKey identifies a field in the values of a simulation, as fields with labels
+// may share the same name.
+func seriesKey(name string, labels data.Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+	return name + labels.String()
+}
+
 func setFrameRow(frame *data.Frame, idx int, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[seriesKey(field.Name, field.Labels)]
 		if ok {
 			field.Set(idx, v)
 		}
@@ -58,7 +67,7 @@ func setFrameRow(frame *data.Frame, idx int, values map[string]any) {
 
 func appendFrameRow(frame *data.Frame, values map[string]any) {
 	for _, field := range frame.Fields {
-		v, ok := values[field.Name]
+		v, ok := values[seriesKey(field.Name, field.Labels)]
 		if ok {
 			field.Append(v)
 		} else {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/apache/arrow/go/v13/arrow/flight"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"google.golang.org/grpc/metadata"
 
@@ -49,29 +50,12 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.Query
 		}
 
 		logger.Info(fmt.Sprintf("InfluxDB executing SQL: %s", qm.RawSQL))
-		info, err := r.client.Execute(ctx, qm.RawSQL)
-		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
-			return tRes, nil
-		}
-		if len(info.Endpoint) != 1 {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("unsupported endpoint count in response: %d", len(info.Endpoint)))
-			return tRes, nil
-		}
-
-		reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
+		res, err := r.query(ctx, qm)
 		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
+			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, err.Error())
 			return tRes, nil
 		}
-		defer reader.Release()
-
-		headers, err := reader.Header()
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to extract headers: %s", err))
-		}
-
-		tRes.Responses[q.RefID] = newQueryDataResponse(reader, *qm.Query, headers)
+		tRes.Responses[q.RefID] = res
 	}
 
 	return tRes, nil
@@ -81,6 +65,69 @@ type runner struct {
 	client *client
 }
 
+// query runs a single query and reads its results, closing the statement and
+// the reader before returning so they are not kept open for the other queries
+// of the request.
+func (r *runner) query(ctx context.Context, qm *queryModel) (backend.DataResponse, error) {
+	info, closeStmt, err := r.execute(ctx, qm)
+	if err != nil {
+		return backend.DataResponse{}, fmt.Errorf("flightsql: %s", err)
+	}
+	defer closeStmt()
+	if len(info.Endpoint) != 1 {
+		return backend.DataResponse{}, fmt.Errorf("unsupported endpoint count in response: %d", len(info.Endpoint))
+	}
+
+	reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
+	if err != nil {
+		return backend.DataResponse{}, fmt.Errorf("flightsql: %s", err)
+	}
+	defer reader.Release()
+
+	headers, err := reader.Header()
+	if err != nil {
+		glog.FromContext(ctx).Error(fmt.Sprintf("Failed to extract headers: %s", err))
+	}
+
+	return newQueryDataResponse(reader, *qm.Query, headers), nil
+}
+
+// execute runs the query. Queries with parameters are executed as prepared
+// statements, so the values are bound by the server instead of being
+// interpolated into the SQL. The returned function closes the prepared
+// statement once its results have been read.
+func (r *runner) execute(ctx context.Context, qm *queryModel) (*flight.FlightInfo, func(), error) {
+	if len(qm.Parameters) == 0 {
+		info, err := r.client.Execute(ctx, qm.RawSQL)
+		return info, func() {}, err
+	}
+
+	stmt, err := r.client.Prepare(ctx, qm.RawSQL)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeStmt := func() {
+		if err := stmt.Close(ctx); err != nil {
+			glog.FromContext(ctx).Warn("Failed to close prepared statement", "err", err)
+		}
+	}
+
+	params, err := newParametersRecord(r.client.Alloc, qm.Parameters)
+	if err != nil {
+		closeStmt()
+		return nil, nil, err
+	}
+	defer params.Release()
+	stmt.SetParameters(params)
+
+	info, err := stmt.Execute(ctx)
+	if err != nil {
+		closeStmt()
+		return nil, nil, err
+	}
+	return info, closeStmt, nil
+}
+
 // runnerFromDataSource creates a runner from the datasource model (the datasource instance's configuration).
 func runnerFromDataSource(dsInfo *models.DatasourceInfo) (*runner, error) {
 	if dsInfo.URL == "" {
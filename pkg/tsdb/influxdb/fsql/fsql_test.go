@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/apache/arrow/go/v13/arrow/flight"
@@ -96,6 +98,107 @@ func (suite *FSQLTestSuite) TestIntegration_QueryData() {
 	})
 }
 
+func (suite *FSQLTestSuite) TestIntegration_QueryDataWithParameters() {
+	suite.Run("should bind parameters to a prepared statement", func() {
+		b, err := json.Marshal(queryRequest{
+			RefID:      "A",
+			RawQuery:   "select * from intTable where keyName = ? and value > ?",
+			Format:     "table",
+			Parameters: []any{"one", 0},
+		})
+		require.NoError(suite.T(), err)
+
+		resp, err := Query(
+			context.Background(),
+			testDatasourceInfo(),
+			backend.QueryDataRequest{
+				Queries: []backend.DataQuery{{RefID: "A", JSON: b}},
+			},
+		)
+
+		require.NoError(suite.T(), err)
+		respA := resp.Responses["A"]
+		require.NoError(suite.T(), respA.Error)
+		require.Len(suite.T(), respA.Frames, 1)
+		frame := respA.Frames[0]
+		require.Equal(suite.T(), 1, frame.Rows())
+		require.Equal(suite.T(), "keyName", frame.Fields[1].Name)
+		v, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(suite.T(), ok)
+		require.Equal(suite.T(), "one", v)
+	})
+}
+
+func (suite *FSQLTestSuite) TestIntegration_CallResource() {
+	call := func(path string) *backend.CallResourceResponse {
+		sender := &fakeSender{}
+		err := CallResource(context.Background(), testDatasourceInfo(), &backend.CallResourceRequest{
+			Path: strings.SplitN(path, "?", 2)[0],
+			URL:  path,
+		}, sender)
+		require.NoError(suite.T(), err)
+		require.NotNil(suite.T(), sender.resp)
+		return sender.resp
+	}
+
+	suite.Run("should list databases", func() {
+		resp := call("databases")
+		require.Equal(suite.T(), http.StatusOK, resp.Status)
+		require.JSONEq(suite.T(), `[""]`, string(resp.Body))
+	})
+
+	suite.Run("should list tables", func() {
+		resp := call("tables")
+		require.Equal(suite.T(), http.StatusOK, resp.Status)
+		var tables []string
+		require.NoError(suite.T(), json.Unmarshal(resp.Body, &tables))
+		require.Contains(suite.T(), tables, "intTable")
+		require.Contains(suite.T(), tables, "foreignTable")
+	})
+
+	suite.Run("should list the columns of a table", func() {
+		resp := call("columns?table=intTable")
+		require.Equal(suite.T(), http.StatusOK, resp.Status)
+		var columns []Column
+		require.NoError(suite.T(), json.Unmarshal(resp.Body, &columns))
+		names := make([]string, 0, len(columns))
+		for _, c := range columns {
+			names = append(names, c.Name)
+		}
+		require.Equal(suite.T(), []string{"id", "keyName", "value", "foreignId"}, names)
+	})
+
+	suite.Run("should require a table to list columns", func() {
+		resp := call("columns")
+		require.Equal(suite.T(), http.StatusBadRequest, resp.Status)
+	})
+
+	suite.Run("should reject unknown resources", func() {
+		resp := call("unknown")
+		require.Equal(suite.T(), http.StatusNotFound, resp.Status)
+	})
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func testDatasourceInfo() *models.DatasourceInfo {
+	return &models.DatasourceInfo{
+		Token:      "secret",
+		URL:        "http://localhost:12345",
+		DbName:     "influxdb",
+		Version:    "test",
+		HTTPMode:   "proxy",
+		SecureGrpc: false,
+	}
+}
+
 func mustQueryJSON(t *testing.T, refID, sql string) []byte {
 	t.Helper()
 
@@ -0,0 +1,227 @@
+package fsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/flight"
+	"github.com/apache/arrow/go/v13/arrow/flight/flightsql"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+// Column describes a column of a table, as reported by the table schema
+// returned by the FlightSQL server.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CallResource serves the schema of the database through FlightSQL metadata
+// calls. The supported paths are:
+//   - databases: the database schemas
+//   - tables: the tables of the database schema set by the database parameter
+//   - columns: the columns of the table set by the table parameter
+func CallResource(ctx context.Context, dsInfo *models.DatasourceInfo, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := glog.FromContext(ctx)
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return sendError(sender, http.StatusBadRequest, fmt.Errorf("bad URL: %w", err))
+	}
+	params := reqURL.Query()
+
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return sendError(sender, http.StatusInternalServerError, err)
+	}
+	defer func(client *client) {
+		err := client.Close()
+		if err != nil {
+			logger.Warn("Failed to close fsql client", "err", err)
+		}
+	}(r.client)
+
+	if r.client.md.Len() != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+	}
+
+	var result any
+	switch req.Path {
+	case "databases":
+		result, err = r.client.databases(ctx)
+	case "tables":
+		result, err = r.client.tables(ctx, params.Get("database"))
+	case "columns":
+		table := params.Get("table")
+		if table == "" {
+			return sendError(sender, http.StatusBadRequest, fmt.Errorf("missing table parameter"))
+		}
+		result, err = r.client.columns(ctx, params.Get("database"), table)
+	default:
+		return sendError(sender, http.StatusNotFound, fmt.Errorf("invalid resource URL: %s", req.Path))
+	}
+	if err != nil {
+		logger.Error("Failed to fetch FlightSQL metadata", "path", req.Path, "error", err)
+		return sendError(sender, http.StatusInternalServerError, fmt.Errorf("flightsql: %w", err))
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return sendError(sender, http.StatusInternalServerError, err)
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  http.StatusOK,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
+
+func sendError(sender backend.CallResourceResponseSender, status int, err error) error {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
+
+// databases returns the sorted names of the database schemas.
+func (c *client) databases(ctx context.Context) ([]string, error) {
+	info, err := c.GetDBSchemas(ctx, &flightsql.GetDBSchemasOpts{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	err = c.readStrings(ctx, info, "db_schema_name", func(name string) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// tables returns the sorted names of the tables of the given database
+// schema, or of all the schemas when it is empty.
+func (c *client) tables(ctx context.Context, database string) ([]string, error) {
+	opts := &flightsql.GetTablesOpts{}
+	if database != "" {
+		opts.DbSchemaFilterPattern = &database
+	}
+	info, err := c.GetTables(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	err = c.readStrings(ctx, info, "table_name", func(name string) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+// columns returns the columns of the table, in the order of its schema.
+func (c *client) columns(ctx context.Context, database, table string) ([]Column, error) {
+	opts := &flightsql.GetTablesOpts{
+		TableNameFilterPattern: &table,
+		IncludeSchema:          true,
+	}
+	if database != "" {
+		opts.DbSchemaFilterPattern = &database
+	}
+	info, err := c.GetTables(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []Column{}
+	err = c.readRecords(ctx, info, func(record arrow.Record) error {
+		indices := record.Schema().FieldIndices("table_schema")
+		if len(indices) == 0 {
+			return fmt.Errorf("table_schema column not found")
+		}
+		schemas, ok := record.Column(indices[0]).(*array.Binary)
+		if !ok {
+			return fmt.Errorf("unexpected table_schema column type %s", record.Column(indices[0]).DataType())
+		}
+		for i := 0; i < schemas.Len(); i++ {
+			// The filter is a pattern, so it may match other tables too.
+			if schemas.IsNull(i) || tableName(record, i) != table {
+				continue
+			}
+			schema, err := flight.DeserializeSchema(schemas.Value(i), c.Alloc)
+			if err != nil {
+				return fmt.Errorf("table schema: %w", err)
+			}
+			for _, f := range schema.Fields() {
+				columns = append(columns, Column{Name: f.Name, Type: f.Type.String()})
+			}
+		}
+		return nil
+	})
+	return columns, err
+}
+
+func tableName(record arrow.Record, row int) string {
+	indices := record.Schema().FieldIndices("table_name")
+	if len(indices) == 0 {
+		return ""
+	}
+	names, ok := record.Column(indices[0]).(*array.String)
+	if !ok || names.IsNull(row) {
+		return ""
+	}
+	return names.Value(row)
+}
+
+// readStrings calls fn with the non null values of the named string column
+// of the results.
+func (c *client) readStrings(ctx context.Context, info *flight.FlightInfo, column string, fn func(string)) error {
+	return c.readRecords(ctx, info, func(record arrow.Record) error {
+		indices := record.Schema().FieldIndices(column)
+		if len(indices) == 0 {
+			return fmt.Errorf("%s column not found", column)
+		}
+		values, ok := record.Column(indices[0]).(*array.String)
+		if !ok {
+			return fmt.Errorf("unexpected %s column type %s", column, record.Column(indices[0]).DataType())
+		}
+		for i := 0; i < values.Len(); i++ {
+			if !values.IsNull(i) {
+				fn(values.Value(i))
+			}
+		}
+		return nil
+	})
+}
+
+// readRecords calls fn with every record of every endpoint of the results.
+func (c *client) readRecords(ctx context.Context, info *flight.FlightInfo, fn func(arrow.Record) error) error {
+	for _, endpoint := range info.Endpoint {
+		reader, err := c.DoGet(ctx, endpoint.Ticket)
+		if err != nil {
+			return err
+		}
+		for reader.Next() {
+			if err := fn(reader.Record()); err != nil {
+				reader.Release()
+				return err
+			}
+		}
+		err = reader.Err()
+		reader.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package fsql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+)
+
+// newParametersRecord builds the single row [arrow.Record] binding the
+// values of a parameterized query to its placeholders. The type of each
+// column is inferred from the JSON value: whole numbers are bound as int64,
+// other numbers as float64.
+func newParametersRecord(alloc memory.Allocator, values []any) (arrow.Record, error) {
+	fields := make([]arrow.Field, len(values))
+	columns := make([]arrow.Array, len(values))
+	defer func() {
+		for _, c := range columns {
+			if c != nil {
+				c.Release()
+			}
+		}
+	}()
+
+	for i, v := range values {
+		name := "$" + strconv.Itoa(i+1)
+		switch v := v.(type) {
+		case nil:
+			fields[i] = arrow.Field{Name: name, Type: arrow.Null, Nullable: true}
+			columns[i] = array.NewNull(1)
+		case string:
+			b := array.NewStringBuilder(alloc)
+			b.Append(v)
+			fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.String}
+			columns[i] = b.NewArray()
+			b.Release()
+		case bool:
+			b := array.NewBooleanBuilder(alloc)
+			b.Append(v)
+			fields[i] = arrow.Field{Name: name, Type: arrow.FixedWidthTypes.Boolean}
+			columns[i] = b.NewArray()
+			b.Release()
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+				b := array.NewInt64Builder(alloc)
+				b.Append(int64(v))
+				fields[i] = arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Int64}
+				columns[i] = b.NewArray()
+				b.Release()
+				continue
+			}
+			b := array.NewFloat64Builder(alloc)
+			b.Append(v)
+			fields[i] = arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64}
+			columns[i] = b.NewArray()
+			b.Release()
+		default:
+			return nil, fmt.Errorf("unsupported type %T for query parameter %d", v, i+1)
+		}
+	}
+
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, 1), nil
+}
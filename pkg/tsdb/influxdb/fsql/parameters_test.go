@@ -0,0 +1,39 @@
+package fsql
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParametersRecord(t *testing.T) {
+	alloc := memory.NewCheckedAllocator(memory.DefaultAllocator)
+	defer alloc.AssertSize(t, 0)
+
+	t.Run("infers the types of the parameters", func(t *testing.T) {
+		record, err := newParametersRecord(alloc, []any{"host", float64(42), 1.5, true, nil})
+		require.NoError(t, err)
+		defer record.Release()
+
+		require.EqualValues(t, 1, record.NumRows())
+		types := []arrow.DataType{}
+		for _, f := range record.Schema().Fields() {
+			types = append(types, f.Type)
+		}
+		require.Equal(t, []arrow.DataType{
+			arrow.BinaryTypes.String,
+			arrow.PrimitiveTypes.Int64,
+			arrow.PrimitiveTypes.Float64,
+			arrow.FixedWidthTypes.Boolean,
+			arrow.Null,
+		}, types)
+		require.Equal(t, "$1", record.ColumnName(0))
+	})
+
+	t.Run("rejects unsupported values", func(t *testing.T) {
+		_, err := newParametersRecord(alloc, []any{"host", []any{"a", "b"}})
+		require.EqualError(t, err, "unsupported type []interface {} for query parameter 2")
+	})
+}
@@ -11,6 +11,10 @@ import (
 
 type queryModel struct {
 	*sqlutil.Query
+
+	// Parameters are bound to the placeholders of the query, in order, by
+	// executing it as a prepared statement.
+	Parameters []any
 }
 
 // queryRequest is an inbound query request as part of a batch of queries sent
@@ -21,6 +25,7 @@ type queryRequest struct {
 	IntervalMilliseconds int    `json:"intervalMs"`
 	MaxDataPoints        int64  `json:"maxDataPoints"`
 	Format               string `json:"format"`
+	Parameters           []any  `json:"parameters,omitempty"`
 }
 
 func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
@@ -55,5 +60,5 @@ func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
 	}
 	query.RawSQL = sql
 
-	return &queryModel{query}, nil
+	return &queryModel{Query: query, Parameters: q.Parameters}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -115,6 +116,22 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 }
 
+// CallResource serves the schema browsing resources of the SQL query language.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	if dsInfo.Version != influxVersionSQL {
+		return sender.Send(&backend.CallResourceResponse{
+			Status: http.StatusNotFound,
+			Body:   []byte(fmt.Sprintf("resources are not supported by the %s query language", dsInfo.Version)),
+		})
+	}
+	return fsql.CallResource(ctx, dsInfo, req, sender)
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*models.DatasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
@@ -69,6 +69,9 @@ type datasourceInfo struct {
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
+
+	// upstream tail connections shared by the open streams
+	tails *tailHub
 }
 
 type QueryJSONModel struct {
@@ -104,6 +107,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			HTTPClient: client,
 			URL:        settings.URL,
 			streams:    make(map[string]data.FrameJSONCache),
+			tails:      newTailHub(websocketTailSource(settings.URL)),
 		}
 		return model, nil
 	}
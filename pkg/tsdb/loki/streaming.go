@@ -2,15 +2,10 @@ package loki
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/url"
-	"os"
-	"os/signal"
 	"strings"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -40,6 +35,16 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 		}, fmt.Errorf("missing expr in channel (subscribe)")
 	}
 
+	opts, err := parseStreamOptions(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := newTailFilter(opts); err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
 	dsInfo.streamsMu.RLock()
 	defer dsInfo.streamsMu.RUnlock()
 
@@ -73,96 +78,69 @@ func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest,
 		return fmt.Errorf("missing expr in cuannel")
 	}
 
-	logger := logger.FromContext(ctx)
-	count := int64(0)
-
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
-	params := url.Values{}
-	params.Add("query", query.Expr)
-
-	wsurl, _ := url.Parse(dsInfo.URL)
-
-	wsurl.Path = "/loki/api/v2alpha/tail"
-
-	if wsurl.Scheme == "https" {
-		wsurl.Scheme = "wss"
-	} else {
-		wsurl.Scheme = "ws"
+	opts, err := parseStreamOptions(req.Data)
+	if err != nil {
+		return err
 	}
-	wsurl.RawQuery = params.Encode()
-
-	logger.Info("Connecting to websocket", "url", wsurl)
-	c, r, err := websocket.DefaultDialer.Dial(wsurl.String(), nil)
+	filter, err := newTailFilter(opts)
 	if err != nil {
-		logger.Error("Error connecting to websocket", "err", err)
-		return fmt.Errorf("error connecting to websocket")
+		return err
 	}
 
+	logger := logger.FromContext(ctx)
+	count := int64(0)
+
+	// Identical expressions share one upstream tail, the stream options are applied per channel
+	frames, unsubscribe := dsInfo.tails.subscribe(query.Expr)
+
 	defer func() {
 		dsInfo.streamsMu.Lock()
 		delete(dsInfo.streams, req.Path)
 		dsInfo.streamsMu.Unlock()
-		if r != nil {
-			_ = r.Body.Close()
-		}
-		err = c.Close()
-		logger.Error("Closing loki websocket", "err", err)
+		unsubscribe()
 	}()
 
 	prev := data.FrameJSONCache{}
 
-	// Read all messages
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				logger.Error("Websocket read:", "err", err)
-				return
+	ticker := time.NewTicker(time.Second * 60) //.Step)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				logger.Info("Socket done")
+				return nil
 			}
 
-			frame := &data.Frame{}
-			err = json.Unmarshal(message, &frame)
-
-			if err == nil && frame != nil {
-				next, _ := data.FrameToJSONCache(frame)
-				if next.SameSchema(&prev) {
-					err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
-				} else {
-					err = sender.SendFrame(frame, data.IncludeAll)
-				}
-				prev = next
-
-				// Cache the initial data
-				dsInfo.streamsMu.Lock()
-				dsInfo.streams[req.Path] = prev
-				dsInfo.streamsMu.Unlock()
+			frame = filter.apply(frame)
+			if frame == nil {
+				continue
 			}
 
-			if err != nil {
-				logger.Error("Websocket write:", "err", err, "raw", message)
-				return
+			next, _ := data.FrameToJSONCache(frame)
+			if next.SameSchema(&prev) {
+				err = sender.SendBytes(next.Bytes(data.IncludeDataOnly))
+			} else {
+				err = sender.SendFrame(frame, data.IncludeAll)
 			}
-		}
-	}()
+			prev = next
 
-	ticker := time.NewTicker(time.Second * 60) //.Step)
-	defer ticker.Stop()
+			// Cache the initial data
+			dsInfo.streamsMu.Lock()
+			dsInfo.streams[req.Path] = prev
+			dsInfo.streamsMu.Unlock()
 
-	for {
-		select {
-		case <-done:
-			logger.Info("Socket done")
-			return nil
+			if err != nil {
+				logger.Error("Websocket write:", "err", err)
+				return nil
+			}
 		case <-ctx.Done():
 			logger.Info("Stop streaming (context canceled)")
 			return nil
 		case t := <-ticker.C:
 			count++
-			logger.Error("Loki websocket ping?", "time", t, "count", count)
+			logger.Debug("Loki websocket ping?", "time", t, "count", count)
 		}
 	}
 }
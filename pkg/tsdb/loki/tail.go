@@ -0,0 +1,407 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"golang.org/x/time/rate"
+)
+
+// defaultTailLinesPerSecond caps the number of lines a single stream channel forwards to
+// its subscribers when the query does not set its own limit.
+const defaultTailLinesPerSecond = 1000
+
+// tailSubscriberBuffer is the number of frames buffered per subscriber before frames
+// start getting dropped for slow consumers.
+const tailSubscriberBuffer = 64
+
+// tailSource reads frames from an upstream Loki tail and writes them to out until the
+// context is canceled or the upstream connection fails.
+type tailSource func(ctx context.Context, expr string, out chan<- *data.Frame) error
+
+// tailHub shares a single upstream Loki tail connection between all stream channels
+// tailing the same expression.
+type tailHub struct {
+	source tailSource
+
+	mu    sync.Mutex
+	tails map[string]*sharedTail
+}
+
+type sharedTail struct {
+	cancel      context.CancelFunc
+	subscribers map[int64]chan *data.Frame
+	nextID      int64
+	// dropped counts the frames dropped for slow subscribers
+	dropped int64
+}
+
+func newTailHub(source tailSource) *tailHub {
+	return &tailHub{
+		source: source,
+		tails:  make(map[string]*sharedTail),
+	}
+}
+
+// subscribe returns a channel receiving every frame read from the upstream tail for expr,
+// opening the upstream connection if this is the first subscriber. The channel is closed
+// when the upstream tail ends. The returned function must be called to unsubscribe; the
+// upstream connection is closed when the last subscriber leaves.
+func (h *tailHub) subscribe(expr string) (<-chan *data.Frame, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tail, ok := h.tails[expr]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		tail = &sharedTail{
+			cancel:      cancel,
+			subscribers: make(map[int64]chan *data.Frame),
+		}
+		h.tails[expr] = tail
+		go h.run(ctx, expr, tail)
+	}
+
+	id := tail.nextID
+	tail.nextID++
+	ch := make(chan *data.Frame, tailSubscriberBuffer)
+	tail.subscribers[id] = ch
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		sub, ok := tail.subscribers[id]
+		if !ok {
+			return
+		}
+		delete(tail.subscribers, id)
+		close(sub)
+
+		if len(tail.subscribers) == 0 {
+			tail.cancel()
+			if h.tails[expr] == tail {
+				delete(h.tails, expr)
+			}
+		}
+	}
+}
+
+func (h *tailHub) run(ctx context.Context, expr string, tail *sharedTail) {
+	frames := make(chan *data.Frame)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.source(ctx, expr, frames)
+	}()
+
+	for {
+		select {
+		case frame := <-frames:
+			h.broadcast(tail, frame)
+		case err := <-done:
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Loki tail ended", "err", err)
+			}
+			h.closeTail(expr, tail)
+			return
+		}
+	}
+}
+
+func (h *tailHub) broadcast(tail *sharedTail, frame *data.Frame) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range tail.subscribers {
+		select {
+		case sub <- frame:
+		default:
+			tail.dropped++
+			logger.Debug("Dropping Loki tail frame for slow subscriber", "dropped", tail.dropped)
+		}
+	}
+}
+
+func (h *tailHub) closeTail(expr string, tail *sharedTail) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	tail.cancel()
+	for id, sub := range tail.subscribers {
+		delete(tail.subscribers, id)
+		close(sub)
+	}
+	if h.tails[expr] == tail {
+		delete(h.tails, expr)
+	}
+}
+
+// websocketTailSource returns a tailSource reading from the Loki tail websocket endpoint.
+func websocketTailSource(dsURL string) tailSource {
+	return func(ctx context.Context, expr string, out chan<- *data.Frame) error {
+		params := url.Values{}
+		params.Add("query", expr)
+
+		wsurl, err := url.Parse(dsURL)
+		if err != nil {
+			return err
+		}
+
+		wsurl.Path = "/loki/api/v2alpha/tail"
+
+		if wsurl.Scheme == "https" {
+			wsurl.Scheme = "wss"
+		} else {
+			wsurl.Scheme = "ws"
+		}
+		wsurl.RawQuery = params.Encode()
+
+		logger.Info("Connecting to websocket", "url", wsurl)
+		c, r, err := websocket.DefaultDialer.DialContext(ctx, wsurl.String(), nil)
+		if err != nil {
+			logger.Error("Error connecting to websocket", "err", err)
+			return fmt.Errorf("error connecting to websocket")
+		}
+		if r != nil {
+			_ = r.Body.Close()
+		}
+
+		go func() {
+			<-ctx.Done()
+			err := c.Close()
+			logger.Info("Closing loki websocket", "err", err)
+		}()
+
+		for {
+			_, message, err := c.ReadMessage()
+			if err != nil {
+				return fmt.Errorf("websocket read: %w", err)
+			}
+
+			frame := &data.Frame{}
+			if err := json.Unmarshal(message, &frame); err != nil || frame == nil {
+				continue
+			}
+
+			select {
+			case out <- frame:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// StreamOptions are optional server-side processing settings for a tail stream, read
+// from the "stream" property of the query model.
+type StreamOptions struct {
+	// LineFilters are substrings that every forwarded line must contain.
+	LineFilters []string `json:"lineFilters,omitempty"`
+	// LineRegex is a regular expression that every forwarded line must match.
+	LineRegex string `json:"lineRegex,omitempty"`
+	// LabelMatchers is a stream selector, e.g. {level=~"error|warn"}, that the labels of
+	// every forwarded line must match.
+	LabelMatchers string `json:"labelMatchers,omitempty"`
+	// MaxLinesPerSecond limits the lines forwarded by the channel. Lines over the limit
+	// are dropped.
+	MaxLinesPerSecond float64 `json:"maxLinesPerSecond,omitempty"`
+}
+
+func parseStreamOptions(raw json.RawMessage) (StreamOptions, error) {
+	model := struct {
+		Stream StreamOptions `json:"stream"`
+	}{}
+	if len(raw) == 0 {
+		return model.Stream, nil
+	}
+	err := json.Unmarshal(raw, &model)
+	return model.Stream, err
+}
+
+// tailFilter applies the stream options of a single channel to frames read from the
+// shared upstream tail.
+type tailFilter struct {
+	lineFilters []string
+	lineRegex   *regexp.Regexp
+	matchers    []*labels.Matcher
+	limiter     *rate.Limiter
+	dropped     int
+}
+
+func newTailFilter(opts StreamOptions) (*tailFilter, error) {
+	f := &tailFilter{
+		lineFilters: opts.LineFilters,
+	}
+
+	if opts.LineRegex != "" {
+		re, err := regexp.Compile(opts.LineRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line regex: %w", err)
+		}
+		f.lineRegex = re
+	}
+
+	if opts.LabelMatchers != "" {
+		matchers, err := parser.ParseMetricSelector(opts.LabelMatchers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label matchers: %w", err)
+		}
+		f.matchers = matchers
+	}
+
+	limit := opts.MaxLinesPerSecond
+	if limit <= 0 || limit > defaultTailLinesPerSecond {
+		limit = defaultTailLinesPerSecond
+	}
+	f.limiter = rate.NewLimiter(rate.Limit(limit), int(limit)+1)
+
+	return f, nil
+}
+
+// apply returns a copy of the frame holding only the rows passing the filters and the
+// rate limit, or nil when no rows remain.
+func (f *tailFilter) apply(frame *data.Frame) *data.Frame {
+	rows, err := frame.RowLen()
+	if err != nil || rows == 0 {
+		return nil
+	}
+
+	lineField := findLineField(frame)
+	labelsField := findLabelsField(frame)
+
+	out := frame.EmptyCopy()
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		meta.Notices = append([]data.Notice(nil), meta.Notices...)
+		out.Meta = &meta
+	}
+	for i, field := range frame.Fields {
+		out.Fields[i].Config = field.Config
+	}
+
+	for i := 0; i < rows; i++ {
+		if lineField != nil && !f.matchLine(stringAt(lineField, i)) {
+			continue
+		}
+		if len(f.matchers) > 0 && !f.matchLabels(labelsAt(labelsField, i)) {
+			continue
+		}
+		if !f.limiter.Allow() {
+			f.dropped++
+			continue
+		}
+		out.AppendRow(frame.RowCopy(i)...)
+	}
+
+	if out.Rows() == 0 {
+		return nil
+	}
+
+	if f.dropped > 0 {
+		out.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("%d lines were dropped because the stream exceeded its rate limit", f.dropped),
+		})
+		f.dropped = 0
+	}
+
+	return out
+}
+
+func (f *tailFilter) matchLine(line string) bool {
+	for _, s := range f.lineFilters {
+		if !strings.Contains(line, s) {
+			return false
+		}
+	}
+	if f.lineRegex != nil && !f.lineRegex.MatchString(line) {
+		return false
+	}
+	return true
+}
+
+func (f *tailFilter) matchLabels(lbls map[string]string) bool {
+	for _, m := range f.matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func findLineField(frame *data.Frame) *data.Field {
+	for _, field := range frame.Fields {
+		if strings.EqualFold(field.Name, "line") && isStringField(field) {
+			return field
+		}
+	}
+	for _, field := range frame.Fields {
+		if isStringField(field) && !strings.EqualFold(field.Name, "labels") && !strings.EqualFold(field.Name, "id") {
+			return field
+		}
+	}
+	return nil
+}
+
+func findLabelsField(frame *data.Frame) *data.Field {
+	for _, field := range frame.Fields {
+		if strings.EqualFold(field.Name, "labels") {
+			return field
+		}
+	}
+	return nil
+}
+
+func isStringField(field *data.Field) bool {
+	t := field.Type()
+	return t == data.FieldTypeString || t == data.FieldTypeNullableString
+}
+
+func stringAt(field *data.Field, idx int) string {
+	switch v := field.At(idx).(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+	return ""
+}
+
+func labelsAt(field *data.Field, idx int) map[string]string {
+	if field == nil {
+		return nil
+	}
+
+	var raw []byte
+	switch v := field.At(idx).(type) {
+	case json.RawMessage:
+		raw = v
+	case *json.RawMessage:
+		if v != nil {
+			raw = *v
+		}
+	case string:
+		raw = []byte(v)
+	case *string:
+		if v != nil {
+			raw = []byte(*v)
+		}
+	}
+
+	lbls := map[string]string{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &lbls)
+	}
+	return lbls
+}
@@ -0,0 +1,128 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTailFrame(lines []string, lbls []string) *data.Frame {
+	raw := make([]json.RawMessage, len(lbls))
+	for i, l := range lbls {
+		raw[i] = json.RawMessage(l)
+	}
+	ts := make([]time.Time, len(lines))
+	return data.NewFrame("",
+		data.NewField("labels", nil, raw),
+		data.NewField("Time", nil, ts),
+		data.NewField("Line", nil, lines),
+	)
+}
+
+func TestTailHub(t *testing.T) {
+	t.Run("identical expressions share one upstream connection", func(t *testing.T) {
+		var connections int32
+		upstream := make(chan *data.Frame)
+		hub := newTailHub(func(ctx context.Context, expr string, out chan<- *data.Frame) error {
+			atomic.AddInt32(&connections, 1)
+			for {
+				select {
+				case f := <-upstream:
+					out <- f
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		})
+
+		first, unsubscribeFirst := hub.subscribe(`{job="a"}`)
+		second, unsubscribeSecond := hub.subscribe(`{job="a"}`)
+
+		frame := makeTailFrame([]string{"hello"}, []string{`{}`})
+		upstream <- frame
+		require.Equal(t, frame, <-first)
+		require.Equal(t, frame, <-second)
+		require.Equal(t, int32(1), atomic.LoadInt32(&connections))
+
+		unsubscribeFirst()
+		unsubscribeSecond()
+
+		hub.mu.Lock()
+		require.Empty(t, hub.tails)
+		hub.mu.Unlock()
+	})
+
+	t.Run("subscribers are closed when the upstream ends", func(t *testing.T) {
+		hub := newTailHub(func(ctx context.Context, expr string, out chan<- *data.Frame) error {
+			return nil
+		})
+
+		frames, unsubscribe := hub.subscribe(`{job="a"}`)
+		defer unsubscribe()
+
+		select {
+		case _, ok := <-frames:
+			require.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("subscriber was not closed")
+		}
+	})
+}
+
+func TestTailFilter(t *testing.T) {
+	frame := makeTailFrame(
+		[]string{"level=error msg=boom", "level=info msg=ok", "level=error msg=timeout"},
+		[]string{`{"app":"api"}`, `{"app":"api"}`, `{"app":"web"}`},
+	)
+
+	t.Run("line filters", func(t *testing.T) {
+		f, err := newTailFilter(StreamOptions{LineFilters: []string{"error"}, LineRegex: "msg=b.*"})
+		require.NoError(t, err)
+		out := f.apply(frame)
+		require.NotNil(t, out)
+		require.Equal(t, 1, out.Rows())
+		require.Equal(t, "level=error msg=boom", out.Fields[2].At(0))
+	})
+
+	t.Run("label matchers", func(t *testing.T) {
+		f, err := newTailFilter(StreamOptions{LabelMatchers: `{app=~"w.*"}`})
+		require.NoError(t, err)
+		out := f.apply(frame)
+		require.NotNil(t, out)
+		require.Equal(t, 1, out.Rows())
+		require.Equal(t, "level=error msg=timeout", out.Fields[2].At(0))
+	})
+
+	t.Run("no matching rows", func(t *testing.T) {
+		f, err := newTailFilter(StreamOptions{LineFilters: []string{"debug"}})
+		require.NoError(t, err)
+		require.Nil(t, f.apply(frame))
+	})
+
+	t.Run("rate limit drops lines over the limit", func(t *testing.T) {
+		f, err := newTailFilter(StreamOptions{MaxLinesPerSecond: 1})
+		require.NoError(t, err)
+		out := f.apply(frame)
+		require.NotNil(t, out)
+		require.Equal(t, 2, out.Rows())
+		require.Len(t, out.Meta.Notices, 1)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := newTailFilter(StreamOptions{LineRegex: "("})
+		require.Error(t, err)
+		_, err = newTailFilter(StreamOptions{LabelMatchers: "{app="})
+		require.Error(t, err)
+	})
+
+	t.Run("parse stream options from the query model", func(t *testing.T) {
+		opts, err := parseStreamOptions([]byte(`{"expr":"{job=\"a\"}","stream":{"lineFilters":["x"],"maxLinesPerSecond":5}}`))
+		require.NoError(t, err)
+		require.Equal(t, StreamOptions{LineFilters: []string{"x"}, MaxLinesPerSecond: 5}, opts)
+	})
+}
@@ -0,0 +1,155 @@
+package kerberos
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+)
+
+// DefaultConfigFilePath is the krb5 configuration used when the datasource does not set one.
+const DefaultConfigFilePath = "/etc/krb5.conf"
+
+// LoginMethod defines which credentials are used to get a Kerberos ticket.
+type LoginMethod string
+
+const (
+	// LoginKeytab logs in the datasource user with the keys of a keytab file.
+	LoginKeytab LoginMethod = "keytab"
+	// LoginCredentialCache uses the tickets of an existing credential cache file, e.g. one kept up to date by kinit.
+	LoginCredentialCache LoginMethod = "credentialCache"
+	// LoginPassword logs in the datasource user with the password stored in secureJsonData.
+	LoginPassword LoginMethod = "password"
+)
+
+// KerberosAuth holds the Kerberos settings of a datasource.
+type KerberosAuth struct {
+	KeytabFilePath     string `json:"keytabFilePath"`
+	CredentialCache    string `json:"credentialCache"`
+	ConfigFilePath     string `json:"configFilePath"`
+	Realm              string `json:"kerberosRealm"`
+	UDPConnectionLimit int    `json:"UDPConnectionLimit"`
+	EnableDNSLookupKDC string `json:"enableDNSLookupKDC"`
+}
+
+// GetKerberosSettings reads the Kerberos settings from the datasource JSON data and applies the defaults.
+func GetKerberosSettings(settings backend.DataSourceInstanceSettings) (KerberosAuth, error) {
+	kerberosAuth := KerberosAuth{
+		ConfigFilePath:     DefaultConfigFilePath,
+		UDPConnectionLimit: 1,
+		EnableDNSLookupKDC: "true",
+	}
+	if err := json.Unmarshal(settings.JSONData, &kerberosAuth); err != nil {
+		return kerberosAuth, fmt.Errorf("error reading kerberos settings: %w", err)
+	}
+	if kerberosAuth.ConfigFilePath == "" {
+		kerberosAuth.ConfigFilePath = DefaultConfigFilePath
+	}
+	return kerberosAuth, nil
+}
+
+// Validate checks that the settings required by the login method are set and that the files they
+// reference can be parsed, so a misconfigured datasource reports what is wrong instead of failing
+// later with a login error.
+func (k KerberosAuth) Validate(method LoginMethod, user string, password string) error {
+	if err := fileExists("krb5 config file", k.ConfigFilePath); err != nil {
+		return err
+	}
+	switch method {
+	case LoginKeytab:
+		if user == "" || k.Realm == "" {
+			return fmt.Errorf("a user and a realm are required to log in with a keytab file")
+		}
+		if k.KeytabFilePath == "" {
+			return fmt.Errorf("a keytab file is required")
+		}
+		if err := fileExists("keytab file", k.KeytabFilePath); err != nil {
+			return err
+		}
+		return validateKeytab(k.KeytabFilePath, user, k.Realm)
+	case LoginCredentialCache:
+		if k.CredentialCache == "" {
+			return fmt.Errorf("a credential cache file is required")
+		}
+		if err := fileExists("credential cache file", k.CredentialCache); err != nil {
+			return err
+		}
+		return validateCredentialCache(k.CredentialCache)
+	case LoginPassword:
+		if user == "" || password == "" || k.Realm == "" {
+			return fmt.Errorf("a user, a password and a realm are required to log in with a password")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported kerberos login method %q", method)
+	}
+}
+
+// Krb5ParseAuthCredentials returns the connection string fragment configuring the krb5 authenticator
+// of the SQL Server driver for the login method.
+func Krb5ParseAuthCredentials(method LoginMethod, user string, password string, kerberosAuth KerberosAuth) string {
+	connStr := fmt.Sprintf("authenticator=krb5;krb5-configfile=%s;", kerberosAuth.ConfigFilePath)
+	switch method {
+	case LoginKeytab:
+		connStr += fmt.Sprintf("user id=%s;krb5-keytabfile=%s;", user, kerberosAuth.KeytabFilePath)
+	case LoginCredentialCache:
+		connStr += fmt.Sprintf("krb5-credcachefile=%s;", kerberosAuth.CredentialCache)
+	case LoginPassword:
+		connStr += fmt.Sprintf("user id=%s;password=%s;", user, password)
+	}
+	if kerberosAuth.Realm != "" {
+		connStr += fmt.Sprintf("krb5-realm=%s;", kerberosAuth.Realm)
+	}
+	if kerberosAuth.UDPConnectionLimit != 1 {
+		connStr += fmt.Sprintf("krb5-udppreferencelimit=%d;", kerberosAuth.UDPConnectionLimit)
+	}
+	if kerberosAuth.EnableDNSLookupKDC != "" && kerberosAuth.EnableDNSLookupKDC != "true" {
+		connStr += fmt.Sprintf("krb5-dnslookupkdc=%s;", kerberosAuth.EnableDNSLookupKDC)
+	}
+	return connStr
+}
+
+func fileExists(name string, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%s %q cannot be read: %w", name, path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s %q is a directory", name, path)
+	}
+	return nil
+}
+
+func validateKeytab(path string, user string, realm string) error {
+	kt, err := keytab.Load(path)
+	if err != nil {
+		return fmt.Errorf("keytab file %q cannot be parsed: %w", path, err)
+	}
+	for _, entry := range kt.Entries {
+		if entry.Principal.Realm == realm && strings.Join(entry.Principal.Components, "/") == user {
+			return nil
+		}
+	}
+	return fmt.Errorf("keytab file %q has no keys for %s@%s", path, user, realm)
+}
+
+func validateCredentialCache(path string) (err error) {
+	// The credential cache parser indexes into the file without checking its length.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("credential cache file %q cannot be parsed: %v", path, r)
+		}
+	}()
+	ccache, err := credentials.LoadCCache(path)
+	if err != nil {
+		return fmt.Errorf("credential cache file %q cannot be parsed: %w", path, err)
+	}
+	if len(ccache.GetClientPrincipalName().NameString) == 0 || ccache.GetClientRealm() == "" {
+		return fmt.Errorf("credential cache file %q has no default principal", path)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package kerberos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetKerberosSettings(t *testing.T) {
+	t.Run("applies the defaults", func(t *testing.T) {
+		settings, err := GetKerberosSettings(backend.DataSourceInstanceSettings{JSONData: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+		assert.Equal(t, KerberosAuth{
+			ConfigFilePath:     DefaultConfigFilePath,
+			UDPConnectionLimit: 1,
+			EnableDNSLookupKDC: "true",
+		}, settings)
+	})
+
+	t.Run("reads the settings", func(t *testing.T) {
+		settings, err := GetKerberosSettings(backend.DataSourceInstanceSettings{JSONData: json.RawMessage(`{
+			"keytabFilePath": "testdata/grafana.keytab",
+			"configFilePath": "testdata/krb5.conf",
+			"kerberosRealm": "EXAMPLE.COM",
+			"UDPConnectionLimit": 0,
+			"enableDNSLookupKDC": "false"
+		}`)})
+		require.NoError(t, err)
+		assert.Equal(t, KerberosAuth{
+			KeytabFilePath:     "testdata/grafana.keytab",
+			ConfigFilePath:     "testdata/krb5.conf",
+			Realm:              "EXAMPLE.COM",
+			UDPConnectionLimit: 0,
+			EnableDNSLookupKDC: "false",
+		}, settings)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	fixtures := KerberosAuth{
+		ConfigFilePath:  "testdata/krb5.conf",
+		KeytabFilePath:  "testdata/grafana.keytab",
+		CredentialCache: "testdata/krb5cc_grafana",
+		Realm:           "EXAMPLE.COM",
+	}
+
+	tests := []struct {
+		name     string
+		method   LoginMethod
+		settings func(k KerberosAuth) KerberosAuth
+		user     string
+		password string
+		err      string
+	}{
+		{name: "keytab", method: LoginKeytab, user: "grafana"},
+		{name: "credential cache", method: LoginCredentialCache},
+		{name: "password", method: LoginPassword, user: "grafana", password: "secret"},
+		{
+			name:     "missing config file",
+			method:   LoginCredentialCache,
+			settings: func(k KerberosAuth) KerberosAuth { k.ConfigFilePath = "testdata/missing.conf"; return k },
+			err:      `krb5 config file "testdata/missing.conf" cannot be read`,
+		},
+		{
+			name:     "missing keytab file",
+			method:   LoginKeytab,
+			user:     "grafana",
+			settings: func(k KerberosAuth) KerberosAuth { k.KeytabFilePath = "testdata/missing.keytab"; return k },
+			err:      `keytab file "testdata/missing.keytab" cannot be read`,
+		},
+		{name: "keytab without user", method: LoginKeytab, err: "a user and a realm are required"},
+		{name: "keytab without keys for the user", method: LoginKeytab, user: "admin", err: `keytab file "testdata/grafana.keytab" has no keys for admin@EXAMPLE.COM`},
+		{
+			name:     "keytab in another realm",
+			method:   LoginKeytab,
+			user:     "grafana",
+			settings: func(k KerberosAuth) KerberosAuth { k.Realm = "OTHER.COM"; return k },
+			err:      "has no keys for grafana@OTHER.COM",
+		},
+		{
+			name:     "invalid keytab file",
+			method:   LoginKeytab,
+			user:     "grafana",
+			settings: func(k KerberosAuth) KerberosAuth { k.KeytabFilePath = "testdata/krb5.conf"; return k },
+			err:      `keytab file "testdata/krb5.conf" cannot be parsed`,
+		},
+		{
+			name:     "invalid credential cache file",
+			method:   LoginCredentialCache,
+			settings: func(k KerberosAuth) KerberosAuth { k.CredentialCache = "testdata/krb5.conf"; return k },
+			err:      `credential cache file "testdata/krb5.conf" cannot be parsed`,
+		},
+		{
+			name:     "truncated credential cache file",
+			method:   LoginCredentialCache,
+			settings: func(k KerberosAuth) KerberosAuth { k.CredentialCache = "testdata/krb5cc_truncated"; return k },
+			err:      `credential cache file "testdata/krb5cc_truncated" has no default principal`,
+		},
+		{
+			name:     "credential cache is a directory",
+			method:   LoginCredentialCache,
+			settings: func(k KerberosAuth) KerberosAuth { k.CredentialCache = "testdata"; return k },
+			err:      `credential cache file "testdata" is a directory`,
+		},
+		{name: "password without password", method: LoginPassword, user: "grafana", err: "a user, a password and a realm are required"},
+		{name: "unknown method", method: LoginMethod("ntlm"), err: `unsupported kerberos login method "ntlm"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := fixtures
+			if tt.settings != nil {
+				settings = tt.settings(settings)
+			}
+			err := settings.Validate(tt.method, tt.user, tt.password)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestKrb5ParseAuthCredentials(t *testing.T) {
+	settings := KerberosAuth{
+		ConfigFilePath:     "testdata/krb5.conf",
+		KeytabFilePath:     "testdata/grafana.keytab",
+		CredentialCache:    "testdata/krb5cc_grafana",
+		Realm:              "EXAMPLE.COM",
+		UDPConnectionLimit: 1,
+		EnableDNSLookupKDC: "true",
+	}
+
+	assert.Equal(t,
+		"authenticator=krb5;krb5-configfile=testdata/krb5.conf;user id=grafana;krb5-keytabfile=testdata/grafana.keytab;krb5-realm=EXAMPLE.COM;",
+		Krb5ParseAuthCredentials(LoginKeytab, "grafana", "", settings))
+	assert.Equal(t,
+		"authenticator=krb5;krb5-configfile=testdata/krb5.conf;krb5-credcachefile=testdata/krb5cc_grafana;krb5-realm=EXAMPLE.COM;",
+		Krb5ParseAuthCredentials(LoginCredentialCache, "grafana", "", settings))
+	assert.Equal(t,
+		"authenticator=krb5;krb5-configfile=testdata/krb5.conf;user id=grafana;password=secret;krb5-realm=EXAMPLE.COM;",
+		Krb5ParseAuthCredentials(LoginPassword, "grafana", "secret", settings))
+}
@@ -21,9 +21,11 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 	mssql "github.com/microsoft/go-mssqldb"
 	_ "github.com/microsoft/go-mssqldb/azuread"
+	_ "github.com/microsoft/go-mssqldb/integratedauth/krb5"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/mssql/kerberos"
 	"github.com/grafana/grafana/pkg/tsdb/mssql/utils"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng/proxyutil"
@@ -39,8 +41,18 @@ const (
 	azureAuthentication     = "Azure AD Authentication"
 	windowsAuthentication   = "Windows Authentication"
 	sqlServerAuthentication = "SQL Server Authentication"
+	kerberosRaw             = "Windows AD: Username + password"
+	kerberosKeytab          = "Windows AD: Keytab"
+	kerberosCredentialCache = "Windows AD: Credential cache"
 )
 
+// kerberosLoginMethods maps the Windows AD authentication types onto the Kerberos login they use.
+var kerberosLoginMethods = map[string]kerberos.LoginMethod{
+	kerberosRaw:             kerberos.LoginPassword,
+	kerberosKeytab:          kerberos.LoginKeytab,
+	kerberosCredentialCache: kerberos.LoginCredentialCache,
+}
+
 func ProvideService(cfg *setting.Cfg) *Service {
 	logger := backend.NewLoggerWith("logger", "tsdb.mssql")
 	return &Service{
@@ -84,6 +96,10 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 		if err != nil {
 			return nil, fmt.Errorf("error reading settings: %w", err)
 		}
+		kerberosAuth, err := kerberos.GetKerberosSettings(settings)
+		if err != nil {
+			return nil, err
+		}
 
 		database := jsonData.Database
 		if database == "" {
@@ -100,7 +116,13 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			UID:                     settings.UID,
 			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
 		}
-		cnnstr, err := generateConnectionString(dsInfo, cfg, azureCredentials, logger)
+		if method, ok := kerberosLoginMethods[jsonData.AuthenticationType]; ok {
+			if err := kerberosAuth.Validate(method, dsInfo.User, dsInfo.DecryptedSecureJSONData["password"]); err != nil {
+				return nil, fmt.Errorf("invalid kerberos settings: %w", err)
+			}
+		}
+
+		cnnstr, err := generateConnectionString(dsInfo, cfg, azureCredentials, kerberosAuth, logger)
 		if err != nil {
 			return nil, err
 		}
@@ -178,7 +200,7 @@ func ParseURL(u string, logger DebugOnlyLogger) (*url.URL, error) {
 	}, nil
 }
 
-func generateConnectionString(dsInfo sqleng.DataSourceInfo, cfg *setting.Cfg, azureCredentials azcredentials.AzureCredentials, logger log.Logger) (string, error) {
+func generateConnectionString(dsInfo sqleng.DataSourceInfo, cfg *setting.Cfg, azureCredentials azcredentials.AzureCredentials, kerberosAuth kerberos.KerberosAuth, logger log.Logger) (string, error) {
 	const dfltPort = "0"
 	var addr util.NetworkAddress
 	if dsInfo.URL != "" {
@@ -223,6 +245,8 @@ func generateConnectionString(dsInfo sqleng.DataSourceInfo, cfg *setting.Cfg, az
 		connStr += azureCredentialDSNFragment
 	case windowsAuthentication:
 		// No user id or password. We're using windows single sign on.
+	case kerberosRaw, kerberosKeytab, kerberosCredentialCache:
+		connStr += kerberos.Krb5ParseAuthCredentials(kerberosLoginMethods[dsInfo.JsonData.AuthenticationType], dsInfo.User, dsInfo.DecryptedSecureJSONData["password"], kerberosAuth)
 	default:
 		connStr += fmt.Sprintf("user id=%s;password=%s;", dsInfo.User, dsInfo.DecryptedSecureJSONData["password"])
 	}
@@ -273,6 +297,8 @@ func getAzureCredentialDSNFragment(azureCredentials azcredentials.AzureCredentia
 	return connStr, nil
 }
 
+var _ sqleng.SQLPaginator = (*mssqlQueryResultTransformer)(nil)
+
 type mssqlQueryResultTransformer struct {
 	userError string
 }
@@ -288,6 +314,53 @@ func (t *mssqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	return err
 }
 
+var (
+	orderByRegex     = regexp.MustCompile(`(?i)\border\s+by\b`)
+	offsetFetchRegex = regexp.MustCompile(`(?i)\b(offset|fetch)\b`)
+)
+
+// Paginate adds OFFSET and FETCH to the ORDER BY clause of the query. SQL Server only pages
+// ordered results, and does not allow ORDER BY in subqueries, so the query must end with
+// its ORDER BY clause.
+func (t *mssqlQueryResultTransformer) Paginate(sql string, offset, limit int64) (string, error) {
+	sql, err := sqleng.TrimQuery(sql, sqleng.StandardComments)
+	if err != nil {
+		return "", err
+	}
+	if !hasTrailingOrderBy(sql) {
+		return "", fmt.Errorf("chunked queries need an ORDER BY clause at the end of the query to read the rows in a stable order")
+	}
+	paged := fmt.Sprintf("%s OFFSET %d ROWS", sql, offset)
+	if limit >= 0 {
+		paged += fmt.Sprintf(" FETCH NEXT %d ROWS ONLY", limit)
+	}
+	return paged, nil
+}
+
+// hasTrailingOrderBy returns true if the last ORDER BY of the query orders the outer query
+// and the query does not page its rows already.
+func hasTrailingOrderBy(sql string) bool {
+	matches := orderByRegex.FindAllStringIndex(sql, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	rest := sql[matches[len(matches)-1][0]:]
+	depth := 0
+	for _, r := range rest {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				// the ORDER BY belongs to a subquery
+				return false
+			}
+		}
+	}
+	return !offsetFetchRegex.MatchString(rest)
+}
+
 // CheckHealth pings the connected SQL database
 func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
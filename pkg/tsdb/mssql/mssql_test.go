@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/mssql/kerberos"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 )
 
@@ -1334,9 +1335,10 @@ func TestTransformQueryError(t *testing.T) {
 
 func TestGenerateConnectionString(t *testing.T) {
 	testCases := []struct {
-		desc       string
-		dataSource sqleng.DataSourceInfo
-		expConnStr string
+		desc         string
+		dataSource   sqleng.DataSourceInfo
+		kerberosAuth kerberos.KerberosAuth
+		expConnStr   string
 	}{
 		{
 			desc: "From URL w/ port",
@@ -1459,13 +1461,61 @@ func TestGenerateConnectionString(t *testing.T) {
 			},
 			expConnStr: "server=localhost;database=database;user id=user;password=;",
 		},
+		{
+			desc: "With Windows AD keytab authentication",
+			dataSource: sqleng.DataSourceInfo{
+				URL:      "localhost:1433",
+				Database: "database",
+				User:     "grafana",
+				JsonData: sqleng.JsonData{AuthenticationType: kerberosKeytab},
+			},
+			kerberosAuth: kerberos.KerberosAuth{
+				ConfigFilePath:     "/etc/krb5.conf",
+				KeytabFilePath:     "/etc/grafana.keytab",
+				Realm:              "EXAMPLE.COM",
+				UDPConnectionLimit: 1,
+				EnableDNSLookupKDC: "true",
+			},
+			expConnStr: "server=localhost;database=database;authenticator=krb5;krb5-configfile=/etc/krb5.conf;user id=grafana;krb5-keytabfile=/etc/grafana.keytab;krb5-realm=EXAMPLE.COM;port=1433;",
+		},
+		{
+			desc: "With Windows AD credential cache authentication",
+			dataSource: sqleng.DataSourceInfo{
+				URL:      "localhost",
+				Database: "database",
+				JsonData: sqleng.JsonData{AuthenticationType: kerberosCredentialCache},
+			},
+			kerberosAuth: kerberos.KerberosAuth{
+				ConfigFilePath:     "/etc/krb5.conf",
+				CredentialCache:    "/tmp/krb5cc_1000",
+				UDPConnectionLimit: 0,
+				EnableDNSLookupKDC: "false",
+			},
+			expConnStr: "server=localhost;database=database;authenticator=krb5;krb5-configfile=/etc/krb5.conf;krb5-credcachefile=/tmp/krb5cc_1000;krb5-udppreferencelimit=0;krb5-dnslookupkdc=false;",
+		},
+		{
+			desc: "With Windows AD username and password authentication",
+			dataSource: sqleng.DataSourceInfo{
+				URL:                     "localhost",
+				Database:                "database",
+				User:                    "grafana",
+				JsonData:                sqleng.JsonData{AuthenticationType: kerberosRaw},
+				DecryptedSecureJSONData: map[string]string{"password": "secret"},
+			},
+			kerberosAuth: kerberos.KerberosAuth{
+				ConfigFilePath:     "/etc/krb5.conf",
+				Realm:              "EXAMPLE.COM",
+				UDPConnectionLimit: 1,
+			},
+			expConnStr: "server=localhost;database=database;authenticator=krb5;krb5-configfile=/etc/krb5.conf;user id=grafana;password=secret;krb5-realm=EXAMPLE.COM;",
+		},
 	}
 
 	logger := backend.NewLoggerWith("logger", "mssql.test")
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			connStr, err := generateConnectionString(tc.dataSource, nil, nil, logger)
+			connStr, err := generateConnectionString(tc.dataSource, nil, nil, tc.kerberosAuth, logger)
 			require.NoError(t, err)
 			assert.Equal(t, tc.expConnStr, connStr)
 		})
@@ -1499,3 +1549,31 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+func TestMSSQLPaginate(t *testing.T) {
+	transformer := &mssqlQueryResultTransformer{}
+
+	sql, err := transformer.Paginate("SELECT * FROM t ORDER BY DATEADD(s, 1, time), id;", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM t ORDER BY DATEADD(s, 1, time), id OFFSET 20 ROWS FETCH NEXT 11 ROWS ONLY", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t ORDER BY id", 20, -1)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM t ORDER BY id OFFSET 20 ROWS", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t ORDER BY id -- oldest first", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM t ORDER BY id OFFSET 20 ROWS FETCH NEXT 11 ROWS ONLY", sql)
+
+	_, err = transformer.Paginate("DECLARE @a INT = 1; SELECT * FROM t ORDER BY id", 20, 11)
+	require.ErrorIs(t, err, sqleng.ErrQueryNotPaginable)
+
+	for _, query := range []string{
+		"SELECT * FROM t",
+		"SELECT * FROM (SELECT TOP 10 * FROM t ORDER BY id) AS s",
+		"SELECT * FROM t ORDER BY id OFFSET 5 ROWS",
+	} {
+		_, err := transformer.Paginate(query, 20, 11)
+		require.Error(t, err, query)
+	}
+}
@@ -198,6 +198,8 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsHandler.QueryData(ctx, req)
 }
 
+var _ sqleng.SQLPaginator = (*mysqlQueryResultTransformer)(nil)
+
 type mysqlQueryResultTransformer struct {
 	userError string
 }
@@ -215,6 +217,20 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	return err
 }
 
+// Paginate reads the rows of the query as a derived table. MySQL needs a limit to skip rows,
+// so the largest limit it supports stands for no limit.
+func (t *mysqlQueryResultTransformer) Paginate(sql string, offset, limit int64) (string, error) {
+	sql, err := sqleng.TrimQuery(sql, sqleng.MySQLComments)
+	if err != nil {
+		return "", err
+	}
+	limitClause := "18446744073709551615"
+	if limit >= 0 {
+		limitClause = strconv.FormatInt(limit, 10)
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS grafana_chunk LIMIT %s OFFSET %d", sql, limitClause, offset), nil
+}
+
 func (t *mysqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	// For the MySQL driver , we have these possible data types:
 	// https://www.w3schools.com/sql/sql_datatypes.asp#:~:text=In%20MySQL%20there%20are%20three,numeric%2C%20and%20date%20and%20time.
@@ -1327,3 +1327,22 @@ func mySQLTestDBConnStr() string {
 	}
 	return fmt.Sprintf("grafana:password@tcp(%s:%s)/grafana_ds_tests?collation=utf8mb4_unicode_ci&sql_mode='ANSI_QUOTES'&parseTime=true&loc=UTC", host, port)
 }
+
+func TestMySQLPaginate(t *testing.T) {
+	transformer := &mysqlQueryResultTransformer{}
+
+	sql, err := transformer.Paginate("SELECT * FROM t ORDER BY id;\n", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t ORDER BY id) AS grafana_chunk LIMIT 11 OFFSET 20", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t", 20, -1)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t) AS grafana_chunk LIMIT 18446744073709551615 OFFSET 20", sql)
+
+	sql, err = transformer.Paginate("SELECT * FROM t # all rows", 20, 11)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM (SELECT * FROM t) AS grafana_chunk LIMIT 11 OFFSET 20", sql)
+
+	_, err = transformer.Paginate("SET @a = 1; SELECT @a", 20, 11)
+	require.ErrorIs(t, err, sqleng.ErrQueryNotPaginable)
+}
@@ -0,0 +1,212 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// tsdbVersion23 is the TSDBVersion of OpenTSDB 2.3
+const tsdbVersion23 = 3
+
+var errExpressionsNotSupported = errors.New("expression queries require OpenTSDB 2.3 or later")
+
+// expressionQueryModel is the query model of an expression query, which is sent to the
+// /api/query/exp endpoint. The time range, aggregator and downsampling options are shared
+// with metric queries.
+type expressionQueryModel struct {
+	Aggregator           string          `json:"aggregator"`
+	DisableDownsampling  bool            `json:"disableDownsampling"`
+	DownsampleInterval   string          `json:"downsampleInterval"`
+	DownsampleAggregator string          `json:"downsampleAggregator"`
+	DownsampleFillPolicy string          `json:"downsampleFillPolicy"`
+	ShouldComputeRate    bool            `json:"shouldComputeRate"`
+	FilterSets           []ExpFilterSet  `json:"filterSets"`
+	Metrics              []ExpMetric     `json:"metrics"`
+	Expressions          []ExpExpression `json:"expressions"`
+	Outputs              []ExpOutput     `json:"outputs"`
+}
+
+func buildExpressionQuery(query backend.DataQuery) (OpenTsdbExpQuery, error) {
+	var model expressionQueryModel
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return OpenTsdbExpQuery{}, fmt.Errorf("failed to parse expression query: %w", err)
+	}
+
+	if len(model.Metrics) == 0 {
+		return OpenTsdbExpQuery{}, fmt.Errorf("expression query needs at least one metric")
+	}
+
+	aggregator := model.Aggregator
+	if aggregator == "" {
+		aggregator = "sum"
+	}
+
+	expQuery := OpenTsdbExpQuery{
+		Time: ExpTime{
+			Start:      query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+			End:        query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+			Aggregator: aggregator,
+			Rate:       model.ShouldComputeRate,
+		},
+		Filters:     model.FilterSets,
+		Metrics:     model.Metrics,
+		Expressions: model.Expressions,
+		Outputs:     model.Outputs,
+	}
+
+	if !model.DisableDownsampling {
+		downsampleAggregator := model.DownsampleAggregator
+		if downsampleAggregator == "" {
+			downsampleAggregator = aggregator
+		}
+		expQuery.Time.Downsampler = &ExpDownsampler{
+			Interval:   formatDownsampleInterval(model.DownsampleInterval),
+			Aggregator: downsampleAggregator,
+		}
+		if model.DownsampleFillPolicy != "" && model.DownsampleFillPolicy != "none" {
+			expQuery.Time.Downsampler.FillPolicy = &ExpFillPolicy{Policy: model.DownsampleFillPolicy}
+		}
+	}
+
+	return expQuery, nil
+}
+
+func (s *Service) executeExpressionQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery) backend.DataResponse {
+	// The expression endpoint was added in OpenTSDB 2.3, an unset version is not checked
+	if dsInfo.TSDBVersion > 0 && dsInfo.TSDBVersion < tsdbVersion23 {
+		return backend.DataResponse{Error: errExpressionsNotSupported}
+	}
+
+	expQuery, err := buildExpressionQuery(query)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	u.Path = path.Join(u.Path, "api/query/exp")
+
+	postData, err := json.Marshal(expQuery)
+	if err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(string(postData)))
+	if err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	frames, err := parseExpressionResponse(logger, res)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	return backend.DataResponse{Frames: frames}
+}
+
+// parseExpressionResponse returns one frame per series of every output. The first column
+// of the data points is the timestamp in milliseconds, the other columns are described by
+// the output meta.
+func parseExpressionResponse(logger log.Logger, res *http.Response) (data.Frames, error) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	var response OpenTsdbExpResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		logger.Info("Failed to unmarshal opentsdb expression response", "error", err, "status", res.Status)
+		return nil, err
+	}
+
+	frames := data.Frames{}
+	for _, output := range response.Outputs {
+		for _, meta := range output.Meta {
+			// Index 0 describes the timestamp column
+			if meta.Index == 0 {
+				continue
+			}
+
+			timeVector := make([]time.Time, 0, len(output.Dps))
+			values := make([]*float64, 0, len(output.Dps))
+			for _, row := range output.Dps {
+				if len(row) <= meta.Index {
+					continue
+				}
+				ts, ok := expValue(row[0])
+				if !ok {
+					continue
+				}
+				timeVector = append(timeVector, time.UnixMilli(int64(ts)).UTC())
+				if v, ok := expValue(row[meta.Index]); ok {
+					values = append(values, &v)
+				} else {
+					values = append(values, nil)
+				}
+			}
+
+			name := output.Alias
+			if name == "" {
+				name = output.ID
+			}
+			valueField := data.NewField("value", meta.CommonTags, values)
+			if len(meta.Metrics) > 0 {
+				valueField.Config = &data.FieldConfig{DisplayNameFromDS: name + " " + strings.Join(meta.Metrics, ",")}
+			}
+			frames = append(frames, data.NewFrame(name,
+				data.NewField("time", nil, timeVector),
+				valueField))
+		}
+	}
+
+	return frames, nil
+}
+
+// expValue reads a data point value, which can be a number or a string such as "NaN".
+func expValue(v any) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case string:
+		switch strings.ToLower(value) {
+		case "nan":
+			return math.NaN(), true
+		case "infinity", "+infinity":
+			return math.Inf(1), true
+		case "-infinity":
+			return math.Inf(-1), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// CheckHealth requests the version of the OpenTSDB server to check that it can be reached.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Failed to get datasource information",
+		}, err
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return healthError(fmt.Sprintf("Invalid URL: %s", err)), nil
+	}
+	u.Path = path.Join(u.Path, "api/version")
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return healthError(fmt.Sprintf("Failed to create request: %s", err)), nil
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		logger.Info("OpenTSDB health check failed", "error", err)
+		return healthError(fmt.Sprintf("OpenTSDB error: %s", err)), nil
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return healthError(fmt.Sprintf("OpenTSDB error: %s", res.Status)), nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
+
+func healthError(message string) *backend.CheckHealthResult {
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusError,
+		Message: message,
+	}
+}
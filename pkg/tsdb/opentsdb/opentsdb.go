@@ -3,11 +3,14 @@ package opentsdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +28,14 @@ import (
 
 var logger = log.New("tsdb.opentsdb")
 
+const (
+	queryTypeExpression = "expression"
+
+	tsdbResolutionMilliseconds = 2
+)
+
+var errMissingMetric = errors.New("query is missing a metric")
+
 type Service struct {
 	im instancemgmt.InstanceManager
 }
@@ -35,9 +46,27 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 	}
 }
 
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+)
+
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+
+	// TSDBVersion is 1 for OpenTSDB <=2.1, 2 for ==2.2 and 3 for ==2.3
+	TSDBVersion int
+	// TSDBResolution is 1 for second and 2 for millisecond resolution
+	TSDBResolution int
+	LookupLimit    int
+}
+
+type JSONData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
+	LookupLimit    int `json:"lookupLimit"`
 }
 
 type DsAccess string
@@ -54,9 +83,19 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := JSONData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jsonData.TSDBVersion,
+			TSDBResolution: jsonData.TSDBResolution,
+			LookupLimit:    jsonData.LookupLimit,
 		}
 
 		return model, nil
@@ -64,20 +103,45 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
-
-	myRefID := q.RefID
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	result := backend.NewQueryDataResponse()
 
+	// Every query is sent separately so results can always be matched to their ref ID
 	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		model, err := simplejson.NewJson(query.JSON)
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: fmt.Errorf("failed to parse query: %w", err)}
+			continue
+		}
+
+		var resp backend.DataResponse
+		if query.QueryType == queryTypeExpression {
+			resp = s.executeExpressionQuery(ctx, logger, dsInfo, query)
+		} else {
+			if model.Get("metric").MustString() == "" {
+				result.Responses[query.RefID] = backend.DataResponse{Error: errMissingMetric}
+				continue
+			}
+			resp = s.executeMetricQuery(ctx, logger, dsInfo, query, model)
+		}
+		result.Responses[query.RefID] = resp
+	}
+
+	return result, nil
+}
+
+func (s *Service) executeMetricQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery, model *simplejson.Json) backend.DataResponse {
+	tsdbQuery := OpenTsdbQuery{
+		Start:        query.TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:          query.TimeRange.To.UnixNano() / int64(time.Millisecond),
+		Queries:      []map[string]any{s.buildMetric(query)},
+		MsResolution: dsInfo.TSDBResolution == tsdbResolutionMilliseconds,
 	}
 
 	// TODO: Don't use global variable
@@ -85,19 +149,14 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: err}
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: err}
 	}
 
 	defer func() {
@@ -107,12 +166,15 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	result, err := s.parseResponse(logger, res, myRefID)
+	result, err := s.parseResponse(logger, res, query.RefID, responseOptions{
+		alias:        model.Get("alias").MustString(),
+		msResolution: tsdbQuery.MsResolution,
+	})
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return backend.DataResponse{Error: err}
 	}
 
-	return result, nil
+	return result.Responses[query.RefID]
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -138,7 +200,15 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
+// responseOptions controls how an /api/query response is framed.
+type responseOptions struct {
+	// alias is the display name of the series, where $tag_<key> is replaced by the tag value
+	alias string
+	// msResolution is set when the timestamps in the response are in milliseconds
+	msResolution bool
+}
+
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string, opts responseOptions) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -165,8 +235,11 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
+		type dataPoint struct {
+			timestamp int64
+			value     float64
+		}
+		points := make([]dataPoint, 0, len(val.DataPoints))
 		name := val.Metric
 		tags := val.Tags
 
@@ -176,12 +249,28 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			points = append(points, dataPoint{timestamp: timestamp, value: value})
+		}
+		// OpenTSDB returns data points as an object, so they have to be sorted by time
+		sort.Slice(points, func(i, j int) bool { return points[i].timestamp < points[j].timestamp })
+
+		timeVector := make([]time.Time, 0, len(points))
+		values := make([]float64, 0, len(points))
+		for _, point := range points {
+			if opts.msResolution {
+				timeVector = append(timeVector, time.UnixMilli(point.timestamp).UTC())
+			} else {
+				timeVector = append(timeVector, time.Unix(point.timestamp, 0).UTC())
+			}
+			values = append(values, point.value)
 		}
-		frames = append(frames, data.NewFrame(name,
+		frame := data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+			data.NewField("value", tags, values))
+		if opts.alias != "" {
+			frame.Fields[1].Config = &data.FieldConfig{DisplayNameFromDS: formatAlias(opts.alias, tags)}
+		}
+		frames = append(frames, frame)
 	}
 	result := resp.Responses[myRefID]
 	result.Frames = frames
@@ -189,6 +278,23 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 	return resp, nil
 }
 
+var aliasTagPattern = regexp.MustCompile(`\$tag_(\w+)|\[\[tag_(\w+)\]\]`)
+
+// formatAlias replaces $tag_<key> and [[tag_<key>]] in the alias with the tag values of the series.
+func formatAlias(alias string, tags map[string]string) string {
+	return aliasTagPattern.ReplaceAllStringFunc(alias, func(in string) string {
+		match := aliasTagPattern.FindStringSubmatch(in)
+		key := match[1]
+		if key == "" {
+			key = match[2]
+		}
+		if value, ok := tags[key]; ok {
+			return value
+		}
+		return in
+	})
+}
+
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	metric := make(map[string]any)
 
@@ -204,13 +310,10 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
 	if !disableDownsampling {
-		downsampleInterval := model.Get("downsampleInterval").MustString()
-		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
-		}
+		downsampleInterval := formatDownsampleInterval(model.Get("downsampleInterval").MustString())
 		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy := model.Get("downsampleFillPolicy").MustString(); fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -222,17 +325,17 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		rateOptions := make(map[string]any)
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := getNumber(model, "counterMax")
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := getNumber(model, "counterResetValue")
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		if !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
@@ -251,9 +354,49 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		metric["filters"] = filters.MustArray()
 	}
 
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
+	}
+
 	return metric
 }
 
+// formatDownsampleInterval returns the downsample interval of the query. Fractional seconds
+// are converted to milliseconds as OpenTSDB only supports integer intervals.
+func formatDownsampleInterval(interval string) string {
+	if interval == "" {
+		return "1m" // default value for blank
+	}
+
+	if strings.HasSuffix(interval, "s") && !strings.HasSuffix(interval, "ms") && strings.Contains(interval, ".") {
+		if seconds, err := strconv.ParseFloat(strings.TrimSuffix(interval, "s"), 64); err == nil {
+			return strconv.FormatInt(int64(seconds*1000), 10) + "ms"
+		}
+	}
+
+	return interval
+}
+
+// getNumber reads a number from the query model, which the query editor may store as a string.
+func getNumber(model *simplejson.Json, key string) (float64, bool) {
+	value, ok := model.CheckGet(key)
+	if !ok {
+		return 0, false
+	}
+	if f, err := value.Float64(); err == nil {
+		return f, true
+	}
+	str, err := value.String()
+	if err != nil || str == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
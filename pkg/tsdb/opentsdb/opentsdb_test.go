@@ -2,14 +2,18 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"math"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,7 +37,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A")
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, "A", responseOptions{})
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -63,7 +67,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, "A")
+		result, err := service.parseResponse(logger, &resp, "A", responseOptions{})
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -100,7 +104,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp, myRefid)
+		result, err := service.parseResponse(logger, &resp, myRefid, responseOptions{})
 		require.NoError(t, err)
 
 		if diff := cmp.Diff(testFrame, result.Responses[myRefid].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
@@ -276,4 +280,286 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+	t.Run("Build metric with counter values as strings", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"shouldComputeRate": true,
+						"isCounter": true,
+						"counterMax": "45",
+						"counterResetValue": "60"
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query)
+
+		metricRateOptions := metric["rateOptions"].(map[string]any)
+		require.Equal(t, float64(45), metricRateOptions["counterMax"])
+		require.Equal(t, float64(60), metricRateOptions["resetValue"])
+	})
+
+	t.Run("Build metric with explicit tags and fill policy", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"downsampleInterval": "5m",
+						"downsampleAggregator": "sum",
+						"downsampleFillPolicy": "zero",
+						"explicitTags": true
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query)
+
+		require.Equal(t, "5m-sum-zero", metric["downsample"])
+		require.True(t, metric["explicitTags"].(bool))
+	})
+
+	t.Run("Build metric uses one minute as default downsample interval", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval: 30 * time.Second,
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"downsampleAggregator": "avg",
+						"downsampleFillPolicy": "none"
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query)
+
+		require.Equal(t, "1m-avg", metric["downsample"])
+	})
+
+	t.Run("Parse response sorts data points and applies alias", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "test",
+				"dps": {
+					"1405544146000": 50.0,
+					"1405544145000": 49.0
+				},
+				"tags" : {
+					"env": "prod",
+					"app": "grafana"
+				}
+			}
+		]`
+
+		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
+		resp.StatusCode = 200
+		result, err := service.parseResponse(logger, &resp, "A", responseOptions{alias: "$tag_app in [[tag_env]]", msResolution: true})
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, time.UnixMilli(1405544145000).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, time.UnixMilli(1405544146000).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, 49.0, frame.Fields[1].At(0))
+		require.Equal(t, "grafana in prod", frame.Fields[1].Config.DisplayNameFromDS)
+	})
+}
+
+func TestFormatDownsampleInterval(t *testing.T) {
+	require.Equal(t, "10m", formatDownsampleInterval("10m"))
+	require.Equal(t, "1m", formatDownsampleInterval(""))
+	require.Equal(t, "500ms", formatDownsampleInterval("0.5s"))
+}
+
+func TestFormatAlias(t *testing.T) {
+	tags := map[string]string{"host": "a", "env": "prod"}
+	require.Equal(t, "a-prod", formatAlias("$tag_host-[[tag_env]]", tags))
+	require.Equal(t, "$tag_missing", formatAlias("$tag_missing", tags))
+}
+
+func TestExpressionQuery(t *testing.T) {
+	t.Run("build expression query", func(t *testing.T) {
+		query := backend.DataQuery{
+			TimeRange: backend.TimeRange{
+				From: time.UnixMilli(1000),
+				To:   time.UnixMilli(2000),
+			},
+			JSON: []byte(`
+				{
+					"queryType": "expression",
+					"aggregator": "avg",
+					"downsampleInterval": "1m",
+					"downsampleFillPolicy": "nan",
+					"filterSets": [{"id": "f1", "tags": [{"type": "wildcard", "tagk": "host", "filter": "*", "groupBy": true}]}],
+					"metrics": [{"id": "a", "metric": "sys.cpu.user", "filter": "f1"}],
+					"expressions": [{"id": "e", "expr": "a * 2"}],
+					"outputs": [{"id": "e", "alias": "double"}]
+				}`,
+			),
+		}
+
+		expQuery, err := buildExpressionQuery(query)
+		require.NoError(t, err)
+
+		require.Equal(t, int64(1000), expQuery.Time.Start)
+		require.Equal(t, int64(2000), expQuery.Time.End)
+		require.Equal(t, "avg", expQuery.Time.Aggregator)
+		require.Equal(t, &ExpDownsampler{Interval: "1m", Aggregator: "avg", FillPolicy: &ExpFillPolicy{Policy: "nan"}}, expQuery.Time.Downsampler)
+		require.Len(t, expQuery.Filters, 1)
+		require.Equal(t, "host", expQuery.Filters[0].Tags[0].Tagk)
+		require.Equal(t, []ExpMetric{{ID: "a", Metric: "sys.cpu.user", Filter: "f1"}}, expQuery.Metrics)
+		require.Equal(t, []ExpExpression{{ID: "e", Expr: "a * 2"}}, expQuery.Expressions)
+	})
+
+	t.Run("build expression query without metrics", func(t *testing.T) {
+		_, err := buildExpressionQuery(backend.DataQuery{JSON: []byte(`{"queryType": "expression"}`)})
+		require.Error(t, err)
+	})
+
+	t.Run("expression queries are rejected before OpenTSDB 2.3", func(t *testing.T) {
+		s := &Service{}
+		dsInfo := &datasourceInfo{HTTPClient: http.DefaultClient, TSDBVersion: 2}
+		resp := s.executeExpressionQuery(context.Background(), logger, dsInfo, backend.DataQuery{
+			JSON: []byte(`{"queryType": "expression", "metrics": [{"id": "a", "metric": "cpu"}]}`),
+		})
+		require.ErrorIs(t, resp.Error, errExpressionsNotSupported)
+	})
+
+	t.Run("parse expression response", func(t *testing.T) {
+		response := `
+		{
+			"outputs": [
+				{
+					"id": "e",
+					"alias": "double",
+					"dps": [[1000, 1.5, "NaN"], [2000, 2.5, 3]],
+					"meta": [
+						{"index": 0, "metrics": ["timestamp"]},
+						{"index": 1, "metrics": ["sys.cpu.user"], "commonTags": {"host": "a"}},
+						{"index": 2, "metrics": ["sys.cpu.user"], "commonTags": {"host": "b"}}
+					]
+				}
+			]
+		}`
+
+		resp := http.Response{Body: io.NopCloser(strings.NewReader(response)), StatusCode: 200}
+		frames, err := parseExpressionResponse(logger, &resp)
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		require.Equal(t, "double", frames[0].Name)
+		require.Equal(t, data.Labels{"host": "a"}, frames[0].Fields[1].Labels)
+		require.Equal(t, time.UnixMilli(1000).UTC(), frames[0].Fields[0].At(0))
+		require.Equal(t, 1.5, *frames[0].Fields[1].At(0).(*float64))
+
+		require.Equal(t, data.Labels{"host": "b"}, frames[1].Fields[1].Labels)
+		require.True(t, math.IsNaN(*frames[1].Fields[1].At(0).(*float64)))
+		require.Equal(t, 3.0, *frames[1].Fields[1].At(1).(*float64))
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	t.Run("queries without a metric return an error", func(t *testing.T) {
+		service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: http.DefaultClient}}}
+		res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: []byte(`{"aggregator": "avg"}`)}},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, res.Responses["A"].Error, errMissingMetric)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("data source is working", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/version", r.URL.Path)
+			_, _ = w.Write([]byte(`{"version": "2.4.0"}`))
+		}))
+		defer srv.Close()
+
+		service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}}}
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("data source returns an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL}}}
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
+
+func TestCallResource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "query": r.URL.RawQuery})
+	}))
+	defer srv.Close()
+
+	service := &Service{im: fakeInstanceManager{dsInfo: &datasourceInfo{HTTPClient: srv.Client(), URL: srv.URL, LookupLimit: 100}}}
+
+	t.Run("proxies suggest requests with the lookup limit", func(t *testing.T) {
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=metrics&q=cpu",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.res.Status)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(sender.res.Body, &body))
+		require.Equal(t, "/api/suggest", body["path"])
+		require.Equal(t, "max=100&q=cpu&type=metrics", body["query"])
+	})
+
+	t.Run("rejects resources that are not allowed", func(t *testing.T) {
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/put",
+			URL:    "api/put",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects methods other than GET", func(t *testing.T) {
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodPost,
+			Path:   "api/suggest",
+			URL:    "api/suggest",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+}
+
+type fakeInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (f fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return f.dsInfo, nil
+}
+
+func (f fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
 }
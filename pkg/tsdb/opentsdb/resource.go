@@ -0,0 +1,95 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// allowedResources are the OpenTSDB endpoints the query editor uses to suggest metrics,
+// tag keys and tag values, which can be called through CallResource.
+var allowedResources = map[string]bool{
+	"api/suggest":        true,
+	"api/search/lookup":  true,
+	"api/aggregators":    true,
+	"api/config/filters": true,
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	if req.Method != http.MethodGet {
+		return fmt.Errorf("invalid HTTP method: %s", req.Method)
+	}
+
+	resourcePath := strings.TrimPrefix(req.Path, "/")
+	if !allowedResources[resourcePath] {
+		return fmt.Errorf("invalid resource: %s", req.Path)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	params := reqURL.Query()
+	// Apply the configured lookup limit unless the caller set one
+	if dsInfo.LookupLimit > 0 {
+		switch resourcePath {
+		case "api/suggest":
+			if params.Get("max") == "" {
+				params.Set("max", strconv.Itoa(dsInfo.LookupLimit))
+			}
+		case "api/search/lookup":
+			if params.Get("limit") == "" {
+				params.Set("limit", strconv.Itoa(dsInfo.LookupLimit))
+			}
+		}
+	}
+	u.RawQuery = params.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status: res.StatusCode,
+		Headers: map[string][]string{
+			"content-type": {"application/json"},
+		},
+		Body: body,
+	})
+}
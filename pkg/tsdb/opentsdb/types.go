@@ -1,13 +1,94 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start        int64            `json:"start"`
+	End          int64            `json:"end"`
+	Queries      []map[string]any `json:"queries"`
+	MsResolution bool             `json:"msResolution,omitempty"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric        string             `json:"metric"`
+	Tags          map[string]string  `json:"tags"`
+	AggregateTags []string           `json:"aggregateTags"`
+	DataPoints    map[string]float64 `json:"dps"`
+}
+
+// OpenTsdbExpQuery is the request body of the /api/query/exp expression endpoint.
+type OpenTsdbExpQuery struct {
+	Time        ExpTime         `json:"time"`
+	Filters     []ExpFilterSet  `json:"filters,omitempty"`
+	Metrics     []ExpMetric     `json:"metrics"`
+	Expressions []ExpExpression `json:"expressions,omitempty"`
+	Outputs     []ExpOutput     `json:"outputs,omitempty"`
+}
+
+type ExpTime struct {
+	Start       int64           `json:"start"`
+	End         int64           `json:"end"`
+	Aggregator  string          `json:"aggregator"`
+	Downsampler *ExpDownsampler `json:"downsampler,omitempty"`
+	Rate        bool            `json:"rate,omitempty"`
+}
+
+type ExpDownsampler struct {
+	Interval   string         `json:"interval"`
+	Aggregator string         `json:"aggregator"`
+	FillPolicy *ExpFillPolicy `json:"fillPolicy,omitempty"`
+}
+
+type ExpFillPolicy struct {
+	Policy string   `json:"policy"`
+	Value  *float64 `json:"value,omitempty"`
+}
+
+type ExpFilterSet struct {
+	ID   string   `json:"id"`
+	Tags []Filter `json:"tags"`
+}
+
+// Filter is a tag filter such as literal_or, wildcard or regexp.
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+type ExpMetric struct {
+	ID         string         `json:"id"`
+	Metric     string         `json:"metric"`
+	Filter     string         `json:"filter,omitempty"`
+	Aggregator string         `json:"aggregator,omitempty"`
+	FillPolicy *ExpFillPolicy `json:"fillPolicy,omitempty"`
+}
+
+type ExpExpression struct {
+	ID   string `json:"id"`
+	Expr string `json:"expr"`
+}
+
+type ExpOutput struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias,omitempty"`
+}
+
+type OpenTsdbExpResponse struct {
+	Outputs []ExpOutputResult `json:"outputs"`
+}
+
+// ExpOutputResult holds the series of one output. Every data point is a row of
+// [timestamp in ms, value of series 1, value of series 2, ...], described by Meta.
+type ExpOutputResult struct {
+	ID    string          `json:"id"`
+	Alias string          `json:"alias"`
+	Dps   [][]any         `json:"dps"`
+	Meta  []ExpOutputMeta `json:"meta"`
+}
+
+type ExpOutputMeta struct {
+	Index          int               `json:"index"`
+	Metrics        []string          `json:"metrics"`
+	CommonTags     map[string]string `json:"commonTags"`
+	AggregatedTags []string          `json:"aggregatedTags"`
 }
@@ -0,0 +1,36 @@
+package parca
+
+import (
+	"errors"
+
+	v1alpha1 "buf.build/gen/go/parca-dev/parca/protocolbuffers/go/parca/query/v1alpha1"
+	"github.com/bufbuild/connect-go"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/profiling"
+)
+
+// topFunctionsToDataFrames reduces the flamegraph to the n functions with the biggest self value, see
+// profiling.TopFunctionsToDataFrames.
+func topFunctionsToDataFrames(resp *connect.Response[v1alpha1.QueryResponse], n int) ([]*data.Frame, error) {
+	flameResponse, ok := resp.Msg.Report.(*v1alpha1.QueryResponse_Flamegraph)
+	if !ok {
+		return nil, errors.New("unknown report type returned from query")
+	}
+
+	tree := flameResponse.Flamegraph
+	if tree == nil || tree.Root == nil {
+		return []*data.Frame{}, nil
+	}
+
+	// The same function can show up in many stacks, so self values are summed up by name. The root node is the
+	// synthetic "total" node and does not represent a function.
+	selfByName := make(map[string]int64)
+	walkTree(tree.Root, func(level int64, value int64, name string, self int64) {
+		if level == 0 {
+			return
+		}
+		selfByName[name] += self
+	})
+
+	return profiling.TopFunctionsToDataFrames(selfByName, tree.Root.Cumulative, n), nil
+}
@@ -0,0 +1,62 @@
+package parca
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1alpha1 "buf.build/gen/go/parca-dev/parca/protocolbuffers/go/parca/query/v1alpha1"
+	"github.com/bufbuild/connect-go"
+	"github.com/grafana/dataplane/sdata/timeseries"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_topFunctionsToDataFrames(t *testing.T) {
+	t.Run("sorts functions by self share", func(t *testing.T) {
+		frames, err := topFunctionsToDataFrames(flamegraphResponse, 2)
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		require.Equal(t, data.Labels{"function": "baz"}, frames[0].Fields[0].Labels)
+		require.Equal(t, 0.08, frames[0].Fields[0].At(0))
+		require.Equal(t, data.Labels{"function": "bar"}, frames[1].Fields[0].Labels)
+		require.Equal(t, 0.01, frames[1].Fields[0].At(0))
+	})
+
+	t.Run("unknown report type", func(t *testing.T) {
+		_, err := topFunctionsToDataFrames(&connect.Response[v1alpha1.QueryResponse]{Msg: &v1alpha1.QueryResponse{}}, 10)
+		require.Error(t, err)
+	})
+}
+
+func Test_queryTopFunctions(t *testing.T) {
+	ds := &ParcaDatasource{
+		client: &FakeClient{},
+	}
+
+	dataQuery := backend.DataQuery{
+		RefID:     "A",
+		QueryType: queryTypeTopFunctions,
+		TimeRange: backend.TimeRange{
+			From: time.UnixMilli(10000),
+			To:   time.UnixMilli(20000),
+		},
+		JSON: []byte(`{"profileTypeId":"foo:bar","labelSelector":"{app=\\\"baz\\\"}","topN":1}`),
+	}
+
+	resp := ds.query(context.Background(), backend.PluginContext{}, dataQuery)
+	require.Nil(t, resp.Error)
+	require.Len(t, resp.Frames, 1)
+	require.Equal(t, data.Labels{"function": "baz"}, resp.Frames[0].Fields[0].Labels)
+}
+
+func Test_seriesToDataFrameIsDataplaneTimeSeries(t *testing.T) {
+	reader, err := timeseries.CollectionReaderFromFrames(seriesToDataFrame(rangeResponse, "process_cpu:samples:count:cpu:nanoseconds"))
+	require.NoError(t, err)
+	collection, err := reader.GetCollection(false)
+	require.NoError(t, err)
+	require.Empty(t, collection.RemainderIndices)
+	require.Len(t, collection.Refs, 1)
+}
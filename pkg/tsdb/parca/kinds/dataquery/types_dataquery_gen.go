@@ -11,9 +11,10 @@ package dataquery
 
 // Defines values for ParcaQueryType.
 const (
-	ParcaQueryTypeBoth    ParcaQueryType = "both"
-	ParcaQueryTypeMetrics ParcaQueryType = "metrics"
-	ParcaQueryTypeProfile ParcaQueryType = "profile"
+	ParcaQueryTypeBoth         ParcaQueryType = "both"
+	ParcaQueryTypeMetrics      ParcaQueryType = "metrics"
+	ParcaQueryTypeProfile      ParcaQueryType = "profile"
+	ParcaQueryTypeTopFunctions ParcaQueryType = "topFunctions"
 )
 
 // These are the common properties available to all queries in all datasources.
@@ -73,6 +74,9 @@ type ParcaDataQuery struct {
 	// In server side expressions, the refId is used as a variable name to identify results.
 	// By default, the UI will assign A->Z; however setting meaningful names may be useful.
 	RefId string `json:"refId"`
+
+	// Sets the number of functions returned by a top functions query.
+	TopN *int64 `json:"topN,omitempty"`
 }
 
 // ParcaQueryType defines model for ParcaQueryType.
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/parca/kinds/dataquery"
+	"github.com/grafana/grafana/pkg/tsdb/profiling"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -27,6 +28,8 @@ const (
 	queryTypeProfile = string(dataquery.ParcaQueryTypeProfile)
 	queryTypeMetrics = string(dataquery.ParcaQueryTypeMetrics)
 	queryTypeBoth    = string(dataquery.ParcaQueryTypeBoth)
+
+	queryTypeTopFunctions = string(dataquery.ParcaQueryTypeTopFunctions)
 )
 
 // query processes single Parca query transforming the response to data.Frame packaged in DataResponse
@@ -73,6 +76,26 @@ func (d *ParcaDatasource) query(ctx context.Context, pCtx backend.PluginContext,
 		response.Frames = append(response.Frames, frame)
 	}
 
+	if query.QueryType == queryTypeTopFunctions {
+		ctxLogger.Debug("Querying SelectMergeStacktraces() for top functions", "queryModel", qm, "function", logEntrypoint())
+		resp, err := d.client.Query(ctx, makeProfileRequest(qm, query))
+		if err != nil {
+			response.Error = err
+			ctxLogger.Error("Failed to process query", "error", err, "queryType", query.QueryType, "function", logEntrypoint())
+			span.RecordError(response.Error)
+			span.SetStatus(codes.Error, response.Error.Error())
+			return response
+		}
+		frames, err := topFunctionsToDataFrames(resp, profiling.TopN(qm.TopN))
+		if err != nil {
+			response.Error = err
+			span.RecordError(response.Error)
+			span.SetStatus(codes.Error, response.Error.Error())
+			return response
+		}
+		response.Frames = append(response.Frames, frames...)
+	}
+
 	return response
 }
 
@@ -239,7 +262,11 @@ func seriesToDataFrame(seriesResp *connect.Response[v1alpha1.QueryRangeResponse]
 
 	for _, series := range seriesResp.Msg.Series {
 		frame := data.NewFrame("series")
-		frame.Meta = &data.FrameMeta{PreferredVisualization: "graph"}
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			TypeVersion:            data.FrameTypeVersion{0, 1},
+			PreferredVisualization: "graph",
+		}
 		frames = append(frames, frame)
 
 		fields := data.Fields{}
@@ -0,0 +1,65 @@
+// Package profiling holds the query helpers shared by the profiling data sources.
+package profiling
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DefaultTopN is the number of functions returned by a top functions query when the query does not set topN.
+const DefaultTopN = 10
+
+type functionShare struct {
+	name string
+	self int64
+}
+
+// TopN returns the number of functions requested by a top functions query.
+func TopN(n *int64) int {
+	if n == nil || *n <= 0 {
+		return DefaultTopN
+	}
+	return int(*n)
+}
+
+// TopFunctionsToDataFrames returns the n functions with the biggest self value, given the self value summed up by
+// function name and the total value of the profile. Each function is returned as its own numeric frame labeled with
+// the function name, holding the share of the total profile value the function spends in itself. The frames follow
+// the dataplane numeric multi format so they can be consumed by server side expressions and alerting.
+func TopFunctionsToDataFrames(selfByName map[string]int64, total int64, n int) []*data.Frame {
+	if total == 0 {
+		return []*data.Frame{}
+	}
+
+	functions := make([]functionShare, 0, len(selfByName))
+	for name, self := range selfByName {
+		if self == 0 {
+			continue
+		}
+		functions = append(functions, functionShare{name: name, self: self})
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].self == functions[j].self {
+			return functions[i].name < functions[j].name
+		}
+		return functions[i].self > functions[j].self
+	})
+	if len(functions) > n {
+		functions = functions[:n]
+	}
+
+	frames := make([]*data.Frame, 0, len(functions))
+	for _, f := range functions {
+		field := data.NewField("share", data.Labels{"function": f.name}, []float64{float64(f.self) / float64(total)})
+		field.Config = &data.FieldConfig{Unit: "percentunit"}
+		frame := data.NewFrame("top_functions", field)
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeNumericMulti,
+			TypeVersion:            data.FrameTypeVersion{0, 1},
+			PreferredVisualization: "table",
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
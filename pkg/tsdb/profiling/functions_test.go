@@ -0,0 +1,63 @@
+package profiling
+
+import (
+	"testing"
+
+	"github.com/grafana/dataplane/sdata/numeric"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopFunctionsToDataFrames(t *testing.T) {
+	selfByName := map[string]int64{"encode": 55, "compress": 20, "worker": 15, "main": 10, "idle": 0}
+
+	t.Run("sorts functions by self share", func(t *testing.T) {
+		frames := TopFunctionsToDataFrames(selfByName, 100, 3)
+		require.Len(t, frames, 3)
+
+		expected := []struct {
+			name  string
+			share float64
+		}{
+			{"encode", 0.55},
+			{"compress", 0.2},
+			{"worker", 0.15},
+		}
+		for i, e := range expected {
+			require.Equal(t, data.Labels{"function": e.name}, frames[i].Fields[0].Labels)
+			require.InDelta(t, e.share, frames[i].Fields[0].At(0).(float64), 0.0001)
+			require.Equal(t, data.FrameTypeNumericMulti, frames[i].Meta.Type)
+		}
+	})
+
+	t.Run("sorts functions with the same share by name", func(t *testing.T) {
+		frames := TopFunctionsToDataFrames(map[string]int64{"b": 1, "a": 1}, 2, 10)
+		require.Len(t, frames, 2)
+		require.Equal(t, data.Labels{"function": "a"}, frames[0].Fields[0].Labels)
+		require.Equal(t, data.Labels{"function": "b"}, frames[1].Fields[0].Labels)
+	})
+
+	t.Run("frames are readable as dataplane numeric data", func(t *testing.T) {
+		frames := TopFunctionsToDataFrames(selfByName, 100, 10)
+		require.Len(t, frames, 4)
+
+		reader, err := numeric.CollectionReaderFromFrames(frames)
+		require.NoError(t, err)
+		collection, err := reader.GetCollection(false)
+		require.NoError(t, err)
+		require.Empty(t, collection.RemainderIndices)
+		require.Len(t, collection.Refs, 4)
+	})
+
+	t.Run("empty profile", func(t *testing.T) {
+		require.Empty(t, TopFunctionsToDataFrames(map[string]int64{}, 0, 10))
+	})
+}
+
+func TestTopN(t *testing.T) {
+	n := int64(3)
+	zero := int64(0)
+	require.Equal(t, 3, TopN(&n))
+	require.Equal(t, DefaultTopN, TopN(&zero))
+	require.Equal(t, DefaultTopN, TopN(nil))
+}
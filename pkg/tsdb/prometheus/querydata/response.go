@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata/exemplar"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/utils"
 	"github.com/grafana/grafana/pkg/util/converter"
+	"github.com/grafana/grafana/pkg/util/frametype"
 )
 
 func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *http.Response) backend.DataResponse {
@@ -116,6 +117,16 @@ func addMetadataToMultiFrame(q *models.Query, frame *data.Frame, enableDataplane
 	frame.Fields[0].Config = &data.FieldConfig{Interval: float64(q.Step.Milliseconds())}
 
 	customName := getName(q, frame.Fields[1])
+
+	// Native histograms are framed as heatmap cells, where the fields are the bucket
+	// boundaries and counts and the series labels are stored on the yMin field.
+	// Only name the frame so the field names used by the heatmap stay intact.
+	if isHistogramFrame(frame) {
+		if customName != "" {
+			frame.Name = customName
+		}
+		return
+	}
 	if customName != "" {
 		frame.Fields[1].Config = &data.FieldConfig{DisplayNameFromDS: customName}
 	}
@@ -190,6 +201,10 @@ func getName(q *models.Query, field *data.Field) string {
 	return legend
 }
 
+func isHistogramFrame(frame *data.Frame) bool {
+	return frame.Meta != nil && frame.Meta.Type == frametype.HeatmapCells
+}
+
 func isExemplarFrame(frame *data.Frame) bool {
 	rt := models.ResultTypeFromFrame(frame)
 	return rt == models.ResultTypeExemplar
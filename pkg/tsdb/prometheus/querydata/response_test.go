@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata/exemplar"
+	"github.com/grafana/grafana/pkg/util/frametype"
 )
 
 func TestQueryData_parseResponse(t *testing.T) {
@@ -55,4 +56,20 @@ func TestQueryData_parseResponse(t *testing.T) {
 		assert.Error(t, result.Error)
 		assert.Equal(t, result.Error.Error(), "unknown result type: ")
 	})
+
+	t.Run("native histograms keep their heatmap field names", func(t *testing.T) {
+		qd := QueryData{exemplarSampler: exemplar.NewStandardDeviationSampler, enableDataplane: true}
+		resBody := `{"data":{"resultType":"vector","result":[{"metric":{"__name__":"http_request_duration_seconds","job":"api"},"histogram":[1649967668.042,{"count":"3","sum":"1.5","buckets":[[0,"0.1","0.5","1"],[0,"0.5","1","2"]]}]}]},"status":"success"}`
+		res := &http.Response{Body: io.NopCloser(bytes.NewBufferString(resBody))}
+		result := qd.parseResponse(context.Background(), &models.Query{}, res)
+		assert.Nil(t, result.Error)
+		assert.Len(t, result.Frames, 1)
+
+		frame := result.Frames[0]
+		assert.Equal(t, frametype.HeatmapCells, frame.Meta.Type)
+		assert.Equal(t, `http_request_duration_seconds{job="api"}`, frame.Name)
+		assert.Equal(t, "yMin", frame.Fields[1].Name)
+		assert.Equal(t, "api", frame.Fields[1].Labels["job"])
+		assert.Equal(t, 2, frame.Rows())
+	})
 }
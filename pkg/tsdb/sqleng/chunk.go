@@ -0,0 +1,232 @@
+package sqleng
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// ChunkMeta is stored in the custom frame metadata of the frame returned by a chunked
+// table query. When ContinuationToken is set, more rows are available and the token can
+// be passed back in the query model to fetch the next chunk.
+type ChunkMeta struct {
+	Offset            int64  `json:"offset"`
+	ContinuationToken string `json:"continuationToken,omitempty"`
+}
+
+// ErrQueryNotPaginable is returned by SQLPaginator implementations when a query can not be
+// safely wrapped or extended, e.g. when it holds several statements. The rows of the chunk
+// are then skipped in Grafana instead.
+var ErrQueryNotPaginable = errors.New("query can not be paginated")
+
+// SQLPaginator is implemented by the query result transformers of dialects that read the
+// rows of a chunked query in the database instead of skipping them in Grafana.
+type SQLPaginator interface {
+	// Paginate returns the query reading at most limit rows after the first offset rows.
+	// If limit is less than 0, there is no limit.
+	Paginate(sql string, offset, limit int64) (string, error)
+}
+
+// CommentSyntax tells which comments a dialect supports.
+type CommentSyntax int
+
+const (
+	// StandardComments are -- line comments and /* */ block comments.
+	StandardComments CommentSyntax = iota
+	// MySQLComments are # line comments, -- line comments followed by whitespace and /* */ block comments.
+	MySQLComments
+)
+
+// TrimQuery removes the whitespace, comments and statement terminators at the end of a query
+// so it can be wrapped or extended. It returns ErrQueryNotPaginable when the query holds more
+// than one statement or ends inside a string or comment.
+func TrimQuery(sql string, syntax CommentSyntax) (string, error) {
+	end := 0            // end of the last token of the statement
+	terminated := false // a statement terminator follows the statement
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		next := i + 1
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j, ok := skipQuoted(sql, i, syntax == MySQLComments)
+			if !ok {
+				return "", ErrQueryNotPaginable
+			}
+			next = j
+		case strings.HasPrefix(sql[i:], "/*"):
+			j := strings.Index(sql[i+2:], "*/")
+			if j < 0 {
+				return "", ErrQueryNotPaginable
+			}
+			i += j + 4
+			continue
+		case isLineComment(sql[i:], syntax):
+			j := strings.IndexByte(sql[i:], '\n')
+			if j < 0 {
+				i = len(sql)
+			} else {
+				i += j + 1
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == ';':
+			terminated = true
+			i++
+			continue
+		}
+
+		if terminated {
+			return "", ErrQueryNotPaginable
+		}
+		i = next
+		end = i
+	}
+	return sql[:end], nil
+}
+
+// skipQuoted returns the position after the string or quoted identifier starting at i,
+// or false if it is not terminated. Quotes are escaped by doubling them, or with a
+// backslash when backslashEscapes is true.
+func skipQuoted(sql string, i int, backslashEscapes bool) (int, bool) {
+	quote := sql[i]
+	for j := i + 1; j < len(sql); j++ {
+		switch {
+		case backslashEscapes && sql[j] == '\\' && quote != '`':
+			j++
+		case sql[j] == quote:
+			if j+1 < len(sql) && sql[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1, true
+		}
+	}
+	return 0, false
+}
+
+func isLineComment(sql string, syntax CommentSyntax) bool {
+	if syntax == MySQLComments {
+		if strings.HasPrefix(sql, "#") {
+			return true
+		}
+		// MySQL reads --1 as two minus signs
+		return strings.HasPrefix(sql, "--") && (len(sql) == 2 || strings.ContainsRune(" \t\n\r", rune(sql[2])))
+	}
+	return strings.HasPrefix(sql, "--")
+}
+
+type continuationToken struct {
+	Offset int64 `json:"offset"`
+}
+
+// encodeContinuationToken returns an opaque token pointing at the given row offset.
+func encodeContinuationToken(offset int64) (string, error) {
+	b, err := json.Marshal(continuationToken{Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeContinuationToken returns the row offset stored in the token. An empty token
+// is valid and points at the first row.
+func decodeContinuationToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid continuation token: %w", err)
+	}
+	var t continuationToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return 0, fmt.Errorf("invalid continuation token: %w", err)
+	}
+	if t.Offset < 0 {
+		return 0, fmt.Errorf("invalid continuation token: negative offset %d", t.Offset)
+	}
+	return t.Offset, nil
+}
+
+// chunkFrameFromRows reads a single chunk of at most chunkSize rows. The first offset rows
+// are skipped without being converted, which is only needed when the dialect does not
+// paginate the query itself. The returned bool reports whether there were rows left unread.
+func chunkFrameFromRows(rows *sql.Rows, offset, chunkSize int64, converters ...sqlutil.Converter) (*data.Frame, bool, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, false, err
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, false, err
+	}
+
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var skipped int64
+	for skipped < offset && rows.Next() {
+		skipped++
+	}
+
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+	var read int64
+	more := false
+	for rows.Next() {
+		if read == chunkSize {
+			more = true
+			break
+		}
+
+		r := scanRow.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, false, err
+		}
+
+		if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+			return nil, false, err
+		}
+		read++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return frame, more, nil
+}
+
+// setChunkMeta annotates the frame with its position in the result and, when more rows
+// are available, the continuation token of the next chunk.
+func setChunkMeta(frame *data.Frame, offset int64, more bool) error {
+	meta := ChunkMeta{Offset: offset}
+	if more {
+		next := offset + int64(frame.Rows())
+		token, err := encodeContinuationToken(next)
+		if err != nil {
+			return err
+		}
+		meta.ContinuationToken = token
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("Showing rows up to %d, more rows are available using the continuation token. Order the rows with ORDER BY to read consistent pages", next),
+		})
+	}
+
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Custom = meta
+	return nil
+}
@@ -0,0 +1,121 @@
+package sqleng
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinuationToken(t *testing.T) {
+	t.Run("empty token starts at the first row", func(t *testing.T) {
+		offset, err := decodeContinuationToken("")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), offset)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		token, err := encodeContinuationToken(42)
+		require.NoError(t, err)
+		offset, err := decodeContinuationToken(token)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), offset)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := decodeContinuationToken("not a token!")
+		require.Error(t, err)
+	})
+}
+
+func TestTrimQuery(t *testing.T) {
+	tests := []struct {
+		desc     string
+		sql      string
+		syntax   CommentSyntax
+		expected string
+		err      error
+	}{
+		{desc: "terminator and whitespace", sql: "SELECT 1;\n", expected: "SELECT 1"},
+		{desc: "trailing line comment", sql: "SELECT 1 -- the answer", expected: "SELECT 1"},
+		{desc: "comment after the terminator", sql: "SELECT 1; -- x\n", expected: "SELECT 1"},
+		{desc: "trailing block comment", sql: "SELECT 1 /* ; */", expected: "SELECT 1"},
+		{desc: "keeps comments inside the query", sql: "SELECT 1 -- first\n, 2", expected: "SELECT 1 -- first\n, 2"},
+		{desc: "terminator inside a string", sql: "SELECT ';' AS a;", expected: "SELECT ';' AS a"},
+		{desc: "comment inside a string", sql: "SELECT '-- a' AS a", expected: "SELECT '-- a' AS a"},
+		{desc: "common table expression", sql: "WITH a AS (SELECT 1 AS x) SELECT x FROM a;", expected: "WITH a AS (SELECT 1 AS x) SELECT x FROM a"},
+		{desc: "several statements", sql: "SELECT 1; SELECT 2", err: ErrQueryNotPaginable},
+		{desc: "unterminated string", sql: "SELECT 'a", err: ErrQueryNotPaginable},
+		{desc: "unterminated block comment", sql: "SELECT 1 /* a", err: ErrQueryNotPaginable},
+		{desc: "mysql hash comment", sql: "SELECT 1 # the answer", syntax: MySQLComments, expected: "SELECT 1"},
+		{desc: "mysql double minus", sql: "SELECT 1--1", syntax: MySQLComments, expected: "SELECT 1--1"},
+		{desc: "mysql backslash escape", sql: "SELECT 'a\\';' -- x", syntax: MySQLComments, expected: "SELECT 'a\\';'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sql, err := TrimQuery(tt.sql, tt.syntax)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql)
+		})
+	}
+}
+
+func TestChunkFrameFromRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec("CREATE TABLE t (id INTEGER NOT NULL, name TEXT)")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = db.Exec("INSERT INTO t (id, name) VALUES (?, ?)", i, "n")
+		require.NoError(t, err)
+	}
+
+	read := func(t *testing.T, offset, chunkSize int64) (*data.Frame, bool) {
+		t.Helper()
+		rows, err := db.Query("SELECT id, name FROM t ORDER BY id")
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+		frame, more, err := chunkFrameFromRows(rows, offset, chunkSize)
+		require.NoError(t, err)
+		return frame, more
+	}
+
+	t.Run("reads a single chunk and resumes from its token", func(t *testing.T) {
+		frame, more := read(t, 0, 4)
+		require.True(t, more)
+		require.Equal(t, 4, frame.Rows())
+
+		require.NoError(t, setChunkMeta(frame, 0, more))
+		meta := frame.Meta.Custom.(ChunkMeta)
+		require.NotEmpty(t, meta.ContinuationToken)
+
+		offset, err := decodeContinuationToken(meta.ContinuationToken)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), offset)
+
+		frame, more = read(t, 8, 4)
+		require.False(t, more)
+		require.Equal(t, 2, frame.Rows())
+
+		require.NoError(t, setChunkMeta(frame, 8, more))
+		meta = frame.Meta.Custom.(ChunkMeta)
+		require.Equal(t, int64(8), meta.Offset)
+		require.Empty(t, meta.ContinuationToken)
+	})
+
+	t.Run("returns an empty frame with the schema when offset is past the end", func(t *testing.T) {
+		frame, more := read(t, 20, 2)
+		require.False(t, more)
+		require.Equal(t, 0, frame.Rows())
+		require.Len(t, frame.Fields, 2)
+	})
+}
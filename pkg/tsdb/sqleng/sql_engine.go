@@ -63,6 +63,9 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// ContinuousAggregates maps hypertables to the TimescaleDB continuous
+	// aggregates built on them, keyed by their bucket width.
+	ContinuousAggregates map[string]map[string]string `json:"timescaledbContinuousAggregates"`
 }
 
 type DataSourceInfo struct {
@@ -102,6 +105,12 @@ type QueryJson struct {
 	FillMode     string  `json:"fillMode"`
 	FillValue    float64 `json:"fillValue"`
 	Format       string  `json:"format"`
+	// ChunkSize enables chunked mode for table queries: rows are returned as
+	// multiple frames of at most ChunkSize rows each.
+	ChunkSize int64 `json:"chunkSize"`
+	// ContinuationToken is returned in the metadata of the last chunk when more
+	// rows are available, and resumes reading after those rows when set.
+	ContinuationToken string `json:"continuationToken"`
 }
 
 func (e *DataSourceHandler) TransformQueryError(logger log.Logger, err error) error {
@@ -255,6 +264,36 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	// Chunked table queries read a single chunk starting at the continuation token
+	chunked := queryJson.ChunkSize > 0 && queryJson.Format == string(dataQueryFormatTable)
+	var offset, skip, chunkSize int64
+	if chunked {
+		offset, err = decodeContinuationToken(queryJson.ContinuationToken)
+		if err != nil {
+			errAppendDebug("invalid continuation token", err, interpolatedQuery)
+			return
+		}
+		chunkSize = queryJson.ChunkSize
+		if e.rowLimit > 0 && e.rowLimit < chunkSize {
+			chunkSize = e.rowLimit
+		}
+		skip = offset
+		if paginator, ok := e.queryResultTransformer.(SQLPaginator); ok {
+			// one more row tells if there are more chunks
+			paginated, err := paginator.Paginate(interpolatedQuery, offset, chunkSize+1)
+			switch {
+			case errors.Is(err, ErrQueryNotPaginable):
+				logger.Debug("Query can not be paginated, skipping rows of the full result", "err", err)
+			case err != nil:
+				errAppendDebug("pagination failed", err, interpolatedQuery)
+				return
+			default:
+				interpolatedQuery = paginated
+				skip = 0
+			}
+		}
+	}
+
 	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
@@ -274,6 +313,30 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
+
+	if chunked {
+		frame, more, err := chunkFrameFromRows(rows, skip, chunkSize, sqlutil.ToConverters(stringConverters...)...)
+		if err != nil {
+			errAppendDebug("convert frame from rows error", err, interpolatedQuery)
+			return
+		}
+
+		if err := convertSQLTimeColumnsToEpochMS(frame, qm); err != nil {
+			errAppendDebug("converting time columns failed", err, interpolatedQuery)
+			return
+		}
+
+		if err := setChunkMeta(frame, offset, more); err != nil {
+			errAppendDebug("failed to set chunk metadata", err, interpolatedQuery)
+			return
+		}
+		frame.Meta.ExecutedQueryString = interpolatedQuery
+
+		queryResult.dataResponse.Frames = data.Frames{frame}
+		ch <- queryResult
+		return
+	}
+
 	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
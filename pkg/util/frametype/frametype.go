@@ -0,0 +1,9 @@
+// Package frametype holds the data frame types Grafana uses that the plugin SDK version in
+// use does not define yet. It has no dependencies so any package can share them.
+package frametype
+
+import "github.com/grafana/grafana-plugin-sdk-go/data"
+
+// HeatmapCells is the frame type of native histogram frames, the heatmap-cells frame type
+// of the frontend.
+const HeatmapCells data.FrameType = "heatmap-cells"
@@ -14,6 +14,14 @@ export interface FormModel {
   user: string;
   password: string;
   email: string;
+  otp?: string;
+}
+
+// MFAEnrollment is the TOTP secret users have to add to their authenticator app
+// when multi-factor authentication is required for them.
+export interface MFAEnrollment {
+  secret: string;
+  url: string;
 }
 
 interface Props {
@@ -32,6 +40,8 @@ interface Props {
     passwordHint: string;
     showDefaultPasswordWarning: boolean;
     loginErrorMessage: string | undefined;
+    mfaRequired: boolean;
+    mfaEnrollment: MFAEnrollment | undefined;
   }) => JSX.Element;
 }
 
@@ -40,6 +50,8 @@ interface State {
   isChangingPassword: boolean;
   showDefaultPasswordWarning: boolean;
   loginErrorMessage?: string;
+  mfaRequired: boolean;
+  mfaEnrollment?: MFAEnrollment;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
@@ -52,6 +64,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
       isChangingPassword: false,
       showDefaultPasswordWarning: false,
       loginErrorMessage: config.loginError,
+      mfaRequired: false,
     };
   }
 
@@ -103,9 +116,12 @@ export class LoginCtrl extends PureComponent<Props, State> {
       })
       .catch((err) => {
         const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
+        const mfa = isFetchError(err) ? getMFAState(err) : undefined;
         this.setState({
           isLoggingIn: false,
           loginErrorMessage: fetchErrorMessage || t('login.error.unknown', 'Unknown error occurred'),
+          mfaRequired: mfa?.required || this.state.mfaRequired,
+          mfaEnrollment: mfa?.enrollment || this.state.mfaEnrollment,
         });
       });
   };
@@ -132,7 +148,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage } = this.state;
+    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage, mfaRequired, mfaEnrollment } =
+      this.state;
     const { login, toGrafana, changePassword } = this;
     const { loginHint, passwordHint, disableLoginForm, disableUserSignUp } = config;
 
@@ -151,6 +168,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
           isChangingPassword,
           showDefaultPasswordWarning,
           loginErrorMessage,
+          mfaRequired,
+          mfaEnrollment,
         })}
       </>
     );
@@ -159,7 +178,9 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
 export default LoginCtrl;
 
-function getErrorMessage(err: FetchError<undefined | { messageId?: string; message?: string }>): string | undefined {
+type LoginErrorData = undefined | { messageId?: string; message?: string; extra?: Partial<MFAEnrollment> };
+
+function getErrorMessage(err: FetchError<LoginErrorData>): string | undefined {
   switch (err.data?.messageId) {
     case 'password-auth.empty':
     case 'password-auth.failed':
@@ -174,3 +195,19 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
       return err.data?.message;
   }
 }
+
+// getMFAState returns whether the user has to provide an authentication code,
+// and the secret to set up when they have to enroll first.
+function getMFAState(err: FetchError<LoginErrorData>): { required: boolean; enrollment?: MFAEnrollment } | undefined {
+  switch (err.data?.messageId) {
+    case 'mfa.required':
+    case 'mfa.invalid-code':
+      return { required: true };
+    case 'mfa.enrollment-required': {
+      const { secret, url } = err.data.extra ?? {};
+      return { required: true, enrollment: secret && url ? { secret, url } : undefined };
+    }
+    default:
+      return undefined;
+  }
+}
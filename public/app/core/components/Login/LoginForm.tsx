@@ -4,11 +4,11 @@ import { useForm } from 'react-hook-form';
 
 import { GrafanaTheme2 } from '@grafana/data';
 import { selectors } from '@grafana/e2e-selectors';
-import { Button, Input, Field, useStyles2 } from '@grafana/ui';
+import { Alert, Button, Input, Field, useStyles2 } from '@grafana/ui';
 
 import { PasswordField } from '../PasswordField/PasswordField';
 
-import { FormModel } from './LoginCtrl';
+import { FormModel, MFAEnrollment } from './LoginCtrl';
 
 interface Props {
   children: ReactElement;
@@ -16,12 +16,23 @@ interface Props {
   isLoggingIn: boolean;
   passwordHint: string;
   loginHint: string;
+  mfaRequired?: boolean;
+  mfaEnrollment?: MFAEnrollment;
 }
 
-export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, loginHint }: Props) => {
+export const LoginForm = ({
+  children,
+  onSubmit,
+  isLoggingIn,
+  passwordHint,
+  loginHint,
+  mfaRequired,
+  mfaEnrollment,
+}: Props) => {
   const styles = useStyles2(getStyles);
   const usernameId = useId();
   const passwordId = useId();
+  const otpId = useId();
   const {
     handleSubmit,
     register,
@@ -49,6 +60,31 @@ export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, login
             placeholder={passwordHint}
           />
         </Field>
+        {mfaEnrollment && (
+          <Alert severity="info" title="Set up multi-factor authentication">
+            Add this secret to your authenticator app, then log in with the code it generates:{' '}
+            <code className={styles.secret}>{mfaEnrollment.secret}</code>
+            <a href={mfaEnrollment.url} className={styles.setupLink}>
+              Open in authenticator app
+            </a>
+          </Alert>
+        )}
+        {mfaRequired && (
+          <Field
+            label="Authentication code"
+            description="Code from your authenticator app, or one of your recovery codes"
+            invalid={!!errors.otp}
+            error={errors.otp?.message}
+          >
+            <Input
+              {...register('otp', { required: 'Authentication code is required' })}
+              id={otpId}
+              autoFocus
+              autoComplete="one-time-code"
+              data-testid="login-otp"
+            />
+          </Field>
+        )}
         <Button
           type="submit"
           data-testid={selectors.pages.Login.submit}
@@ -70,6 +106,16 @@ export const getStyles = (theme: GrafanaTheme2) => {
       paddingBottom: theme.spacing(2),
     }),
 
+    secret: css({
+      wordBreak: 'break-all',
+    }),
+
+    setupLink: css({
+      display: 'block',
+      marginTop: theme.spacing(1),
+      textDecoration: 'underline',
+    }),
+
     submitButton: css({
       justifyContent: 'center',
       width: '100%',
@@ -163,4 +163,37 @@ describe('Login Page', () => {
       'You have exceeded the number of login attempts for this user. Please try again later.'
     );
   });
+
+  it('asks for an authentication code when multi-factor authentication is required', async () => {
+    postMock.mockRejectedValueOnce({
+      data: {
+        message: 'Authentication code required',
+        messageId: 'mfa.required',
+        statusCode: 401,
+      },
+      status: 401,
+      statusText: 'Unauthorized',
+    });
+    postMock.mockResolvedValueOnce({ message: 'Logged in' });
+
+    render(<LoginPage />);
+
+    await userEvent.type(screen.getByLabelText('Email or username'), 'admin');
+    await userEvent.type(screen.getByLabelText('Password'), 'test');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    const alert = await screen.findByRole('alert', { name: 'Login failed' });
+    expect(alert).toHaveTextContent('Authentication code required');
+
+    await userEvent.type(screen.getByLabelText(/Authentication code/), '123456');
+    await userEvent.click(screen.getByRole('button', { name: 'Log in' }));
+
+    await waitFor(() =>
+      expect(postMock).toHaveBeenLastCalledWith(
+        '/login',
+        { user: 'admin', password: 'test', otp: '123456' },
+        { showErrorAlert: false }
+      )
+    );
+  });
 });
@@ -35,6 +35,8 @@ export const LoginPage = () => {
         isChangingPassword,
         showDefaultPasswordWarning,
         loginErrorMessage,
+        mfaRequired,
+        mfaEnrollment,
       }) => (
         <LoginLayout isChangingPassword={isChangingPassword}>
           {!isChangingPassword && (
@@ -46,7 +48,14 @@ export const LoginPage = () => {
               )}
 
               {!disableLoginForm && (
-                <LoginForm onSubmit={login} loginHint={loginHint} passwordHint={passwordHint} isLoggingIn={isLoggingIn}>
+                <LoginForm
+                  onSubmit={login}
+                  loginHint={loginHint}
+                  passwordHint={passwordHint}
+                  isLoggingIn={isLoggingIn}
+                  mfaRequired={mfaRequired}
+                  mfaEnrollment={mfaEnrollment}
+                >
                   <HorizontalGroup justify="flex-end">
                     {!config.auth.disableLogin && (
                       <LinkButton
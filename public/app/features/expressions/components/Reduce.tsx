@@ -38,11 +38,13 @@ export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props)
         newSettings = {
           mode: ReducerMode.ReplaceNonNumbers,
           replaceWithValue: replaceWithNumber,
+          quantile: query.settings?.quantile,
         };
         break;
       default:
         newSettings = {
           mode: value.value,
+          quantile: query.settings?.quantile,
         };
     }
     onSettingsChanged(newSettings);
@@ -50,7 +52,16 @@ export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props)
 
   const onReplaceWithChanged = (e: React.FormEvent<HTMLInputElement>) => {
     const value = e.currentTarget.valueAsNumber;
-    onSettingsChanged({ mode: ReducerMode.ReplaceNonNumbers, replaceWithValue: value ?? 0 });
+    onSettingsChanged({
+      mode: ReducerMode.ReplaceNonNumbers,
+      replaceWithValue: value ?? 0,
+      quantile: query.settings?.quantile,
+    });
+  };
+
+  const onQuantileChanged = (e: React.FormEvent<HTMLInputElement>) => {
+    const value = e.currentTarget.valueAsNumber;
+    onSettingsChanged({ ...query.settings, quantile: value });
   };
 
   const mode = query.settings?.mode ?? ReducerMode.Strict;
@@ -66,6 +77,25 @@ export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props)
     );
   };
 
+  const quantile = () => {
+    if (query.reducer !== 'histogram_quantile') {
+      return;
+    }
+    return (
+      <InlineField label="Quantile" labelWidth={labelWidth} tooltip="A number between 0 and 1, e.g. 0.99">
+        <Input
+          type="number"
+          min={0}
+          max={1}
+          step={0.01}
+          width={10}
+          onChange={onQuantileChanged}
+          value={query.settings?.quantile ?? ''}
+        />
+      </InlineField>
+    );
+  };
+
   return (
     <>
       <InlineFieldRow>
@@ -77,6 +107,7 @@ export const Reduce = ({ labelWidth = 'auto', onChange, refIds, query }: Props)
         <InlineField label="Function" labelWidth={labelWidth}>
           <Select options={reducerTypes} value={reducer} onChange={onSelectReducer} width={20} />
         </InlineField>
+        {quantile()}
         <InlineField label="Mode" labelWidth={labelWidth}>
           <Select onChange={onModeChanged} options={reducerModes} value={mode} width={25} />
         </InlineField>
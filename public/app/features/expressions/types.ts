@@ -68,6 +68,16 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  {
+    value: 'histogram_quantile',
+    label: 'Histogram quantile',
+    description: 'Get a quantile of the latest native histogram sample',
+  },
+  {
+    value: 'histogram_count',
+    label: 'Histogram count',
+    description: 'Get the number of observations in the latest native histogram sample',
+  },
 ];
 
 export enum ReducerMode {
@@ -136,6 +146,7 @@ export interface ThresholdExpressionQuery extends ExpressionQuery {
 export interface ExpressionQuerySettings {
   mode?: ReducerMode;
   replaceWithValue?: number;
+  quantile?: number;
 }
 
 export interface ClassicCondition {
@@ -3,7 +3,7 @@ import React, { PureComponent } from 'react';
 import { DataSourcePluginOptionsEditorProps, SelectableValue, updateDatasourcePluginOption } from '@grafana/data';
 import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
 import { getBackendSrv, getTemplateSrv, isFetchError, TemplateSrv, config } from '@grafana/runtime';
-import { Alert, Divider, SecureSocksProxySettings } from '@grafana/ui';
+import { Alert, Divider, Field, Input, SecureSocksProxySettings } from '@grafana/ui';
 
 import ResponseParser from '../azure_monitor/response_parser';
 import {
@@ -17,6 +17,9 @@ import { routeNames } from '../utils/common';
 
 import { MonitorConfig } from './MonitorConfig';
 
+// DEFAULT_MAX_RESULT_ROWS matches the default of the backend
+const DEFAULT_MAX_RESULT_ROWS = 50000;
+
 export type Props = DataSourcePluginOptionsEditorProps<AzureDataSourceJsonData, AzureDataSourceSecureJsonData>;
 
 interface ErrorMessage {
@@ -62,6 +65,17 @@ export class ConfigEditor extends PureComponent<Props, State> {
     }
   };
 
+  private onMaxResultRowsChange = (event: React.ChangeEvent<HTMLInputElement>): void => {
+    const maxResultRows = parseInt(event.currentTarget.value, 10);
+    this.updateOptions((options) => ({
+      ...options,
+      jsonData: {
+        ...options.jsonData,
+        maxResultRows: maxResultRows > 0 ? maxResultRows : undefined,
+      },
+    }));
+  };
+
   private getSubscriptions = async (): Promise<Array<SelectableValue<string>>> => {
     await this.saveOptions();
 
@@ -109,19 +123,34 @@ export class ConfigEditor extends PureComponent<Props, State> {
             {error.details && <details style={{ whiteSpace: 'pre-wrap' }}>{error.details}</details>}
           </Alert>
         )}
-        {config.secureSocksDSProxyEnabled && (
-          <>
-            <Divider />
-            <ConfigSection
-              title="Additional settings"
-              description="Additional settings are optional settings that can be configured for more control over your data source. This includes Secure Socks Proxy."
-              isCollapsible={true}
-              isInitiallyOpen={options.jsonData.enableSecureSocksProxy !== undefined}
-            >
-              <SecureSocksProxySettings options={options} onOptionsChange={onOptionsChange} />
-            </ConfigSection>
-          </>
-        )}
+        <Divider />
+        <ConfigSection
+          title="Additional settings"
+          description="Additional settings are optional settings that can be configured for more control over your data source. This includes the maximum result rows and Secure Socks Proxy."
+          isCollapsible={true}
+          isInitiallyOpen={
+            options.jsonData.enableSecureSocksProxy !== undefined || options.jsonData.maxResultRows !== undefined
+          }
+        >
+          <Field
+            label="Maximum result rows"
+            description={`Maximum number of rows read from paged Logs and Azure Resource Graph results. Defaults to ${DEFAULT_MAX_RESULT_ROWS}.`}
+            htmlFor="max-result-rows"
+          >
+            <Input
+              id="max-result-rows"
+              className="width-15"
+              type="number"
+              min={1}
+              placeholder={String(DEFAULT_MAX_RESULT_ROWS)}
+              value={options.jsonData.maxResultRows ?? ''}
+              onChange={this.onMaxResultRowsChange}
+            />
+          </Field>
+          {config.secureSocksDSProxyEnabled && (
+            <SecureSocksProxySettings options={options} onOptionsChange={onOptionsChange} />
+          )}
+        </ConfigSection>
       </>
     );
   }
@@ -86,6 +86,9 @@ export interface AzureDataSourceJsonData extends DataSourceJsonData {
   // App Insights
   appInsightsAppId?: string;
 
+  /** Maximum number of rows read from paged Logs and Azure Resource Graph results */
+  maxResultRows?: number;
+
   enableSecureSocksProxy?: boolean;
 }
 
@@ -53,6 +53,10 @@ export function CheatSheet() {
         </li>
         <li>$__unixEpochGroup(column,&apos;5m&apos;) -&gt; floor(column/300)*300</li>
         <li>$__unixEpochGroupAlias(column,&apos;5m&apos;) -&gt; floor(column/300)*300 AS &quot;time&quot;</li>
+        <li>
+          $__continuousAggregate(table[, 1h=view_1h, ...]) -&gt; the coarsest continuous aggregate of the table whose
+          bucket width fits the panel interval, or the table itself
+        </li>
       </ul>
       <p>Example of group by and order by with $__timeGroup:</p>
       <pre>
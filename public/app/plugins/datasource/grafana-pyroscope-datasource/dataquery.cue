@@ -38,8 +38,10 @@ composableKinds: DataQuery: {
 				// Allows to group the results.
 				groupBy: [...string]
 				// Sets the maximum number of nodes in the flamegraph.
-				maxNodes?:           int64
-				#PyroscopeQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				maxNodes?: int64
+				// Sets the number of functions returned by a top functions query.
+				topN?:               int64
+				#PyroscopeQueryType: "metrics" | "profile" | "topFunctions" | *"both" @cuetsy(kind="type")
 			}
 		}]
 		lenses: []
@@ -10,7 +10,7 @@
 
 import * as common from '@grafana/schema';
 
-export type PyroscopeQueryType = ('metrics' | 'profile' | 'both');
+export type PyroscopeQueryType = ('metrics' | 'profile' | 'topFunctions' | 'both');
 
 export const defaultPyroscopeQueryType: PyroscopeQueryType = 'both';
 
@@ -35,6 +35,10 @@ export interface GrafanaPyroscope extends common.DataQuery {
    * Specifies the query span selectors.
    */
   spanSelector?: Array<string>;
+  /**
+   * Sets the number of functions returned by a top functions query.
+   */
+  topN?: number;
 }
 
 export const defaultGrafanaPyroscope: Partial<GrafanaPyroscope> = {
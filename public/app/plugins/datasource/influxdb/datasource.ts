@@ -261,6 +261,13 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
       });
     }
 
+    if (query.parameters) {
+      // Parameters are bound by the server, so their values are not escaped
+      expandedQuery.parameters = query.parameters.map((param) =>
+        typeof param === 'string' ? this.templateSrv.replace(param, scopedVars) : param
+      );
+    }
+
     return {
       ...expandedQuery,
       adhocFilters: this.templateSrv.getAdhocFilters(this.name) ?? [],
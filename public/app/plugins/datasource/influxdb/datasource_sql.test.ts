@@ -39,5 +39,12 @@ describe('InfluxDB SQL Support', () => {
         `SELECT "$interpolationVar2", time FROM iox.$interpolationVar WHERE time >= $__timeFrom AND time <= $__timeTo`
       );
     });
+
+    it('should replace template variables in parameters without escaping them', () => {
+      replaceMock.mockImplementation((value: string) => value.replace('$host', 'a.b|c'));
+      const query = ds.applyVariables({ ...sqlQuery, parameters: ['$host', 42] }, {});
+      expect(query.parameters).toEqual(['a.b|c', 42]);
+      replaceMock.mockReset();
+    });
   });
 });
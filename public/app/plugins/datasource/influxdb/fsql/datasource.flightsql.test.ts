@@ -43,7 +43,24 @@ describe('flightsql datasource', () => {
   const ds = new FlightSQLDatasource(instanceSettings, templateSrv);
 
   it('should add template variables to the responses', async () => {
+    jest.spyOn(ds, 'getResource').mockResolvedValue([{ name: 'time', type: 'timestamp[ns]' }]);
     const fields = await ds.fetchFields({ dataset: 'test', table: 'table' });
     expect(fields[0].name).toBe('$templateVar');
   });
+
+  it('should fetch the columns of a table through the columns resource', async () => {
+    const getResource = jest
+      .spyOn(ds, 'getResource')
+      .mockResolvedValue([{ name: 'time', type: 'timestamp[ns, tz=UTC]' }]);
+    const fields = await ds.fetchFields({ dataset: 'test', table: 'iox.cpu' });
+    expect(getResource).toHaveBeenCalledWith('columns', { database: 'iox', table: 'cpu' });
+    expect(fields[1]).toMatchObject({ name: 'time', raqbFieldType: 'datetime', icon: 'clock-nine' });
+  });
+
+  it('should fetch the tables of a database through the tables resource', async () => {
+    const getResource = jest.spyOn(ds, 'getResource').mockResolvedValue(['cpu']);
+    const tables = await ds.fetchTables('iox');
+    expect(getResource).toHaveBeenCalledWith('tables', { database: 'iox' });
+    expect(tables).toEqual(['$templateVar', 'cpu']);
+  });
 });
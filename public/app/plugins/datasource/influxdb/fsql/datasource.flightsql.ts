@@ -4,10 +4,9 @@ import { getTemplateSrv, TemplateSrv } from '@grafana/runtime';
 import { DB, SqlDatasource, SQLQuery, formatSQL } from '@grafana/sql';
 
 import { mapFieldsToTypes } from './fields';
-import { buildColumnQuery, buildTableQuery } from './flightsqlMetaQuery';
 import { getSqlCompletionProvider } from './sqlCompletionProvider';
-import { quoteLiteral, quoteIdentifierIfNecessary, toRawSql } from './sqlUtil';
-import { FlightSQLOptions } from './types';
+import { quoteLiteral, quoteIdentifierIfNecessary, toRawSql, unquoteIdentifier } from './sqlUtil';
+import { FlightSQLColumn, FlightSQLOptions } from './types';
 
 export class FlightSQLDatasource extends SqlDatasource {
   sqlLanguageDefinition: LanguageDefinition | undefined;
@@ -40,13 +39,12 @@ export class FlightSQLDatasource extends SqlDatasource {
   }
 
   async fetchDatasets(): Promise<string[]> {
-    return Promise.resolve(['iox']);
+    return this.getResource<string[]>('databases');
   }
 
   async fetchTables(dataset?: string): Promise<string[]> {
-    const query = buildTableQuery(dataset);
-    const tables = await this.runSql<string[]>(query, { refId: 'tables' });
-    const tableNames = tables.map((t) => quoteIdentifierIfNecessary(t[0]));
+    const tables = await this.getResource<string[]>('tables', dataset ? { database: dataset } : {});
+    const tableNames = tables.map((t) => quoteIdentifierIfNecessary(t));
     tableNames.unshift(...this.getTemplateVariables());
     return tableNames;
   }
@@ -55,15 +53,18 @@ export class FlightSQLDatasource extends SqlDatasource {
     if (!query.dataset || !query.table) {
       return [];
     }
-    const interpolatedTable = this.templateSrv.replace(query.table);
-    const queryString = buildColumnQuery(interpolatedTable, query.dataset);
-    const frame = await this.runSql<string[]>(queryString, { refId: 'fields' });
-    const fields = frame.map((f) => ({
-      name: f[0],
-      text: f[0],
-      value: quoteIdentifierIfNecessary(f[0]),
-      type: f[1],
-      label: f[0],
+    const interpolatedTable = unquoteIdentifier(this.templateSrv.replace(query.table));
+    // check for schema qualified table
+    const [database, table] = interpolatedTable.includes('.')
+      ? interpolatedTable.split('.', 2)
+      : [query.dataset, interpolatedTable];
+    const columns = await this.getResource<FlightSQLColumn[]>('columns', { database, table });
+    const fields = columns.map((c) => ({
+      name: c.name,
+      text: c.name,
+      value: quoteIdentifierIfNecessary(c.name),
+      type: c.type,
+      label: c.name,
     }));
     fields.unshift(
       ...this.getTemplateVariables().map((v) => ({
@@ -4,13 +4,14 @@ export function mapFieldsToTypes(columns: SQLSelectableValue[]) {
   const fields: SQLSelectableValue[] = [];
   for (const col of columns) {
     let type: RAQBFieldTypes = 'text';
-    switch (col.type?.toUpperCase()) {
+    switch (normalizeColumnType(col.type)) {
       case 'BOOLEAN':
       case 'BOOL': {
         type = 'boolean';
         break;
       }
       case 'BYTES':
+      case 'UTF8':
       case 'VARCHAR': {
         type = 'text';
         break;
@@ -20,6 +21,7 @@ export function mapFieldsToTypes(columns: SQLSelectableValue[]) {
       case 'INT':
       case 'INTEGER':
       case 'INT64':
+      case 'UINT64':
       case 'NUMERIC':
       case 'BIGNUMERIC': {
         type = 'number';
@@ -50,11 +52,17 @@ export function mapFieldsToTypes(columns: SQLSelectableValue[]) {
         break;
     }
 
-    fields.push({ ...col, raqbFieldType: type, icon: mapColumnTypeToIcon(col.type!.toUpperCase()) });
+    fields.push({ ...col, raqbFieldType: type, icon: mapColumnTypeToIcon(normalizeColumnType(col.type)) });
   }
   return fields;
 }
 
+// Arrow types reported by the FlightSQL table schemas carry their parameters, e.g. timestamp[ns, tz=UTC]
+function normalizeColumnType(type?: string) {
+  const upper = type?.toUpperCase() ?? '';
+  return upper.startsWith('TIMESTAMP[') ? 'TIMESTAMP' : upper;
+}
+
 export function mapColumnTypeToIcon(type: string) {
   switch (type) {
     case 'TIME':
@@ -62,6 +70,7 @@ export function mapColumnTypeToIcon(type: string) {
     case 'TIMESTAMP':
       return 'clock-nine';
     case 'BOOLEAN':
+    case 'BOOL':
       return 'toggle-off';
     case 'INTEGER':
     case 'FLOAT':
@@ -72,6 +81,7 @@ export function mapColumnTypeToIcon(type: string) {
     case 'TINYINT':
     case 'BYTEINT':
     case 'INT64':
+    case 'UINT64':
     case 'NUMERIC':
     case 'DECIMAL':
       return 'calculator-alt';
@@ -79,6 +89,7 @@ export function mapColumnTypeToIcon(type: string) {
     case 'VARCHAR':
     case 'STRING':
     case 'BYTES':
+    case 'UTF8':
     case 'TEXT':
     case 'TINYTEXT':
     case 'MEDIUMTEXT':
@@ -5,3 +5,9 @@ export interface FlightSQLOptions extends SQLOptions {
 }
 
 export interface FlightSQLQuery extends SQLQuery {}
+
+/** A column of a table, as returned by the `columns` resource */
+export interface FlightSQLColumn {
+  name: string;
+  type: string;
+}
@@ -90,6 +90,8 @@ export interface InfluxQuery extends DataQuery {
 
   textEditor?: boolean;
   adhocFilters?: AdHocVariableFilter[];
+  // SQL only: values bound to the placeholders of rawSql by a prepared statement
+  parameters?: Array<string | number | boolean | null>;
 }
 
 export type MetadataQueryType = 'TAG_KEYS' | 'TAG_VALUES' | 'MEASUREMENTS' | 'FIELDS' | 'RETENTION_POLICIES';
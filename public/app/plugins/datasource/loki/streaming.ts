@@ -19,7 +19,8 @@ import { LokiQuery } from './types';
  * possible collisions
  */
 export async function getLiveStreamKey(query: LokiQuery): Promise<string> {
-  const str = JSON.stringify({ expr: query.expr });
+  // Channels are filtered server-side, so queries with different stream options need different channels
+  const str = JSON.stringify({ expr: query.expr, stream: query.stream });
 
   const msgUint8 = new TextEncoder().encode(str); // encode as (utf-8) Uint8Array
   const hashBuffer = await crypto.subtle.digest('SHA-1', msgUint8); // hash the message
@@ -31,6 +31,23 @@ export interface LokiQuery extends LokiQueryFromSchema {
    * @experimental
    */
   splitDuration?: string;
+
+  /**
+   * Server-side filters applied to live tailing streams.
+   * @experimental
+   */
+  stream?: LokiStreamOptions;
+}
+
+export interface LokiStreamOptions {
+  /** Substrings that every streamed line must contain */
+  lineFilters?: string[];
+  /** Regular expression that every streamed line must match */
+  lineRegex?: string;
+  /** Stream selector, e.g. {level=~"error|warn"}, that the labels of every streamed line must match */
+  labelMatchers?: string;
+  /** Maximum number of lines streamed per second */
+  maxLinesPerSecond?: number;
 }
 
 export interface LokiOptions extends DataSourceJsonData {
@@ -86,10 +86,32 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
     updateDatasourcePluginJsonDataOption(props, 'connectionTimeout', connectionTimeout ?? 0);
   };
 
+  const onUDPConnectionLimitChanged = (UDPConnectionLimit?: number) => {
+    updateDatasourcePluginJsonDataOption(props, 'UDPConnectionLimit', UDPConnectionLimit ?? 1);
+  };
+
+  const onEnableDNSLookupKDCChanged = (event: SyntheticEvent<HTMLInputElement>) => {
+    updateDatasourcePluginJsonDataOption(props, 'enableDNSLookupKDC', String(event.currentTarget.checked));
+  };
+
+  const kerberosAuthTypes = [
+    MSSQLAuthenticationType.kerberosRaw,
+    MSSQLAuthenticationType.kerberosKeytab,
+    MSSQLAuthenticationType.kerberosCredentialCache,
+  ];
+  const isKerberosAuth = !!jsonData.authenticationType && kerberosAuthTypes.includes(jsonData.authenticationType);
+  const usesPassword =
+    !jsonData.authenticationType ||
+    jsonData.authenticationType === MSSQLAuthenticationType.sqlAuth ||
+    jsonData.authenticationType === MSSQLAuthenticationType.kerberosRaw;
+
   const buildAuthenticationOptions = (): Array<SelectableValue<MSSQLAuthenticationType>> => {
     const basicAuthenticationOptions: Array<SelectableValue<MSSQLAuthenticationType>> = [
       { value: MSSQLAuthenticationType.sqlAuth, label: 'SQL Server Authentication' },
       { value: MSSQLAuthenticationType.windowsAuth, label: 'Windows Authentication' },
+      { value: MSSQLAuthenticationType.kerberosRaw, label: 'Windows AD: Username + password' },
+      { value: MSSQLAuthenticationType.kerberosKeytab, label: 'Windows AD: Keytab' },
+      { value: MSSQLAuthenticationType.kerberosCredentialCache, label: 'Windows AD: Credential cache' },
     ];
 
     if (azureAuthIsSupported) {
@@ -232,6 +254,10 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
                 <i>Windows Authentication</i> Windows Integrated Security - single sign on for users who are already
                 logged onto Windows and have enabled this option for MS SQL Server.
               </li>
+              <li>
+                <i>Windows AD</i> Kerberos authentication against Active Directory with a username and password, a
+                keytab file or an existing credential cache file.
+              </li>
               {azureAuthIsSupported && (
                 <li>
                   <i>Azure Authentication</i> Securely authenticate and access Azure resources and applications using
@@ -251,18 +277,20 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
           />
         </Field>
 
-        {/* Basic SQL auth. Render if authType === MSSQLAuthenticationType.sqlAuth OR
+        {/* Username and password. Render for SQL Server and Windows AD password authentication OR
         if no authType exists, which will be the case when creating a new data source */}
-        {(jsonData.authenticationType === MSSQLAuthenticationType.sqlAuth || !jsonData.authenticationType) && (
+        {(usesPassword || jsonData.authenticationType === MSSQLAuthenticationType.kerberosKeytab) && (
+          <Field label="Username" required invalid={!dsSettings.user} error={'Username is required'}>
+            <Input
+              value={dsSettings.user || ''}
+              placeholder="user"
+              onChange={onDSOptionChanged('user')}
+              width={LONG_WIDTH}
+            />
+          </Field>
+        )}
+        {usesPassword && (
           <>
-            <Field label="Username" required invalid={!dsSettings.user} error={'Username is required'}>
-              <Input
-                value={dsSettings.user || ''}
-                placeholder="user"
-                onChange={onDSOptionChanged('user')}
-                width={LONG_WIDTH}
-              />
-            </Field>
             <Field
               label="Password"
               required
@@ -281,6 +309,53 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
           </>
         )}
 
+        {jsonData.authenticationType === MSSQLAuthenticationType.kerberosKeytab && (
+          <Field
+            label="Keytab file path"
+            required
+            invalid={!jsonData.keytabFilePath}
+            error={'Keytab file path is required'}
+          >
+            <Input
+              value={jsonData.keytabFilePath || ''}
+              placeholder="/home/grafana/grafana.keytab"
+              onChange={onUpdateDatasourceJsonDataOption(props, 'keytabFilePath')}
+              width={LONG_WIDTH}
+            />
+          </Field>
+        )}
+
+        {jsonData.authenticationType === MSSQLAuthenticationType.kerberosCredentialCache && (
+          <Field
+            label="Credential cache path"
+            required
+            invalid={!jsonData.credentialCache}
+            error={'Credential cache path is required'}
+          >
+            <Input
+              value={jsonData.credentialCache || ''}
+              placeholder="/tmp/krb5cc_1000"
+              onChange={onUpdateDatasourceJsonDataOption(props, 'credentialCache')}
+              width={LONG_WIDTH}
+            />
+          </Field>
+        )}
+
+        {isKerberosAuth && (
+          <Field
+            label="Realm"
+            required={jsonData.authenticationType !== MSSQLAuthenticationType.kerberosCredentialCache}
+            description="Kerberos realm of the user, for example EXAMPLE.COM."
+          >
+            <Input
+              value={jsonData.kerberosRealm || ''}
+              placeholder="EXAMPLE.COM"
+              onChange={onUpdateDatasourceJsonDataOption(props, 'kerberosRealm')}
+              width={LONG_WIDTH}
+            />
+          </Field>
+        )}
+
         {azureAuthIsSupported && jsonData.authenticationType === MSSQLAuthenticationType.azureAuth && (
           <FieldSet label="Azure Authentication Settings">
             <azureAuthSettings.azureAuthSettingsUI dataSourceConfig={dsSettings} onChange={onOptionsChange} />
@@ -332,6 +407,47 @@ export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<Ms
             />
           </Field>
         </ConfigSubSection>
+
+        {isKerberosAuth && (
+          <ConfigSubSection title="Windows AD: Advanced Settings">
+            <Field label="krb5 config file path" description="Defaults to /etc/krb5.conf.">
+              <Input
+                width={LONG_WIDTH}
+                placeholder="/etc/krb5.conf"
+                value={jsonData.configFilePath || ''}
+                onChange={onUpdateDatasourceJsonDataOption(props, 'configFilePath')}
+              />
+            </Field>
+            <Field
+              label="UDP preference limit"
+              description={
+                <span>
+                  Messages to the KDC larger than this limit are sent over TCP. The default is <code>1</code>, meaning
+                  TCP is always used.
+                </span>
+              }
+            >
+              <NumberInput
+                width={LONG_WIDTH}
+                placeholder="1"
+                min={0}
+                value={jsonData.UDPConnectionLimit}
+                onChange={onUDPConnectionLimitChanged}
+              />
+            </Field>
+            <Field
+              htmlFor="enableDNSLookupKDC"
+              label="DNS lookup KDC"
+              description="Find the KDC through DNS SRV records."
+            >
+              <Switch
+                id="enableDNSLookupKDC"
+                value={jsonData.enableDNSLookupKDC !== 'false'}
+                onChange={onEnableDNSLookupKDCChanged}
+              />
+            </Field>
+          </ConfigSubSection>
+        )}
         {config.secureSocksDSProxyEnabled && (
           <SecureSocksProxySettings options={dsSettings} onOptionsChange={onOptionsChange} />
         )}
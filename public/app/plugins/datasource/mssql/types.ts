@@ -6,6 +6,9 @@ export enum MSSQLAuthenticationType {
   sqlAuth = 'SQL Server Authentication',
   windowsAuth = 'Windows Authentication',
   azureAuth = 'Azure AD Authentication',
+  kerberosRaw = 'Windows AD: Username + password',
+  kerberosKeytab = 'Windows AD: Keytab',
+  kerberosCredentialCache = 'Windows AD: Credential cache',
 }
 
 export enum MSSQLEncryptOptions {
@@ -41,6 +44,12 @@ export interface MssqlOptions extends SQLOptions {
   serverName?: string;
   connectionTimeout?: number;
   azureCredentials?: AzureCredentialsType;
+  keytabFilePath?: string;
+  credentialCache?: string;
+  configFilePath?: string;
+  kerberosRealm?: string;
+  UDPConnectionLimit?: number;
+  enableDNSLookupKDC?: string;
 }
 
 export interface MssqlSecureOptions {
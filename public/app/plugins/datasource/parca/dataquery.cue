@@ -32,8 +32,10 @@ composableKinds: DataQuery: {
 				// Specifies the query label selectors.
 				labelSelector: string | *"{}"
 				// Specifies the type of profile to query.
-				profileTypeId:   string
-				#ParcaQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				profileTypeId: string
+				// Sets the number of functions returned by a top functions query.
+				topN?:           int64
+				#ParcaQueryType: "metrics" | "profile" | "topFunctions" | *"both" @cuetsy(kind="type")
 			}
 		}]
 		lenses: []
@@ -10,7 +10,7 @@
 
 import * as common from '@grafana/schema';
 
-export type ParcaQueryType = ('metrics' | 'profile' | 'both');
+export type ParcaQueryType = ('metrics' | 'profile' | 'topFunctions' | 'both');
 
 export const defaultParcaQueryType: ParcaQueryType = 'both';
 
@@ -23,6 +23,10 @@ export interface Parca extends common.DataQuery {
    * Specifies the type of profile to query.
    */
   profileTypeId: string;
+  /**
+   * Sets the number of functions returned by a top functions query.
+   */
+  topN?: number;
 }
 
 export const defaultParca: Partial<Parca> = {